@@ -0,0 +1,186 @@
+// Package archive writes ranges of the chain to a portable archive and reads them back, so that a node
+// can be seeded from a file instead of syncing from the network.
+//
+// An archive is a gzip stream that starts with a header naming the network the blocks belong to,
+// followed by one record per block. A record is the length of its payload, the payload itself and the
+// CRC-32 (Castagnoli) checksum of the payload. The payload is the CBOR encoding of the block, its receipts,
+// its state update and the classes that it introduced to the state. A record with an empty payload marks
+// the end of the archive, so that truncated archives are detected.
+package archive
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/encoder"
+)
+
+const (
+	magic   = "JUNOARCHIVE"
+	version = uint8(1)
+
+	// maxPayloadSize bounds the memory that is allocated for a record before its checksum is verified.
+	maxPayloadSize = 1 << 30
+)
+
+var (
+	ErrNotAnArchive     = errors.New("not a juno archive")
+	ErrUnknownVersion   = errors.New("unknown archive version")
+	ErrChecksumMismatch = errors.New("archive record checksum mismatch")
+	ErrTruncated        = errors.New("archive is truncated")
+	ErrMissingClass     = errors.New("class is neither in the archive nor in the database")
+
+	crcTable = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// entry is everything that is needed to store a block.
+type entry struct {
+	Block       *core.Block
+	StateUpdate *core.StateUpdate
+	Classes     map[felt.Felt]core.Class
+}
+
+type writer struct {
+	gz  *gzip.Writer
+	buf *bufio.Writer
+}
+
+// newWriter writes the header of an archive of blocks of the given network to w.
+func newWriter(w io.Writer, network string) (*writer, error) {
+	gz := gzip.NewWriter(w)
+	aw := &writer{gz: gz, buf: bufio.NewWriter(gz)}
+
+	if _, err := aw.buf.WriteString(magic); err != nil {
+		return nil, err
+	}
+	if err := aw.buf.WriteByte(version); err != nil {
+		return nil, err
+	}
+	if err := aw.writeRecord([]byte(network)); err != nil {
+		return nil, err
+	}
+	return aw, nil
+}
+
+func (w *writer) write(e *entry) error {
+	payload, err := encoder.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxPayloadSize {
+		return fmt.Errorf("block %d does not fit in an archive record", e.Block.Number)
+	}
+	return w.writeRecord(payload)
+}
+
+func (w *writer) writeRecord(payload []byte) error {
+	if err := binary.Write(w.buf, binary.BigEndian, uint32(len(payload))); err != nil {
+		return err
+	}
+	if _, err := w.buf.Write(payload); err != nil {
+		return err
+	}
+	return binary.Write(w.buf, binary.BigEndian, crc32.Checksum(payload, crcTable))
+}
+
+// close writes the end of the archive and flushes it. The underlying writer is not closed.
+func (w *writer) close() error {
+	if err := w.writeRecord(nil); err != nil {
+		return err
+	}
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+	return w.gz.Close()
+}
+
+type reader struct {
+	gz      *gzip.Reader
+	buf     *bufio.Reader
+	network string
+}
+
+// newReader reads the header of the archive in r.
+func newReader(r io.Reader) (*reader, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotAnArchive, err)
+	}
+	ar := &reader{gz: gz, buf: bufio.NewReader(gz)}
+
+	header := make([]byte, len(magic)+1)
+	if _, err = io.ReadFull(ar.buf, header); err != nil || string(header[:len(magic)]) != magic {
+		return nil, ErrNotAnArchive
+	}
+	if header[len(magic)] != version {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownVersion, header[len(magic)])
+	}
+
+	network, err := ar.readRecord()
+	if err != nil {
+		return nil, err
+	}
+	ar.network = string(network)
+	return ar, nil
+}
+
+// next returns the next entry of the archive, or io.EOF once the end of the archive is reached.
+func (r *reader) next() (*entry, error) {
+	payload, err := r.readRecord()
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) == 0 {
+		return nil, io.EOF
+	}
+
+	e := new(entry)
+	if err = encoder.UnmarshalWithJSONMaps(payload, e); err != nil {
+		return nil, err
+	}
+	if e.Block == nil || e.Block.Header == nil || e.StateUpdate == nil || e.StateUpdate.StateDiff == nil {
+		return nil, errors.New("archive record is not a block")
+	}
+	return e, nil
+}
+
+func (r *reader) readRecord() ([]byte, error) {
+	var length uint32
+	if err := binary.Read(r.buf, binary.BigEndian, &length); err != nil {
+		return nil, truncatedOn(err)
+	}
+	if length > maxPayloadSize {
+		return nil, fmt.Errorf("archive record of %d bytes is too large", length)
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r.buf, payload); err != nil {
+		return nil, truncatedOn(err)
+	}
+	var checksum uint32
+	if err := binary.Read(r.buf, binary.BigEndian, &checksum); err != nil {
+		return nil, truncatedOn(err)
+	}
+	if checksum != crc32.Checksum(payload, crcTable) {
+		return nil, ErrChecksumMismatch
+	}
+	return payload, nil
+}
+
+func (r *reader) close() error {
+	return r.gz.Close()
+}
+
+func truncatedOn(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrTruncated
+	}
+	return err
+}
@@ -0,0 +1,194 @@
+package archive_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/NethermindEth/juno/archive"
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/clients/feeder"
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
+	"github.com/NethermindEth/juno/db/pebble"
+	adaptfeeder "github.com/NethermindEth/juno/starknetdata/feeder"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImport(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+	log := utils.NewNopZapLogger()
+	ctx := context.Background()
+
+	source := blockchain.New(pebble.NewMemTest(), utils.MAINNET, log)
+	for number := uint64(0); number < 3; number++ {
+		block, err := gw.BlockByNumber(ctx, number)
+		require.NoError(t, err)
+		update, err := gw.StateUpdate(ctx, number)
+		require.NoError(t, err)
+		classes := make(map[felt.Felt]core.Class)
+		for _, classHash := range update.StateDiff.ClassHashes() {
+			classes[*classHash], err = gw.Class(ctx, classHash)
+			require.NoError(t, err)
+		}
+		require.NoError(t, source.Store(block, update, classes))
+	}
+
+	export := func(t *testing.T, from, to uint64) []byte {
+		t.Helper()
+
+		var archived bytes.Buffer
+		require.NoError(t, archive.Export(ctx, source, from, to, &archived, log))
+		return archived.Bytes()
+	}
+	// withBlocks returns a chain that holds the blocks of the source up to the given number, excluded
+	withBlocks := func(t *testing.T, count uint64) *blockchain.Blockchain {
+		t.Helper()
+
+		chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET, log)
+		if count > 0 {
+			imported, err := archive.Import(ctx, chain, bytes.NewReader(export(t, 0, count-1)), log)
+			require.NoError(t, err)
+			require.Equal(t, count, imported)
+		}
+		return chain
+	}
+	assertSameHead := func(t *testing.T, chain *blockchain.Blockchain) {
+		t.Helper()
+
+		for number := uint64(0); number < 3; number++ {
+			expectedBlock, err := source.BlockByNumber(number)
+			require.NoError(t, err)
+			block, err := chain.BlockByNumber(number)
+			require.NoError(t, err)
+			assert.Equal(t, expectedBlock, block)
+
+			expectedUpdate, err := source.StateUpdateByNumber(number)
+			require.NoError(t, err)
+			update, err := chain.StateUpdateByNumber(number)
+			require.NoError(t, err)
+			assert.Equal(t, expectedUpdate, update)
+		}
+		expectedRoot, err := source.StateCommitment()
+		require.NoError(t, err)
+		root, err := chain.StateCommitment()
+		require.NoError(t, err)
+		assert.Equal(t, expectedRoot, root)
+	}
+
+	t.Run("invalid ranges", func(t *testing.T) {
+		require.Error(t, archive.Export(ctx, source, 2, 1, io.Discard, log))
+		require.Error(t, archive.Export(ctx, source, 0, 3, io.Discard, log))
+	})
+
+	t.Run("into an empty database", func(t *testing.T) {
+		chain := withBlocks(t, 3)
+		assertSameHead(t, chain)
+	})
+
+	t.Run("continuing a database", func(t *testing.T) {
+		chain := withBlocks(t, 1)
+		imported, err := archive.Import(ctx, chain, bytes.NewReader(export(t, 1, 2)), log)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), imported)
+		assertSameHead(t, chain)
+	})
+
+	t.Run("overlapping the database", func(t *testing.T) {
+		chain := withBlocks(t, 2)
+		imported, err := archive.Import(ctx, chain, bytes.NewReader(export(t, 0, 2)), log)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), imported)
+		assertSameHead(t, chain)
+	})
+
+	t.Run("range that does not continue the database", func(t *testing.T) {
+		chain := withBlocks(t, 0)
+		imported, err := archive.Import(ctx, chain, bytes.NewReader(export(t, 1, 2)), log)
+		require.Error(t, err)
+		assert.Zero(t, imported)
+	})
+
+	t.Run("classes declared before the archive are not in the database", func(t *testing.T) {
+		testDB := pebble.NewMemTest()
+		chain := blockchain.New(testDB, utils.MAINNET, log)
+		imported, err := archive.Import(ctx, chain, bytes.NewReader(export(t, 0, 0)), log)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), imported)
+
+		update, err := source.StateUpdateByNumber(1)
+		require.NoError(t, err)
+		require.NoError(t, testDB.Update(func(txn db.Transaction) error {
+			for _, classHash := range update.StateDiff.ClassHashes() {
+				if err := txn.Delete(db.Class.Key(classHash.Marshal())); err != nil {
+					return err
+				}
+			}
+			return nil
+		}))
+
+		imported, err = archive.Import(ctx, chain, bytes.NewReader(export(t, 1, 2)), log)
+		require.ErrorIs(t, err, archive.ErrMissingClass)
+		assert.Zero(t, imported)
+	})
+
+	t.Run("another network", func(t *testing.T) {
+		chain := blockchain.New(pebble.NewMemTest(), utils.GOERLI, log)
+		_, err := archive.Import(ctx, chain, bytes.NewReader(export(t, 0, 2)), log)
+		require.Error(t, err)
+	})
+
+	t.Run("not an archive", func(t *testing.T) {
+		chain := withBlocks(t, 0)
+		_, err := archive.Import(ctx, chain, bytes.NewReader([]byte("not an archive")), log)
+		require.ErrorIs(t, err, archive.ErrNotAnArchive)
+	})
+
+	// decompressed returns the archive without its compression, so that it can be damaged in ways that
+	// the compression does not detect
+	decompressed := func(t *testing.T, archived []byte) []byte {
+		t.Helper()
+
+		gz, err := gzip.NewReader(bytes.NewReader(archived))
+		require.NoError(t, err)
+		raw, err := io.ReadAll(gz)
+		require.NoError(t, err)
+		return raw
+	}
+	compressed := func(t *testing.T, raw []byte) []byte {
+		t.Helper()
+
+		var archived bytes.Buffer
+		gz := gzip.NewWriter(&archived)
+		_, err := gz.Write(raw)
+		require.NoError(t, err)
+		require.NoError(t, gz.Close())
+		return archived.Bytes()
+	}
+
+	t.Run("corrupted archive", func(t *testing.T) {
+		raw := decompressed(t, export(t, 0, 2))
+		raw[len(raw)/2]++
+
+		chain := withBlocks(t, 0)
+		_, err := archive.Import(ctx, chain, bytes.NewReader(compressed(t, raw)), log)
+		require.ErrorIs(t, err, archive.ErrChecksumMismatch)
+	})
+
+	t.Run("truncated archive", func(t *testing.T) {
+		raw := decompressed(t, export(t, 0, 2))
+
+		chain := withBlocks(t, 0)
+		// only the end of archive record is missing, so all the blocks are stored
+		imported, err := archive.Import(ctx, chain, bytes.NewReader(compressed(t, raw[:len(raw)-8])), log)
+		require.ErrorIs(t, err, archive.ErrTruncated)
+		assert.Equal(t, uint64(3), imported)
+	})
+}
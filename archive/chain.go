@@ -0,0 +1,203 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
+	"github.com/NethermindEth/juno/utils"
+)
+
+// progressInterval is the number of blocks between progress logs.
+const progressInterval = 1000
+
+// Export writes the blocks from `from` to `to`, both included, to an archive in w. Along with each block,
+// the archive holds the classes that the block introduced to the state, unless they were introduced
+// before `from`: those are expected to be in the database that the archive is imported into.
+func Export(ctx context.Context, chain *blockchain.Blockchain, from, to uint64, w io.Writer, log utils.SimpleLogger) error {
+	if from > to {
+		return fmt.Errorf("invalid block range: %d > %d", from, to)
+	}
+	height, err := chain.Height()
+	if err != nil {
+		return err
+	}
+	if to > height {
+		return fmt.Errorf("block %d is beyond the head %d", to, height)
+	}
+
+	state, closer, err := chain.HeadState()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := closer(); closeErr != nil {
+			log.Errorw("Error closing the head state", "err", closeErr)
+		}
+	}()
+
+	aw, err := newWriter(w, chain.Network().String())
+	if err != nil {
+		return err
+	}
+
+	exported := make(map[felt.Felt]struct{})
+	for number := from; number <= to; number++ {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		e := &entry{Classes: make(map[felt.Felt]core.Class)}
+		if e.Block, err = chain.BlockByNumber(number); err != nil {
+			return err
+		}
+		if e.StateUpdate, err = chain.StateUpdateByNumber(number); err != nil {
+			return err
+		}
+		for _, classHash := range e.StateUpdate.StateDiff.ClassHashes() {
+			if _, ok := exported[*classHash]; ok {
+				continue
+			}
+
+			var declared *core.DeclaredClass
+			if declared, err = state.Class(classHash); err != nil {
+				return fmt.Errorf("class %s of block %d: %w", classHash, number, err)
+			}
+			if declared.At >= from {
+				e.Classes[*classHash] = declared.Class
+				exported[*classHash] = struct{}{}
+			}
+		}
+
+		if err = aw.write(e); err != nil {
+			return err
+		}
+		if number%progressInterval == 0 {
+			log.Infow("Exported block", "number", number)
+		}
+	}
+	return aw.close()
+}
+
+// Import stores the blocks of the archive in r in the chain. The blocks go through the same checks as
+// synced blocks, and storing them re-verifies the state root of every block. The blocks of the archive
+// that are already stored are skipped, as long as they match the stored ones. The classes that a block
+// references but the archive does not hold must already be in the database. The number of blocks that
+// were stored is returned.
+func Import(ctx context.Context, chain *blockchain.Blockchain, r io.Reader, log utils.SimpleLogger) (uint64, error) {
+	ar, err := newReader(r)
+	if err != nil {
+		return 0, err
+	}
+	if network := chain.Network().String(); ar.network != network {
+		return 0, fmt.Errorf("archive of network %q can not be imported into %q", ar.network, network)
+	}
+
+	next, err := nextHeight(chain)
+	if err != nil {
+		return 0, err
+	}
+
+	var imported uint64
+	for {
+		if err = ctx.Err(); err != nil {
+			return imported, err
+		}
+
+		var e *entry
+		if e, err = ar.next(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return imported, ar.close()
+			}
+			return imported, err
+		}
+
+		number := e.Block.Number
+		if number < next {
+			if err = checkStored(chain, e.Block); err != nil {
+				return imported, err
+			}
+			continue
+		} else if number > next {
+			return imported, fmt.Errorf("archive continues at block %d but the next block is %d", number, next)
+		}
+
+		if err = checkClasses(chain, e); err != nil {
+			return imported, fmt.Errorf("block %d: %w", number, err)
+		}
+		if err = chain.SanityCheckNewHeight(e.Block, e.StateUpdate, e.Classes); err != nil {
+			return imported, fmt.Errorf("block %d: %w", number, err)
+		}
+		if err = chain.Store(e.Block, e.StateUpdate, e.Classes); err != nil {
+			return imported, fmt.Errorf("block %d: %w", number, err)
+		}
+
+		next++
+		imported++
+		if number%progressInterval == 0 {
+			log.Infow("Imported block", "number", number)
+		}
+	}
+}
+
+func nextHeight(chain *blockchain.Blockchain) (uint64, error) {
+	height, err := chain.Height()
+	if errors.Is(err, db.ErrKeyNotFound) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return height + 1, nil
+}
+
+// checkClasses checks that the classes that the block references but the archive does not hold, because
+// they were declared before the start of the archive, are in the database.
+func checkClasses(chain *blockchain.Blockchain, e *entry) (err error) {
+	var referenced []*felt.Felt
+	for _, classHash := range e.StateUpdate.StateDiff.ClassHashes() {
+		if _, ok := e.Classes[*classHash]; !ok {
+			referenced = append(referenced, classHash)
+		}
+	}
+	if len(referenced) == 0 {
+		return nil
+	}
+
+	state, closer, err := chain.HeadState()
+	if errors.Is(err, db.ErrKeyNotFound) {
+		// the database is empty
+		return fmt.Errorf("%w: %s", ErrMissingClass, referenced[0])
+	} else if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := closer(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	for _, classHash := range referenced {
+		if _, err = state.Class(classHash); errors.Is(err, db.ErrKeyNotFound) {
+			return fmt.Errorf("%w: %s", ErrMissingClass, classHash)
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkStored(chain *blockchain.Blockchain, block *core.Block) error {
+	stored, err := chain.BlockHeaderByNumber(block.Number)
+	if err != nil {
+		return err
+	}
+	if !stored.Hash.Equal(block.Hash) {
+		return fmt.Errorf("block %d of the archive does not match the stored one", block.Number)
+	}
+	return nil
+}
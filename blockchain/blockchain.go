@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"encoding/binary"
 	"errors"
+	"sync"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/NethermindEth/juno/core"
 	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/core/trie"
 	"github.com/NethermindEth/juno/db"
 	"github.com/NethermindEth/juno/encoder"
 	"github.com/NethermindEth/juno/utils"
@@ -32,8 +34,30 @@ type Reader interface {
 	Receipt(hash *felt.Felt) (receipt *core.TransactionReceipt, blockHash *felt.Felt, blockNumber uint64, err error)
 	StateUpdateByNumber(number uint64) (update *core.StateUpdate, err error)
 	StateUpdateByHash(hash *felt.Felt) (update *core.StateUpdate, err error)
+
+	HeadState() (core.StateReader, StateCloser, error)
+	StateProof(blockNumber uint64, addr *felt.Felt, keys []*felt.Felt) (*core.StateProof, *core.Header, error)
+	HeadContractStorage(addr, start *felt.Felt, fn trie.LeafFunc) (*core.Header, error)
+	StateAtBlockHash(blockHash *felt.Felt) (core.StateReader, StateCloser, error)
+	StateAtBlockNumber(blockNumber uint64) (core.StateReader, StateCloser, error)
+
+	Events(filter *EventFilter, token *ContinuationToken, chunkSize uint64) ([]*FilteredEvent, *ContinuationToken, error)
+
+	Pending() (*Pending, error)
+	PendingState() (core.StateReader, StateCloser, error)
 }
 
+// StateCloser releases the resources held by a [core.StateReader] returned from a [Reader].
+type StateCloser = func() error
+
+var ErrParentDoesNotMatchHead = errors.New("block's parent hash does not match head block hash")
+
+// ErrStateProofTooDeep is returned when the state of a block too far behind the head is to be proved.
+var ErrStateProofTooDeep = errors.New("block is too far behind the head to prove its state")
+
+// maxStateProofDepth bounds the blocks reverted in memory to prove the state of an older block.
+const maxStateProofDepth = 128
+
 var supportedStarknetVersion = semver.MustParse("0.11.0")
 
 func checkBlockVersion(protocolVersion string) error {
@@ -60,18 +84,34 @@ type Blockchain struct {
 	network  utils.Network
 	database db.DB
 
+	pendingLock sync.RWMutex
+	pending     *Pending
+
+	feed *feed
+
+	// the most blocks that a single call to Events scans for matching events
+	maxEventScanBlocks uint64
+
 	log utils.SimpleLogger
 }
 
 func New(database db.DB, network utils.Network, log utils.SimpleLogger) *Blockchain {
 	registerCoreTypesToEncoder()
 	return &Blockchain{
-		database: database,
-		network:  network,
-		log:      log,
+		database:           database,
+		network:            network,
+		feed:               newFeed(),
+		maxEventScanBlocks: defaultMaxEventScanBlocks,
+		log:                log,
 	}
 }
 
+// WithMaxEventScanBlocks sets the most blocks that a single call to Events scans for matching events.
+func (b *Blockchain) WithMaxEventScanBlocks(maxBlocks uint64) *Blockchain {
+	b.maxEventScanBlocks = maxBlocks
+	return b
+}
+
 func (b *Blockchain) Network() utils.Network {
 	return b.network
 }
@@ -105,6 +145,48 @@ func (b *Blockchain) height(txn db.Transaction) (uint64, error) {
 	})
 }
 
+// L1Height returns the number of the highest stored block that is known to be accepted on L1.
+// [db.ErrKeyNotFound] is returned if there is none.
+func (b *Blockchain) L1Height() (uint64, error) {
+	var height uint64
+	return height, b.database.View(func(txn db.Transaction) error {
+		var err error
+		height, err = l1Height(txn)
+		return err
+	})
+}
+
+func l1Height(txn db.Transaction) (uint64, error) {
+	var height uint64
+	return height, txn.Get(db.L1Height.Key(), func(val []byte) error {
+		height = binary.BigEndian.Uint64(val)
+		return nil
+	})
+}
+
+// updateL1Height keeps track of the highest block accepted on L1 as the status of the block with the given
+// number is stored, accepted tells whether it is accepted on L1:
+// [db.L1Height]() -> (BlockNumber)
+func updateL1Height(txn db.Transaction, number uint64, accepted bool) error {
+	height, err := l1Height(txn)
+	if err != nil && !errors.Is(err, db.ErrKeyNotFound) {
+		return err
+	}
+	known := err == nil
+
+	switch {
+	case accepted && (!known || number > height):
+		return txn.Set(db.L1Height.Key(), blockNumberKey(number))
+	case !accepted && known && number <= height:
+		if number == 0 {
+			return txn.Delete(db.L1Height.Key())
+		}
+		return txn.Set(db.L1Height.Key(), blockNumberKey(number-1))
+	default:
+		return nil
+	}
+}
+
 func (b *Blockchain) Head() (*core.Block, error) {
 	var head *core.Block
 	return head, b.database.View(func(txn db.Transaction) error {
@@ -227,18 +309,126 @@ func (b *Blockchain) Receipt(hash *felt.Felt) (*core.TransactionReceipt, *felt.F
 	})
 }
 
+// HeadState returns a StateReader that provides a stable view of the latest state.
+// The returned StateCloser must be called once the StateReader is no longer needed.
+func (b *Blockchain) HeadState() (core.StateReader, StateCloser, error) {
+	txn := b.database.NewTransaction(false)
+	if _, err := b.height(txn); err != nil {
+		return nil, nil, db.CloseAndWrapOnError(txn.Discard, err)
+	}
+
+	return core.NewState(txn), txn.Discard, nil
+}
+
+// StateProof proves the state of the contract at the given address and the values of the given storage
+// locations of the contract against the state commitment of the block with the given number, whose header
+// is returned along with the proof. The tries are only kept for the head, so they are rebuilt in memory for
+// older blocks by reverting the blocks after them, which is why only the blocks at most
+// maxStateProofDepth blocks behind the head can be proved.
+func (b *Blockchain) StateProof(blockNumber uint64, addr *felt.Felt, keys []*felt.Felt) (*core.StateProof,
+	*core.Header, error,
+) {
+	// the reverts are never committed, so they do not hold up the writers
+	txn := db.NewOverlay(b.database.NewTransaction(false))
+	height, err := b.height(txn)
+	if err != nil {
+		return nil, nil, db.CloseAndWrapOnError(txn.Discard, err)
+	} else if blockNumber > height {
+		return nil, nil, db.CloseAndWrapOnError(txn.Discard, db.ErrKeyNotFound)
+	} else if height-blockNumber > maxStateProofDepth {
+		return nil, nil, db.CloseAndWrapOnError(txn.Discard, ErrStateProofTooDeep)
+	}
+
+	state := core.NewState(txn)
+	for number := height; number > blockNumber; number-- {
+		var stateUpdate *core.StateUpdate
+		if stateUpdate, err = stateUpdateByNumber(txn, number); err != nil {
+			return nil, nil, db.CloseAndWrapOnError(txn.Discard, err)
+		}
+		if err = state.Revert(number, stateUpdate); err != nil {
+			return nil, nil, db.CloseAndWrapOnError(txn.Discard, err)
+		}
+	}
+
+	header, err := blockHeaderByNumber(txn, blockNumber)
+	if err != nil {
+		return nil, nil, db.CloseAndWrapOnError(txn.Discard, err)
+	}
+	proof, err := state.Prove(addr, keys)
+	if err != nil {
+		return nil, nil, db.CloseAndWrapOnError(txn.Discard, err)
+	}
+	return proof, header, txn.Discard()
+}
+
+// HeadContractStorage calls fn with the storage locations of the contract at the given address that hold a
+// value in the state of the head, starting at the given key, in ascending order of keys, until fn returns
+// false or an error. The header of the head is returned, the iteration is consistent with it.
+func (b *Blockchain) HeadContractStorage(addr, start *felt.Felt, fn trie.LeafFunc) (*core.Header, error) {
+	var header *core.Header
+	err := b.database.View(func(txn db.Transaction) error {
+		height, err := b.height(txn)
+		if err != nil {
+			return err
+		}
+
+		if header, err = blockHeaderByNumber(txn, height); err != nil {
+			return err
+		}
+		return core.NewState(txn).IterateContractStorage(addr, start, fn)
+	})
+	return header, err
+}
+
+// StateAtBlockNumber returns a StateReader that provides a stable view of the state as it was
+// after the block with the given number was applied.
+// The returned StateCloser must be called once the StateReader is no longer needed.
+func (b *Blockchain) StateAtBlockNumber(blockNumber uint64) (core.StateReader, StateCloser, error) {
+	txn := b.database.NewTransaction(false)
+	if _, err := blockHeaderByNumber(txn, blockNumber); err != nil {
+		return nil, nil, db.CloseAndWrapOnError(txn.Discard, err)
+	}
+
+	return core.NewStateSnapshot(core.NewState(txn), blockNumber), txn.Discard, nil
+}
+
+// StateAtBlockHash returns a StateReader that provides a stable view of the state as it was
+// after the block with the given hash was applied.
+// The returned StateCloser must be called once the StateReader is no longer needed.
+func (b *Blockchain) StateAtBlockHash(blockHash *felt.Felt) (core.StateReader, StateCloser, error) {
+	txn := b.database.NewTransaction(false)
+	header, err := blockHeaderByHash(txn, blockHash)
+	if err != nil {
+		return nil, nil, db.CloseAndWrapOnError(txn.Discard, err)
+	}
+
+	return core.NewStateSnapshot(core.NewState(txn), header.Number), txn.Discard, nil
+}
+
+// Subscribe returns a subscription to the events published as blocks are stored and reverted. Up to
+// bufferSize events are buffered for the subscriber, the policy decides what happens once it is full.
+func (b *Blockchain) Subscribe(bufferSize int, policy SlowConsumerPolicy) *Subscription {
+	return b.feed.subscribe(bufferSize, policy)
+}
+
 // Store takes a block and state update and performs sanity checks before putting in the database.
 func (b *Blockchain) Store(block *core.Block, stateUpdate *core.StateUpdate, declaredClasses map[felt.Felt]core.Class) error {
-	return b.database.Update(func(txn db.Transaction) error {
+	err := b.database.Update(func(txn db.Transaction) error {
 		if err := b.verifyBlock(txn, block); err != nil {
 			return err
 		}
-		if err := core.NewState(txn).Update(stateUpdate, declaredClasses); err != nil {
+		if err := verifyClassesAvailable(txn, stateUpdate.StateDiff, declaredClasses); err != nil {
+			return err
+		}
+		if err := core.NewState(txn).Update(block.Number, stateUpdate, declaredClasses); err != nil {
 			return err
 		}
 		if err := storeBlockHeader(txn, block.Header); err != nil {
 			return err
 		}
+		if err := updateL1Height(txn, block.Number, block.Status == core.BlockAcceptedOnL1); err != nil {
+			return err
+		}
 		for i, tx := range block.Transactions {
 			if err := storeTransactionAndReceipt(txn, block.Number, uint64(i), tx,
 				block.Receipts[i]); err != nil {
@@ -250,12 +440,156 @@ func (b *Blockchain) Store(block *core.Block, stateUpdate *core.StateUpdate, dec
 			return err
 		}
 
+		if err := storeEventsBloom(txn, block.Number, block.Receipts); err != nil {
+			return err
+		}
+
 		// Head of the blockchain is maintained as follows:
 		// [db.ChainHeight]() -> (BlockNumber)
 		heightBin := make([]byte, lenOfByteSlice)
 		binary.BigEndian.PutUint64(heightBin, block.Number)
 		return txn.Set(db.ChainHeight.Key(), heightBin)
 	})
+	if err != nil {
+		return err
+	}
+
+	b.feed.publish(&BlockStored{
+		Block:       block,
+		StateUpdate: stateUpdate,
+		NewClasses:  declaredClasses,
+	})
+	return nil
+}
+
+// RevertHead reverts the head block: its state update is undone and the block, its transactions,
+// receipts and state update are removed. The parent block becomes the new head.
+func (b *Blockchain) RevertHead() error {
+	reverted := new(HeadReverted)
+	err := b.database.Update(func(txn db.Transaction) error {
+		blockNumber, err := b.height(txn)
+		if err != nil {
+			return err
+		}
+
+		stateUpdate, err := stateUpdateByNumber(txn, blockNumber)
+		if err != nil {
+			return err
+		}
+
+		if err = core.NewState(txn).Revert(blockNumber, stateUpdate); err != nil {
+			return err
+		}
+
+		block, err := blockByNumber(txn, blockNumber)
+		if err != nil {
+			return err
+		}
+		reverted.Block, reverted.StateUpdate = block, stateUpdate
+
+		if err = updateL1Height(txn, blockNumber, false); err != nil {
+			return err
+		}
+
+		if err = removeTransactionsAndReceipts(txn, blockNumber); err != nil {
+			return err
+		}
+
+		numBytes := blockNumberKey(blockNumber)
+		for _, key := range [][]byte{
+			db.BlockHeadersByNumber.Key(numBytes),
+			db.BlockHeaderNumbersByHash.Key(block.Hash.Marshal()),
+			db.StateUpdatesByBlockNumber.Key(numBytes),
+			db.EventsBloomByBlockNumber.Key(numBytes),
+		} {
+			if err = txn.Delete(key); err != nil {
+				return err
+			}
+		}
+
+		if blockNumber == 0 {
+			return txn.Delete(db.ChainHeight.Key())
+		}
+		return txn.Set(db.ChainHeight.Key(), blockNumberKey(blockNumber-1))
+	})
+	if err != nil {
+		return err
+	}
+
+	b.feed.publish(reverted)
+	return nil
+}
+
+// SetBlockStatus updates the status of the stored block with the given number.
+func (b *Blockchain) SetBlockStatus(number uint64, status core.BlockStatus) error {
+	var changed *core.Header
+	err := b.database.Update(func(txn db.Transaction) error {
+		header, err := blockHeaderByNumber(txn, number)
+		if err != nil {
+			return err
+		}
+		if header.Status == status {
+			return nil
+		}
+
+		header.Status = status
+		if err = storeBlockHeader(txn, header); err != nil {
+			return err
+		}
+		if err = updateL1Height(txn, number, status == core.BlockAcceptedOnL1); err != nil {
+			return err
+		}
+		changed = header
+		return nil
+	})
+	if err != nil || changed == nil {
+		return err
+	}
+
+	b.feed.publish(&BlockStatusChanged{Header: changed})
+	return nil
+}
+
+// SetAcceptedOnL1 marks the stored block with the given number, and the blocks before it that are not
+// accepted on L1 yet, as accepted on L1 in a single transaction, as blocks are accepted on L1 in order.
+func (b *Blockchain) SetAcceptedOnL1(number uint64) error {
+	var changed []*core.Header
+	err := b.database.Update(func(txn db.Transaction) error {
+		height, err := l1Height(txn)
+		known := err == nil
+		if err != nil && !errors.Is(err, db.ErrKeyNotFound) {
+			return err
+		}
+
+		for n := number; !known || n > height; n-- {
+			var header *core.Header
+			if header, err = blockHeaderByNumber(txn, n); err != nil {
+				return err
+			}
+			if header.Status == core.BlockAcceptedOnL1 {
+				break
+			}
+
+			header.Status = core.BlockAcceptedOnL1
+			if err = storeBlockHeader(txn, header); err != nil {
+				return err
+			}
+			changed = append(changed, header)
+
+			if n == 0 {
+				break
+			}
+		}
+		return updateL1Height(txn, number, true)
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := len(changed) - 1; i >= 0; i-- {
+		b.feed.publish(&BlockStatusChanged{Header: changed[i]})
+	}
+	return nil
 }
 
 // VerifyBlock assumes the block has already been sanity-checked.
@@ -287,7 +621,7 @@ func (b *Blockchain) verifyBlock(txn db.Transaction, block *core.Block) error {
 			return errors.New("block number difference between head and incoming block is not 1")
 		}
 		if !block.ParentHash.Equal(head.Hash) {
-			return errors.New("block's parent hash does not match head block hash")
+			return ErrParentDoesNotMatchHead
 		}
 	}
 
@@ -383,7 +717,38 @@ func blockByNumber(txn db.Transaction, number uint64) (*core.Block, error) {
 		block.Transactions = append(block.Transactions, tx)
 	}
 
-	prefix = db.ReceiptsByBlockNumberAndIndex.Key(numBytes)
+	if err = iterator.Close(); err != nil {
+		return nil, err
+	}
+
+	if block.Receipts, err = receiptsByBlockNumber(txn, number); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// blockByHash retrieves a block from database by its hash
+func blockByHash(txn db.Transaction, hash *felt.Felt) (*core.Block, error) {
+	var block *core.Block
+	return block, txn.Get(db.BlockHeaderNumbersByHash.Key(hash.Marshal()), func(val []byte) error {
+		var err error
+		block, err = blockByNumber(txn, binary.BigEndian.Uint64(val))
+		return err
+	})
+}
+
+// receiptsByBlockNumber retrieves the receipts of all transactions in a block, in order
+func receiptsByBlockNumber(txn db.Transaction, number uint64) ([]*core.TransactionReceipt, error) {
+	iterator, err := txn.NewIterator()
+	if err != nil {
+		return nil, err
+	}
+
+	numBytes := make([]byte, lenOfByteSlice)
+	binary.BigEndian.PutUint64(numBytes, number)
+
+	var receipts []*core.TransactionReceipt
+	prefix := db.ReceiptsByBlockNumberAndIndex.Key(numBytes)
 	for iterator.Seek(prefix); iterator.Valid(); iterator.Next() {
 		if !bytes.Equal(iterator.Key()[:len(prefix)], prefix) {
 			break
@@ -399,23 +764,13 @@ func blockByNumber(txn db.Transaction, number uint64) (*core.Block, error) {
 			return nil, db.CloseAndWrapOnError(iterator.Close, err)
 		}
 
-		block.Receipts = append(block.Receipts, receipt)
+		receipts = append(receipts, receipt)
 	}
 
-	if err := iterator.Close(); err != nil {
+	if err = iterator.Close(); err != nil {
 		return nil, err
 	}
-	return block, nil
-}
-
-// blockByHash retrieves a block from database by its hash
-func blockByHash(txn db.Transaction, hash *felt.Felt) (*core.Block, error) {
-	var block *core.Block
-	return block, txn.Get(db.BlockHeaderNumbersByHash.Key(hash.Marshal()), func(val []byte) error {
-		var err error
-		block, err = blockByNumber(txn, binary.BigEndian.Uint64(val))
-		return err
-	})
+	return receipts, nil
 }
 
 func storeStateUpdate(txn db.Transaction, blockNumber uint64, update *core.StateUpdate) error {
@@ -476,6 +831,10 @@ func (b *Blockchain) SanityCheckNewHeight(block *core.Block, stateUpdate *core.S
 		}
 	}
 
+	if err := core.VerifyCompiledClassHashes(stateUpdate.StateDiff, classes); err != nil {
+		return err
+	}
+
 	if bErr := core.VerifyBlockHash(block, b.network); bErr != nil {
 		if errors.As(bErr, new(core.CantVerifyTransactionHashError)) {
 			for ; bErr != nil; bErr = errors.Unwrap(bErr) {
@@ -545,6 +904,29 @@ func storeTransactionAndReceipt(txn db.Transaction, number, i uint64, t core.Tra
 	return nil
 }
 
+// removeTransactionsAndReceipts removes all transactions and receipts of a block, along with
+// the index entries that point to them.
+func removeTransactionsAndReceipts(txn db.Transaction, blockNumber uint64) error {
+	receipts, err := receiptsByBlockNumber(txn, blockNumber)
+	if err != nil {
+		return err
+	}
+
+	for i, receipt := range receipts {
+		bnIndexBytes := (&txAndReceiptDBKey{blockNumber, uint64(i)}).MarshalBinary()
+		for _, key := range [][]byte{
+			db.TransactionBlockNumbersAndIndicesByHash.Key(receipt.TransactionHash.Marshal()),
+			db.TransactionsByBlockNumberAndIndex.Key(bnIndexBytes),
+			db.ReceiptsByBlockNumberAndIndex.Key(bnIndexBytes),
+		} {
+			if err = txn.Delete(key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // transactionBlockNumberAndIndexByHash gets the block number and index for a given transaction hash
 func transactionBlockNumberAndIndexByHash(txn db.Transaction, hash *felt.Felt) (*txAndReceiptDBKey, error) {
 	var bnIndex *txAndReceiptDBKey
@@ -12,6 +12,7 @@ import (
 	"github.com/NethermindEth/juno/core/felt"
 	"github.com/NethermindEth/juno/db"
 	"github.com/NethermindEth/juno/db/pebble"
+	"github.com/NethermindEth/juno/starknetdata"
 	adaptfeeder "github.com/NethermindEth/juno/starknetdata/feeder"
 	"github.com/NethermindEth/juno/utils"
 	"github.com/stretchr/testify/assert"
@@ -39,7 +40,7 @@ func TestNew(t *testing.T) {
 
 		testDB := pebble.NewMemTest()
 		chain := blockchain.New(testDB, utils.MAINNET, log)
-		assert.NoError(t, chain.Store(block0, stateUpdate0, nil))
+		assert.NoError(t, chain.Store(block0, stateUpdate0, referencedClasses(t, gw, stateUpdate0)))
 
 		chain = blockchain.New(testDB, utils.MAINNET, log)
 		b, err := chain.Head()
@@ -67,7 +68,7 @@ func TestHeight(t *testing.T) {
 
 		testDB := pebble.NewMemTest()
 		chain := blockchain.New(testDB, utils.MAINNET, log)
-		assert.NoError(t, chain.Store(block0, stateUpdate0, nil))
+		assert.NoError(t, chain.Store(block0, stateUpdate0, referencedClasses(t, gw, stateUpdate0)))
 
 		chain = blockchain.New(testDB, utils.MAINNET, log)
 		height, err := chain.Height()
@@ -88,7 +89,7 @@ func TestBlockByNumberAndHash(t *testing.T) {
 		update, err := gw.StateUpdate(context.Background(), 0)
 		require.NoError(t, err)
 
-		require.NoError(t, chain.Store(block, update, nil))
+		require.NoError(t, chain.Store(block, update, referencedClasses(t, gw, update)))
 
 		storedByNumber, err := chain.BlockByNumber(block.Number)
 		require.NoError(t, err)
@@ -138,18 +139,18 @@ func TestVerifyBlock(t *testing.T) {
 
 	t.Run("error if version is invalid", func(t *testing.T) {
 		mainnetBlock0.ProtocolVersion = "notasemver"
-		require.Error(t, chain.Store(mainnetBlock0, mainnetStateUpdate0, nil))
+		require.Error(t, chain.Store(mainnetBlock0, mainnetStateUpdate0, referencedClasses(t, gw, mainnetStateUpdate0)))
 	})
 
 	t.Run("error if version is unsupported", func(t *testing.T) {
 		mainnetBlock0.ProtocolVersion = "99.0.0"
 		semver.MustParse(mainnetBlock0.ProtocolVersion)
-		require.Error(t, chain.Store(mainnetBlock0, mainnetStateUpdate0, nil))
+		require.Error(t, chain.Store(mainnetBlock0, mainnetStateUpdate0, referencedClasses(t, gw, mainnetStateUpdate0)))
 	})
 
 	t.Run("no error with no version string", func(t *testing.T) {
 		mainnetBlock0.ProtocolVersion = ""
-		require.NoError(t, chain.Store(mainnetBlock0, mainnetStateUpdate0, nil))
+		require.NoError(t, chain.Store(mainnetBlock0, mainnetStateUpdate0, referencedClasses(t, gw, mainnetStateUpdate0)))
 	})
 
 	t.Run("error if difference between incoming block number and head is not 1",
@@ -180,7 +181,7 @@ func TestSanityCheckNewHeight(t *testing.T) {
 	mainnetStateUpdate0, err := gw.StateUpdate(context.Background(), 0)
 	require.NoError(t, err)
 
-	require.NoError(t, chain.Store(mainnetBlock0, mainnetStateUpdate0, nil))
+	require.NoError(t, chain.Store(mainnetBlock0, mainnetStateUpdate0, referencedClasses(t, gw, mainnetStateUpdate0)))
 
 	t.Run("error when block hash does not match state update's block hash", func(t *testing.T) {
 		mainnetBlock1, err := gw.BlockByNumber(context.Background(), 1)
@@ -199,6 +200,28 @@ func TestSanityCheckNewHeight(t *testing.T) {
 			assert.EqualError(t, chain.SanityCheckNewHeight(mainnetBlock1, stateUpdate, nil),
 				"block's GlobalStateRoot does not match state update's NewRoot")
 		})
+
+	t.Run("error when compiled class hash does not match the declared compiled class hash", func(t *testing.T) {
+		mainnetBlock1, err := gw.BlockByNumber(context.Background(), 1)
+		require.NoError(t, err)
+
+		compiled := &core.CompiledClass{Bytecode: []*felt.Felt{h1}}
+		class := &core.Cairo1Class{AbiHash: new(felt.Felt), ProgramHash: new(felt.Felt), Compiled: compiled}
+		classes := map[felt.Felt]core.Class{*h1: class}
+		declare := func(compiledClassHash *felt.Felt) *core.StateUpdate {
+			return &core.StateUpdate{
+				BlockHash: mainnetBlock1.Hash,
+				NewRoot:   mainnetBlock1.GlobalStateRoot,
+				StateDiff: &core.StateDiff{
+					DeclaredV1Classes: []core.DeclaredV1Class{{ClassHash: h1, CompiledClassHash: compiledClassHash}},
+				},
+			}
+		}
+
+		assert.ErrorIs(t, chain.SanityCheckNewHeight(mainnetBlock1, declare(new(felt.Felt)), classes),
+			core.ErrCompiledClassHashMismatch)
+		assert.NoError(t, chain.SanityCheckNewHeight(mainnetBlock1, declare(compiled.Hash()), classes))
+	})
 }
 
 func TestStore(t *testing.T) {
@@ -215,7 +238,7 @@ func TestStore(t *testing.T) {
 
 	t.Run("add block to empty blockchain", func(t *testing.T) {
 		chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET, log)
-		require.NoError(t, chain.Store(block0, stateUpdate0, nil))
+		require.NoError(t, chain.Store(block0, stateUpdate0, referencedClasses(t, gw, stateUpdate0)))
 
 		headBlock, err := chain.Head()
 		require.NoError(t, err)
@@ -241,8 +264,8 @@ func TestStore(t *testing.T) {
 		require.NoError(t, err)
 
 		chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET, log)
-		require.NoError(t, chain.Store(block0, stateUpdate0, nil))
-		require.NoError(t, chain.Store(block1, stateUpdate1, nil))
+		require.NoError(t, chain.Store(block0, stateUpdate0, referencedClasses(t, gw, stateUpdate0)))
+		require.NoError(t, chain.Store(block1, stateUpdate1, referencedClasses(t, gw, stateUpdate1)))
 
 		headBlock, err := chain.Head()
 		require.NoError(t, err)
@@ -276,7 +299,7 @@ func TestTransactionAndReceipt(t *testing.T) {
 		su, err := gw.StateUpdate(context.Background(), i)
 		require.NoError(t, err)
 
-		require.NoError(t, chain.Store(b, su, nil))
+		require.NoError(t, chain.Store(b, su, referencedClasses(t, gw, su)))
 	}
 
 	t.Run("GetTransactionByBlockNumberAndIndex returns error if transaction does not exist", func(t *testing.T) {
@@ -333,3 +356,472 @@ func TestTransactionAndReceipt(t *testing.T) {
 		}
 	})
 }
+
+func TestHeadState(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET, utils.NewNopZapLogger())
+
+	t.Run("empty blockchain", func(t *testing.T) {
+		_, _, err := chain.HeadState()
+		require.ErrorIs(t, err, db.ErrKeyNotFound)
+	})
+
+	block0, err := gw.BlockByNumber(context.Background(), 0)
+	require.NoError(t, err)
+	stateUpdate0, err := gw.StateUpdate(context.Background(), 0)
+	require.NoError(t, err)
+	require.NoError(t, chain.Store(block0, stateUpdate0, referencedClasses(t, gw, stateUpdate0)))
+
+	t.Run("reads from head state", func(t *testing.T) {
+		state, closer, err := chain.HeadState()
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, closer())
+		})
+
+		for _, dc := range stateUpdate0.StateDiff.DeployedContracts {
+			classHash, err := state.ContractClassHash(dc.Address)
+			require.NoError(t, err)
+			assert.Equal(t, dc.ClassHash, classHash)
+		}
+	})
+}
+
+func TestStateAtBlock(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET, utils.NewNopZapLogger())
+
+	t.Run("non-existent block", func(t *testing.T) {
+		_, _, err := chain.StateAtBlockNumber(0)
+		require.ErrorIs(t, err, db.ErrKeyNotFound)
+
+		_, _, err = chain.StateAtBlockHash(&felt.Zero)
+		require.ErrorIs(t, err, db.ErrKeyNotFound)
+	})
+
+	var blocks []*core.Block
+	for i := uint64(0); i < 2; i++ {
+		b, err := gw.BlockByNumber(context.Background(), i)
+		require.NoError(t, err)
+		su, err := gw.StateUpdate(context.Background(), i)
+		require.NoError(t, err)
+		require.NoError(t, chain.Store(b, su, referencedClasses(t, gw, su)))
+		blocks = append(blocks, b)
+	}
+
+	su1, err := gw.StateUpdate(context.Background(), 1)
+	require.NoError(t, err)
+	deployedIn1 := su1.StateDiff.DeployedContracts[0]
+
+	t.Run("contract deployed later does not exist in older state", func(t *testing.T) {
+		state, closer, err := chain.StateAtBlockNumber(0)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, closer())
+		})
+
+		_, err = state.ContractClassHash(deployedIn1.Address)
+		require.ErrorIs(t, err, core.ErrContractNotDeployed)
+	})
+
+	t.Run("state by hash", func(t *testing.T) {
+		state, closer, err := chain.StateAtBlockHash(blocks[1].Hash)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, closer())
+		})
+
+		classHash, err := state.ContractClassHash(deployedIn1.Address)
+		require.NoError(t, err)
+		assert.Equal(t, deployedIn1.ClassHash, classHash)
+	})
+}
+
+func TestStateProof(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET, utils.NewNopZapLogger())
+	addr := utils.HexToFelt(t, "0x20cfa74ee3564b4cd5435cdace0f9c4d43b939620e4a0bb5076105df0a626c6")
+	keys := []*felt.Felt{utils.HexToFelt(t, "0x5")}
+
+	t.Run("empty blockchain", func(t *testing.T) {
+		_, _, err := chain.StateProof(0, addr, nil)
+		require.ErrorIs(t, err, db.ErrKeyNotFound)
+	})
+
+	blocks := make([]*core.Block, 3)
+	for number := range blocks {
+		block, err := gw.BlockByNumber(context.Background(), uint64(number))
+		require.NoError(t, err)
+		su, err := gw.StateUpdate(context.Background(), uint64(number))
+		require.NoError(t, err)
+		require.NoError(t, chain.Store(block, su, referencedClasses(t, gw, su)))
+		blocks[number] = block
+	}
+
+	for _, block := range []*core.Block{blocks[2], blocks[0]} {
+		proof, header, err := chain.StateProof(block.Number, addr, keys)
+		require.NoError(t, err)
+		assert.Equal(t, block.Header, header)
+		assert.Equal(t, block.GlobalStateRoot, proof.ContractsRoot)
+		require.NotNil(t, proof.Contract)
+		assert.Len(t, proof.Contract.StorageProofs, 1)
+	}
+
+	t.Run("the state of the head is left as it is", func(t *testing.T) {
+		root, err := chain.StateCommitment()
+		require.NoError(t, err)
+		assert.Equal(t, blocks[2].GlobalStateRoot, root)
+	})
+
+	t.Run("block that is not stored", func(t *testing.T) {
+		_, _, err := chain.StateProof(3, addr, keys)
+		require.ErrorIs(t, err, db.ErrKeyNotFound)
+	})
+}
+
+func TestHeadContractStorage(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET, utils.NewNopZapLogger())
+	addr := utils.HexToFelt(t, "0x20cfa74ee3564b4cd5435cdace0f9c4d43b939620e4a0bb5076105df0a626c6")
+	collect := func(key, value *felt.Felt) (bool, error) {
+		return true, nil
+	}
+
+	t.Run("empty blockchain", func(t *testing.T) {
+		_, err := chain.HeadContractStorage(addr, nil, collect)
+		require.ErrorIs(t, err, db.ErrKeyNotFound)
+	})
+
+	block0, err := gw.BlockByNumber(context.Background(), 0)
+	require.NoError(t, err)
+	su0, err := gw.StateUpdate(context.Background(), 0)
+	require.NoError(t, err)
+	require.NoError(t, chain.Store(block0, su0, referencedClasses(t, gw, su0)))
+
+	t.Run("contract is not deployed", func(t *testing.T) {
+		_, err := chain.HeadContractStorage(utils.HexToFelt(t, "0xDEADBEEF"), nil, collect)
+		require.ErrorIs(t, err, core.ErrContractNotDeployed)
+	})
+
+	t.Run("storage of the head", func(t *testing.T) {
+		expected := make(map[felt.Felt]*felt.Felt)
+		for _, diff := range su0.StateDiff.StorageDiffs[*addr] {
+			expected[*diff.Key] = diff.Value
+		}
+		require.NotEmpty(t, expected)
+
+		stored := make(map[felt.Felt]*felt.Felt)
+		header, err := chain.HeadContractStorage(addr, nil, func(key, value *felt.Felt) (bool, error) {
+			stored[*key] = value
+			return true, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, block0.Header, header)
+		assert.Equal(t, expected, stored)
+	})
+}
+
+func TestRevertHead(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET, utils.NewNopZapLogger())
+
+	t.Run("empty blockchain", func(t *testing.T) {
+		require.ErrorIs(t, chain.RevertHead(), db.ErrKeyNotFound)
+	})
+
+	var blocks []*core.Block
+	var stateUpdates []*core.StateUpdate
+	for i := uint64(0); i < 3; i++ {
+		b, err := gw.BlockByNumber(context.Background(), i)
+		require.NoError(t, err)
+		su, err := gw.StateUpdate(context.Background(), i)
+		require.NoError(t, err)
+		require.NoError(t, chain.Store(b, su, referencedClasses(t, gw, su)))
+		blocks = append(blocks, b)
+		stateUpdates = append(stateUpdates, su)
+	}
+
+	require.NoError(t, chain.RevertHead())
+
+	t.Run("parent is the new head", func(t *testing.T) {
+		head, err := chain.Head()
+		require.NoError(t, err)
+		assert.Equal(t, blocks[1], head)
+
+		root, err := chain.StateCommitment()
+		require.NoError(t, err)
+		assert.Equal(t, stateUpdates[1].NewRoot, root)
+	})
+
+	t.Run("reverted block is removed", func(t *testing.T) {
+		_, err := chain.BlockByNumber(2)
+		require.ErrorIs(t, err, db.ErrKeyNotFound)
+		_, err = chain.BlockByHash(blocks[2].Hash)
+		require.ErrorIs(t, err, db.ErrKeyNotFound)
+		_, err = chain.StateUpdateByNumber(2)
+		require.ErrorIs(t, err, db.ErrKeyNotFound)
+
+		for _, tx := range blocks[2].Transactions {
+			_, err = chain.TransactionByHash(tx.Hash())
+			require.ErrorIs(t, err, db.ErrKeyNotFound)
+			_, _, _, err = chain.Receipt(tx.Hash())
+			require.ErrorIs(t, err, db.ErrKeyNotFound)
+		}
+	})
+
+	t.Run("reverted block can be stored again", func(t *testing.T) {
+		require.NoError(t, chain.Store(blocks[2], stateUpdates[2], referencedClasses(t, gw, stateUpdates[2])))
+		head, err := chain.Head()
+		require.NoError(t, err)
+		assert.Equal(t, blocks[2], head)
+	})
+
+	t.Run("revert all blocks", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			require.NoError(t, chain.RevertHead())
+		}
+
+		_, err := chain.Height()
+		require.ErrorIs(t, err, db.ErrKeyNotFound)
+
+		root, err := chain.StateCommitment()
+		require.NoError(t, err)
+		assert.Equal(t, &felt.Zero, root)
+	})
+}
+
+func TestSetBlockStatus(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET, utils.NewNopZapLogger())
+
+	t.Run("block does not exist", func(t *testing.T) {
+		require.ErrorIs(t, chain.SetBlockStatus(0, core.BlockAcceptedOnL1), db.ErrKeyNotFound)
+	})
+
+	block0, err := gw.BlockByNumber(context.Background(), 0)
+	require.NoError(t, err)
+	su0, err := gw.StateUpdate(context.Background(), 0)
+	require.NoError(t, err)
+	block0.Status = core.BlockAcceptedOnL2
+	require.NoError(t, chain.Store(block0, su0, referencedClasses(t, gw, su0)))
+
+	sub := chain.Subscribe(2, blockchain.SlowConsumerUnsubscribe)
+	t.Cleanup(sub.Unsubscribe)
+	require.NoError(t, chain.SetBlockStatus(0, core.BlockAcceptedOnL1))
+	// the status did not change
+	require.NoError(t, chain.SetBlockStatus(0, core.BlockAcceptedOnL1))
+
+	header, err := chain.BlockHeaderByNumber(0)
+	require.NoError(t, err)
+	assert.Equal(t, core.BlockAcceptedOnL1, header.Status)
+	assert.Equal(t, &blockchain.BlockStatusChanged{Header: header}, <-sub.Events())
+	assert.Empty(t, sub.Events())
+
+	header, err = chain.BlockHeaderByHash(block0.Hash)
+	require.NoError(t, err)
+	assert.Equal(t, core.BlockAcceptedOnL1, header.Status)
+}
+
+func TestL1Height(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET, utils.NewNopZapLogger())
+	_, err := chain.L1Height()
+	require.ErrorIs(t, err, db.ErrKeyNotFound)
+
+	for number := uint64(0); number < 3; number++ {
+		block, err := gw.BlockByNumber(context.Background(), number)
+		require.NoError(t, err)
+		su, err := gw.StateUpdate(context.Background(), number)
+		require.NoError(t, err)
+		if number > 0 {
+			block.Status = core.BlockAcceptedOnL2
+		}
+		require.NoError(t, chain.Store(block, su, referencedClasses(t, gw, su)))
+	}
+
+	checkL1Height := func(t *testing.T, expected uint64) {
+		t.Helper()
+		height, err := chain.L1Height()
+		require.NoError(t, err)
+		assert.Equal(t, expected, height)
+	}
+	checkL1Height(t, 0)
+
+	require.NoError(t, chain.SetBlockStatus(2, core.BlockAcceptedOnL1))
+	checkL1Height(t, 2)
+	// a lower block does not lower it
+	require.NoError(t, chain.SetBlockStatus(1, core.BlockAcceptedOnL1))
+	checkL1Height(t, 2)
+
+	require.NoError(t, chain.SetBlockStatus(1, core.BlockRejected))
+	checkL1Height(t, 0)
+
+	require.NoError(t, chain.SetBlockStatus(1, core.BlockAcceptedOnL1))
+	checkL1Height(t, 1)
+	require.NoError(t, chain.RevertHead())
+	checkL1Height(t, 1)
+	require.NoError(t, chain.RevertHead())
+	checkL1Height(t, 0)
+	require.NoError(t, chain.RevertHead())
+	_, err = chain.L1Height()
+	require.ErrorIs(t, err, db.ErrKeyNotFound)
+}
+
+func TestSetAcceptedOnL1(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET, utils.NewNopZapLogger())
+	for number := uint64(0); number < 3; number++ {
+		block, err := gw.BlockByNumber(context.Background(), number)
+		require.NoError(t, err)
+		su, err := gw.StateUpdate(context.Background(), number)
+		require.NoError(t, err)
+		block.Status = core.BlockAcceptedOnL2
+		require.NoError(t, chain.Store(block, su, referencedClasses(t, gw, su)))
+	}
+
+	sub := chain.Subscribe(3, blockchain.SlowConsumerUnsubscribe)
+	t.Cleanup(sub.Unsubscribe)
+
+	checkAccepted := func(t *testing.T, numbers ...uint64) {
+		t.Helper()
+		for _, number := range numbers {
+			header, err := chain.BlockHeaderByNumber(number)
+			require.NoError(t, err)
+			assert.Equal(t, core.BlockAcceptedOnL1, header.Status)
+			assert.Equal(t, &blockchain.BlockStatusChanged{Header: header}, <-sub.Events())
+		}
+		assert.Empty(t, sub.Events())
+
+		height, err := chain.L1Height()
+		require.NoError(t, err)
+		assert.Equal(t, numbers[len(numbers)-1], height)
+	}
+
+	require.NoError(t, chain.SetAcceptedOnL1(1))
+	checkAccepted(t, 0, 1)
+	header, err := chain.BlockHeaderByNumber(2)
+	require.NoError(t, err)
+	assert.Equal(t, core.BlockAcceptedOnL2, header.Status)
+
+	require.NoError(t, chain.SetAcceptedOnL1(2))
+	checkAccepted(t, 2)
+
+	require.ErrorIs(t, chain.SetAcceptedOnL1(3), db.ErrKeyNotFound)
+}
+
+func TestSubscribe(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET, utils.NewNopZapLogger())
+
+	var blocks []*core.Block
+	var stateUpdates []*core.StateUpdate
+	var classes []map[felt.Felt]core.Class
+	for i := uint64(0); i < 2; i++ {
+		b, err := gw.BlockByNumber(context.Background(), i)
+		require.NoError(t, err)
+		su, err := gw.StateUpdate(context.Background(), i)
+		require.NoError(t, err)
+		blocks = append(blocks, b)
+		stateUpdates = append(stateUpdates, su)
+		classes = append(classes, referencedClasses(t, gw, su))
+	}
+
+	sub := chain.Subscribe(3, blockchain.SlowConsumerUnsubscribe)
+	dropping := chain.Subscribe(1, blockchain.SlowConsumerDrop)
+	unsubscribed := chain.Subscribe(1, blockchain.SlowConsumerUnsubscribe)
+	unsubscribed.Unsubscribe()
+	unsubscribed.Unsubscribe()
+
+	t.Run("failed writes are not published", func(t *testing.T) {
+		require.Error(t, chain.RevertHead())
+		require.Error(t, chain.Store(blocks[1], stateUpdates[1], classes[1]))
+		assert.Empty(t, sub.Events())
+	})
+
+	require.NoError(t, chain.Store(blocks[0], stateUpdates[0], classes[0]))
+	require.NoError(t, chain.Store(blocks[1], stateUpdates[1], classes[1]))
+	require.NoError(t, chain.RevertHead())
+
+	t.Run("events are received in order", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			assert.Equal(t, &blockchain.BlockStored{
+				Block:       blocks[i],
+				StateUpdate: stateUpdates[i],
+				NewClasses:  classes[i],
+			}, <-sub.Events())
+		}
+
+		reverted, ok := (<-sub.Events()).(*blockchain.HeadReverted)
+		require.True(t, ok)
+		assert.Equal(t, blocks[1].Hash, reverted.Block.Hash)
+		assert.Equal(t, blocks[1].Transactions, reverted.Block.Transactions)
+		assert.Equal(t, stateUpdates[1], reverted.StateUpdate)
+		assert.NoError(t, sub.Err())
+	})
+
+	t.Run("slow consumer policies", func(t *testing.T) {
+		assert.Equal(t, uint64(2), dropping.Dropped())
+		stored, ok := (<-dropping.Events()).(*blockchain.BlockStored)
+		require.True(t, ok)
+		assert.Equal(t, blocks[0], stored.Block)
+
+		require.NoError(t, chain.Store(blocks[1], stateUpdates[1], classes[1]))
+		require.NoError(t, chain.RevertHead())
+		require.NoError(t, chain.Store(blocks[1], stateUpdates[1], classes[1]))
+		require.NoError(t, chain.RevertHead())
+
+		// the buffer had room for three events only
+		for i := 0; i < 3; i++ {
+			_, ok = <-sub.Events()
+			assert.True(t, ok)
+		}
+		_, ok = <-sub.Events()
+		assert.False(t, ok)
+		assert.ErrorIs(t, sub.Err(), blockchain.ErrSlowConsumer)
+	})
+
+	t.Run("unsubscribed", func(t *testing.T) {
+		_, ok := <-unsubscribed.Events()
+		assert.False(t, ok)
+		assert.NoError(t, unsubscribed.Err())
+	})
+}
+
+func referencedClasses(t *testing.T, gw starknetdata.StarknetData, update *core.StateUpdate) map[felt.Felt]core.Class {
+	t.Helper()
+
+	classes := make(map[felt.Felt]core.Class)
+	for _, classHash := range update.StateDiff.ClassHashes() {
+		class, err := gw.Class(context.Background(), classHash)
+		require.NoError(t, err)
+		classes[*classHash] = class
+	}
+	return classes
+}
@@ -0,0 +1,97 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
+)
+
+var ErrMissingClass = errors.New("referenced class is not available")
+
+// verifyClassesAvailable checks that the definitions of all the classes referenced by a state diff
+// are either given in declaredClasses or already stored.
+func verifyClassesAvailable(txn db.Transaction, stateDiff *core.StateDiff, declaredClasses map[felt.Felt]core.Class) error {
+	for _, classHash := range stateDiff.ClassHashes() {
+		if declaredClasses[*classHash] != nil {
+			continue
+		}
+
+		stored, err := isClassStored(txn, classHash)
+		if err != nil {
+			return err
+		}
+		if !stored {
+			return fmt.Errorf("%w: %s", ErrMissingClass, classHash)
+		}
+	}
+	return nil
+}
+
+func isClassStored(txn db.Transaction, classHash *felt.Felt) (bool, error) {
+	err := txn.Get(db.Class.Key(classHash.Marshal()), func(val []byte) error {
+		return nil
+	})
+	if errors.Is(err, db.ErrKeyNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// MissingClasses returns the hashes of the classes that are referenced by the stored state updates
+// but whose definitions are not stored, mapped to the number of the first block referencing them.
+func (b *Blockchain) MissingClasses() (map[felt.Felt]uint64, error) {
+	missing := make(map[felt.Felt]uint64)
+	return missing, b.database.View(func(txn db.Transaction) error {
+		height, err := b.height(txn)
+		if errors.Is(err, db.ErrKeyNotFound) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		for blockNumber := uint64(0); blockNumber <= height; blockNumber++ {
+			var update *core.StateUpdate
+			update, err = stateUpdateByNumber(txn, blockNumber)
+			if err != nil {
+				return err
+			}
+
+			for _, classHash := range update.StateDiff.ClassHashes() {
+				if _, found := missing[*classHash]; found {
+					continue
+				}
+
+				var stored bool
+				stored, err = isClassStored(txn, classHash)
+				if err != nil {
+					return err
+				}
+				if !stored {
+					missing[*classHash] = blockNumber
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// StoreMissingClass stores the definition of a class reported by [Blockchain.MissingClasses].
+func (b *Blockchain) StoreMissingClass(classHash *felt.Felt, class core.Class, declaredAt uint64) error {
+	if class == nil {
+		return fmt.Errorf("%w: %s", ErrMissingClass, classHash)
+	}
+
+	if err := core.VerifyClassHashes(map[felt.Felt]core.Class{*classHash: class}); err != nil {
+		if !errors.As(err, new(core.CantVerifyClassHashError)) {
+			return err
+		}
+		b.log.Debugw("Class hash verification failed", "hash", classHash.ShortString(), "err", err.Error())
+	}
+
+	return b.database.Update(func(txn db.Transaction) error {
+		return core.NewState(txn).RestoreClass(classHash, class, declaredAt)
+	})
+}
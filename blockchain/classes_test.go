@@ -0,0 +1,116 @@
+package blockchain_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/clients/feeder"
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
+	"github.com/NethermindEth/juno/db/pebble"
+	adaptfeeder "github.com/NethermindEth/juno/starknetdata/feeder"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreRequiresReferencedClasses(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET, utils.NewNopZapLogger())
+
+	block0, err := gw.BlockByNumber(context.Background(), 0)
+	require.NoError(t, err)
+	stateUpdate0, err := gw.StateUpdate(context.Background(), 0)
+	require.NoError(t, err)
+
+	t.Run("classes are not given", func(t *testing.T) {
+		require.ErrorIs(t, chain.Store(block0, stateUpdate0, nil), blockchain.ErrMissingClass)
+	})
+
+	classHash := stateUpdate0.StateDiff.DeployedContracts[0].ClassHash
+	t.Run("class is nil", func(t *testing.T) {
+		classes := map[felt.Felt]core.Class{*classHash: nil}
+		require.ErrorIs(t, chain.Store(block0, stateUpdate0, classes), blockchain.ErrMissingClass)
+	})
+
+	require.NoError(t, chain.Store(block0, stateUpdate0, referencedClasses(t, gw, stateUpdate0)))
+
+	t.Run("stored classes do not have to be given again", func(t *testing.T) {
+		block1, err := gw.BlockByNumber(context.Background(), 1)
+		require.NoError(t, err)
+		stateUpdate1, err := gw.StateUpdate(context.Background(), 1)
+		require.NoError(t, err)
+		require.NoError(t, chain.Store(block1, stateUpdate1, nil))
+	})
+}
+
+func TestMissingClasses(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	testDB := pebble.NewMemTest()
+	chain := blockchain.New(testDB, utils.MAINNET, utils.NewNopZapLogger())
+
+	t.Run("empty blockchain", func(t *testing.T) {
+		missing, err := chain.MissingClasses()
+		require.NoError(t, err)
+		assert.Empty(t, missing)
+	})
+
+	for i := uint64(0); i < 3; i++ {
+		b, err := gw.BlockByNumber(context.Background(), i)
+		require.NoError(t, err)
+		su, err := gw.StateUpdate(context.Background(), i)
+		require.NoError(t, err)
+		require.NoError(t, chain.Store(b, su, referencedClasses(t, gw, su)))
+	}
+
+	t.Run("no missing classes", func(t *testing.T) {
+		missing, err := chain.MissingClasses()
+		require.NoError(t, err)
+		assert.Empty(t, missing)
+	})
+
+	classHash := utils.HexToFelt(t, "0x10455c752b86932ce552f2b0fe81a880746649b9aee7e0d842bf3f52378f9f8")
+	// simulate a database in which the class was never stored
+	require.NoError(t, testDB.Update(func(txn db.Transaction) error {
+		return txn.Delete(db.Class.Key(classHash.Marshal()))
+	}))
+
+	t.Run("missing class is reported with the first block referencing it", func(t *testing.T) {
+		missing, err := chain.MissingClasses()
+		require.NoError(t, err)
+		assert.Equal(t, map[felt.Felt]uint64{*classHash: 0}, missing)
+	})
+
+	t.Run("nil class cannot be stored", func(t *testing.T) {
+		require.ErrorIs(t, chain.StoreMissingClass(classHash, nil, 0), blockchain.ErrMissingClass)
+	})
+
+	t.Run("missing class is stored", func(t *testing.T) {
+		class, err := gw.Class(context.Background(), classHash)
+		require.NoError(t, err)
+		require.NoError(t, chain.StoreMissingClass(classHash, class, 0))
+
+		missing, err := chain.MissingClasses()
+		require.NoError(t, err)
+		assert.Empty(t, missing)
+
+		state, closer, err := chain.HeadState()
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, closer())
+		})
+
+		declaredClass, err := state.Class(classHash)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(0), declaredClass.At)
+		assert.Equal(t, class, declaredClass.Class)
+	})
+}
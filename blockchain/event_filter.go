@@ -0,0 +1,268 @@
+package blockchain
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
+	"github.com/bits-and-blooms/bloom/v3"
+)
+
+const (
+	eventsBloomFalsePositiveRate = 0.01
+
+	// defaultMaxEventScanBlocks bounds the work done by a single call to Events, a filter that matches few
+	// events would otherwise scan the whole chain
+	defaultMaxEventScanBlocks = 10_000
+)
+
+var ErrInvalidContinuationToken = errors.New("invalid continuation token")
+
+// EventFilter selects the events emitted in the blocks from FromBlock to ToBlock, both inclusive.
+// If Address is set, only events emitted by that contract match. Keys are matched by position:
+// an event matches if, for every non-empty Keys[i], its i-th key is one of the values in Keys[i].
+type EventFilter struct {
+	FromBlock uint64
+	ToBlock   uint64
+	Address   *felt.Felt
+	Keys      [][]*felt.Felt
+}
+
+// FilteredEvent is an event along with the block and transaction it was emitted in.
+type FilteredEvent struct {
+	*core.Event
+	BlockNumber     uint64
+	BlockHash       *felt.Felt
+	TransactionHash *felt.Felt
+}
+
+// ContinuationToken points to the first matching event that was not returned in a chunk of events:
+// the matching event with index Offset among the matching events of block BlockNumber.
+type ContinuationToken struct {
+	BlockNumber uint64
+	Offset      uint64
+}
+
+func (t *ContinuationToken) String() string {
+	return fmt.Sprintf("%d-%d", t.BlockNumber, t.Offset)
+}
+
+// ParseContinuationToken parses a token returned by [ContinuationToken.String].
+func ParseContinuationToken(token string) (*ContinuationToken, error) {
+	var t ContinuationToken
+	if n, err := fmt.Sscanf(token, "%d-%d", &t.BlockNumber, &t.Offset); err != nil || n != 2 {
+		return nil, ErrInvalidContinuationToken
+	}
+
+	if t.String() != token {
+		return nil, ErrInvalidContinuationToken
+	}
+	return &t, nil
+}
+
+// Events returns at most chunkSize events that match the given filter, in the order they were emitted.
+// If there are more matching events, a token is returned that can be used to get the next chunk. At most
+// maxEventScanBlocks blocks are scanned per call, so a chunk may hold fewer events, or none, along with a token.
+func (b *Blockchain) Events(filter *EventFilter, token *ContinuationToken, chunkSize uint64) ([]*FilteredEvent,
+	*ContinuationToken, error,
+) {
+	var events []*FilteredEvent
+	var nextToken *ContinuationToken
+	return events, nextToken, b.database.View(func(txn db.Transaction) error {
+		height, err := b.height(txn)
+		if err != nil {
+			return err
+		}
+
+		fromBlock, toBlock := filter.FromBlock, filter.ToBlock
+		if toBlock > height {
+			toBlock = height
+		}
+
+		var offset uint64
+		if token != nil {
+			if token.BlockNumber < fromBlock || token.BlockNumber > toBlock {
+				return ErrInvalidContinuationToken
+			}
+			fromBlock, offset = token.BlockNumber, token.Offset
+		}
+
+		for blockNumber := fromBlock; blockNumber <= toBlock; blockNumber++ {
+			if blockNumber-fromBlock == b.maxEventScanBlocks {
+				nextToken = &ContinuationToken{BlockNumber: blockNumber}
+				return nil
+			}
+
+			var blockEvents []*FilteredEvent
+			blockEvents, err = matchingEventsInBlock(txn, blockNumber, filter)
+			if err != nil {
+				return err
+			}
+
+			if offset > uint64(len(blockEvents)) {
+				return ErrInvalidContinuationToken
+			}
+
+			for i := offset; i < uint64(len(blockEvents)); i++ {
+				if uint64(len(events)) == chunkSize {
+					nextToken = &ContinuationToken{BlockNumber: blockNumber, Offset: i}
+					return nil
+				}
+				events = append(events, blockEvents[i])
+			}
+			offset = 0
+		}
+		return nil
+	})
+}
+
+func matchingEventsInBlock(txn db.Transaction, blockNumber uint64, filter *EventFilter) ([]*FilteredEvent, error) {
+	eventsBloom, err := eventsBloomByNumber(txn, blockNumber)
+	if err != nil && !errors.Is(err, db.ErrKeyNotFound) {
+		return nil, err
+	}
+
+	// blocks without a bloom filter are scanned
+	if eventsBloom != nil && !filter.mayMatch(eventsBloom) {
+		return nil, nil
+	}
+
+	header, err := blockHeaderByNumber(txn, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	receipts, err := receiptsByBlockNumber(txn, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	var matching []*FilteredEvent
+	for _, receipt := range receipts {
+		for _, event := range receipt.Events {
+			if !filter.Matches(event) {
+				continue
+			}
+
+			matching = append(matching, &FilteredEvent{
+				Event:           event,
+				BlockNumber:     blockNumber,
+				BlockHash:       header.Hash,
+				TransactionHash: receipt.TransactionHash,
+			})
+		}
+	}
+	return matching, nil
+}
+
+// Matches returns whether the given event matches the address and keys of the filter, the block range is not checked.
+func (f *EventFilter) Matches(event *core.Event) bool {
+	if f.Address != nil && !f.Address.Equal(event.From) {
+		return false
+	}
+
+	for i, keys := range f.Keys {
+		if len(keys) == 0 {
+			continue
+		}
+
+		if i >= len(event.Keys) {
+			return false
+		}
+
+		found := false
+		for _, key := range keys {
+			if key.Equal(event.Keys[i]) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// mayMatch returns false if none of the events summarised by the given bloom filter match.
+func (f *EventFilter) mayMatch(eventsBloom *bloom.BloomFilter) bool {
+	if f.Address != nil {
+		addrBytes := f.Address.Bytes()
+		if !eventsBloom.Test(addrBytes[:]) {
+			return false
+		}
+	}
+
+	for _, keys := range f.Keys {
+		if len(keys) == 0 {
+			continue
+		}
+
+		found := false
+		for _, key := range keys {
+			keyBytes := key.Bytes()
+			if eventsBloom.Test(keyBytes[:]) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// storeEventsBloom stores a bloom filter of the addresses and keys of all events emitted in a block.
+// Bloom filters are maintained as follows:
+//
+// [db.EventsBloomByBlockNumber](BlockNumber) -> (BloomFilter)
+func storeEventsBloom(txn db.Transaction, blockNumber uint64, receipts []*core.TransactionReceipt) error {
+	var numItems uint
+	for _, receipt := range receipts {
+		for _, event := range receipt.Events {
+			numItems += uint(1 + len(event.Keys))
+		}
+	}
+
+	if numItems == 0 {
+		numItems = 1
+	}
+
+	eventsBloom := bloom.NewWithEstimates(numItems, eventsBloomFalsePositiveRate)
+	for _, receipt := range receipts {
+		for _, event := range receipt.Events {
+			addrBytes := event.From.Bytes()
+			eventsBloom.Add(addrBytes[:])
+			for _, key := range event.Keys {
+				keyBytes := key.Bytes()
+				eventsBloom.Add(keyBytes[:])
+			}
+		}
+	}
+
+	bloomBytes, err := eventsBloom.GobEncode()
+	if err != nil {
+		return err
+	}
+	return txn.Set(db.EventsBloomByBlockNumber.Key(blockNumberKey(blockNumber)), bloomBytes)
+}
+
+func eventsBloomByNumber(txn db.Transaction, blockNumber uint64) (*bloom.BloomFilter, error) {
+	var eventsBloom *bloom.BloomFilter
+	return eventsBloom, txn.Get(db.EventsBloomByBlockNumber.Key(blockNumberKey(blockNumber)), func(val []byte) error {
+		eventsBloom = new(bloom.BloomFilter)
+		return eventsBloom.GobDecode(val)
+	})
+}
+
+func blockNumberKey(blockNumber uint64) []byte {
+	numBytes := make([]byte, lenOfByteSlice)
+	binary.BigEndian.PutUint64(numBytes, blockNumber)
+	return numBytes
+}
@@ -0,0 +1,199 @@
+package blockchain_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/clients/feeder"
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db/pebble"
+	adaptfeeder "github.com/NethermindEth/juno/starknetdata/feeder"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvents(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET, utils.NewNopZapLogger())
+
+	var allEvents []*blockchain.FilteredEvent
+	for i := uint64(0); i < 3; i++ {
+		b, err := gw.BlockByNumber(context.Background(), i)
+		require.NoError(t, err)
+		su, err := gw.StateUpdate(context.Background(), i)
+		require.NoError(t, err)
+
+		// the first mainnet blocks do not emit any events, so some are made up
+		for j, receipt := range b.Receipts {
+			receipt.Events = append(receipt.Events, &core.Event{
+				From: new(felt.Felt).SetUint64(uint64(j % 2)),
+				Keys: []*felt.Felt{new(felt.Felt).SetUint64(i), new(felt.Felt).SetUint64(uint64(j))},
+				Data: []*felt.Felt{new(felt.Felt).SetUint64(i), new(felt.Felt).SetUint64(uint64(j))},
+			})
+		}
+		require.NoError(t, chain.Store(b, su, referencedClasses(t, gw, su)))
+
+		for _, receipt := range b.Receipts {
+			for _, event := range receipt.Events {
+				allEvents = append(allEvents, &blockchain.FilteredEvent{
+					Event:           event,
+					BlockNumber:     b.Number,
+					BlockHash:       b.Hash,
+					TransactionHash: receipt.TransactionHash,
+				})
+			}
+		}
+	}
+	require.NotEmpty(t, allEvents)
+
+	allBlocks := &blockchain.EventFilter{FromBlock: 0, ToBlock: math.MaxUint64}
+
+	t.Run("all events", func(t *testing.T) {
+		events, token, err := chain.Events(allBlocks, nil, uint64(len(allEvents)))
+		require.NoError(t, err)
+		assert.Nil(t, token)
+		assert.Equal(t, allEvents, events)
+	})
+
+	t.Run("chunks of a single event", func(t *testing.T) {
+		var (
+			events []*blockchain.FilteredEvent
+			token  *blockchain.ContinuationToken
+		)
+		for {
+			chunk, nextToken, err := chain.Events(allBlocks, token, 1)
+			require.NoError(t, err)
+			events = append(events, chunk...)
+			if nextToken == nil {
+				break
+			}
+
+			// tokens survive a round trip through their string representation
+			token, err = blockchain.ParseContinuationToken(nextToken.String())
+			require.NoError(t, err)
+		}
+		assert.Equal(t, allEvents, events)
+	})
+
+	t.Run("scanned blocks are bounded", func(t *testing.T) {
+		chain.WithMaxEventScanBlocks(1)
+		t.Cleanup(func() {
+			chain.WithMaxEventScanBlocks(10_000)
+		})
+
+		// the first block with matching events is only reached by following the tokens
+		filter := &blockchain.EventFilter{ToBlock: math.MaxUint64, Keys: [][]*felt.Felt{{new(felt.Felt).SetUint64(2)}}}
+		events, token, err := chain.Events(filter, nil, 100)
+		require.NoError(t, err)
+		assert.Empty(t, events)
+		require.Equal(t, &blockchain.ContinuationToken{BlockNumber: 1}, token)
+
+		events, token, err = chain.Events(filter, token, 100)
+		require.NoError(t, err)
+		assert.Empty(t, events)
+		require.Equal(t, &blockchain.ContinuationToken{BlockNumber: 2}, token)
+
+		events, token, err = chain.Events(filter, token, 100)
+		require.NoError(t, err)
+		assert.Nil(t, token)
+		require.NotEmpty(t, events)
+		for _, event := range events {
+			assert.Equal(t, uint64(2), event.BlockNumber)
+		}
+	})
+
+	t.Run("block range", func(t *testing.T) {
+		var expected []*blockchain.FilteredEvent
+		for _, event := range allEvents {
+			if event.BlockNumber == 1 {
+				expected = append(expected, event)
+			}
+		}
+
+		events, token, err := chain.Events(&blockchain.EventFilter{FromBlock: 1, ToBlock: 1}, nil, 100)
+		require.NoError(t, err)
+		assert.Nil(t, token)
+		assert.Equal(t, expected, events)
+	})
+
+	t.Run("address", func(t *testing.T) {
+		address := allEvents[0].From
+		var expected []*blockchain.FilteredEvent
+		for _, event := range allEvents {
+			if event.From.Equal(address) {
+				expected = append(expected, event)
+			}
+		}
+
+		events, _, err := chain.Events(&blockchain.EventFilter{ToBlock: math.MaxUint64, Address: address}, nil, 100)
+		require.NoError(t, err)
+		assert.Equal(t, expected, events)
+
+		events, _, err = chain.Events(&blockchain.EventFilter{
+			ToBlock: math.MaxUint64,
+			Address: utils.HexToFelt(t, "0xDEADBEEF"),
+		}, nil, 100)
+		require.NoError(t, err)
+		assert.Empty(t, events)
+	})
+
+	t.Run("keys", func(t *testing.T) {
+		var keyed *blockchain.FilteredEvent
+		for _, event := range allEvents {
+			if len(event.Keys) > 0 {
+				keyed = event
+				break
+			}
+		}
+		require.NotNil(t, keyed)
+
+		var expected []*blockchain.FilteredEvent
+		for _, event := range allEvents {
+			if len(event.Keys) > 0 && event.Keys[0].Equal(keyed.Keys[0]) {
+				expected = append(expected, event)
+			}
+		}
+
+		events, _, err := chain.Events(&blockchain.EventFilter{
+			ToBlock: math.MaxUint64,
+			Keys:    [][]*felt.Felt{{utils.HexToFelt(t, "0xDEADBEEF"), keyed.Keys[0]}},
+		}, nil, 100)
+		require.NoError(t, err)
+		assert.Equal(t, expected, events)
+
+		// an empty list matches any key
+		events, _, err = chain.Events(&blockchain.EventFilter{
+			ToBlock: math.MaxUint64,
+			Keys:    [][]*felt.Felt{{}},
+		}, nil, uint64(len(allEvents)))
+		require.NoError(t, err)
+		assert.Equal(t, allEvents, events)
+
+		events, _, err = chain.Events(&blockchain.EventFilter{
+			ToBlock: math.MaxUint64,
+			Keys:    [][]*felt.Felt{{utils.HexToFelt(t, "0xDEADBEEF")}},
+		}, nil, 100)
+		require.NoError(t, err)
+		assert.Empty(t, events)
+	})
+
+	t.Run("invalid continuation token", func(t *testing.T) {
+		_, _, err := chain.Events(&blockchain.EventFilter{FromBlock: 1, ToBlock: 2}, &blockchain.ContinuationToken{}, 1)
+		require.ErrorIs(t, err, blockchain.ErrInvalidContinuationToken)
+
+		_, _, err = chain.Events(allBlocks, &blockchain.ContinuationToken{BlockNumber: 0, Offset: 1000}, 1)
+		require.ErrorIs(t, err, blockchain.ErrInvalidContinuationToken)
+
+		for _, token := range []string{"", "1", "a-b", "1-2-3", "01-2"} {
+			_, err = blockchain.ParseContinuationToken(token)
+			require.ErrorIs(t, err, blockchain.ErrInvalidContinuationToken, token)
+		}
+	})
+}
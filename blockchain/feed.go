@@ -0,0 +1,137 @@
+package blockchain
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+)
+
+var ErrSlowConsumer = errors.New("subscriber is not consuming events fast enough")
+
+// Event is published to the subscribers of a [Blockchain] whenever the chain changes. It is either a
+// [*BlockStored], a [*HeadReverted] or a [*BlockStatusChanged].
+type Event interface {
+	isEvent()
+}
+
+// BlockStored is published after a block is stored, along with its state update and the definitions of
+// the classes that it references.
+type BlockStored struct {
+	Block       *core.Block
+	StateUpdate *core.StateUpdate
+	NewClasses  map[felt.Felt]core.Class
+}
+
+// HeadReverted is published after the head block is reverted, it carries the removed block and state update.
+type HeadReverted struct {
+	Block       *core.Block
+	StateUpdate *core.StateUpdate
+}
+
+// BlockStatusChanged is published after the status of a stored block changes, e.g. once it is accepted on
+// L1, it carries the header of the block with the new status.
+type BlockStatusChanged struct {
+	Header *core.Header
+}
+
+func (*BlockStored) isEvent()        {}
+func (*HeadReverted) isEvent()       {}
+func (*BlockStatusChanged) isEvent() {}
+
+// SlowConsumerPolicy decides what happens to a subscriber whose buffer is full when an event is published.
+// Publishing never blocks, so a slow subscriber can not hold up the chain.
+type SlowConsumerPolicy uint8
+
+const (
+	// SlowConsumerUnsubscribe closes the subscription, [Subscription.Err] then returns [ErrSlowConsumer].
+	SlowConsumerUnsubscribe SlowConsumerPolicy = iota
+	// SlowConsumerDrop drops the events that do not fit in the buffer, [Subscription.Dropped] counts them.
+	SlowConsumerDrop
+)
+
+// Subscription receives the events published by a [Blockchain] in the order they happened.
+type Subscription struct {
+	feed    *feed
+	events  chan Event
+	policy  SlowConsumerPolicy
+	dropped uint64
+	err     error
+}
+
+// Events returns the channel the events are delivered on. It is closed when the subscription ends.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Unsubscribe ends the subscription, it is safe to call more than once.
+func (s *Subscription) Unsubscribe() {
+	s.feed.mu.Lock()
+	defer s.feed.mu.Unlock()
+	s.feed.remove(s, nil)
+}
+
+// Err returns the reason the subscription was ended by the publisher, if any. It is only meaningful once
+// the events channel is closed.
+func (s *Subscription) Err() error {
+	s.feed.mu.Lock()
+	defer s.feed.mu.Unlock()
+	return s.err
+}
+
+// Dropped returns the number of events dropped because the buffer was full.
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+type feed struct {
+	mu   sync.Mutex
+	subs map[*Subscription]struct{}
+}
+
+func newFeed() *feed {
+	return &feed{subs: make(map[*Subscription]struct{})}
+}
+
+func (f *feed) subscribe(bufferSize int, policy SlowConsumerPolicy) *Subscription {
+	sub := &Subscription{
+		feed:   f,
+		events: make(chan Event, bufferSize),
+		policy: policy,
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.subs[sub] = struct{}{}
+	return sub
+}
+
+func (f *feed) publish(event Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for sub := range f.subs {
+		select {
+		case sub.events <- event:
+		default:
+			if sub.policy == SlowConsumerDrop {
+				atomic.AddUint64(&sub.dropped, 1)
+			} else {
+				f.remove(sub, ErrSlowConsumer)
+			}
+		}
+	}
+}
+
+// remove ends a subscription, the caller must hold the lock.
+func (f *feed) remove(sub *Subscription, err error) {
+	if _, found := f.subs[sub]; !found {
+		return
+	}
+
+	delete(f.subs, sub)
+	sub.err = err
+	close(sub.events)
+}
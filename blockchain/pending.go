@@ -0,0 +1,100 @@
+package blockchain
+
+import (
+	"errors"
+
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
+)
+
+// Pending is the block that the sequencer is currently building on top of the head, along with its
+// state update and the definitions of the classes that it references.
+// The block has no hash, number or state root, and the state update has no block hash or new root.
+type Pending struct {
+	Block       *core.Block
+	StateUpdate *core.StateUpdate
+	NewClasses  map[felt.Felt]core.Class
+}
+
+// StorePending keeps the given pending block in memory. It is rejected with [ErrParentDoesNotMatchHead]
+// if it is not built on top of the head.
+func (b *Blockchain) StorePending(pending *Pending) error {
+	return b.database.View(func(txn db.Transaction) error {
+		if _, err := b.pendingParent(txn, pending); err != nil {
+			return err
+		}
+
+		b.pendingLock.Lock()
+		defer b.pendingLock.Unlock()
+		b.pending = pending
+		return nil
+	})
+}
+
+// Pending returns the latest pending block. [db.ErrKeyNotFound] is returned if there is no pending block
+// or if the head has moved past it.
+func (b *Blockchain) Pending() (*Pending, error) {
+	var pending *Pending
+	return pending, b.database.View(func(txn db.Transaction) error {
+		var err error
+		pending, _, err = b.validPending(txn)
+		return err
+	})
+}
+
+// PendingState returns a StateReader that provides a stable view of the state as it will be once
+// the pending block is applied.
+// The returned StateCloser must be called once the StateReader is no longer needed.
+func (b *Blockchain) PendingState() (core.StateReader, StateCloser, error) {
+	txn := b.database.NewTransaction(false)
+	pending, blockNumber, err := b.validPending(txn)
+	if err != nil {
+		return nil, nil, db.CloseAndWrapOnError(txn.Discard, err)
+	}
+
+	return core.NewPendingState(blockNumber, pending.StateUpdate.StateDiff, pending.NewClasses,
+		core.NewState(txn)), txn.Discard, nil
+}
+
+// validPending returns the latest pending block along with its number, if it is built on top of the head.
+func (b *Blockchain) validPending(txn db.Transaction) (*Pending, uint64, error) {
+	b.pendingLock.RLock()
+	pending := b.pending
+	b.pendingLock.RUnlock()
+
+	if pending == nil {
+		return nil, 0, db.ErrKeyNotFound
+	}
+
+	blockNumber, err := b.pendingParent(txn, pending)
+	if errors.Is(err, ErrParentDoesNotMatchHead) {
+		return nil, 0, db.ErrKeyNotFound
+	} else if err != nil {
+		return nil, 0, err
+	}
+	return pending, blockNumber, nil
+}
+
+// pendingParent checks that the pending block is built on top of the head and returns its number.
+func (b *Blockchain) pendingParent(txn db.Transaction, pending *Pending) (uint64, error) {
+	height, err := b.height(txn)
+	if errors.Is(err, db.ErrKeyNotFound) {
+		if !pending.Block.ParentHash.Equal(&felt.Zero) {
+			return 0, ErrParentDoesNotMatchHead
+		}
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	head, err := blockHeaderByNumber(txn, height)
+	if err != nil {
+		return 0, err
+	}
+
+	if !pending.Block.ParentHash.Equal(head.Hash) {
+		return 0, ErrParentDoesNotMatchHead
+	}
+	return head.Number + 1, nil
+}
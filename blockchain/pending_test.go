@@ -0,0 +1,99 @@
+package blockchain_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/clients/feeder"
+	"github.com/NethermindEth/juno/db"
+	"github.com/NethermindEth/juno/db/pebble"
+	adaptfeeder "github.com/NethermindEth/juno/starknetdata/feeder"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPending(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET, utils.NewNopZapLogger())
+
+	// the pending block in the test data is built on top of mainnet block 1
+	pendingBlock, err := gw.BlockPending(context.Background())
+	require.NoError(t, err)
+	pendingUpdate, err := gw.StateUpdatePending(context.Background())
+	require.NoError(t, err)
+	pending := &blockchain.Pending{
+		Block:       pendingBlock,
+		StateUpdate: pendingUpdate,
+		NewClasses:  referencedClasses(t, gw, pendingUpdate),
+	}
+
+	t.Run("no pending block", func(t *testing.T) {
+		_, err := chain.Pending()
+		require.ErrorIs(t, err, db.ErrKeyNotFound)
+		_, _, err = chain.PendingState()
+		require.ErrorIs(t, err, db.ErrKeyNotFound)
+	})
+
+	t.Run("pending block must be built on top of the head", func(t *testing.T) {
+		require.ErrorIs(t, chain.StorePending(pending), blockchain.ErrParentDoesNotMatchHead)
+	})
+
+	for i := uint64(0); i < 2; i++ {
+		b, err := gw.BlockByNumber(context.Background(), i)
+		require.NoError(t, err)
+		su, err := gw.StateUpdate(context.Background(), i)
+		require.NoError(t, err)
+		require.NoError(t, chain.Store(b, su, referencedClasses(t, gw, su)))
+	}
+
+	require.NoError(t, chain.StorePending(pending))
+
+	t.Run("pending block is returned", func(t *testing.T) {
+		got, err := chain.Pending()
+		require.NoError(t, err)
+		assert.Equal(t, pending, got)
+	})
+
+	t.Run("pending state reads the pending state diff", func(t *testing.T) {
+		state, closer, err := chain.PendingState()
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, closer())
+		})
+
+		for _, dc := range pendingUpdate.StateDiff.DeployedContracts {
+			classHash, err := state.ContractClassHash(dc.Address)
+			require.NoError(t, err)
+			assert.Equal(t, dc.ClassHash, classHash)
+
+			declaredClass, err := state.Class(dc.ClassHash)
+			require.NoError(t, err)
+			assert.Equal(t, pending.NewClasses[*dc.ClassHash], declaredClass.Class)
+		}
+
+		for addr, diffs := range pendingUpdate.StateDiff.StorageDiffs {
+			addr := addr
+			for _, diff := range diffs {
+				value, err := state.ContractStorage(&addr, diff.Key)
+				require.NoError(t, err)
+				assert.Equal(t, diff.Value, value)
+			}
+		}
+	})
+
+	t.Run("pending block is dropped once the head moves", func(t *testing.T) {
+		b, err := gw.BlockByNumber(context.Background(), 2)
+		require.NoError(t, err)
+		su, err := gw.StateUpdate(context.Background(), 2)
+		require.NoError(t, err)
+		require.NoError(t, chain.Store(b, su, nil))
+
+		_, err = chain.Pending()
+		require.ErrorIs(t, err, db.ErrKeyNotFound)
+	})
+}
@@ -0,0 +1,158 @@
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const jsonrpcVersion = "2.0"
+
+// Client is a minimal Ethereum JSON-RPC client that supports the calls Juno needs to follow L1.
+type Client struct {
+	url    string
+	client *http.Client
+	nextID uint64
+}
+
+func NewClient(clientURL string) *Client {
+	return &Client{
+		url:    clientURL,
+		client: http.DefaultClient,
+	}
+}
+
+type request struct {
+	Version string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  []any  `json:"params"`
+	ID      uint64 `json:"id"`
+}
+
+type response struct {
+	Version string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      uint64          `json:"id"`
+}
+
+// Error is an error returned by the Ethereum node.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("ethereum json-rpc error %d: %s", e.Code, e.Message)
+}
+
+// call performs a JSON-RPC call and unmarshals its result into the given value.
+func (c *Client) call(ctx context.Context, result any, method string, params ...any) error {
+	if params == nil {
+		params = []any{}
+	}
+
+	reqBytes, err := json.Marshal(&request{
+		Version: jsonrpcVersion,
+		Method:  method,
+		Params:  params,
+		ID:      atomic.AddUint64(&c.nextID, 1),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.url, bytes.NewReader(reqBytes))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return errors.New(res.Status)
+	}
+
+	resBytes, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	var resp response
+	if err = json.Unmarshal(resBytes, &resp); err != nil {
+		return err
+	}
+
+	if resp.Error != nil {
+		return resp.Error
+	}
+	return json.Unmarshal(resp.Result, result)
+}
+
+// BlockNumber returns the number of the most recent Ethereum block.
+func (c *Client) BlockNumber(ctx context.Context) (uint64, error) {
+	var number hexutil.Uint64
+	if err := c.call(ctx, &number, "eth_blockNumber"); err != nil {
+		return 0, err
+	}
+	return uint64(number), nil
+}
+
+// FilterQuery selects the logs emitted by Address in the blocks from FromBlock to ToBlock, both inclusive.
+// Topics are matched by position: a log matches if, for every non-empty Topics[i], its i-th topic is one
+// of the values in Topics[i].
+type FilterQuery struct {
+	FromBlock uint64
+	ToBlock   uint64
+	Address   common.Address
+	Topics    [][]common.Hash
+}
+
+func (q *FilterQuery) MarshalJSON() ([]byte, error) {
+	topics := make([]any, len(q.Topics))
+	for i, t := range q.Topics {
+		if len(t) > 0 {
+			topics[i] = t
+		}
+	}
+
+	return json.Marshal(map[string]any{
+		"fromBlock": hexutil.Uint64(q.FromBlock),
+		"toBlock":   hexutil.Uint64(q.ToBlock),
+		"address":   q.Address,
+		"topics":    topics,
+	})
+}
+
+// Log is an event emitted by an Ethereum contract.
+type Log struct {
+	Address     common.Address `json:"address"`
+	Topics      []common.Hash  `json:"topics"`
+	Data        hexutil.Bytes  `json:"data"`
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	TxHash      common.Hash    `json:"transactionHash"`
+	Index       hexutil.Uint   `json:"logIndex"`
+	Removed     bool           `json:"removed"`
+}
+
+// Logs returns the logs that match the given query, in the order they were emitted.
+func (c *Client) Logs(ctx context.Context, query *FilterQuery) ([]Log, error) {
+	var logs []Log
+	if err := c.call(ctx, &logs, "eth_getLogs", query); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
@@ -0,0 +1,114 @@
+package ethereum_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NethermindEth/juno/clients/ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockNumber(t *testing.T) {
+	node := new(ethereum.TestNode)
+	client, closeFn := ethereum.NewTestClient(node)
+	t.Cleanup(closeFn)
+
+	number, err := client.BlockNumber(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), number)
+
+	node.SetHead(17_000_000)
+	number, err = client.BlockNumber(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(17_000_000), number)
+}
+
+func TestLogs(t *testing.T) {
+	node := new(ethereum.TestNode)
+	client, closeFn := ethereum.NewTestClient(node)
+	t.Cleanup(closeFn)
+
+	address := common.HexToAddress("0x1")
+	otherAddress := common.HexToAddress("0x2")
+	topic := common.HexToHash("0xa")
+	otherTopic := common.HexToHash("0xb")
+
+	logs := []ethereum.Log{
+		{Address: address, Topics: []common.Hash{topic}, Data: []byte{1}, BlockNumber: 1},
+		{Address: address, Topics: []common.Hash{otherTopic}, Data: []byte{2}, BlockNumber: 2},
+		{Address: otherAddress, Topics: []common.Hash{topic}, Data: []byte{3}, BlockNumber: 3},
+		{Address: address, Topics: []common.Hash{topic}, Data: []byte{4}, BlockNumber: 4},
+	}
+	for _, log := range logs {
+		node.AddLog(log)
+	}
+
+	tests := map[string]struct {
+		query    ethereum.FilterQuery
+		expected []ethereum.Log
+	}{
+		"all logs of a contract": {
+			query:    ethereum.FilterQuery{FromBlock: 0, ToBlock: 4, Address: address},
+			expected: []ethereum.Log{logs[0], logs[1], logs[3]},
+		},
+		"block range": {
+			query:    ethereum.FilterQuery{FromBlock: 2, ToBlock: 3, Address: address},
+			expected: []ethereum.Log{logs[1]},
+		},
+		"topic": {
+			query:    ethereum.FilterQuery{FromBlock: 0, ToBlock: 4, Address: address, Topics: [][]common.Hash{{topic}}},
+			expected: []ethereum.Log{logs[0], logs[3]},
+		},
+		"one of several topics": {
+			query: ethereum.FilterQuery{
+				FromBlock: 0,
+				ToBlock:   4,
+				Address:   address,
+				Topics:    [][]common.Hash{{topic, otherTopic}},
+			},
+			expected: []ethereum.Log{logs[0], logs[1], logs[3]},
+		},
+		"no matching logs": {
+			query:    ethereum.FilterQuery{FromBlock: 5, ToBlock: 10, Address: address},
+			expected: []ethereum.Log{},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			matching, err := client.Logs(context.Background(), &test.query)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, matching)
+		})
+	}
+}
+
+func TestCallErrors(t *testing.T) {
+	t.Run("http error", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		t.Cleanup(srv.Close)
+
+		_, err := ethereum.NewClient(srv.URL).BlockNumber(context.Background())
+		require.Error(t, err)
+	})
+
+	t.Run("json-rpc error", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, err := w.Write([]byte(`{"jsonrpc":"2.0","error":{"code":-32005,"message":"query returned more than 10000 results"},"id":1}`))
+			require.NoError(t, err)
+		}))
+		t.Cleanup(srv.Close)
+
+		_, err := ethereum.NewClient(srv.URL).Logs(context.Background(), &ethereum.FilterQuery{})
+		var rpcErr *ethereum.Error
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, -32005, rpcErr.Code)
+	})
+}
@@ -0,0 +1,142 @@
+package ethereum
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/NethermindEth/juno/jsonrpc"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// TestNode is a fake Ethereum node that serves eth_blockNumber and eth_getLogs from memory.
+type TestNode struct {
+	mu   sync.Mutex
+	head uint64
+	logs []Log
+}
+
+// SetHead sets the number of the most recent block of the node.
+func (n *TestNode) SetHead(number uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.head = number
+}
+
+// AddLog adds a log to the node, advancing its head to the block of the log if needed.
+func (n *TestNode) AddLog(log Log) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.logs = append(n.logs, log)
+	if uint64(log.BlockNumber) > n.head {
+		n.head = uint64(log.BlockNumber)
+	}
+}
+
+func (n *TestNode) blockNumber() hexutil.Uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return hexutil.Uint64(n.head)
+}
+
+func (n *TestNode) filterLogs(filter *testFilter) []Log {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	logs := []Log{}
+	for _, log := range n.logs {
+		number := uint64(log.BlockNumber)
+		if number < uint64(filter.FromBlock) || number > uint64(filter.ToBlock) || log.Address != filter.Address {
+			continue
+		}
+		if filter.matchesTopics(log.Topics) {
+			logs = append(logs, log)
+		}
+	}
+	return logs
+}
+
+type testFilter struct {
+	FromBlock hexutil.Uint64  `json:"fromBlock"`
+	ToBlock   hexutil.Uint64  `json:"toBlock"`
+	Address   common.Address  `json:"address"`
+	Topics    [][]common.Hash `json:"topics"`
+}
+
+func (f *testFilter) matchesTopics(topics []common.Hash) bool {
+	for i, options := range f.Topics {
+		if len(options) == 0 {
+			continue
+		}
+
+		if i >= len(topics) {
+			return false
+		}
+
+		found := false
+		for _, option := range options {
+			if option == topics[i] {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+type closeTestClient func()
+
+// NewTestClient returns a client connected to a server backed by the given test node and a function to
+// close the server.
+func NewTestClient(node *TestNode) (*Client, closeTestClient) {
+	srv := newTestServer(node)
+	return NewClient(srv.URL), srv.Close
+}
+
+func newTestServer(node *TestNode) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+			ID     uint64            `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		res := response{Version: jsonrpcVersion, ID: req.ID}
+		var result any
+		switch req.Method {
+		case "eth_blockNumber":
+			result = node.blockNumber()
+		case "eth_getLogs":
+			var filter testFilter
+			if len(req.Params) != 1 || json.Unmarshal(req.Params[0], &filter) != nil {
+				res.Error = &Error{Code: jsonrpc.InvalidParams, Message: "invalid params"}
+				break
+			}
+			result = node.filterLogs(&filter)
+		default:
+			res.Error = &Error{Code: jsonrpc.MethodNotFound, Message: "method not found"}
+		}
+
+		if res.Error == nil {
+			var err error
+			if res.Result, err = json.Marshal(result); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if err := json.NewEncoder(w).Encode(&res); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+}
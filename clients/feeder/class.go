@@ -83,3 +83,38 @@ func (c *ClassDefinition) UnmarshalJSON(data []byte) error {
 	c.V0 = new(Cairo0Definition)
 	return json.Unmarshal(data, c.V0)
 }
+
+type CompiledEntryPoint struct {
+	Selector *felt.Felt `json:"selector"`
+	Builtins []string   `json:"builtins"`
+	Offset   uint64     `json:"offset"`
+}
+
+type CompiledClass struct {
+	EntryPoints struct {
+		External    []CompiledEntryPoint `json:"EXTERNAL"`
+		L1Handler   []CompiledEntryPoint `json:"L1_HANDLER"`
+		Constructor []CompiledEntryPoint `json:"CONSTRUCTOR"`
+	} `json:"entry_points_by_type"`
+	Prime           string          `json:"prime"`
+	CompilerVersion string          `json:"compiler_version"`
+	Bytecode        []*felt.Felt    `json:"bytecode"`
+	Hints           json.RawMessage `json:"hints"`
+	PythonicHints   json.RawMessage `json:"pythonic_hints"`
+	// nil for the classes that were compiled before the bytecode was split into segments
+	BytecodeSegmentLengths *SegmentLengths `json:"bytecode_segment_lengths,omitempty"`
+}
+
+// SegmentLengths is the nested list of the lengths of the segments of the bytecode. A segment is either a
+// run of bytecode, with a length, or a list of segments.
+type SegmentLengths struct {
+	Length   uint64
+	Children []SegmentLengths
+}
+
+func (l *SegmentLengths) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &l.Length); err == nil {
+		return nil
+	}
+	return json.Unmarshal(data, &l.Children)
+}
@@ -0,0 +1,172 @@
+package feeder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// The responses of the feeder are kept in a directory tree, the layout of which is also the layout of the
+// test data:
+//
+//	block/<block number>.json            get_block, along with pending.json and latest.json
+//	state_update/<block number>.json     get_state_update, along with pending.json
+//	transaction/<transaction hash>.json  get_transaction
+//	class/<class hash>.json              get_class_by_hash
+//	compiled_class/<class hash>.json     get_compiled_class_by_class_hash
+//	signature/<block number>.json        get_signature
+//	public_key.json                      get_public_key
+
+var errNotInTree = errors.New("request has no response in a response tree")
+
+// responseFile returns the path of the file that holds the response to the request for u, relative to the
+// root of a response tree.
+func responseFile(u *url.URL) (string, error) {
+	var dir, arg string
+	switch endpoint := path.Base(u.Path); endpoint {
+	case "get_block":
+		dir, arg = "block", "blockNumber"
+	case "get_state_update":
+		dir, arg = "state_update", "blockNumber"
+	case "get_transaction":
+		dir, arg = "transaction", "transactionHash"
+	case "get_class_by_hash":
+		dir, arg = "class", "classHash"
+	case "get_compiled_class_by_class_hash":
+		dir, arg = "compiled_class", "classHash"
+	case "get_signature":
+		dir, arg = "signature", "blockNumber"
+	case "get_public_key":
+		return "public_key.json", nil
+	default:
+		return "", fmt.Errorf("%w: %s", errNotInTree, endpoint)
+	}
+
+	// the name comes from the request, so it must not lead out of the directory
+	name := u.Query().Get(arg)
+	if name == "" || name == "." || name == ".." || name != filepath.Base(name) {
+		return "", fmt.Errorf("%w: %s=%q", errNotInTree, arg, name)
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// readResponse reads the response to the request for u from the response tree at root.
+func readResponse(root string, u *url.URL) ([]byte, error) {
+	file, err := responseFile(u)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(filepath.Join(root, file))
+}
+
+// responseStatus is the status of the response that readResponse failed to read with err.
+func responseStatus(err error) int {
+	switch {
+	case err == nil:
+		return http.StatusOK
+	case errors.Is(err, errNotInTree):
+		return http.StatusBadRequest
+	case errors.Is(err, fs.ErrNotExist):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// directoryTransport answers the requests to the feeder with the responses in a response tree.
+type directoryTransport struct {
+	root string
+}
+
+func (t *directoryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readResponse(t.root, req.URL)
+	status := responseStatus(err)
+	if status == http.StatusInternalServerError {
+		return nil, err
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// NewDirectoryClient returns a client that replays the responses in the response tree at root instead of
+// requesting them from a feeder gateway, e.g. the ones recorded by a client with a recorder. The requests
+// whose response is missing fail with a 404 status, and are not retried.
+func NewDirectoryClient(root string) *Client {
+	c := NewClient("file:///").WithMaxRetries(0)
+	c.client = &http.Client{Transport: &directoryTransport{root: root}}
+	return c
+}
+
+// recorder saves the successful responses to the requests that go through it into a response tree.
+type recorder struct {
+	root string
+	next http.RoundTripper
+}
+
+func (r *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := r.next.RoundTrip(req)
+	if err != nil || res.StatusCode != http.StatusOK {
+		return res, err
+	}
+	file, err := responseFile(req.URL)
+	if err != nil {
+		// there is nowhere to record the response
+		return res, nil
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if closeErr := res.Body.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = writeResponse(filepath.Join(r.root, file), body); err != nil {
+		return nil, err
+	}
+
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	return res, nil
+}
+
+// writeResponse replaces the file at the given path with the response, such that readers never see a
+// partially written response.
+func writeResponse(filePath string, body []byte) error {
+	dir := filepath.Dir(filePath)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		if removeErr := os.Remove(tmp.Name()); removeErr != nil {
+			return fmt.Errorf("%w, and removing %s failed: %v", err, tmp.Name(), removeErr)
+		}
+		return err
+	}
+	return os.Rename(tmp.Name(), filePath)
+}
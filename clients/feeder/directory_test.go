@@ -0,0 +1,94 @@
+package feeder_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NethermindEth/juno/clients/feeder"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	root := t.TempDir()
+	client.WithRecorder(root)
+	ctx := context.Background()
+
+	classHash := utils.HexToFelt(t, "0x10455c752b86932ce552f2b0fe81a880746649b9aee7e0d842bf3f52378f9f8")
+	txnHash := utils.HexToFelt(t, "0x93f542728e403f1edcea4a41f1509a39be35ebcad7d4b5aa77623e5e6480d")
+
+	block, err := client.Block(ctx, 1)
+	require.NoError(t, err)
+	pending, err := client.PendingBlock(ctx)
+	require.NoError(t, err)
+	update, err := client.StateUpdate(ctx, 1)
+	require.NoError(t, err)
+	class, err := client.ClassDefinition(ctx, classHash)
+	require.NoError(t, err)
+	txn, err := client.Transaction(ctx, txnHash)
+	require.NoError(t, err)
+	signature, err := client.Signature(ctx, 1)
+	require.NoError(t, err)
+	publicKey, err := client.PublicKey(ctx)
+	require.NoError(t, err)
+
+	// failed requests are not recorded
+	_, err = client.Block(ctx, 3)
+	require.Error(t, err)
+
+	t.Run("responses are recorded as they are", func(t *testing.T) {
+		recorded, err := os.ReadFile(filepath.Join(root, "block", "1.json"))
+		require.NoError(t, err)
+		expected, err := os.ReadFile(filepath.Join("testdata", "mainnet", "block", "1.json"))
+		require.NoError(t, err)
+		assert.Equal(t, expected, recorded)
+
+		assert.NoFileExists(t, filepath.Join(root, "block", "3.json"))
+	})
+
+	t.Run("recorded responses are replayed", func(t *testing.T) {
+		replay := feeder.NewDirectoryClient(root)
+
+		replayedBlock, err := replay.Block(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, block, replayedBlock)
+
+		replayedPending, err := replay.PendingBlock(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, pending, replayedPending)
+
+		replayedUpdate, err := replay.StateUpdate(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, update, replayedUpdate)
+
+		replayedClass, err := replay.ClassDefinition(ctx, classHash)
+		require.NoError(t, err)
+		assert.Equal(t, class, replayedClass)
+
+		replayedTxn, err := replay.Transaction(ctx, txnHash)
+		require.NoError(t, err)
+		assert.Equal(t, txn, replayedTxn)
+
+		replayedSignature, err := replay.Signature(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, signature, replayedSignature)
+
+		replayedPublicKey, err := replay.PublicKey(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, publicKey, replayedPublicKey)
+	})
+
+	t.Run("missing responses", func(t *testing.T) {
+		replay := feeder.NewDirectoryClient(root)
+
+		_, err := replay.Block(ctx, 3)
+		require.EqualError(t, err, "404 Not Found")
+		_, err = replay.LatestBlock(ctx)
+		require.EqualError(t, err, "404 Not Found")
+	})
+}
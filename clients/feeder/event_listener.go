@@ -0,0 +1,32 @@
+package feeder
+
+import "time"
+
+// EventListener is notified of the requests that a [Client] makes to the feeder.
+type EventListener interface {
+	// OnResponse is called when a request to the given endpoint returns. The status is 0 if the request
+	// failed without a response.
+	OnResponse(endpoint string, status int, took time.Duration)
+	// OnRetry is called when a failed request to the given endpoint is retried.
+	OnRetry(endpoint string)
+}
+
+var _ EventListener = (*SelectiveListener)(nil)
+
+// SelectiveListener is an [EventListener] that only calls the callbacks that are set.
+type SelectiveListener struct {
+	OnResponseCb func(endpoint string, status int, took time.Duration)
+	OnRetryCb    func(endpoint string)
+}
+
+func (l *SelectiveListener) OnResponse(endpoint string, status int, took time.Duration) {
+	if l.OnResponseCb != nil {
+		l.OnResponseCb(endpoint, status, took)
+	}
+}
+
+func (l *SelectiveListener) OnRetry(endpoint string) {
+	if l.OnRetryCb != nil {
+		l.OnRetryCb(endpoint)
+	}
+}
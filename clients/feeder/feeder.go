@@ -8,16 +8,23 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
-	"os"
+	"path"
 	"path/filepath"
+	"runtime"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/NethermindEth/juno/core/felt"
 	"github.com/NethermindEth/juno/utils"
 )
 
+const (
+	// pendingBlockID is the block number the feeder gateway accepts for the pending block.
+	pendingBlockID = "pending"
+	// latestBlockID is the block number the feeder gateway accepts for the latest accepted block.
+	latestBlockID = "latest"
+)
+
 type Backoff func(wait time.Duration) time.Duration
 
 type Client struct {
@@ -28,6 +35,7 @@ type Client struct {
 	maxWait    time.Duration
 	minWait    time.Duration
 	log        utils.SimpleLogger
+	listener   EventListener
 }
 
 func (c *Client) WithBackoff(b Backoff) *Client {
@@ -55,6 +63,25 @@ func (c *Client) WithLogger(log utils.SimpleLogger) *Client {
 	return c
 }
 
+// WithRecorder makes the client save every successful response of the feeder into the response tree at
+// root, which a client made by NewDirectoryClient can replay.
+func (c *Client) WithRecorder(root string) *Client {
+	next := c.client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	client := *c.client
+	client.Transport = &recorder{root: root, next: next}
+	c.client = &client
+	return c
+}
+
+// WithListener sets the listener that is notified of the requests to the feeder.
+func (c *Client) WithListener(listener EventListener) *Client {
+	c.listener = listener
+	return c
+}
+
 func ExponentialBackoff(wait time.Duration) time.Duration {
 	return wait * 2
 }
@@ -72,52 +99,18 @@ func NewTestClient(network utils.Network) (*Client, closeTestClient) {
 }
 
 func newTestServer(network utils.Network) *httptest.Server {
-	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		queryMap, err := url.ParseQuery(r.URL.RawQuery)
-		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-
-		wd, err := os.Getwd()
-		if err != nil {
-			panic(err)
-		}
-
-		base := wd[:strings.LastIndex(wd, "juno")+4]
-		queryArg := ""
-		dir := ""
-
-		switch {
-		case strings.HasSuffix(r.URL.Path, "get_block"):
-			dir = "block"
-			queryArg = "blockNumber"
-		case strings.HasSuffix(r.URL.Path, "get_state_update"):
-			dir = "state_update"
-			queryArg = "blockNumber"
-		case strings.HasSuffix(r.URL.Path, "get_transaction"):
-			dir = "transaction"
-			queryArg = "transactionHash"
-		case strings.HasSuffix(r.URL.Path, "get_class_by_hash"):
-			dir = "class"
-			queryArg = "classHash"
-		}
-
-		fileName, found := queryMap[queryArg]
-		if !found {
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
+	// the test data is found next to this file, wherever the tests are run from
+	_, thisFile, _, _ := runtime.Caller(0)
+	testdata := filepath.Join(filepath.Dir(thisFile), "testdata")
 
-		path := filepath.Join(base, "clients", "feeder", "testdata", network.String(), dir, fileName[0]+".json")
-		read, err := os.ReadFile(path)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := readResponse(filepath.Join(testdata, network.String()), r.URL)
 		if err != nil {
-			w.WriteHeader(http.StatusNotFound)
+			w.WriteHeader(responseStatus(err))
 			return
 		}
 
-		_, err = w.Write(read)
-		if err != nil {
+		if _, err = w.Write(body); err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 		}
 	}))
@@ -132,6 +125,7 @@ func NewClient(clientURL string) *Client {
 		maxWait:    time.Minute,
 		minWait:    time.Second,
 		log:        utils.NewNopZapLogger(),
+		listener:   &SelectiveListener{},
 	}
 }
 
@@ -170,7 +164,18 @@ func (c *Client) get(ctx context.Context, queryURL string) ([]byte, error) {
 				return nil, err
 			}
 
+			endpoint := path.Base(req.URL.Path)
+			if i > 0 {
+				c.listener.OnRetry(endpoint)
+			}
+
+			start := time.Now()
 			res, err = c.client.Do(req)
+			status := 0
+			if err == nil {
+				status = res.StatusCode
+			}
+			c.listener.OnResponse(endpoint, status, time.Since(start))
 			if err == nil {
 				if res.StatusCode == http.StatusOK {
 					resBytes, err = io.ReadAll(res.Body)
@@ -203,8 +208,17 @@ func (c *Client) get(ctx context.Context, queryURL string) ([]byte, error) {
 }
 
 func (c *Client) StateUpdate(ctx context.Context, blockNumber uint64) (*StateUpdate, error) {
+	return c.stateUpdate(ctx, strconv.FormatUint(blockNumber, 10))
+}
+
+// PendingStateUpdate returns the state update of the pending block, which has neither a block hash nor a new root.
+func (c *Client) PendingStateUpdate(ctx context.Context) (*StateUpdate, error) {
+	return c.stateUpdate(ctx, pendingBlockID)
+}
+
+func (c *Client) stateUpdate(ctx context.Context, blockID string) (*StateUpdate, error) {
 	queryURL := c.buildQueryString("get_state_update", map[string]string{
-		"blockNumber": strconv.FormatUint(blockNumber, 10),
+		"blockNumber": blockID,
 	})
 
 	body, err := c.get(ctx, queryURL)
@@ -237,8 +251,23 @@ func (c *Client) Transaction(ctx context.Context, transactionHash *felt.Felt) (*
 }
 
 func (c *Client) Block(ctx context.Context, blockNumber uint64) (*Block, error) {
+	return c.block(ctx, strconv.FormatUint(blockNumber, 10))
+}
+
+// PendingBlock returns the block that is currently being built by the sequencer. It has no hash, number
+// or state root.
+func (c *Client) PendingBlock(ctx context.Context) (*Block, error) {
+	return c.block(ctx, pendingBlockID)
+}
+
+// LatestBlock returns the latest block accepted on L2.
+func (c *Client) LatestBlock(ctx context.Context) (*Block, error) {
+	return c.block(ctx, latestBlockID)
+}
+
+func (c *Client) block(ctx context.Context, blockID string) (*Block, error) {
 	queryURL := c.buildQueryString("get_block", map[string]string{
-		"blockNumber": strconv.FormatUint(blockNumber, 10),
+		"blockNumber": blockID,
 	})
 
 	body, err := c.get(ctx, queryURL)
@@ -269,3 +298,55 @@ func (c *Client) ClassDefinition(ctx context.Context, classHash *felt.Felt) (*Cl
 	}
 	return class, nil
 }
+
+// CompiledClassDefinition returns the Cairo assembly that the Sierra class with the given hash compiles to.
+func (c *Client) CompiledClassDefinition(ctx context.Context, classHash *felt.Felt) (*CompiledClass, error) {
+	queryURL := c.buildQueryString("get_compiled_class_by_class_hash", map[string]string{
+		"classHash": classHash.String(),
+	})
+
+	body, err := c.get(ctx, queryURL)
+	if err != nil {
+		return nil, err
+	}
+
+	class := new(CompiledClass)
+	if err = json.Unmarshal(body, class); err != nil {
+		return nil, err
+	}
+	return class, nil
+}
+
+// Signature returns the signature of the sequencer over the block with the given number.
+func (c *Client) Signature(ctx context.Context, blockNumber uint64) (*Signature, error) {
+	queryURL := c.buildQueryString("get_signature", map[string]string{
+		"blockNumber": strconv.FormatUint(blockNumber, 10),
+	})
+
+	body, err := c.get(ctx, queryURL)
+	if err != nil {
+		return nil, err
+	}
+
+	signature := new(Signature)
+	if err = json.Unmarshal(body, signature); err != nil {
+		return nil, err
+	}
+	return signature, nil
+}
+
+// PublicKey returns the public key that the sequencer signs blocks with.
+func (c *Client) PublicKey(ctx context.Context) (*felt.Felt, error) {
+	queryURL := c.buildQueryString("get_public_key", nil)
+
+	body, err := c.get(ctx, queryURL)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey := new(felt.Felt)
+	if err = json.Unmarshal(body, publicKey); err != nil {
+		return nil, err
+	}
+	return publicKey, nil
+}
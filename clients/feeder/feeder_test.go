@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/NethermindEth/juno/clients/feeder"
 	"github.com/NethermindEth/juno/core/felt"
@@ -322,6 +323,42 @@ func TestBlock(t *testing.T) {
 	})
 }
 
+func TestPendingBlock(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+
+	pendingBlock, err := client.PendingBlock(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "PENDING", pendingBlock.Status)
+	assert.Nil(t, pendingBlock.Hash)
+	assert.Nil(t, pendingBlock.StateRoot)
+	assert.NotNil(t, pendingBlock.ParentHash)
+	assert.NotEmpty(t, pendingBlock.Transactions)
+}
+
+func TestLatestBlock(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+
+	latestBlock, err := client.LatestBlock(context.Background())
+	require.NoError(t, err)
+	block2, err := client.Block(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, block2, latestBlock)
+}
+
+func TestPendingStateUpdate(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+
+	pendingUpdate, err := client.PendingStateUpdate(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, pendingUpdate.BlockHash)
+	assert.Nil(t, pendingUpdate.NewRoot)
+	assert.NotNil(t, pendingUpdate.OldRoot)
+	assert.NotEmpty(t, pendingUpdate.StateDiff.DeployedContracts)
+}
+
 func TestClassDefinition(t *testing.T) {
 	client, closeFn := feeder.NewTestClient(utils.MAINNET)
 	t.Cleanup(closeFn)
@@ -341,6 +378,76 @@ func TestClassDefinition(t *testing.T) {
 	})
 }
 
+func TestCompiledClassDefinition(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	ctx := context.Background()
+
+	compiled, err := client.CompiledClassDefinition(ctx, utils.HexToFelt(t, "0xc0de"))
+	require.NoError(t, err)
+	assert.Equal(t, "0x800000000000011000000000000000000000000000000000000000000000001", compiled.Prime)
+	assert.Equal(t, "2.6.0", compiled.CompilerVersion)
+	require.Len(t, compiled.Bytecode, 10)
+	assert.Equal(t, utils.HexToFelt(t, "0xa0680017fff8000"), compiled.Bytecode[0])
+	assert.Equal(t, utils.HexToFelt(t, "0x400280007ff97fff"), compiled.Bytecode[9])
+	assert.JSONEq(t, `[[0, ["memory[ap + 0] = 6956 <= memory[fp + -6]"]]]`, string(compiled.PythonicHints))
+	assert.NotEmpty(t, compiled.Hints)
+	assert.Equal(t, []feeder.CompiledEntryPoint{{
+		Selector: utils.HexToFelt(t, "0x22ff5f21f0b81b113e63f7db6da94fedef11b2119b4088b89664fb9a3cb658"),
+		Builtins: []string{"range_check"},
+		Offset:   0,
+	}}, compiled.EntryPoints.External)
+	assert.Empty(t, compiled.EntryPoints.L1Handler)
+	assert.Equal(t, []feeder.CompiledEntryPoint{{
+		Selector: utils.HexToFelt(t, "0x28ffe4ff0f226a9107253e17a904099aa4f63a02a5621de0576e5aa71bc5194"),
+		Builtins: []string{},
+		Offset:   7,
+	}}, compiled.EntryPoints.Constructor)
+	assert.Equal(t, &feeder.SegmentLengths{Children: []feeder.SegmentLengths{
+		{Length: 3},
+		{Children: []feeder.SegmentLengths{{Length: 2}, {Length: 2}}},
+		{Length: 3},
+	}}, compiled.BytecodeSegmentLengths)
+
+	t.Run("class compiled without bytecode segments", func(t *testing.T) {
+		unsegmented, err := client.CompiledClassDefinition(ctx, utils.HexToFelt(t, "0xc0df"))
+		require.NoError(t, err)
+		assert.Equal(t, compiled.Bytecode, unsegmented.Bytecode)
+		assert.Nil(t, unsegmented.BytecodeSegmentLengths)
+	})
+
+	t.Run("unknown class", func(t *testing.T) {
+		_, err := client.CompiledClassDefinition(ctx, new(felt.Felt))
+		assert.Error(t, err)
+	})
+}
+
+func TestSignature(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+
+	signature, err := client.Signature(context.Background(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), signature.BlockNumber)
+	assert.Equal(t, []*felt.Felt{
+		utils.HexToFelt(t, "0x1997079aca62c11e523279cd41a1cb8b02980f0db3af26f64e5cb4fa4f88111"),
+		utils.HexToFelt(t, "0x4ead7d59a5552aaa4a8225f7321df060948d3dc7d25c87f7a675b12ecd5f675"),
+	}, signature.Signature)
+	assert.Equal(t, utils.HexToFelt(t, "0x47c3637b57c2b079b93c61539950c17e868a28f46cdef28f88521067f21e943"),
+		signature.SignatureInput.BlockHash)
+	assert.Equal(t, utils.HexToFelt(t, "0x6c4a7559b57caded12ad2275f78c4ac310ff54b2e233d25c9cf4891c251b450"),
+		signature.SignatureInput.StateDiffCommitment)
+}
+
+func TestPublicKey(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+
+	publicKey, err := client.PublicKey(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, utils.HexToFelt(t, "0x1388d65f3f9de8bf3cf9921f3dd30808b8aa6f0468a7f5ebcc810053a6909a4"), publicKey)
+}
+
 func TestHttpError(t *testing.T) {
 	maxRetries := 2
 	callCount := make(map[string]int)
@@ -366,11 +473,26 @@ func TestHttpError(t *testing.T) {
 		assert.EqualError(t, err, "500 Internal Server Error")
 	})
 
+	t.Run("HTTP err in GetCompiledClassDefinition", func(t *testing.T) {
+		_, err := client.CompiledClassDefinition(context.Background(), new(felt.Felt))
+		assert.EqualError(t, err, "500 Internal Server Error")
+	})
+
 	t.Run("HTTP err in GetStateUpdate", func(t *testing.T) {
 		_, err := client.StateUpdate(context.Background(), 0)
 		assert.EqualError(t, err, "500 Internal Server Error")
 	})
 
+	t.Run("HTTP err in GetSignature", func(t *testing.T) {
+		_, err := client.Signature(context.Background(), 0)
+		assert.EqualError(t, err, "500 Internal Server Error")
+	})
+
+	t.Run("HTTP err in GetPublicKey", func(t *testing.T) {
+		_, err := client.PublicKey(context.Background())
+		assert.EqualError(t, err, "500 Internal Server Error")
+	})
+
 	for _, called := range callCount {
 		assert.Equal(t, maxRetries+1, called)
 	}
@@ -391,3 +513,37 @@ func TestBackoffFailure(t *testing.T) {
 	assert.EqualError(t, err, "500 Internal Server Error")
 	assert.Equal(t, maxRetries, try-1) // we have retried `maxRetries` times
 }
+
+func TestClientListener(t *testing.T) {
+	failures := 2
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, err := w.Write([]byte(`"0x1"`))
+		require.NoError(t, err)
+	}))
+	t.Cleanup(srv.Close)
+
+	var statuses []int
+	retries := 0
+	listener := &feeder.SelectiveListener{
+		OnResponseCb: func(endpoint string, status int, took time.Duration) {
+			assert.Equal(t, "get_public_key", endpoint)
+			assert.Greater(t, took, time.Duration(0))
+			statuses = append(statuses, status)
+		},
+		OnRetryCb: func(endpoint string) {
+			assert.Equal(t, "get_public_key", endpoint)
+			retries++
+		},
+	}
+	client := feeder.NewClient(srv.URL).WithBackoff(feeder.NopBackoff).WithMaxRetries(2).WithListener(listener)
+
+	_, err := client.PublicKey(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK}, statuses)
+	assert.Equal(t, 2, retries)
+}
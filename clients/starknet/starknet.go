@@ -0,0 +1,224 @@
+// Package starknet provides a client for the Starknet JSON-RPC API, as served by Juno and by other Starknet
+// nodes that follow the specification.
+package starknet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/rpc"
+)
+
+const jsonrpcVersion = "2.0"
+
+// Client is a minimal Starknet JSON-RPC client that supports the calls Juno needs to sync from another node.
+type Client struct {
+	url    string
+	client *http.Client
+	nextID uint64
+}
+
+func NewClient(clientURL string) *Client {
+	return &Client{
+		url:    clientURL,
+		client: http.DefaultClient,
+	}
+}
+
+type request struct {
+	Version string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  []any  `json:"params"`
+	ID      uint64 `json:"id"`
+}
+
+type response struct {
+	Version string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      uint64          `json:"id"`
+}
+
+// Error is an error returned by the Starknet node.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("starknet json-rpc error %d: %s", e.Code, e.Message)
+}
+
+// Is matches the errors with the same code, such as the errors of the specification in the rpc package.
+func (e *Error) Is(target error) bool {
+	var other *Error
+	return errors.As(target, &other) && other.Code == e.Code
+}
+
+var (
+	ErrBlockNotFound     = &Error{Code: rpc.ErrBlockNotFound.Code, Message: rpc.ErrBlockNotFound.Message}
+	ErrClassHashNotFound = &Error{Code: rpc.ErrClassHashNotFound.Code, Message: rpc.ErrClassHashNotFound.Message}
+)
+
+// call is a JSON-RPC call whose result is unmarshalled into result.
+type call struct {
+	method string
+	params []any
+	result any
+}
+
+func (c *Client) newRequest(method string, params []any) *request {
+	if params == nil {
+		params = []any{}
+	}
+	return &request{
+		Version: jsonrpcVersion,
+		Method:  method,
+		Params:  params,
+		ID:      atomic.AddUint64(&c.nextID, 1),
+	}
+}
+
+// do performs a single call.
+func (c *Client) do(ctx context.Context, method string, result any, params ...any) error {
+	req := c.newRequest(method, params)
+	var res response
+	if err := c.post(ctx, req, &res); err != nil {
+		return err
+	}
+	return unmarshalResult(&res, req.ID, result)
+}
+
+// batch performs the calls in a single request, and fails if any of them fails.
+func (c *Client) batch(ctx context.Context, calls []call) error {
+	if len(calls) == 0 {
+		return nil
+	}
+
+	reqs := make([]*request, len(calls))
+	for i, cl := range calls {
+		reqs[i] = c.newRequest(cl.method, cl.params)
+	}
+
+	var ress []response
+	if err := c.post(ctx, reqs, &ress); err != nil {
+		return err
+	}
+	if len(ress) != len(reqs) {
+		return fmt.Errorf("batch of %d calls got %d responses", len(reqs), len(ress))
+	}
+
+	// the responses may come in any order
+	byID := make(map[uint64]*response, len(ress))
+	for i := range ress {
+		byID[ress[i].ID] = &ress[i]
+	}
+	for i, req := range reqs {
+		res, ok := byID[req.ID]
+		if !ok {
+			return fmt.Errorf("no response to call %d of the batch", req.ID)
+		}
+		if err := unmarshalResult(res, req.ID, calls[i].result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// post sends the body to the node and unmarshals the response into res.
+func (c *Client) post(ctx context.Context, body, res any) error {
+	reqBytes, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.url, bytes.NewReader(reqBytes))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	httpRes, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer httpRes.Body.Close()
+
+	if httpRes.StatusCode != http.StatusOK {
+		return errors.New(httpRes.Status)
+	}
+
+	resBytes, err := io.ReadAll(httpRes.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(resBytes, res)
+}
+
+func unmarshalResult(res *response, id uint64, result any) error {
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.ID != id {
+		return fmt.Errorf("response to call %d has id %d", id, res.ID)
+	}
+	return json.Unmarshal(res.Result, result)
+}
+
+// BlockWithTxs returns the block with the given id, along with its transactions.
+func (c *Client) BlockWithTxs(ctx context.Context, id *rpc.BlockID) (*rpc.BlockWithTxs, error) {
+	block := new(rpc.BlockWithTxs)
+	if err := c.do(ctx, "starknet_getBlockWithTxs", block, id); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// TransactionByHash returns the transaction with the given hash.
+func (c *Client) TransactionByHash(ctx context.Context, hash *felt.Felt) (*rpc.Transaction, error) {
+	txn := new(rpc.Transaction)
+	if err := c.do(ctx, "starknet_getTransactionByHash", txn, hash); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+// TransactionReceipts returns the receipts of the transactions with the given hashes, in the same order, all
+// in a single request.
+func (c *Client) TransactionReceipts(ctx context.Context, hashes []*felt.Felt) ([]*rpc.TransactionReceipt, error) {
+	receipts := make([]*rpc.TransactionReceipt, len(hashes))
+	calls := make([]call, len(hashes))
+	for i, hash := range hashes {
+		receipts[i] = new(rpc.TransactionReceipt)
+		calls[i] = call{method: "starknet_getTransactionReceipt", params: []any{hash}, result: receipts[i]}
+	}
+	if err := c.batch(ctx, calls); err != nil {
+		return nil, err
+	}
+	return receipts, nil
+}
+
+// StateUpdate returns the state update of the block with the given id.
+func (c *Client) StateUpdate(ctx context.Context, id *rpc.BlockID) (*rpc.StateUpdate, error) {
+	update := new(rpc.StateUpdate)
+	if err := c.do(ctx, "starknet_getStateUpdate", update, id); err != nil {
+		return nil, err
+	}
+	return update, nil
+}
+
+// Class returns the definition of the class with the given hash, as of the block with the given id.
+func (c *Client) Class(ctx context.Context, id *rpc.BlockID, classHash *felt.Felt) (*rpc.Class, error) {
+	class := new(rpc.Class)
+	if err := c.do(ctx, "starknet_getClass", class, id, classHash); err != nil {
+		return nil, err
+	}
+	return class, nil
+}
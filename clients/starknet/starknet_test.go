@@ -0,0 +1,187 @@
+package starknet_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/NethermindEth/juno/clients/starknet"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type request struct {
+	Version string            `json:"jsonrpc"`
+	Method  string            `json:"method"`
+	Params  []json.RawMessage `json:"params"`
+	ID      uint64            `json:"id"`
+}
+
+type response struct {
+	Version string          `json:"jsonrpc"`
+	Result  any             `json:"result,omitempty"`
+	Error   *starknet.Error `json:"error,omitempty"`
+	ID      uint64          `json:"id"`
+}
+
+// newTestClient returns a client of a node that answers the posted requests with handle, and counts
+// the posts.
+func newTestClient(t *testing.T, handle func(t *testing.T, body []byte) any) (*starknet.Client, *uint64) {
+	t.Helper()
+
+	posts := new(uint64)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddUint64(posts, 1)
+		var body json.RawMessage
+		if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&body)) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(handle(t, body)))
+	}))
+	t.Cleanup(srv.Close)
+	return starknet.NewClient(srv.URL), posts
+}
+
+func TestTransactionReceipts(t *testing.T) {
+	hashes := []*felt.Felt{new(felt.Felt).SetUint64(1), new(felt.Felt).SetUint64(2), new(felt.Felt).SetUint64(3)}
+
+	// receipts answers a batch of receipt requests in reverse order, with the receipts of the requested hashes
+	receipts := func(t *testing.T, body []byte) []response {
+		var reqs []request
+		require.NoError(t, json.Unmarshal(body, &reqs))
+
+		ress := make([]response, 0, len(reqs))
+		for i := len(reqs) - 1; i >= 0; i-- {
+			assert.Equal(t, "starknet_getTransactionReceipt", reqs[i].Method)
+			require.Len(t, reqs[i].Params, 1)
+			hash := new(felt.Felt)
+			require.NoError(t, json.Unmarshal(reqs[i].Params[0], hash))
+			ress = append(ress, response{
+				Version: "2.0",
+				Result:  &rpc.TransactionReceipt{Hash: hash, Status: rpc.StatusAcceptedL2},
+				ID:      reqs[i].ID,
+			})
+		}
+		return ress
+	}
+
+	t.Run("the receipts are requested in a single batch and matched by id", func(t *testing.T) {
+		client, posts := newTestClient(t, func(t *testing.T, body []byte) any {
+			return receipts(t, body)
+		})
+
+		got, err := client.TransactionReceipts(context.Background(), hashes)
+		require.NoError(t, err)
+		require.Len(t, got, len(hashes))
+		for i, receipt := range got {
+			assert.Equal(t, hashes[i], receipt.Hash)
+		}
+		assert.Equal(t, uint64(1), atomic.LoadUint64(posts))
+	})
+
+	t.Run("no receipts are no request", func(t *testing.T) {
+		client, posts := newTestClient(t, func(t *testing.T, body []byte) any {
+			return receipts(t, body)
+		})
+
+		got, err := client.TransactionReceipts(context.Background(), nil)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+		assert.Zero(t, atomic.LoadUint64(posts))
+	})
+
+	t.Run("a missing response fails the batch", func(t *testing.T) {
+		client, _ := newTestClient(t, func(t *testing.T, body []byte) any {
+			return receipts(t, body)[1:]
+		})
+
+		_, err := client.TransactionReceipts(context.Background(), hashes)
+		assert.Error(t, err)
+	})
+
+	t.Run("a response to another call fails the batch", func(t *testing.T) {
+		client, _ := newTestClient(t, func(t *testing.T, body []byte) any {
+			ress := receipts(t, body)
+			ress[0].ID += 100
+			return ress
+		})
+
+		_, err := client.TransactionReceipts(context.Background(), hashes)
+		assert.Error(t, err)
+	})
+
+	t.Run("an error fails the batch", func(t *testing.T) {
+		client, _ := newTestClient(t, func(t *testing.T, body []byte) any {
+			ress := receipts(t, body)
+			ress[1].Result = nil
+			ress[1].Error = &starknet.Error{Code: rpc.ErrTxnHashNotFound.Code, Message: rpc.ErrTxnHashNotFound.Message}
+			return ress
+		})
+
+		_, err := client.TransactionReceipts(context.Background(), hashes)
+		var rpcErr *starknet.Error
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, rpc.ErrTxnHashNotFound.Code, rpcErr.Code)
+	})
+}
+
+func TestCall(t *testing.T) {
+	blockID := &rpc.BlockID{Number: 7}
+
+	t.Run("the result of a call is returned", func(t *testing.T) {
+		root := new(felt.Felt).SetUint64(0x2007)
+		client, _ := newTestClient(t, func(t *testing.T, body []byte) any {
+			var req request
+			require.NoError(t, json.Unmarshal(body, &req))
+			assert.Equal(t, "2.0", req.Version)
+			assert.Equal(t, "starknet_getStateUpdate", req.Method)
+			require.Len(t, req.Params, 1)
+			assert.JSONEq(t, `{"block_number":7}`, string(req.Params[0]))
+			return response{Version: "2.0", Result: &rpc.StateUpdate{NewRoot: root}, ID: req.ID}
+		})
+
+		update, err := client.StateUpdate(context.Background(), blockID)
+		require.NoError(t, err)
+		assert.Equal(t, root, update.NewRoot)
+	})
+
+	t.Run("a response to another call is an error", func(t *testing.T) {
+		client, _ := newTestClient(t, func(t *testing.T, body []byte) any {
+			var req request
+			require.NoError(t, json.Unmarshal(body, &req))
+			return response{Version: "2.0", Result: &rpc.StateUpdate{}, ID: req.ID + 1}
+		})
+
+		_, err := client.StateUpdate(context.Background(), blockID)
+		assert.Error(t, err)
+	})
+
+	t.Run("errors of the node match the errors of the specification with the same code", func(t *testing.T) {
+		client, _ := newTestClient(t, func(t *testing.T, body []byte) any {
+			var req request
+			require.NoError(t, json.Unmarshal(body, &req))
+			return response{Version: "2.0", Error: &starknet.Error{Code: rpc.ErrBlockNotFound.Code, Message: "no block"}, ID: req.ID}
+		})
+
+		_, err := client.BlockWithTxs(context.Background(), blockID)
+		assert.ErrorIs(t, err, starknet.ErrBlockNotFound)
+		assert.False(t, errors.Is(err, starknet.ErrClassHashNotFound))
+	})
+
+	t.Run("http errors are errors", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		t.Cleanup(srv.Close)
+
+		_, err := starknet.NewClient(srv.URL).BlockWithTxs(context.Background(), blockID)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"io"
+	"os"
+
+	"github.com/NethermindEth/juno/archive"
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/db"
+	"github.com/NethermindEth/juno/db/pebble"
+	"github.com/NethermindEth/juno/migration"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/spf13/cobra"
+)
+
+const (
+	fromF  = "from"
+	toF    = "to"
+	inputF = "input"
+
+	defaultFrom  = uint64(0)
+	defaultTo    = uint64(0)
+	defaultInput = ""
+
+	fromUsage          = "The first block to export."
+	toUsage            = "The last block to export. If not set, the blocks are exported up to the latest synced block."
+	archiveOutputUsage = "The file the archive is written to. If not set, it is written to the standard output."
+	inputUsage         = "The archive to import. If not set, it is read from the standard input."
+)
+
+// NewExportCmd returns a command that writes a range of the blocks in the database to an archive, which
+// can be imported into another database. The database must not be in use by a running node.
+func NewExportCmd() *cobra.Command {
+	exportCmd := &cobra.Command{
+		Use:   "export [flags]",
+		Short: "Exports a range of synced blocks, with their state updates and classes, to an archive.",
+		Args:  cobra.NoArgs,
+	}
+
+	var dbPath, output string
+	var from, to uint64
+	network := utils.MAINNET
+
+	exportCmd.RunE = func(cmd *cobra.Command, _ []string) error {
+		var err error
+		if network, err = networkFromFlags(cmd, network); err != nil {
+			return err
+		}
+		path, err := existingDBPath(dbPath, network)
+		if err != nil {
+			return err
+		}
+
+		return withChain(path, network, func(chain *blockchain.Blockchain, log utils.SimpleLogger) error {
+			if !cmd.Flags().Changed(toF) {
+				if to, err = chain.Height(); err != nil {
+					return err
+				}
+			}
+
+			if output == "" {
+				return archive.Export(cmd.Context(), chain, from, to, cmd.OutOrStdout(), log)
+			}
+			file, createErr := os.Create(output)
+			if createErr != nil {
+				return createErr
+			}
+			return db.CloseAndWrapOnError(file.Close, archive.Export(cmd.Context(), chain, from, to, file, log))
+		})
+	}
+
+	exportCmd.Flags().StringVar(&dbPath, dbPathF, defaultDBPath, dbPathUsage)
+	exportCmd.Flags().Var(&network, networkF, networkUsage)
+	exportCmd.Flags().Uint64Var(&from, fromF, defaultFrom, fromUsage)
+	exportCmd.Flags().Uint64Var(&to, toF, defaultTo, toUsage)
+	exportCmd.Flags().StringVar(&output, outputF, defaultOutput, archiveOutputUsage)
+	addCustomNetworkFlags(exportCmd)
+
+	return exportCmd
+}
+
+// NewImportCmd returns a command that stores the blocks of an archive in the database, after verifying
+// them like synced blocks. The database is created if it does not exist, and must not be in use by a
+// running node.
+func NewImportCmd() *cobra.Command {
+	importCmd := &cobra.Command{
+		Use:   "import [flags]",
+		Short: "Imports the blocks of an archive that continues the synced blocks.",
+		Args:  cobra.NoArgs,
+	}
+
+	var dbPath, input string
+	network := utils.MAINNET
+
+	importCmd.RunE = func(cmd *cobra.Command, _ []string) error {
+		var err error
+		if network, err = networkFromFlags(cmd, network); err != nil {
+			return err
+		}
+		path, err := dbPathOrDefault(dbPath, network)
+		if err != nil {
+			return err
+		}
+
+		return withChain(path, network, func(chain *blockchain.Blockchain, log utils.SimpleLogger) error {
+			if input == "" {
+				return importArchive(cmd, chain, cmd.InOrStdin(), log)
+			}
+			file, openErr := os.Open(input)
+			if openErr != nil {
+				return openErr
+			}
+			return db.CloseAndWrapOnError(file.Close, importArchive(cmd, chain, file, log))
+		})
+	}
+
+	importCmd.Flags().StringVar(&dbPath, dbPathF, defaultDBPath, dbPathUsage)
+	importCmd.Flags().Var(&network, networkF, networkUsage)
+	importCmd.Flags().StringVar(&input, inputF, defaultInput, inputUsage)
+	addCustomNetworkFlags(importCmd)
+
+	return importCmd
+}
+
+func importArchive(cmd *cobra.Command, chain *blockchain.Blockchain, r io.Reader, log utils.SimpleLogger) error {
+	imported, err := archive.Import(cmd.Context(), chain, r, log)
+	log.Infow("Imported blocks", "count", imported)
+	return err
+}
+
+// withChain opens the database at the given path and calls fn with the chain in it.
+func withChain(dbPath string, network utils.Network, fn func(*blockchain.Blockchain, utils.SimpleLogger) error) error {
+	log, err := utils.NewZapLogger(utils.INFO)
+	if err != nil {
+		return err
+	}
+	dbLog, err := utils.NewZapLogger(utils.ERROR)
+	if err != nil {
+		return err
+	}
+	database, err := pebble.New(dbPath, dbLog)
+	if err != nil {
+		return err
+	}
+
+	if err = migration.MigrateIfNeeded(database, network, log); err != nil {
+		return db.CloseAndWrapOnError(database.Close, err)
+	}
+	return db.CloseAndWrapOnError(database.Close, fn(blockchain.New(database, network, log), log))
+}
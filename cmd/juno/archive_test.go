@@ -0,0 +1,100 @@
+package main_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/clients/feeder"
+	juno "github.com/NethermindEth/juno/cmd/juno"
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db/pebble"
+	"github.com/NethermindEth/juno/node"
+	adaptfeeder "github.com/NethermindEth/juno/starknetdata/feeder"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImport(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+	ctx := context.Background()
+
+	sourcePath := t.TempDir()
+	database, err := pebble.New(sourcePath, utils.NewNopZapLogger())
+	require.NoError(t, err)
+	source := blockchain.New(database, utils.MAINNET, utils.NewNopZapLogger())
+	for number := uint64(0); number < 2; number++ {
+		block, err := gw.BlockByNumber(ctx, number)
+		require.NoError(t, err)
+		update, err := gw.StateUpdate(ctx, number)
+		require.NoError(t, err)
+		classes := make(map[felt.Felt]core.Class)
+		for _, classHash := range update.StateDiff.ClassHashes() {
+			classes[*classHash], err = gw.Class(ctx, classHash)
+			require.NoError(t, err)
+		}
+		require.NoError(t, source.Store(block, update, classes))
+	}
+	expectedHead, err := source.HeadsHeader()
+	require.NoError(t, err)
+	require.NoError(t, database.Close())
+
+	execute := func(t *testing.T, args ...string) error {
+		t.Helper()
+
+		cmd := juno.NewCmd(new(node.Config), func(_ *cobra.Command, _ []string) error { return nil })
+		cmd.SetArgs(args)
+		return cmd.ExecuteContext(ctx)
+	}
+
+	t.Run("database does not exist", func(t *testing.T) {
+		missing := filepath.Join(t.TempDir(), "missing")
+		err := execute(t, "export", "--db-path", missing, "--output", filepath.Join(t.TempDir(), "archive"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+		assert.NoDirExists(t, missing)
+	})
+
+	t.Run("block beyond the head", func(t *testing.T) {
+		err := execute(t, "export", "--db-path", sourcePath, "--to", "2", "--output", filepath.Join(t.TempDir(), "archive"))
+		require.Error(t, err)
+	})
+
+	t.Run("export and import the synced blocks", func(t *testing.T) {
+		archivePath := filepath.Join(t.TempDir(), "archive")
+		require.NoError(t, execute(t, "export", "--db-path", sourcePath, "--output", archivePath))
+
+		targetPath := filepath.Join(t.TempDir(), "target")
+		require.NoError(t, execute(t, "import", "--db-path", targetPath, "--input", archivePath))
+
+		target, err := pebble.New(targetPath, utils.NewNopZapLogger())
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, target.Close())
+		})
+		head, err := blockchain.New(target, utils.MAINNET, utils.NewNopZapLogger()).HeadsHeader()
+		require.NoError(t, err)
+		assert.Equal(t, expectedHead, head)
+	})
+
+	t.Run("custom network", func(t *testing.T) {
+		customFlags := []string{
+			"--network", "custom", "--cn-name", "devnet", "--cn-feeder-url", "http://localhost:5050/feeder_gateway/",
+			"--cn-chain-id", "SN_DEVNET", "--cn-unverifiable-range", "0,1",
+		}
+		archivePath := filepath.Join(t.TempDir(), "archive")
+		require.NoError(t, execute(t, append([]string{"export", "--db-path", sourcePath, "--output", archivePath}, customFlags...)...))
+
+		targetPath := filepath.Join(t.TempDir(), "target")
+		require.Error(t, execute(t, "import", "--db-path", targetPath, "--input", archivePath))
+		require.NoError(t, execute(t, append([]string{"import", "--db-path", targetPath, "--input", archivePath}, customFlags...)...))
+
+		require.Error(t, execute(t, "import", "--db-path", targetPath, "--input", archivePath, "--network", "custom"))
+	})
+}
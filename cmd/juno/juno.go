@@ -6,7 +6,9 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/NethermindEth/juno/core/felt"
 	"github.com/NethermindEth/juno/node"
 	"github.com/NethermindEth/juno/utils"
 	"github.com/mitchellh/mapstructure"
@@ -28,22 +30,96 @@ const (
 	configF   = "config"
 	logLevelF = "log-level"
 	rpcPortF  = "rpc-port"
+	wsF       = "ws"
+	wsPortF   = "ws-port"
 	dbPathF   = "db-path"
 	networkF  = "network"
 	pprofF    = "pprof"
+	metricsF  = "metrics"
+
+	metricsPortF      = "metrics-port"
+	repairClassesF    = "repair-classes"
+	ethNodeF          = "eth-node"
+	verifySignaturesF = "verify-signatures"
+	replayDirF        = "replay-dir"
+	recordDirF        = "record-dir"
+	upstreamQuorumF   = "upstream-quorum"
+	upstreamTimeoutF  = "upstream-timeout"
+	p2pF              = "p2p"
+	p2pPortF          = "p2p-port"
+	p2pPeersF         = "p2p-peers"
+
+	cnNameF                     = "cn-name"
+	cnFeederURLF                = "cn-feeder-url"
+	cnChainIDF                  = "cn-chain-id"
+	cnFirst07BlockF             = "cn-first-07-block"
+	cnUnverifiableRangeF        = "cn-unverifiable-range"
+	cnFallBackSequencerAddressF = "cn-fallback-sequencer-address"
+	cnPublicKeyF                = "cn-public-key"
 
 	defaultConfig  = ""
 	defaultRPCPort = uint16(6060)
+	defaultWS      = false
+	defaultWSPort  = uint16(6061)
 	defaultDBPath  = ""
 	defaultPprof   = false
+	defaultMetrics = false
+
+	defaultMetricsPort      = uint16(9090)
+	defaultRepairClasses    = false
+	defaultEthNode          = ""
+	defaultVerifySignatures = false
+	defaultReplayDir        = ""
+	defaultRecordDir        = ""
+	defaultUpstreamQuorum   = 1
+	defaultUpstreamTimeout  = time.Minute
+	defaultP2P              = false
+	defaultP2PPort          = uint16(6062)
+
+	defaultCnName                     = ""
+	defaultCnFeederURL                = ""
+	defaultCnChainID                  = ""
+	defaultCnFirst07Block             = uint64(0)
+	defaultCnFallBackSequencerAddress = ""
+	defaultCnPublicKey                = ""
 
 	configFlagUsage   = "The yaml configuration file."
 	logLevelFlagUsage = "Options: debug, info, warn, error."
 	rpcPortUsage      = "The port on which the RPC server will listen for requests. " +
 		"Warning: this exposes the node to external requests and potentially DoS attacks."
+	wsUsage      = "Enables the WebSocket RPC server, which also supports subscriptions."
+	wsPortUsage  = "The port on which the WebSocket RPC server will listen for connections."
 	dbPathUsage  = "Location of the database files."
-	networkUsage = "Options: mainnet, goerli, goerli2, integration."
+	networkUsage = "Options: mainnet, goerli, goerli2, integration, custom. A custom network is described by the cn-* options."
 	pprofUsage   = "Enables the pprof server and listens on port 9080."
+	metricsUsage = "Enables the Prometheus metrics endpoint, which listens on the port set by metrics-port."
+
+	metricsPortUsage   = "The port on which the Prometheus metrics endpoint listens, on localhost only."
+	repairClassesUsage = "Fetches the classes that are referenced by synced blocks but missing from the database before syncing."
+	ethNodeUsage       = "The URL of an Ethereum node's JSON-RPC API. If set, the state roots of synced blocks are verified " +
+		"against the ones posted to the Starknet core contract on L1."
+	verifySignaturesUsage = "Rejects the synced blocks whose signature by the sequencer does not verify against the public key " +
+		"of the sequencer."
+	replayDirUsage = "A directory of recorded feeder gateway responses that blocks are synced from instead of the feeder gateway, " +
+		"for reproducible syncs."
+	recordDirUsage = "A directory that every response of the feeder gateway is recorded into, in the layout that " +
+		"replay-dir reads."
+	upstreamQuorumUsage = "The number of the upstreams, which are listed in the configuration file, that must agree on a block " +
+		"hash and state root for a block to be synced."
+	upstreamTimeoutUsage = "How long an upstream is waited for before another one is asked instead."
+	p2pUsage             = "Enables the peer-to-peer network of Juno nodes, which blocks are synced from when the feeder " +
+		"gateway or the upstreams fail."
+	p2pPortUsage  = "The port on which other Juno nodes connect to this one."
+	p2pPeersUsage = "The host:port addresses of the Juno nodes to connect to first, more are learned from them."
+
+	cnNameUsage                     = "The name of the custom network, which names its default database directory."
+	cnFeederURLUsage                = "The URL of the feeder gateway of the custom network."
+	cnChainIDUsage                  = "The chain ID of the custom network, such as SN_MAIN."
+	cnFirst07BlockUsage             = "The first block of the custom network that is hashed with the post-0.7.0 algorithm."
+	cnUnverifiableRangeUsage        = "The first and last block of the range of blocks of the custom network whose hashes can not be verified."
+	cnFallBackSequencerAddressUsage = "The sequencer address that blocks of the custom network without one are hashed with."
+	cnPublicKeyUsage                = "The public key that the sequencer of the custom network signs blocks with, which " +
+		"verify-signatures needs."
 )
 
 var Version string
@@ -88,8 +164,10 @@ func NewCmd(config *node.Config, run func(*cobra.Command, []string) error) *cobr
 		Use:     "juno [flags]",
 		Short:   "Starknet client implementation in Go.",
 		Version: Version,
+		Args:    cobra.NoArgs,
 		RunE:    run,
 	}
+	junoCmd.AddCommand(NewDumpStorageCmd(), NewExportCmd(), NewImportCmd())
 
 	var cfgFile string
 
@@ -111,7 +189,18 @@ func NewCmd(config *node.Config, run func(*cobra.Command, []string) error) *cobr
 
 		// TextUnmarshallerHookFunc allows us to unmarshal values that satisfy the
 		// encoding.TextUnmarshaller interface (see the LogLevel type for an example).
-		return v.Unmarshal(config, viper.DecodeHook(mapstructure.TextUnmarshallerHookFunc()))
+		decodeHook := mapstructure.ComposeDecodeHookFunc(
+			mapstructure.TextUnmarshallerHookFunc(),
+			mapstructure.StringToTimeDurationHookFunc(),
+		)
+		if err := v.Unmarshal(config, viper.DecodeHook(decodeHook)); err != nil {
+			return err
+		}
+
+		if config.Network.Custom() != nil {
+			return setCustomNetwork(v, config)
+		}
+		return nil
 	}
 
 	// For testing purposes, these variables cannot be declared outside the function because Cobra
@@ -122,9 +211,95 @@ func NewCmd(config *node.Config, run func(*cobra.Command, []string) error) *cobr
 	junoCmd.Flags().StringVar(&cfgFile, configF, defaultConfig, configFlagUsage)
 	junoCmd.Flags().Var(&defaultLogLevel, logLevelF, logLevelFlagUsage)
 	junoCmd.Flags().Uint16(rpcPortF, defaultRPCPort, rpcPortUsage)
+	junoCmd.Flags().Bool(wsF, defaultWS, wsUsage)
+	junoCmd.Flags().Uint16(wsPortF, defaultWSPort, wsPortUsage)
 	junoCmd.Flags().String(dbPathF, defaultDBPath, dbPathUsage)
 	junoCmd.Flags().Var(&defaultNetwork, networkF, networkUsage)
 	junoCmd.Flags().Bool(pprofF, defaultPprof, pprofUsage)
+	junoCmd.Flags().Bool(metricsF, defaultMetrics, metricsUsage)
+	junoCmd.Flags().Uint16(metricsPortF, defaultMetricsPort, metricsPortUsage)
+	junoCmd.Flags().Bool(repairClassesF, defaultRepairClasses, repairClassesUsage)
+	junoCmd.Flags().String(ethNodeF, defaultEthNode, ethNodeUsage)
+	junoCmd.Flags().Bool(verifySignaturesF, defaultVerifySignatures, verifySignaturesUsage)
+	junoCmd.Flags().String(replayDirF, defaultReplayDir, replayDirUsage)
+	junoCmd.Flags().String(recordDirF, defaultRecordDir, recordDirUsage)
+	junoCmd.Flags().Int(upstreamQuorumF, defaultUpstreamQuorum, upstreamQuorumUsage)
+	junoCmd.Flags().Duration(upstreamTimeoutF, defaultUpstreamTimeout, upstreamTimeoutUsage)
+	junoCmd.Flags().Bool(p2pF, defaultP2P, p2pUsage)
+	junoCmd.Flags().Uint16(p2pPortF, defaultP2PPort, p2pPortUsage)
+	junoCmd.Flags().StringSlice(p2pPeersF, nil, p2pPeersUsage)
+	addCustomNetworkFlags(junoCmd)
 
 	return junoCmd
 }
+
+// addCustomNetworkFlags adds the cn-* options, which describe a custom network, to the given command.
+func addCustomNetworkFlags(cmd *cobra.Command) {
+	cmd.Flags().String(cnNameF, defaultCnName, cnNameUsage)
+	cmd.Flags().String(cnFeederURLF, defaultCnFeederURL, cnFeederURLUsage)
+	cmd.Flags().String(cnChainIDF, defaultCnChainID, cnChainIDUsage)
+	cmd.Flags().Uint64(cnFirst07BlockF, defaultCnFirst07Block, cnFirst07BlockUsage)
+	cmd.Flags().IntSlice(cnUnverifiableRangeF, nil, cnUnverifiableRangeUsage)
+	cmd.Flags().String(cnFallBackSequencerAddressF, defaultCnFallBackSequencerAddress, cnFallBackSequencerAddressUsage)
+	cmd.Flags().String(cnPublicKeyF, defaultCnPublicKey, cnPublicKeyUsage)
+}
+
+// customNetworkConfig is the configuration that describes a custom network.
+type customNetworkConfig struct {
+	Name                     string   `mapstructure:"cn-name"`
+	FeederURL                string   `mapstructure:"cn-feeder-url"`
+	ChainID                  string   `mapstructure:"cn-chain-id"`
+	First07Block             uint64   `mapstructure:"cn-first-07-block"`
+	UnverifiableRange        []uint64 `mapstructure:"cn-unverifiable-range"`
+	FallBackSequencerAddress string   `mapstructure:"cn-fallback-sequencer-address"`
+	PublicKey                string   `mapstructure:"cn-public-key"`
+}
+
+// setCustomNetwork sets the network of the config to the custom network described by the cn-* options.
+func setCustomNetwork(v *viper.Viper, config *node.Config) error {
+	var err error
+	config.Network, err = customNetwork(v)
+	return err
+}
+
+// networkFromFlags returns the given network, or, if it is custom, the custom network described by the cn-*
+// flags of the given command, which were added by addCustomNetworkFlags.
+func networkFromFlags(cmd *cobra.Command, network utils.Network) (utils.Network, error) {
+	if network.Custom() == nil {
+		return network, nil
+	}
+
+	v := viper.New()
+	if err := v.BindPFlags(cmd.Flags()); err != nil {
+		return utils.Network{}, err
+	}
+	return customNetwork(v)
+}
+
+// customNetwork returns the custom network described by the cn-* options.
+func customNetwork(v *viper.Viper) (utils.Network, error) {
+	cnConfig := new(customNetworkConfig)
+	if err := v.Unmarshal(cnConfig); err != nil {
+		return utils.Network{}, err
+	}
+
+	custom := &utils.CustomNetwork{
+		Name:              cnConfig.Name,
+		FeederURL:         cnConfig.FeederURL,
+		ChainID:           cnConfig.ChainID,
+		First07Block:      cnConfig.First07Block,
+		UnverifiableRange: cnConfig.UnverifiableRange,
+	}
+	var err error
+	if cnConfig.FallBackSequencerAddress != "" {
+		if custom.FallBackSequencerAddress, err = new(felt.Felt).SetString(cnConfig.FallBackSequencerAddress); err != nil {
+			return utils.Network{}, fmt.Errorf("custom network fallback sequencer address: %w", err)
+		}
+	}
+	if cnConfig.PublicKey != "" {
+		if custom.PublicKey, err = new(felt.Felt).SetString(cnConfig.PublicKey); err != nil {
+			return utils.Network{}, fmt.Errorf("custom network public key: %w", err)
+		}
+	}
+	return utils.NewCustomNetwork(custom)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"testing"
+	"time"
 
 	juno "github.com/NethermindEth/juno/cmd/juno"
 	"github.com/NethermindEth/juno/node"
@@ -22,9 +23,26 @@ func TestConfigPrecedence(t *testing.T) {
 	// implementation.
 	defaultLogLevel := utils.INFO
 	defaultRPCPort := uint16(6060)
+	defaultWSPort := uint16(6061)
+	defaultMetricsPort := uint16(9090)
 	defaultDBPath := ""
 	defaultNetwork := utils.MAINNET
 	defaultPprof := false
+	defaultUpstreamQuorum := 1
+	defaultUpstreamTimeout := time.Minute
+	defaultP2PPort := uint16(6062)
+	defaultP2PPeers := []string{}
+
+	customNetwork, err := utils.NewCustomNetwork(&utils.CustomNetwork{
+		Name:                     "devnet",
+		FeederURL:                "http://localhost:5050/feeder_gateway/",
+		ChainID:                  "SN_DEVNET",
+		First07Block:             5,
+		UnverifiableRange:        []uint64{0, 4},
+		FallBackSequencerAddress: utils.HexToFelt(t, "0x55"),
+		PublicKey:                utils.HexToFelt(t, "0x66"),
+	})
+	require.NoError(t, err)
 
 	tests := map[string]struct {
 		cfgFile         bool
@@ -34,23 +52,39 @@ func TestConfigPrecedence(t *testing.T) {
 		expectedConfig  *node.Config
 	}{
 		"default config with no flags": {
-			inputArgs: []string{""},
+			inputArgs: []string{},
 			expectedConfig: &node.Config{
-				LogLevel:     defaultLogLevel,
-				RPCPort:      defaultRPCPort,
-				DatabasePath: defaultDBPath,
-				Network:      defaultNetwork,
-				Pprof:        defaultPprof,
+				LogLevel:        defaultLogLevel,
+				RPCPort:         defaultRPCPort,
+				WSPort:          defaultWSPort,
+				MetricsPort:     defaultMetricsPort,
+				P2PPort:         defaultP2PPort,
+				P2PPeers:        defaultP2PPeers,
+				UpstreamQuorum:  defaultUpstreamQuorum,
+				UpstreamTimeout: defaultUpstreamTimeout,
+				DatabasePath:    defaultDBPath,
+				Network:         defaultNetwork,
+				Pprof:           defaultPprof,
 			},
 		},
+		"positional arguments": {
+			inputArgs: []string{"mainnet"},
+			expectErr: true,
+		},
 		"config file path is empty string": {
 			inputArgs: []string{"--config", ""},
 			expectedConfig: &node.Config{
-				LogLevel:     defaultLogLevel,
-				RPCPort:      defaultRPCPort,
-				DatabasePath: defaultDBPath,
-				Network:      defaultNetwork,
-				Pprof:        defaultPprof,
+				LogLevel:        defaultLogLevel,
+				RPCPort:         defaultRPCPort,
+				WSPort:          defaultWSPort,
+				MetricsPort:     defaultMetricsPort,
+				P2PPort:         defaultP2PPort,
+				P2PPeers:        defaultP2PPeers,
+				UpstreamQuorum:  defaultUpstreamQuorum,
+				UpstreamTimeout: defaultUpstreamTimeout,
+				DatabasePath:    defaultDBPath,
+				Network:         defaultNetwork,
+				Pprof:           defaultPprof,
 			},
 		},
 		"config file doesn't exist": {
@@ -61,25 +95,69 @@ func TestConfigPrecedence(t *testing.T) {
 			cfgFile:         true,
 			cfgFileContents: "\n",
 			expectedConfig: &node.Config{
-				LogLevel: defaultLogLevel,
-				RPCPort:  defaultRPCPort,
-				Network:  defaultNetwork,
+				LogLevel:        defaultLogLevel,
+				RPCPort:         defaultRPCPort,
+				WSPort:          defaultWSPort,
+				MetricsPort:     defaultMetricsPort,
+				P2PPort:         defaultP2PPort,
+				P2PPeers:        defaultP2PPeers,
+				UpstreamQuorum:  defaultUpstreamQuorum,
+				UpstreamTimeout: defaultUpstreamTimeout,
+				Network:         defaultNetwork,
 			},
 		},
 		"config file with all settings but without any other flags": {
 			cfgFile: true,
 			cfgFileContents: `log-level: debug
 rpc-port: 4576
+ws: true
+ws-port: 4577
 db-path: /home/.juno
 network: goerli2
 pprof: true
+metrics: true
+metrics-port: 4579
+repair-classes: true
+eth-node: http://localhost:8545
+verify-signatures: true
+replay-dir: /home/.juno-replay
+record-dir: /home/.juno-record
+upstreams:
+  - feeder-url: https://alpha-mainnet.starknet.io/
+  - rpc-url: http://primary.example.com:6060/
+    allow-incomplete-blocks: true
+upstream-quorum: 2
+upstream-timeout: 30s
+p2p: true
+p2p-port: 4578
+p2p-peers:
+  - 192.168.1.2:6062
+  - node.example.com:6062
 `,
 			expectedConfig: &node.Config{
-				LogLevel:     utils.DEBUG,
-				RPCPort:      4576,
-				DatabasePath: "/home/.juno",
-				Network:      utils.GOERLI2,
-				Pprof:        true,
+				LogLevel:        utils.DEBUG,
+				RPCPort:         4576,
+				WS:              true,
+				WSPort:          4577,
+				MetricsPort:     4579,
+				P2PPort:         4578,
+				UpstreamQuorum:  2,
+				UpstreamTimeout: 30 * time.Second,
+				P2P:             true,
+				P2PPeers:        []string{"192.168.1.2:6062", "node.example.com:6062"},
+				Upstreams: []node.Upstream{
+					{FeederURL: "https://alpha-mainnet.starknet.io/"},
+					{RPCURL: "http://primary.example.com:6060/", AllowIncompleteBlocks: true},
+				},
+				DatabasePath:     "/home/.juno",
+				Network:          utils.GOERLI2,
+				Pprof:            true,
+				Metrics:          true,
+				RepairClasses:    true,
+				EthNode:          "http://localhost:8545",
+				VerifySignatures: true,
+				ReplayDir:        "/home/.juno-replay",
+				RecordDir:        "/home/.juno-record",
 			},
 		},
 		"config file with some settings but without any other flags": {
@@ -88,24 +166,48 @@ pprof: true
 rpc-port: 4576
 `,
 			expectedConfig: &node.Config{
-				LogLevel:     utils.DEBUG,
-				RPCPort:      4576,
-				DatabasePath: defaultDBPath,
-				Network:      defaultNetwork,
-				Pprof:        defaultPprof,
+				LogLevel:        utils.DEBUG,
+				RPCPort:         4576,
+				WSPort:          defaultWSPort,
+				MetricsPort:     defaultMetricsPort,
+				P2PPort:         defaultP2PPort,
+				P2PPeers:        defaultP2PPeers,
+				UpstreamQuorum:  defaultUpstreamQuorum,
+				UpstreamTimeout: defaultUpstreamTimeout,
+				DatabasePath:    defaultDBPath,
+				Network:         defaultNetwork,
+				Pprof:           defaultPprof,
 			},
 		},
 		"all flags without config file": {
 			inputArgs: []string{
-				"--log-level", "debug", "--rpc-port", "4576",
-				"--db-path", "/home/.juno", "--network", "goerli", "--pprof",
+				"--log-level", "debug", "--rpc-port", "4576", "--ws", "--ws-port", "4577",
+				"--db-path", "/home/.juno", "--network", "goerli", "--pprof", "--metrics", "--metrics-port", "4579", "--repair-classes",
+				"--eth-node", "http://localhost:8545", "--verify-signatures",
+				"--replay-dir", "/home/.juno-replay", "--record-dir", "/home/.juno-record",
+				"--upstream-quorum", "2", "--upstream-timeout", "30s",
+				"--p2p", "--p2p-port", "4578", "--p2p-peers", "192.168.1.2:6062,node.example.com:6062",
 			},
 			expectedConfig: &node.Config{
-				LogLevel:     utils.DEBUG,
-				RPCPort:      4576,
-				DatabasePath: "/home/.juno",
-				Network:      utils.GOERLI,
-				Pprof:        true,
+				LogLevel:         utils.DEBUG,
+				RPCPort:          4576,
+				WS:               true,
+				WSPort:           4577,
+				MetricsPort:      4579,
+				P2PPort:          4578,
+				UpstreamQuorum:   2,
+				UpstreamTimeout:  30 * time.Second,
+				P2P:              true,
+				P2PPeers:         []string{"192.168.1.2:6062", "node.example.com:6062"},
+				DatabasePath:     "/home/.juno",
+				Network:          utils.GOERLI,
+				Pprof:            true,
+				Metrics:          true,
+				RepairClasses:    true,
+				EthNode:          "http://localhost:8545",
+				VerifySignatures: true,
+				ReplayDir:        "/home/.juno-replay",
+				RecordDir:        "/home/.juno-record",
 			},
 		},
 		"some flags without config file": {
@@ -114,10 +216,16 @@ rpc-port: 4576
 				"--network", "integration",
 			},
 			expectedConfig: &node.Config{
-				LogLevel:     utils.DEBUG,
-				RPCPort:      4576,
-				DatabasePath: "/home/.juno",
-				Network:      utils.INTEGRATION,
+				LogLevel:        utils.DEBUG,
+				RPCPort:         4576,
+				WSPort:          defaultWSPort,
+				MetricsPort:     defaultMetricsPort,
+				P2PPort:         defaultP2PPort,
+				P2PPeers:        defaultP2PPeers,
+				UpstreamQuorum:  defaultUpstreamQuorum,
+				UpstreamTimeout: defaultUpstreamTimeout,
+				DatabasePath:    "/home/.juno",
+				Network:         utils.INTEGRATION,
 			},
 		},
 		"all setting set in both config file and flags": {
@@ -133,11 +241,17 @@ pprof: true
 				"--db-path", "/home/flag/.juno", "--network", "integration", "--pprof",
 			},
 			expectedConfig: &node.Config{
-				LogLevel:     utils.ERROR,
-				RPCPort:      4577,
-				DatabasePath: "/home/flag/.juno",
-				Network:      utils.INTEGRATION,
-				Pprof:        true,
+				LogLevel:        utils.ERROR,
+				RPCPort:         4577,
+				WSPort:          defaultWSPort,
+				MetricsPort:     defaultMetricsPort,
+				P2PPort:         defaultP2PPort,
+				P2PPeers:        defaultP2PPeers,
+				UpstreamQuorum:  defaultUpstreamQuorum,
+				UpstreamTimeout: defaultUpstreamTimeout,
+				DatabasePath:    "/home/flag/.juno",
+				Network:         utils.INTEGRATION,
+				Pprof:           true,
 			},
 		},
 		"some setting set in both config file and flags": {
@@ -148,11 +262,17 @@ network: goerli
 `,
 			inputArgs: []string{"--db-path", "/home/flag/.juno"},
 			expectedConfig: &node.Config{
-				LogLevel:     utils.WARN,
-				RPCPort:      4576,
-				DatabasePath: "/home/flag/.juno",
-				Network:      utils.GOERLI,
-				Pprof:        defaultPprof,
+				LogLevel:        utils.WARN,
+				RPCPort:         4576,
+				WSPort:          defaultWSPort,
+				MetricsPort:     defaultMetricsPort,
+				P2PPort:         defaultP2PPort,
+				P2PPeers:        defaultP2PPeers,
+				UpstreamQuorum:  defaultUpstreamQuorum,
+				UpstreamTimeout: defaultUpstreamTimeout,
+				DatabasePath:    "/home/flag/.juno",
+				Network:         utils.GOERLI,
+				Pprof:           defaultPprof,
 			},
 		},
 		"some setting set in default, config file and flags": {
@@ -160,13 +280,64 @@ network: goerli
 			cfgFileContents: "network: goerli2",
 			inputArgs:       []string{"--db-path", "/home/flag/.juno", "--pprof"},
 			expectedConfig: &node.Config{
-				LogLevel:     defaultLogLevel,
-				RPCPort:      defaultRPCPort,
-				DatabasePath: "/home/flag/.juno",
-				Network:      utils.GOERLI2,
-				Pprof:        true,
+				LogLevel:        defaultLogLevel,
+				RPCPort:         defaultRPCPort,
+				WSPort:          defaultWSPort,
+				MetricsPort:     defaultMetricsPort,
+				P2PPort:         defaultP2PPort,
+				P2PPeers:        defaultP2PPeers,
+				UpstreamQuorum:  defaultUpstreamQuorum,
+				UpstreamTimeout: defaultUpstreamTimeout,
+				DatabasePath:    "/home/flag/.juno",
+				Network:         utils.GOERLI2,
+				Pprof:           true,
 			},
 		},
+		"custom network in config file": {
+			cfgFile: true,
+			cfgFileContents: `network: custom
+cn-name: devnet
+cn-feeder-url: http://localhost:5050/feeder_gateway/
+cn-chain-id: SN_DEVNET
+cn-first-07-block: 5
+cn-unverifiable-range: [0, 4]
+cn-fallback-sequencer-address: "0x55"
+cn-public-key: "0x66"
+`,
+			expectedConfig: &node.Config{
+				LogLevel:        defaultLogLevel,
+				RPCPort:         defaultRPCPort,
+				WSPort:          defaultWSPort,
+				MetricsPort:     defaultMetricsPort,
+				P2PPort:         defaultP2PPort,
+				P2PPeers:        defaultP2PPeers,
+				UpstreamQuorum:  defaultUpstreamQuorum,
+				UpstreamTimeout: defaultUpstreamTimeout,
+				Network:         customNetwork,
+			},
+		},
+		"custom network in flags": {
+			inputArgs: []string{
+				"--network", "custom", "--cn-name", "devnet", "--cn-feeder-url", "http://localhost:5050/feeder_gateway/",
+				"--cn-chain-id", "SN_DEVNET", "--cn-first-07-block", "5", "--cn-unverifiable-range", "0,4",
+				"--cn-fallback-sequencer-address", "0x55", "--cn-public-key", "0x66",
+			},
+			expectedConfig: &node.Config{
+				LogLevel:        defaultLogLevel,
+				RPCPort:         defaultRPCPort,
+				WSPort:          defaultWSPort,
+				MetricsPort:     defaultMetricsPort,
+				P2PPort:         defaultP2PPort,
+				P2PPeers:        defaultP2PPeers,
+				UpstreamQuorum:  defaultUpstreamQuorum,
+				UpstreamTimeout: defaultUpstreamTimeout,
+				Network:         customNetwork,
+			},
+		},
+		"custom network without a feeder URL": {
+			inputArgs: []string{"--network", "custom", "--cn-name", "devnet", "--cn-chain-id", "SN_DEVNET"},
+			expectErr: true,
+		},
 	}
 
 	for name, tc := range tests {
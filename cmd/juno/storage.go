@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
+	"github.com/NethermindEth/juno/rpc"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/spf13/cobra"
+)
+
+const (
+	contractF = "contract"
+	outputF   = "output"
+
+	defaultContract = ""
+	defaultOutput   = ""
+
+	contractUsage = "The address of the contract whose storage is dumped."
+	outputUsage   = "The file the storage is written to. If not set, it is written to the standard output."
+)
+
+// NewDumpStorageCmd returns a command that writes the whole storage of a contract, as of the latest block
+// in the database, to JSON. The database must not be in use by a running node.
+func NewDumpStorageCmd() *cobra.Command {
+	dumpCmd := &cobra.Command{
+		Use:   "dump-storage --contract <address> [flags]",
+		Short: "Dumps the storage of a contract, as of the latest synced block, to JSON.",
+		Args:  cobra.NoArgs,
+	}
+
+	var dbPath, contract, output string
+	network := utils.MAINNET
+
+	dumpCmd.RunE = func(cmd *cobra.Command, _ []string) error {
+		addr, err := new(felt.Felt).SetString(contract)
+		if err != nil {
+			return fmt.Errorf("invalid contract address %q: %w", contract, err)
+		}
+
+		if network, err = networkFromFlags(cmd, network); err != nil {
+			return err
+		}
+		path, err := existingDBPath(dbPath, network)
+		if err != nil {
+			return err
+		}
+
+		return withChain(path, network, func(chain *blockchain.Blockchain, _ utils.SimpleLogger) error {
+			if output == "" {
+				return writeStorage(cmd.OutOrStdout(), chain, addr)
+			}
+			file, createErr := os.Create(output)
+			if createErr != nil {
+				return createErr
+			}
+			return db.CloseAndWrapOnError(file.Close, writeStorage(file, chain, addr))
+		})
+	}
+
+	dumpCmd.Flags().StringVar(&dbPath, dbPathF, defaultDBPath, dbPathUsage)
+	dumpCmd.Flags().Var(&network, networkF, networkUsage)
+	dumpCmd.Flags().StringVar(&contract, contractF, defaultContract, contractUsage)
+	dumpCmd.Flags().StringVar(&output, outputF, defaultOutput, outputUsage)
+	addCustomNetworkFlags(dumpCmd)
+
+	return dumpCmd
+}
+
+// dbPathOrDefault returns the given database path, or the path that the node uses by default for the
+// network if it is empty.
+func dbPathOrDefault(dbPath string, network utils.Network) (string, error) {
+	if dbPath != "" {
+		return dbPath, nil
+	}
+	dirPrefix, err := utils.DefaultDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dirPrefix, network.String()), nil
+}
+
+// existingDBPath returns the database path like dbPathOrDefault, and checks that the database exists,
+// since opening a database that does not exist would create an empty one.
+func existingDBPath(dbPath string, network utils.Network) (string, error) {
+	path, err := dbPathOrDefault(dbPath, network)
+	if err != nil {
+		return "", err
+	}
+	if _, err = os.Stat(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// writeStorage writes the storage of the contract at the given address in the state of the head, along
+// with the head the storage belongs to. The storage locations are streamed, so that the storage of a
+// contract does not need to fit in memory, and the head is only known once they are written.
+func writeStorage(w io.Writer, chain *blockchain.Blockchain, addr *felt.Felt) error {
+	buf := bufio.NewWriter(w)
+	separator := "\n"
+	writeEntry := func(key, value *felt.Felt) (bool, error) {
+		entry, err := json.Marshal(rpc.StorageEntry{Key: key, Value: value})
+		if err != nil {
+			return false, err
+		}
+
+		if _, err = buf.WriteString(separator); err != nil {
+			return false, err
+		}
+		separator = ",\n"
+		_, err = buf.Write(entry)
+		return err == nil, err
+	}
+
+	if _, err := fmt.Fprintf(buf, "{\"contract_address\":%q,\"storage\":[", addr.String()); err != nil {
+		return err
+	}
+	head, err := chain.HeadContractStorage(addr, nil, writeEntry)
+	if err != nil {
+		return err
+	}
+	if _, err = fmt.Fprintf(buf, "\n],\"block_number\":%d,\"block_hash\":%q}\n", head.Number, head.Hash.String()); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
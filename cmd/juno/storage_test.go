@@ -0,0 +1,168 @@
+package main_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NethermindEth/juno/blockchain"
+	juno "github.com/NethermindEth/juno/cmd/juno"
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/crypto"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/core/trie"
+	"github.com/NethermindEth/juno/db/pebble"
+	"github.com/NethermindEth/juno/migration"
+	"github.com/NethermindEth/juno/node"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumpStorage(t *testing.T) {
+	addr := utils.HexToFelt(t, "0x20cfa74ee3564b4cd5435cdace0f9c4d43b939620e4a0bb5076105df0a626c6")
+	classHash := utils.HexToFelt(t, "0xc1a55")
+	expected := make(map[string]string)
+	var storageDiffs []core.StorageDiff
+	for i := uint64(1); i <= 10; i++ {
+		key := new(felt.Felt).SetUint64(i * 7919)
+		value := new(felt.Felt).SetUint64(i)
+		storageDiffs = append(storageDiffs, core.StorageDiff{Key: key, Value: value})
+		expected[key.String()] = value.String()
+	}
+
+	// the state root commits to the contract, whose commitment commits to its storage
+	var storageRoot, stateRoot *felt.Felt
+	require.NoError(t, trie.RunOnTempTrie(251, func(storage *trie.Trie) error {
+		for _, diff := range storageDiffs {
+			_, err := storage.Put(diff.Key, diff.Value)
+			require.NoError(t, err)
+		}
+		var err error
+		storageRoot, err = storage.Root()
+		return err
+	}))
+	commitment := crypto.Pedersen(crypto.Pedersen(crypto.Pedersen(classHash, storageRoot), &felt.Zero), &felt.Zero)
+	require.NoError(t, trie.RunOnTempTrie(251, func(contracts *trie.Trie) error {
+		_, err := contracts.Put(addr, commitment)
+		require.NoError(t, err)
+		stateRoot, err = contracts.Root()
+		return err
+	}))
+
+	// the blockchain does not verify block hashes, so a made up block is enough for a database to dump from
+	block0 := &core.Block{Header: &core.Header{
+		Number:     0,
+		Hash:       utils.HexToFelt(t, "0xb10c"),
+		ParentHash: new(felt.Felt),
+	}}
+	su0 := &core.StateUpdate{
+		OldRoot: new(felt.Felt),
+		NewRoot: stateRoot,
+		StateDiff: &core.StateDiff{
+			DeployedContracts: []core.DeployedContract{{Address: addr, ClassHash: classHash}},
+			StorageDiffs:      map[felt.Felt][]core.StorageDiff{*addr: storageDiffs},
+		},
+	}
+
+	dbPath := t.TempDir()
+	database, err := pebble.New(dbPath, utils.NewNopZapLogger())
+	require.NoError(t, err)
+	chain := blockchain.New(database, utils.MAINNET, utils.NewNopZapLogger())
+	require.NoError(t, chain.Store(block0, su0, map[felt.Felt]core.Class{*classHash: &core.Cairo0Class{}}))
+	require.NoError(t, database.Close())
+
+	execute := func(t *testing.T, args ...string) (string, error) {
+		t.Helper()
+
+		cmd := juno.NewCmd(new(node.Config), func(_ *cobra.Command, _ []string) error { return nil })
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetArgs(append([]string{"dump-storage"}, args...))
+		err := cmd.ExecuteContext(context.Background())
+		return out.String(), err
+	}
+
+	checkDump := func(t *testing.T, dump []byte) {
+		t.Helper()
+
+		var decoded struct {
+			ContractAddress string `json:"contract_address"`
+			BlockNumber     uint64 `json:"block_number"`
+			BlockHash       string `json:"block_hash"`
+			Storage         []struct {
+				Key   string `json:"key"`
+				Value string `json:"value"`
+			} `json:"storage"`
+		}
+		require.NoError(t, json.Unmarshal(dump, &decoded))
+		assert.Equal(t, addr.String(), decoded.ContractAddress)
+		assert.Equal(t, uint64(0), decoded.BlockNumber)
+		assert.Equal(t, block0.Hash.String(), decoded.BlockHash)
+
+		stored := make(map[string]string)
+		for _, entry := range decoded.Storage {
+			stored[entry.Key] = entry.Value
+		}
+		assert.Len(t, decoded.Storage, len(expected))
+		assert.Equal(t, expected, stored)
+	}
+
+	t.Run("invalid contract address", func(t *testing.T) {
+		_, err := execute(t, "--db-path", dbPath, "--contract", "not an address")
+		require.Error(t, err)
+	})
+
+	t.Run("database does not exist", func(t *testing.T) {
+		missing := filepath.Join(t.TempDir(), "missing")
+		_, err := execute(t, "--db-path", missing, "--contract", addr.String())
+		require.ErrorIs(t, err, os.ErrNotExist)
+		assert.NoDirExists(t, missing)
+	})
+
+	t.Run("contract is not deployed", func(t *testing.T) {
+		_, err := execute(t, "--db-path", dbPath, "--contract", "0xdeadbeef")
+		require.ErrorIs(t, err, core.ErrContractNotDeployed)
+	})
+
+	t.Run("dump to the standard output", func(t *testing.T) {
+		out, err := execute(t, "--db-path", dbPath, "--contract", addr.String())
+		require.NoError(t, err)
+		checkDump(t, []byte(out))
+
+		// the database is migrated before it is read
+		database, err := pebble.New(dbPath, utils.NewNopZapLogger())
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, database.Close())
+		})
+		version, err := migration.SchemaVersion(database)
+		require.NoError(t, err)
+		assert.NotZero(t, version)
+	})
+
+	t.Run("custom network", func(t *testing.T) {
+		_, err := execute(t, "--db-path", dbPath, "--contract", addr.String(), "--network", "custom")
+		require.Error(t, err)
+
+		out, err := execute(t, "--db-path", dbPath, "--contract", addr.String(), "--network", "custom", "--cn-name", "devnet",
+			"--cn-feeder-url", "http://localhost:5050/feeder_gateway/", "--cn-chain-id", "SN_DEVNET")
+		require.NoError(t, err)
+		checkDump(t, []byte(out))
+	})
+
+	t.Run("dump to a file", func(t *testing.T) {
+		output := filepath.Join(t.TempDir(), "storage.json")
+		out, err := execute(t, "--db-path", dbPath, "--contract", addr.String(), "--output", output)
+		require.NoError(t, err)
+		assert.Empty(t, out)
+
+		dump, err := os.ReadFile(output)
+		require.NoError(t, err)
+		checkDump(t, dump)
+	})
+}
@@ -9,6 +9,32 @@ import (
 	"github.com/NethermindEth/juno/utils"
 )
 
+// BlockStatus is the finality status of a block. The zero value is [BlockAcceptedOnL2] so that
+// headers stored before statuses were tracked decode as accepted on L2.
+type BlockStatus uint8
+
+const (
+	BlockAcceptedOnL2 BlockStatus = iota
+	BlockAcceptedOnL1
+	BlockPending
+	BlockRejected
+)
+
+func (s BlockStatus) String() string {
+	switch s {
+	case BlockAcceptedOnL2:
+		return "ACCEPTED_ON_L2"
+	case BlockAcceptedOnL1:
+		return "ACCEPTED_ON_L1"
+	case BlockPending:
+		return "PENDING"
+	case BlockRejected:
+		return "REJECTED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
 type Header struct {
 	// The hash of this block
 	Hash *felt.Felt
@@ -30,6 +56,8 @@ type Header struct {
 	ProtocolVersion string
 	// Extraneous data that might be useful for running transactions
 	ExtraData *felt.Felt
+	// The finality status of this block
+	Status BlockStatus
 }
 
 type Block struct {
@@ -51,6 +79,17 @@ func networkBlockHashMetaInfo(network utils.Network) *blockHashMetaInfo {
 		panic(fmt.Sprintf("Error while creating FallBackSequencerAddress %s", err))
 	}
 
+	if custom := network.Custom(); custom != nil {
+		if custom.FallBackSequencerAddress != nil {
+			fallBackSequencerAddress = custom.FallBackSequencerAddress
+		}
+		return &blockHashMetaInfo{
+			First07Block:             custom.First07Block,
+			UnverifiableRange:        custom.UnverifiableRange,
+			FallBackSequencerAddress: fallBackSequencerAddress,
+		}
+	}
+
 	switch network {
 	case utils.MAINNET:
 		fallBackSequencerAddress, err = new(felt.Felt).SetString(
@@ -81,7 +120,7 @@ func networkBlockHashMetaInfo(network utils.Network) *blockHashMetaInfo {
 		}
 	default:
 		// This should never happen
-		panic(fmt.Sprintf("unknown network: %d", network))
+		panic(utils.ErrUnknownNetwork)
 	}
 }
 
@@ -134,6 +173,35 @@ func VerifyBlockHash(b *Block, network utils.Network) error {
 	return errors.New("can not verify hash in block header")
 }
 
+var ErrInvalidBlockSignature = errors.New("invalid block signature")
+
+// BlockSignature is the signature of the sequencer over the hash and the state diff commitment of a block.
+type BlockSignature struct {
+	BlockHash           *felt.Felt
+	StateDiffCommitment *felt.Felt
+	R                   *felt.Felt
+	S                   *felt.Felt
+}
+
+// VerifyBlockSignature verifies that the signature was made over the block and its state diff by the
+// sequencer with the given public key.
+func VerifyBlockSignature(b *Block, stateUpdate *StateUpdate, signature *BlockSignature, publicKey *felt.Felt) error {
+	if !signature.BlockHash.Equal(b.Hash) {
+		return fmt.Errorf("%w: signed block hash %s does not match block hash %s", ErrInvalidBlockSignature,
+			signature.BlockHash, b.Hash)
+	}
+	if commitment := stateUpdate.StateDiff.Commitment(); !signature.StateDiffCommitment.Equal(commitment) {
+		return fmt.Errorf("%w: signed state diff commitment %s does not match state diff commitment %s",
+			ErrInvalidBlockSignature, signature.StateDiffCommitment, commitment)
+	}
+
+	msgHash := crypto.Pedersen(signature.BlockHash, signature.StateDiffCommitment)
+	if !crypto.VerifyECDSA(publicKey, msgHash, signature.R, signature.S) {
+		return ErrInvalidBlockSignature
+	}
+	return nil
+}
+
 // blockHash computes the block hash, with option to override sequence address
 func blockHash(b *Block, network utils.Network, overrideSeqAddr *felt.Felt) (*felt.Felt, error) {
 	metaInfo := networkBlockHashMetaInfo(network)
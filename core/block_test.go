@@ -204,6 +204,46 @@ func TestBlockHash(t *testing.T) {
 		assert.NoError(t, core.VerifyBlockHash(block119802, utils.GOERLI))
 	})
 
+	t.Run("custom network", func(t *testing.T) {
+		mainnetBlock2, err := mainnetGW.BlockByNumber(context.Background(), 2)
+		require.NoError(t, err)
+		mainnetBlock833, err := mainnetGW.BlockByNumber(context.Background(), 833)
+		require.NoError(t, err)
+
+		mainnetLike := &utils.CustomNetwork{
+			Name:                     "mainnet-like",
+			FeederURL:                "http://localhost/feeder_gateway/",
+			ChainID:                  "SN_MAIN",
+			First07Block:             833,
+			FallBackSequencerAddress: utils.HexToFelt(t, "0x021f4b90b0377c82bf330b7b5295820769e72d79d8acd0effa0ebde6e9988bc5"),
+		}
+		network, err := utils.NewCustomNetwork(mainnetLike)
+		require.NoError(t, err)
+		assert.NoError(t, core.VerifyBlockHash(mainnetBlock2, network))
+		assert.NoError(t, core.VerifyBlockHash(mainnetBlock833, network))
+
+		t.Run("pre 0.7.0 block hashed with the post 0.7.0 algorithm", func(t *testing.T) {
+			allPost07 := *mainnetLike
+			allPost07.First07Block = 0
+			network, err := utils.NewCustomNetwork(&allPost07)
+			require.NoError(t, err)
+			assert.EqualError(t, core.VerifyBlockHash(mainnetBlock2, network), "can not verify hash in block header")
+		})
+
+		t.Run("block in the unverifiable range", func(t *testing.T) {
+			withUnverifiableRange := *mainnetLike
+			withUnverifiableRange.UnverifiableRange = []uint64{0, 10}
+			network, err := utils.NewCustomNetwork(&withUnverifiableRange)
+			require.NoError(t, err)
+
+			block := *mainnetBlock2
+			header := *block.Header
+			header.Hash = h1
+			block.Header = &header
+			assert.NoError(t, core.VerifyBlockHash(&block, network))
+		})
+	})
+
 	t.Run("error if len of transactions do not match len of receipts", func(t *testing.T) {
 		mainnetBlock1, err := mainnetGW.BlockByNumber(context.Background(), 1)
 		require.NoError(t, err)
@@ -229,3 +269,50 @@ func TestBlockHash(t *testing.T) {
 			assert.EqualError(t, core.VerifyBlockHash(mainnetBlock1, utils.MAINNET), expectedErr)
 		})
 }
+
+func TestVerifyBlockSignature(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+	ctx := context.Background()
+
+	// the fixtures are signed by the key that the test feeder serves, rather than by the mainnet sequencer
+	publicKey, err := client.PublicKey(ctx)
+	require.NoError(t, err)
+
+	block0, err := gw.BlockByNumber(ctx, 0)
+	require.NoError(t, err)
+	update0, err := gw.StateUpdate(ctx, 0)
+	require.NoError(t, err)
+	signature0, err := gw.BlockSignature(ctx, 0)
+	require.NoError(t, err)
+	block1, err := gw.BlockByNumber(ctx, 1)
+	require.NoError(t, err)
+	update1, err := gw.StateUpdate(ctx, 1)
+	require.NoError(t, err)
+	signature1, err := gw.BlockSignature(ctx, 1)
+	require.NoError(t, err)
+
+	t.Run("valid signatures", func(t *testing.T) {
+		assert.NoError(t, core.VerifyBlockSignature(block0, update0, signature0, publicKey))
+		assert.NoError(t, core.VerifyBlockSignature(block1, update1, signature1, publicKey))
+	})
+
+	t.Run("signature of another block", func(t *testing.T) {
+		assert.ErrorIs(t, core.VerifyBlockSignature(block0, update0, signature1, publicKey), core.ErrInvalidBlockSignature)
+	})
+
+	t.Run("signature by another key", func(t *testing.T) {
+		assert.ErrorIs(t, core.VerifyBlockSignature(block0, update0, signature0, block0.Hash), core.ErrInvalidBlockSignature)
+	})
+
+	t.Run("signature over another state diff commitment", func(t *testing.T) {
+		tampered := *signature0
+		tampered.StateDiffCommitment = new(felt.Felt).SetUint64(1)
+		assert.ErrorIs(t, core.VerifyBlockSignature(block0, update0, &tampered, publicKey), core.ErrInvalidBlockSignature)
+	})
+
+	t.Run("signature over another state diff", func(t *testing.T) {
+		assert.ErrorIs(t, core.VerifyBlockSignature(block0, update1, signature0, publicKey), core.ErrInvalidBlockSignature)
+	})
+}
@@ -1,7 +1,10 @@
 package core
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
 
 	"github.com/NethermindEth/juno/core/crypto"
 	"github.com/NethermindEth/juno/core/felt"
@@ -30,6 +33,8 @@ type Cairo0Class struct {
 	Constructors []EntryPoint
 	// An ascii-encoded array of builtin names imported by the class.
 	Builtins []*felt.Felt
+	// The base64 encoding of the gzip-compressed ".json" file compiler output.
+	Program string
 	// The starknet_keccak hash of the ".json" file compiler output.
 	ProgramHash *felt.Felt
 	Bytecode    []*felt.Felt
@@ -82,6 +87,8 @@ type Cairo1Class struct {
 	Program         []*felt.Felt
 	ProgramHash     *felt.Felt
 	SemanticVersion string
+	// The Cairo assembly that the Sierra program compiles to, nil if it is not known.
+	Compiled *CompiledClass
 }
 
 type SierraEntryPoint struct {
@@ -115,6 +122,115 @@ func flattenSierraEntryPoints(entryPoints []SierraEntryPoint) []*felt.Felt {
 	return result
 }
 
+// CompiledClass is the Cairo assembly (CASM) that the Sierra program of a [Cairo1Class] compiles to.
+type CompiledClass struct {
+	Bytecode        []*felt.Felt
+	PythonicHints   json.RawMessage
+	CompilerVersion string
+	Hints           json.RawMessage
+	Prime           *big.Int
+	External        []CompiledEntryPoint
+	L1Handler       []CompiledEntryPoint
+	Constructor     []CompiledEntryPoint
+	// BytecodeSegmentLengths is nil for the classes that were compiled before the bytecode was split into
+	// segments, whose bytecode is hashed as a whole.
+	BytecodeSegmentLengths *SegmentLengths
+}
+
+// SegmentLengths is the nested list of the lengths of the segments of the bytecode. A segment is either a
+// run of bytecode, with a length, or a list of segments.
+type SegmentLengths struct {
+	Length   uint64
+	Children []SegmentLengths
+}
+
+type CompiledEntryPoint struct {
+	Offset   uint64
+	Builtins []string
+	Selector *felt.Felt
+}
+
+// Hash calculates the compiled class hash, which the state diff of the block that declares the
+// [Cairo1Class] commits to.
+func (c *CompiledClass) Hash() *felt.Felt {
+	return crypto.PoseidonArray(
+		new(felt.Felt).SetBytes([]byte("COMPILED_CLASS_V1")),
+		crypto.PoseidonArray(flattenCompiledEntryPoints(c.External)...),
+		crypto.PoseidonArray(flattenCompiledEntryPoints(c.L1Handler)...),
+		crypto.PoseidonArray(flattenCompiledEntryPoints(c.Constructor)...),
+		c.bytecodeHash(),
+	)
+}
+
+// bytecodeHash hashes the bytecode as a tree of its segments, where a run of bytecode hashes to the hash
+// of its felts and a list of segments to one plus the hash of the lengths and hashes of its segments.
+func (c *CompiledClass) bytecodeHash() *felt.Felt {
+	if c.BytecodeSegmentLengths == nil || c.BytecodeSegmentLengths.Children == nil {
+		return crypto.PoseidonArray(c.Bytecode...)
+	}
+	hash, _ := segmentHash(c.Bytecode, c.BytecodeSegmentLengths)
+	return hash
+}
+
+// segmentHash hashes the segment at the start of the bytecode, and returns its length along with the hash.
+// Lengths beyond the end of the bytecode are cut short, so that a malformed class hashes to a mismatching
+// compiled class hash rather than causing a panic.
+func segmentHash(bytecode []*felt.Felt, lengths *SegmentLengths) (*felt.Felt, uint64) {
+	if lengths.Children == nil {
+		length := lengths.Length
+		if length > uint64(len(bytecode)) {
+			length = uint64(len(bytecode))
+		}
+		return crypto.PoseidonArray(bytecode[:length]...), length
+	}
+
+	var offset uint64
+	elems := make([]*felt.Felt, 0, 2*len(lengths.Children))
+	for i := range lengths.Children {
+		hash, length := segmentHash(bytecode[offset:], &lengths.Children[i])
+		elems = append(elems, new(felt.Felt).SetUint64(length), hash)
+		offset += length
+	}
+	hash := crypto.PoseidonArray(elems...)
+	return hash.Add(hash, new(felt.Felt).SetUint64(1)), offset
+}
+
+func flattenCompiledEntryPoints(entryPoints []CompiledEntryPoint) []*felt.Felt {
+	result := make([]*felt.Felt, len(entryPoints)*3)
+	for i, entryPoint := range entryPoints {
+		builtins := make([]*felt.Felt, len(entryPoint.Builtins))
+		for j, builtin := range entryPoint.Builtins {
+			builtins[j] = new(felt.Felt).SetBytes([]byte(builtin))
+		}
+		// It is important that Selector is first because the order
+		// influences the compiled class hash.
+		result[3*i] = entryPoint.Selector
+		result[3*i+1] = new(felt.Felt).SetUint64(entryPoint.Offset)
+		result[3*i+2] = crypto.PoseidonArray(builtins...)
+	}
+	return result
+}
+
+var ErrCompiledClassHashMismatch = errors.New("compiled class hash mismatch")
+
+// VerifyCompiledClassHashes checks that the compiled classes of the Cairo 1 classes declared by the state
+// diff hash to the compiled class hashes that the state diff commits to. Classes whose compiled class is
+// not known are not checked.
+func VerifyCompiledClassHashes(stateDiff *StateDiff, classes map[felt.Felt]Class) error {
+	for _, declared := range stateDiff.DeclaredV1Classes {
+		class, ok := classes[*declared.ClassHash].(*Cairo1Class)
+		if !ok || class.Compiled == nil {
+			continue
+		}
+
+		if compiledClassHash := class.Compiled.Hash(); !compiledClassHash.Equal(declared.CompiledClassHash) {
+			return fmt.Errorf("%w: class %s compiles to %s, state diff commits to %s", ErrCompiledClassHashMismatch,
+				declared.ClassHash, compiledClassHash, declared.CompiledClassHash)
+		}
+	}
+	return nil
+}
+
 type CantVerifyClassHashError struct {
 	c           Class
 	hashFailure error
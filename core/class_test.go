@@ -2,9 +2,12 @@ package core_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 	"reflect"
+	"sync"
 	"testing"
 
 	"github.com/NethermindEth/juno/clients/feeder"
@@ -118,6 +121,28 @@ func TestClassEncoding(t *testing.T) {
 				SemanticVersion: "0.1.0",
 			},
 		},
+		{
+			name: "V1 with compiled class",
+			class: &core.Cairo1Class{
+				Abi:         "abi",
+				AbiHash:     utils.HexToFelt(t, "0xDEADBEEF"),
+				Program:     []*felt.Felt{utils.HexToFelt(t, "0xDEAD")},
+				ProgramHash: utils.HexToFelt(t, "0xBEEFDEAD"),
+				Compiled: &core.CompiledClass{
+					Bytecode:        []*felt.Felt{utils.HexToFelt(t, "0xBEEF")},
+					PythonicHints:   json.RawMessage(`[[0,["memory[ap + 0] = 0"]]]`),
+					CompilerVersion: "1.0.0",
+					Hints:           json.RawMessage(`[]`),
+					Prime:           new(big.Int).SetBytes(utils.HexToFelt(t, "0xDEADBEEF").Marshal()),
+					External: []core.CompiledEntryPoint{
+						{Offset: 7, Builtins: []string{"range_check"}, Selector: utils.HexToFelt(t, "0xDEADBEEF")},
+					},
+					L1Handler:   []core.CompiledEntryPoint{},
+					Constructor: []core.CompiledEntryPoint{},
+				},
+				SemanticVersion: "0.1.0",
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -127,9 +152,111 @@ func TestClassEncoding(t *testing.T) {
 	}
 }
 
+func TestCompiledClassHash(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+	ctx := context.Background()
+
+	// the fixtures are small hand-written classes, one with bytecode segments and one without, so these
+	// hashes pin the implementation down rather than come from the network
+	tests := map[string]struct {
+		classHash         string
+		compiledClassHash string
+	}{
+		"segmented bytecode": {
+			classHash:         "0xc0de",
+			compiledClassHash: "0xa724aadde23ff9a00a510b0938695736e7c60041b15e764f22e929c4786cb5",
+		},
+		"bytecode without segments": {
+			classHash:         "0xc0df",
+			compiledClassHash: "0x3c8525b31cbf23af6fe20ce94b917b033ae8e83125bf61e0594ccabd661e414",
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			compiled, err := gw.CompiledClass(ctx, utils.HexToFelt(t, tc.classHash))
+			require.NoError(t, err)
+			assert.Equal(t, utils.HexToFelt(t, tc.compiledClassHash), compiled.Hash())
+		})
+	}
+
+	segmented, err := gw.CompiledClass(ctx, utils.HexToFelt(t, "0xc0de"))
+	require.NoError(t, err)
+	compiledClassHash := segmented.Hash()
+
+	t.Run("a single segment is hashed as the whole bytecode", func(t *testing.T) {
+		singleSegment := *segmented
+		singleSegment.BytecodeSegmentLengths = &core.SegmentLengths{Length: uint64(len(segmented.Bytecode))}
+		assert.Equal(t, utils.HexToFelt(t, tests["bytecode without segments"].compiledClassHash), singleSegment.Hash())
+	})
+
+	t.Run("the segments are part of the hash", func(t *testing.T) {
+		flattened := *segmented
+		flattened.BytecodeSegmentLengths = &core.SegmentLengths{Children: []core.SegmentLengths{
+			{Length: 3}, {Length: 2}, {Length: 2}, {Length: 3},
+		}}
+		assert.NotEqual(t, compiledClassHash, flattened.Hash())
+	})
+
+	t.Run("builtins are part of the hash", func(t *testing.T) {
+		withoutBuiltins := *segmented
+		withoutBuiltins.External = []core.CompiledEntryPoint{{Offset: 0, Builtins: []string{}, Selector: segmented.External[0].Selector}}
+		assert.NotEqual(t, compiledClassHash, withoutBuiltins.Hash())
+	})
+
+	t.Run("segments beyond the bytecode", func(t *testing.T) {
+		truncated := *segmented
+		truncated.Bytecode = segmented.Bytecode[:4]
+		assert.NotPanics(t, func() {
+			assert.NotEqual(t, compiledClassHash, truncated.Hash())
+		})
+	})
+}
+
+func TestVerifyCompiledClassHashes(t *testing.T) {
+	compiled := &core.CompiledClass{
+		Bytecode: []*felt.Felt{utils.HexToFelt(t, "0xDEAD")},
+		External: []core.CompiledEntryPoint{{Offset: 1, Builtins: []string{}, Selector: utils.HexToFelt(t, "0xBEEF")}},
+	}
+	classHash := utils.HexToFelt(t, "0xc1a55")
+	classes := map[felt.Felt]core.Class{*classHash: &core.Cairo1Class{Compiled: compiled}}
+	declare := func(compiledClassHash *felt.Felt) *core.StateDiff {
+		return &core.StateDiff{DeclaredV1Classes: []core.DeclaredV1Class{
+			{ClassHash: classHash, CompiledClassHash: compiledClassHash},
+		}}
+	}
+
+	t.Run("matching compiled class hash", func(t *testing.T) {
+		assert.NoError(t, core.VerifyCompiledClassHashes(declare(compiled.Hash()), classes))
+	})
+
+	t.Run("mismatching compiled class hash", func(t *testing.T) {
+		err := core.VerifyCompiledClassHashes(declare(utils.HexToFelt(t, "0xbad")), classes)
+		assert.ErrorIs(t, err, core.ErrCompiledClassHashMismatch)
+	})
+
+	t.Run("unknown compiled class is not checked", func(t *testing.T) {
+		withoutCompiled := map[felt.Felt]core.Class{*classHash: &core.Cairo1Class{}}
+		assert.NoError(t, core.VerifyCompiledClassHashes(declare(utils.HexToFelt(t, "0xbad")), withoutCompiled))
+	})
+}
+
+var registerClassTypes sync.Once
+
+// registerClassTypesToEncoder makes classes, which are stored behind the core.Class interface, decodable
+func registerClassTypesToEncoder(t *testing.T) {
+	t.Helper()
+	registerClassTypes.Do(func() {
+		require.NoError(t, encoder.RegisterType(reflect.TypeOf(&core.Cairo0Class{})))
+		require.NoError(t, encoder.RegisterType(reflect.TypeOf(&core.Cairo1Class{})))
+	})
+}
+
 func checkClassSymmetry(t *testing.T, input core.Class) {
 	t.Helper()
-	require.NoError(t, encoder.RegisterType(reflect.TypeOf(input)))
+	registerClassTypesToEncoder(t)
 
 	data, err := encoder.Marshal(input)
 	require.NoError(t, err)
@@ -133,17 +133,32 @@ func (c *Contract) Root() (*felt.Felt, error) {
 	return cStorage.Root()
 }
 
+// OnValueChanged is called with the previous value of a storage location whenever it is changed.
+type OnValueChanged = func(location, oldValue *felt.Felt) error
+
 // UpdateStorage applies a change-set to the contract storage.
-func (c *Contract) UpdateStorage(diff []StorageDiff) error {
+// If cb is not nil, it is called for every storage location that is written to.
+func (c *Contract) UpdateStorage(diff []StorageDiff, cb OnValueChanged) error {
 	cStorage, err := storage(c.Address, c.txn)
 	if err != nil {
 		return err
 	}
 	// apply the diff
 	for _, pair := range diff {
-		if _, err := cStorage.Put(pair.Key, pair.Value); err != nil {
+		oldValue, err := cStorage.Put(pair.Key, pair.Value)
+		if err != nil {
 			return err
 		}
+
+		if cb != nil {
+			// Put returns a nil old value when a zero value is written to an empty location
+			if oldValue == nil {
+				oldValue = new(felt.Felt)
+			}
+			if err = cb(pair.Key, oldValue); err != nil {
+				return err
+			}
+		}
 	}
 
 	// update contract storage root in the database
@@ -87,7 +87,7 @@ func TestNewContract(t *testing.T) {
 			oldRoot, err := contract.Root()
 			require.NoError(t, err)
 
-			require.NoError(t, contract.UpdateStorage([]core.StorageDiff{{Key: addr, Value: classHash}}))
+			require.NoError(t, contract.UpdateStorage([]core.StorageDiff{{Key: addr, Value: classHash}}, nil))
 
 			newContract, err := core.NewContract(addr, txn)
 			require.NoError(t, err)
@@ -120,7 +120,7 @@ func TestNewContract(t *testing.T) {
 			assert.Error(t, contract.UpdateNonce(&felt.Zero))
 		})
 		t.Run("UpdateStorage()", func(t *testing.T) {
-			assert.Error(t, contract.UpdateStorage(nil))
+			assert.Error(t, contract.UpdateStorage(nil, nil))
 		})
 	})
 }
@@ -183,7 +183,7 @@ func TestUpdateStorageAndStorage(t *testing.T) {
 		oldRoot, err := contract.Root()
 		require.NoError(t, err)
 
-		require.NoError(t, contract.UpdateStorage([]core.StorageDiff{{Key: addr, Value: classHash}}))
+		require.NoError(t, contract.UpdateStorage([]core.StorageDiff{{Key: addr, Value: classHash}}, nil))
 
 		gotValue, err := contract.Storage(addr)
 		require.NoError(t, err)
@@ -195,7 +195,7 @@ func TestUpdateStorageAndStorage(t *testing.T) {
 	})
 
 	t.Run("delete key from storage with storage diff", func(t *testing.T) {
-		require.NoError(t, contract.UpdateStorage([]core.StorageDiff{{Key: addr, Value: new(felt.Felt)}}))
+		require.NoError(t, contract.UpdateStorage([]core.StorageDiff{{Key: addr, Value: new(felt.Felt)}}, nil))
 
 		_, err := contract.Storage(addr)
 		require.EqualError(t, err, db.ErrKeyNotFound.Error())
@@ -0,0 +1,84 @@
+package crypto
+
+import (
+	"math/big"
+
+	"github.com/NethermindEth/juno/core/felt"
+	starkcurve "github.com/consensys/gnark-crypto/ecc/stark-curve"
+	"github.com/consensys/gnark-crypto/ecc/stark-curve/fp"
+	"github.com/consensys/gnark-crypto/ecc/stark-curve/fr"
+)
+
+// ecdsaElementBits is the number of bits that the message hash, r and the inverse of s must fit in.
+const ecdsaElementBits = 251
+
+var ecdsaElementBound = new(big.Int).Lsh(big.NewInt(1), ecdsaElementBits)
+
+// VerifyECDSA checks that (r, s) is a valid [ECDSA signature] over the STARK curve of the message hash, by
+// the key whose x coordinate is the given public key. As the public key does not carry the y coordinate,
+// the signature is accepted for either of the two points that have the given x coordinate.
+//
+// [ECDSA signature]: https://docs.starkware.co/starkex/crypto/stark-curve.html
+func VerifyECDSA(publicKey, msgHash, r, s *felt.Felt) bool {
+	order := fr.Modulus()
+	rInt := r.BigInt(new(big.Int))
+	sInt := s.BigInt(new(big.Int))
+	msgInt := msgHash.BigInt(new(big.Int))
+
+	if !inRange(rInt, 1, ecdsaElementBound) || !inRange(sInt, 1, order) || !inRange(msgInt, 0, ecdsaElementBound) {
+		return false
+	}
+	w := new(big.Int).ModInverse(sInt, order)
+	if w == nil || !inRange(w, 1, ecdsaElementBound) {
+		return false
+	}
+
+	key, ok := pointWithX(publicKey.Impl())
+	if !ok {
+		return false
+	}
+
+	var msgPoint, keyPoint starkcurve.G1Jac
+	_, generator := starkcurve.Generators()
+	msgPoint.ScalarMultiplicationAffine(&generator, msgInt)
+	keyPoint.ScalarMultiplicationAffine(key, rInt)
+
+	// the other point with the same x coordinate is the negation of key, so it is checked by subtracting
+	for _, negate := range []bool{false, true} {
+		var candidate starkcurve.G1Jac
+		candidate.Set(&msgPoint)
+		if negate {
+			candidate.SubAssign(&keyPoint)
+		} else {
+			candidate.AddAssign(&keyPoint)
+		}
+		candidate.ScalarMultiplication(&candidate, w)
+
+		var candidateAffine starkcurve.G1Affine
+		candidateAffine.FromJacobian(&candidate)
+		if !candidateAffine.IsInfinity() && candidateAffine.X.BigInt(new(big.Int)).Cmp(rInt) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// inRange checks that low <= n < high.
+func inRange(n *big.Int, low int64, high *big.Int) bool {
+	return n.Cmp(big.NewInt(low)) >= 0 && n.Cmp(high) < 0
+}
+
+// pointWithX returns one of the points of the STARK curve with the given x coordinate, if there is one.
+func pointWithX(x *fp.Element) (*starkcurve.G1Affine, bool) {
+	alpha, beta := starkcurve.CurveCoefficients()
+
+	// y^2 = x^3 + alpha * x + beta
+	var ySquared, y fp.Element
+	ySquared.Square(x).Mul(&ySquared, x)
+	ySquared.Add(&ySquared, new(fp.Element).Mul(&alpha, x))
+	ySquared.Add(&ySquared, &beta)
+	if y.Sqrt(&ySquared) == nil {
+		return nil, false
+	}
+	return &starkcurve.G1Affine{X: *x, Y: y}, true
+}
@@ -0,0 +1,83 @@
+package crypto_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/crypto"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/utils"
+	starkcurve "github.com/consensys/gnark-crypto/ecc/stark-curve"
+	"github.com/consensys/gnark-crypto/ecc/stark-curve/fr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sign signs the message hash with the given private key and nonce, it returns the x coordinate of the
+// public key along with the signature.
+func sign(t *testing.T, privateKey, msgHash, nonce *big.Int) (publicKey, r, s *felt.Felt) {
+	t.Helper()
+
+	order := fr.Modulus()
+	var publicPoint, noncePoint starkcurve.G1Affine
+	publicPoint.ScalarMultiplicationBase(privateKey)
+	noncePoint.ScalarMultiplicationBase(nonce)
+
+	rInt := noncePoint.X.BigInt(new(big.Int))
+	require.Equal(t, -1, rInt.Cmp(order))
+
+	// s = (msgHash + r * privateKey) / nonce
+	sInt := new(big.Int).Mul(rInt, privateKey)
+	sInt.Add(sInt, msgHash)
+	sInt.Mul(sInt, new(big.Int).ModInverse(nonce, order))
+	sInt.Mod(sInt, order)
+
+	publicKeyBytes := publicPoint.X.Bytes()
+	rBytes := rInt.FillBytes(make([]byte, felt.Bytes))
+	sBytes := sInt.FillBytes(make([]byte, felt.Bytes))
+	return new(felt.Felt).SetBytes(publicKeyBytes[:]), new(felt.Felt).SetBytes(rBytes), new(felt.Felt).SetBytes(sBytes)
+}
+
+func TestVerifyECDSA(t *testing.T) {
+	t.Run("known signature", func(t *testing.T) {
+		publicKey := utils.HexToFelt(t, "0x1ef15c18599971b7beced415a40f0c7deacfd9b0d1819e03d723d8bc943cfca")
+		msgHash := utils.HexToFelt(t, "0x2")
+		r := utils.HexToFelt(t, "0x411494b501a98abd8262b0da1351e17899a0c4ef23dd2f96fec5ba847310b20")
+		s := utils.HexToFelt(t, "0x405c3191ab3883ef2b763af35bc5f5d15b3b4e99461d70e84c654a351a7c81b")
+
+		assert.True(t, crypto.VerifyECDSA(publicKey, msgHash, r, s))
+		assert.False(t, crypto.VerifyECDSA(publicKey, utils.HexToFelt(t, "0x3"), r, s))
+	})
+
+	privateKey := big.NewInt(0x1234567890abcdef)
+	msgHash := utils.HexToFelt(t, "0x397e76d1667c4454bfb83514e120583af836f8e32a516765497823eabe16a3f")
+	publicKey, r, s := sign(t, privateKey, msgHash.BigInt(new(big.Int)), big.NewInt(0x5eed))
+
+	t.Run("valid signature", func(t *testing.T) {
+		assert.True(t, crypto.VerifyECDSA(publicKey, msgHash, r, s))
+	})
+
+	t.Run("signature by a key with the other y coordinate", func(t *testing.T) {
+		negatedKey := new(big.Int).Sub(fr.Modulus(), privateKey)
+		otherPublicKey, otherR, otherS := sign(t, negatedKey, msgHash.BigInt(new(big.Int)), big.NewInt(0x5eed))
+		require.Equal(t, publicKey, otherPublicKey)
+		assert.True(t, crypto.VerifyECDSA(publicKey, msgHash, otherR, otherS))
+	})
+
+	t.Run("tampered signatures are rejected", func(t *testing.T) {
+		one := new(felt.Felt).SetUint64(1)
+		assert.False(t, crypto.VerifyECDSA(publicKey, new(felt.Felt).Add(msgHash, one), r, s))
+		assert.False(t, crypto.VerifyECDSA(publicKey, msgHash, new(felt.Felt).Add(r, one), s))
+		assert.False(t, crypto.VerifyECDSA(publicKey, msgHash, r, new(felt.Felt).Add(s, one)))
+		assert.False(t, crypto.VerifyECDSA(new(felt.Felt).Add(publicKey, one), msgHash, r, s))
+	})
+
+	t.Run("out of range values are rejected", func(t *testing.T) {
+		assert.False(t, crypto.VerifyECDSA(publicKey, msgHash, new(felt.Felt), s))
+		assert.False(t, crypto.VerifyECDSA(publicKey, msgHash, r, new(felt.Felt)))
+
+		tooBig := utils.HexToFelt(t, "0x800000000000000000000000000000000000000000000000000000000000000")
+		assert.False(t, crypto.VerifyECDSA(publicKey, tooBig, r, s))
+		assert.False(t, crypto.VerifyECDSA(publicKey, msgHash, tooBig, s))
+	})
+}
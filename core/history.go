@@ -0,0 +1,187 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
+)
+
+const blockNumberLen = 8
+
+// ErrCheckHeadState is returned by history lookups when the value has not changed
+// since the queried block, which means the value in the head state is the answer.
+var ErrCheckHeadState = errors.New("check head state")
+
+// The history of contract values is maintained as follows:
+//
+// [db.ContractNonceHistory](ContractAddress, BlockNumber) -> (NonceBeforeBlock)
+// [db.ContractClassHashHistory](ContractAddress, BlockNumber) -> (ClassHashBeforeBlock)
+// [db.ContractStorageHistory](ContractAddress, StorageLocation, BlockNumber) -> (ValueBeforeBlock)
+// [db.ContractDeploymentHeight](ContractAddress) -> (BlockNumber)
+//
+// An entry is written for every block that changes a value and holds the value it had before
+// that block was applied. The value as of block N is therefore the one stored in the first
+// entry after N, or the head value if there is no such entry.
+
+func historyKey(prefix []byte, blockNumber uint64) []byte {
+	return append(prefix, uint64Bytes(blockNumber)...)
+}
+
+func uint64Bytes(n uint64) []byte {
+	b := make([]byte, blockNumberLen)
+	binary.BigEndian.PutUint64(b, n)
+	return b
+}
+
+func logOldValue(txn db.Transaction, prefix []byte, oldValue *felt.Felt, blockNumber uint64) error {
+	return txn.Set(historyKey(prefix, blockNumber), oldValue.Marshal())
+}
+
+// loggedValue returns the old value that was logged for the key with the given prefix at the given block.
+func loggedValue(txn db.Transaction, prefix []byte, blockNumber uint64) (*felt.Felt, error) {
+	var value *felt.Felt
+	if err := txn.Get(historyKey(prefix, blockNumber), func(val []byte) error {
+		value = new(felt.Felt).SetBytes(val)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func deleteLog(txn db.Transaction, prefix []byte, blockNumber uint64) error {
+	return txn.Delete(historyKey(prefix, blockNumber))
+}
+
+// valueAt returns the value that the key with the given prefix held after the block with the given
+// number was applied. [ErrCheckHeadState] is returned if the value has not changed since.
+func valueAt(txn db.Transaction, prefix []byte, blockNumber uint64) (*felt.Felt, error) {
+	it, err := txn.NewIterator()
+	if err != nil {
+		return nil, err
+	}
+
+	if !it.Seek(historyKey(prefix, blockNumber+1)) {
+		return nil, db.CloseAndWrapOnError(it.Close, ErrCheckHeadState)
+	}
+
+	key := it.Key()
+	if len(key) != len(prefix)+blockNumberLen || !bytes.HasPrefix(key, prefix) {
+		return nil, db.CloseAndWrapOnError(it.Close, ErrCheckHeadState)
+	}
+
+	val, err := it.Value()
+	if err != nil {
+		return nil, db.CloseAndWrapOnError(it.Close, err)
+	}
+	value := new(felt.Felt).SetBytes(val)
+	return value, it.Close()
+}
+
+func nonceHistoryPrefix(addr *felt.Felt) []byte {
+	return db.ContractNonceHistory.Key(addr.Marshal())
+}
+
+func classHashHistoryPrefix(addr *felt.Felt) []byte {
+	return db.ContractClassHashHistory.Key(addr.Marshal())
+}
+
+func storageHistoryPrefix(addr, location *felt.Felt) []byte {
+	return db.ContractStorageHistory.Key(addr.Marshal(), location.Marshal())
+}
+
+func setDeploymentHeight(txn db.Transaction, addr *felt.Felt, blockNumber uint64) error {
+	return txn.Set(db.ContractDeploymentHeight.Key(addr.Marshal()), uint64Bytes(blockNumber))
+}
+
+// ContractNonceAt returns the nonce of the contract at the given address as of the given block.
+func (s *State) ContractNonceAt(addr *felt.Felt, blockNumber uint64) (*felt.Felt, error) {
+	return valueAt(s.txn, nonceHistoryPrefix(addr), blockNumber)
+}
+
+// ContractClassHashAt returns the class hash of the contract at the given address as of the given block.
+func (s *State) ContractClassHashAt(addr *felt.Felt, blockNumber uint64) (*felt.Felt, error) {
+	return valueAt(s.txn, classHashHistoryPrefix(addr), blockNumber)
+}
+
+// ContractStorageAt returns the value of a storage location of the contract at the given address
+// as of the given block.
+func (s *State) ContractStorageAt(addr, key *felt.Felt, blockNumber uint64) (*felt.Felt, error) {
+	return valueAt(s.txn, storageHistoryPrefix(addr, key), blockNumber)
+}
+
+// ContractIsAlreadyDeployedAt returns whether the contract at the given address was deployed
+// at or before the given block.
+func (s *State) ContractIsAlreadyDeployedAt(addr *felt.Felt, blockNumber uint64) (bool, error) {
+	var deployedAt uint64
+	err := s.txn.Get(db.ContractDeploymentHeight.Key(addr.Marshal()), func(val []byte) error {
+		deployedAt = binary.BigEndian.Uint64(val)
+		return nil
+	})
+	if errors.Is(err, db.ErrKeyNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return deployedAt <= blockNumber, nil
+}
+
+// BackfillHistory records the history of the contract values that the state diff of the given block
+// changed, for the blocks that were stored before the history was recorded. The blocks must be backfilled
+// in order, from the first one, as the value that every key was last set to is tracked in
+// [db.MigrationScratch], which is left for the caller to clear once all the blocks are backfilled.
+func BackfillHistory(txn db.Transaction, blockNumber uint64, diff *StateDiff) error {
+	// the values are logged in the order that [State.Update] applies them in
+	for _, contract := range diff.DeployedContracts {
+		if err := setDeploymentHeight(txn, contract.Address, blockNumber); err != nil {
+			return err
+		}
+		if err := txn.Set(db.MigrationScratch.Key(classHashHistoryPrefix(contract.Address)), contract.ClassHash.Marshal()); err != nil {
+			return err
+		}
+	}
+
+	for _, replaced := range diff.ReplacedClasses {
+		if err := backfillValue(txn, classHashHistoryPrefix(replaced.Address), replaced.ClassHash, blockNumber); err != nil {
+			return err
+		}
+	}
+
+	for addr, nonce := range diff.Nonces {
+		addr := addr
+		if err := backfillValue(txn, nonceHistoryPrefix(&addr), nonce, blockNumber); err != nil {
+			return err
+		}
+	}
+
+	for addr, storageDiff := range diff.StorageDiffs {
+		addr := addr
+		for _, pair := range storageDiff {
+			if err := backfillValue(txn, storageHistoryPrefix(&addr, pair.Key), pair.Value, blockNumber); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// backfillValue logs the value that the key with the given prefix was last set to, or zero if it was not
+// set before, as its old value at the given block, and tracks the new value it is set to.
+func backfillValue(txn db.Transaction, prefix []byte, newValue *felt.Felt, blockNumber uint64) error {
+	scratchKey := db.MigrationScratch.Key(prefix)
+	oldValue := new(felt.Felt)
+	if err := txn.Get(scratchKey, func(val []byte) error {
+		oldValue.SetBytes(val)
+		return nil
+	}); err != nil && !errors.Is(err, db.ErrKeyNotFound) {
+		return err
+	}
+
+	if err := logOldValue(txn, prefix, oldValue, blockNumber); err != nil {
+		return err
+	}
+	return txn.Set(scratchKey, newValue.Marshal())
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"github.com/NethermindEth/juno/core/felt"
+)
+
+var _ StateReader = (*pendingState)(nil)
+
+// pendingState is a read-only view of the state that results from applying the state diff of the
+// pending block on top of the head state. Values that the pending block does not change are read
+// from the head state.
+type pendingState struct {
+	blockNumber uint64
+	stateDiff   *StateDiff
+	newClasses  map[felt.Felt]Class
+	head        StateReader
+}
+
+// NewPendingState returns a StateReader that reads the head state as it will be once the pending block
+// with the given number, state diff and newly declared classes is applied.
+func NewPendingState(blockNumber uint64, stateDiff *StateDiff, newClasses map[felt.Felt]Class, head StateReader) StateReader {
+	return &pendingState{
+		blockNumber: blockNumber,
+		stateDiff:   stateDiff,
+		newClasses:  newClasses,
+		head:        head,
+	}
+}
+
+func (p *pendingState) ContractClassHash(addr *felt.Felt) (*felt.Felt, error) {
+	for i := len(p.stateDiff.ReplacedClasses) - 1; i >= 0; i-- {
+		if p.stateDiff.ReplacedClasses[i].Address.Equal(addr) {
+			return p.stateDiff.ReplacedClasses[i].ClassHash, nil
+		}
+	}
+
+	if classHash := p.deployedClassHash(addr); classHash != nil {
+		return classHash, nil
+	}
+	return p.head.ContractClassHash(addr)
+}
+
+func (p *pendingState) ContractNonce(addr *felt.Felt) (*felt.Felt, error) {
+	if nonce, found := p.stateDiff.Nonces[*addr]; found {
+		return nonce, nil
+	}
+
+	if p.deployedClassHash(addr) != nil {
+		return &felt.Zero, nil
+	}
+	return p.head.ContractNonce(addr)
+}
+
+func (p *pendingState) ContractStorage(addr, key *felt.Felt) (*felt.Felt, error) {
+	diffs := p.stateDiff.StorageDiffs[*addr]
+	for i := len(diffs) - 1; i >= 0; i-- {
+		if diffs[i].Key.Equal(key) {
+			return diffs[i].Value, nil
+		}
+	}
+
+	if p.deployedClassHash(addr) != nil {
+		return &felt.Zero, nil
+	}
+	return p.head.ContractStorage(addr, key)
+}
+
+func (p *pendingState) Class(classHash *felt.Felt) (*DeclaredClass, error) {
+	if class := p.newClasses[*classHash]; class != nil {
+		return &DeclaredClass{
+			At:    p.blockNumber,
+			Class: class,
+		}, nil
+	}
+	return p.head.Class(classHash)
+}
+
+// deployedClassHash returns the class hash of the contract at the given address if it is deployed
+// by the pending block, nil otherwise.
+func (p *pendingState) deployedClassHash(addr *felt.Felt) *felt.Felt {
+	for _, deployedContract := range p.stateDiff.DeployedContracts {
+		if deployedContract.Address.Equal(addr) {
+			return deployedContract.ClassHash
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/mocks"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPendingState(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	head := mocks.NewMockStateReader(mockCtrl)
+
+	deployedAddr := utils.HexToFelt(t, "0x1")
+	replacedAddr := utils.HexToFelt(t, "0x2")
+	headAddr := utils.HexToFelt(t, "0x3")
+	newClass := &core.Cairo0Class{}
+
+	stateDiff := &core.StateDiff{
+		StorageDiffs: map[felt.Felt][]core.StorageDiff{
+			*deployedAddr: {{Key: utils.HexToFelt(t, "0x5"), Value: utils.HexToFelt(t, "0x55")}},
+			*headAddr: {
+				{Key: utils.HexToFelt(t, "0x6"), Value: utils.HexToFelt(t, "0x66")},
+				{Key: utils.HexToFelt(t, "0x6"), Value: utils.HexToFelt(t, "0x67")},
+			},
+		},
+		Nonces: map[felt.Felt]*felt.Felt{
+			*headAddr: utils.HexToFelt(t, "0x7"),
+		},
+		DeployedContracts: []core.DeployedContract{
+			{Address: deployedAddr, ClassHash: utils.HexToFelt(t, "0xC1")},
+		},
+		ReplacedClasses: []core.ReplacedClass{
+			{Address: replacedAddr, ClassHash: utils.HexToFelt(t, "0xC2")},
+		},
+	}
+	newClasses := map[felt.Felt]core.Class{*utils.HexToFelt(t, "0xC1"): newClass}
+
+	state := core.NewPendingState(42, stateDiff, newClasses, head)
+
+	t.Run("class hash", func(t *testing.T) {
+		classHash, err := state.ContractClassHash(deployedAddr)
+		require.NoError(t, err)
+		assert.Equal(t, utils.HexToFelt(t, "0xC1"), classHash)
+
+		classHash, err = state.ContractClassHash(replacedAddr)
+		require.NoError(t, err)
+		assert.Equal(t, utils.HexToFelt(t, "0xC2"), classHash)
+
+		head.EXPECT().ContractClassHash(headAddr).Return(utils.HexToFelt(t, "0xC3"), nil)
+		classHash, err = state.ContractClassHash(headAddr)
+		require.NoError(t, err)
+		assert.Equal(t, utils.HexToFelt(t, "0xC3"), classHash)
+	})
+
+	t.Run("nonce", func(t *testing.T) {
+		nonce, err := state.ContractNonce(headAddr)
+		require.NoError(t, err)
+		assert.Equal(t, utils.HexToFelt(t, "0x7"), nonce)
+
+		nonce, err = state.ContractNonce(deployedAddr)
+		require.NoError(t, err)
+		assert.Equal(t, &felt.Zero, nonce)
+
+		head.EXPECT().ContractNonce(replacedAddr).Return(utils.HexToFelt(t, "0x8"), nil)
+		nonce, err = state.ContractNonce(replacedAddr)
+		require.NoError(t, err)
+		assert.Equal(t, utils.HexToFelt(t, "0x8"), nonce)
+	})
+
+	t.Run("storage", func(t *testing.T) {
+		value, err := state.ContractStorage(deployedAddr, utils.HexToFelt(t, "0x5"))
+		require.NoError(t, err)
+		assert.Equal(t, utils.HexToFelt(t, "0x55"), value)
+
+		value, err = state.ContractStorage(deployedAddr, utils.HexToFelt(t, "0x6"))
+		require.NoError(t, err)
+		assert.Equal(t, &felt.Zero, value)
+
+		// the last write wins
+		value, err = state.ContractStorage(headAddr, utils.HexToFelt(t, "0x6"))
+		require.NoError(t, err)
+		assert.Equal(t, utils.HexToFelt(t, "0x67"), value)
+
+		head.EXPECT().ContractStorage(headAddr, utils.HexToFelt(t, "0x5")).Return(utils.HexToFelt(t, "0x9"), nil)
+		value, err = state.ContractStorage(headAddr, utils.HexToFelt(t, "0x5"))
+		require.NoError(t, err)
+		assert.Equal(t, utils.HexToFelt(t, "0x9"), value)
+	})
+
+	t.Run("class", func(t *testing.T) {
+		declaredClass, err := state.Class(utils.HexToFelt(t, "0xC1"))
+		require.NoError(t, err)
+		assert.Equal(t, &core.DeclaredClass{At: 42, Class: newClass}, declaredClass)
+
+		headClass := &core.DeclaredClass{At: 1, Class: &core.Cairo1Class{}}
+		head.EXPECT().Class(utils.HexToFelt(t, "0xC3")).Return(headClass, nil)
+		declaredClass, err = state.Class(utils.HexToFelt(t, "0xC3"))
+		require.NoError(t, err)
+		assert.Equal(t, headClass, declaredClass)
+	})
+}
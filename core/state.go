@@ -19,6 +19,16 @@ var (
 	leafVersion  = new(felt.Felt).SetBytes([]byte(`CONTRACT_CLASS_LEAF_V0`))
 )
 
+//go:generate mockgen -destination=../mocks/mock_state.go -package=mocks github.com/NethermindEth/juno/core StateReader
+type StateReader interface {
+	ContractClassHash(addr *felt.Felt) (*felt.Felt, error)
+	ContractNonce(addr *felt.Felt) (*felt.Felt, error)
+	ContractStorage(addr, key *felt.Felt) (*felt.Felt, error)
+	Class(classHash *felt.Felt) (*DeclaredClass, error)
+}
+
+var _ StateReader = (*State)(nil)
+
 type State struct {
 	txn db.Transaction
 }
@@ -29,11 +39,15 @@ func NewState(txn db.Transaction) *State {
 
 // putNewContract creates a contract storage instance in the state and stores the relation between contract address and class hash to be
 // queried later with [GetContractClass].
-func (s *State) putNewContract(addr, classHash *felt.Felt) error {
+func (s *State) putNewContract(addr, classHash *felt.Felt, blockNumber uint64) error {
 	contract, err := DeployContract(addr, classHash, s.txn)
 	if err != nil {
 		return err
 	}
+
+	if err = setDeploymentHeight(s.txn, addr, blockNumber); err != nil {
+		return err
+	}
 	return s.updateContractCommitment(contract)
 }
 
@@ -55,6 +69,35 @@ func (s *State) ContractNonce(addr *felt.Felt) (*felt.Felt, error) {
 	return contract.Nonce()
 }
 
+// ContractStorage returns the value of a storage location of the contract at a given address.
+// Storage locations that have never been written to hold the zero value.
+func (s *State) ContractStorage(addr, key *felt.Felt) (*felt.Felt, error) {
+	contract, err := NewContract(addr, s.txn)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := contract.Storage(key)
+	if errors.Is(err, db.ErrKeyNotFound) {
+		return new(felt.Felt), nil
+	}
+	return value, err
+}
+
+// Class returns the class with the given hash along with the number of the block it was declared at.
+func (s *State) Class(classHash *felt.Felt) (*DeclaredClass, error) {
+	classKey := db.Class.Key(classHash.Marshal())
+
+	var class DeclaredClass
+	err := s.txn.Get(classKey, func(val []byte) error {
+		return encoder.UnmarshalWithJSONMaps(val, &class)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &class, nil
+}
+
 // Root returns the state commitment.
 func (s *State) Root() (*felt.Felt, error) {
 	var storageRoot, classesRoot *felt.Felt
@@ -92,6 +135,103 @@ func (s *State) Root() (*felt.Felt, error) {
 	return crypto.PoseidonArray(stateVersion, storageRoot, classesRoot), nil
 }
 
+// StateProof proves the state of a contract and the values of some of its storage locations against the
+// state commitment, which is derived from the roots of the contracts and classes tries.
+type StateProof struct {
+	ContractsRoot *felt.Felt
+	ClassesRoot   *felt.Felt
+	// ContractProof proves the commitment of the contract against ContractsRoot
+	ContractProof []trie.ProofNode
+	// Contract is nil if there is no contract at the address, ContractProof then proves its absence
+	Contract *ContractProof
+}
+
+// ContractProof carries the fields a contract commitment is calculated from, along with proofs of storage
+// values against the root of the contract storage.
+type ContractProof struct {
+	ClassHash     *felt.Felt
+	Nonce         *felt.Felt
+	StorageRoot   *felt.Felt
+	StorageProofs [][]trie.ProofNode
+}
+
+// Prove returns a [StateProof] of the contract at the given address and of the values of the given storage
+// locations of the contract.
+func (s *State) Prove(addr *felt.Felt, keys []*felt.Felt) (*StateProof, error) {
+	proof := new(StateProof)
+
+	contracts, closer, err := s.storage()
+	if err != nil {
+		return nil, err
+	}
+	if proof.ContractsRoot, err = contracts.Root(); err != nil {
+		return nil, err
+	}
+	if proof.ContractProof, err = contracts.Prove(addr); err != nil {
+		return nil, err
+	}
+	if err = closer(); err != nil {
+		return nil, err
+	}
+
+	classes, closer, err := s.classesTrie()
+	if err != nil {
+		return nil, err
+	}
+	if proof.ClassesRoot, err = classes.Root(); err != nil {
+		return nil, err
+	}
+	if err = closer(); err != nil {
+		return nil, err
+	}
+
+	contract, err := NewContract(addr, s.txn)
+	if errors.Is(err, ErrContractNotDeployed) {
+		return proof, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	proof.Contract = new(ContractProof)
+	if proof.Contract.ClassHash, err = contract.ClassHash(); err != nil {
+		return nil, err
+	}
+	if proof.Contract.Nonce, err = contract.Nonce(); err != nil {
+		return nil, err
+	}
+
+	contractStorage, err := storage(addr, s.txn)
+	if err != nil {
+		return nil, err
+	}
+	if proof.Contract.StorageRoot, err = contractStorage.Root(); err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		var storageProof []trie.ProofNode
+		if storageProof, err = contractStorage.Prove(key); err != nil {
+			return nil, err
+		}
+		proof.Contract.StorageProofs = append(proof.Contract.StorageProofs, storageProof)
+	}
+	return proof, nil
+}
+
+// IterateContractStorage calls fn with the storage locations of the contract at the given address that hold
+// a value, starting at the given key, in ascending order of keys, until fn returns false or an error.
+// A nil start iterates over the whole storage.
+func (s *State) IterateContractStorage(addr, start *felt.Felt, fn trie.LeafFunc) error {
+	if _, err := NewContract(addr, s.txn); err != nil {
+		return err
+	}
+
+	contractStorage, err := storage(addr, s.txn)
+	if err != nil {
+		return err
+	}
+	return contractStorage.Iterate(start, nil, fn)
+}
+
 // storage returns a [core.Trie] that represents the Starknet global state in the given Txn context.
 func (s *State) storage() (*trie.Trie, func() error, error) {
 	return s.globalTrie(db.StateTrie, trie.NewTriePedersen)
@@ -148,8 +288,9 @@ func (s *State) globalTrie(bucket db.Bucket, newTrie trie.NewTrieFunc) (*trie.Tr
 // Update applies a StateUpdate to the State object. State is not
 // updated if an error is encountered during the operation. If update's
 // old or new root does not match the state's old or new roots,
-// [ErrMismatchedRoot] is returned.
-func (s *State) Update(update *StateUpdate, declaredClasses map[felt.Felt]Class) error {
+// [ErrMismatchedRoot] is returned. The previous values of all changed
+// contract fields are recorded as history of the given block number.
+func (s *State) Update(blockNumber uint64, update *StateUpdate, declaredClasses map[felt.Felt]Class) error {
 	currentRoot, err := s.Root()
 	if err != nil {
 		return err
@@ -158,9 +299,8 @@ func (s *State) Update(update *StateUpdate, declaredClasses map[felt.Felt]Class)
 	}
 
 	// register declared classes mentioned in stateDiff.deployedContracts and stateDiff.declaredClasses
-	// Todo: add test cases for retrieving Classes when State struct is extended to return Classes.
 	for classHash, class := range declaredClasses {
-		if err = s.putClass(&classHash, class); err != nil {
+		if err = s.putClass(&classHash, class, blockNumber); err != nil {
 			return err
 		}
 	}
@@ -169,7 +309,7 @@ func (s *State) Update(update *StateUpdate, declaredClasses map[felt.Felt]Class)
 		return err
 	}
 
-	if err = s.updateContracts(update.StateDiff); err != nil {
+	if err = s.updateContracts(blockNumber, update.StateDiff); err != nil {
 		return err
 	}
 
@@ -182,31 +322,31 @@ func (s *State) Update(update *StateUpdate, declaredClasses map[felt.Felt]Class)
 	return nil
 }
 
-func (s *State) updateContracts(diff *StateDiff) error {
+func (s *State) updateContracts(blockNumber uint64, diff *StateDiff) error {
 	// register deployed contracts
 	for _, contract := range diff.DeployedContracts {
-		if err := s.putNewContract(contract.Address, contract.ClassHash); err != nil {
+		if err := s.putNewContract(contract.Address, contract.ClassHash, blockNumber); err != nil {
 			return err
 		}
 	}
 
 	// replace contract instances
 	for _, replace := range diff.ReplacedClasses {
-		if err := s.replaceContract(replace.Address, replace.ClassHash); err != nil {
+		if err := s.replaceContract(replace.Address, replace.ClassHash, blockNumber); err != nil {
 			return err
 		}
 	}
 
 	// update contract nonces
 	for addr, nonce := range diff.Nonces {
-		if err := s.updateContractNonce(&addr, nonce); err != nil {
+		if err := s.updateContractNonce(&addr, nonce, blockNumber); err != nil {
 			return err
 		}
 	}
 
 	// update contract storages
 	for addr, storageDiff := range diff.StorageDiffs {
-		if err := s.updateContractStorage(&addr, storageDiff); err != nil {
+		if err := s.updateContractStorage(&addr, storageDiff, blockNumber); err != nil {
 			return err
 		}
 	}
@@ -215,12 +355,21 @@ func (s *State) updateContracts(diff *StateDiff) error {
 }
 
 // replaceContract replaces the class that a contract at a given address instantiates
-func (s *State) replaceContract(addr, classHash *felt.Felt) error {
+func (s *State) replaceContract(addr, classHash *felt.Felt, blockNumber uint64) error {
 	contract, err := NewContract(addr, s.txn)
 	if err != nil {
 		return err
 	}
 
+	oldClassHash, err := contract.ClassHash()
+	if err != nil {
+		return err
+	}
+
+	if err = logOldValue(s.txn, classHashHistoryPrefix(addr), oldClassHash, blockNumber); err != nil {
+		return err
+	}
+
 	if err = contract.Replace(classHash); err != nil {
 		return err
 	}
@@ -228,7 +377,23 @@ func (s *State) replaceContract(addr, classHash *felt.Felt) error {
 	return s.updateContractCommitment(contract)
 }
 
-func (s *State) putClass(classHash *felt.Felt, class Class) error {
+// DeclaredClass is a [Class] along with the number of the block it was declared at.
+type DeclaredClass struct {
+	At    uint64
+	Class Class
+}
+
+// RestoreClass stores the definition of a class that was declared at the given block but is missing
+// from the database. The class is left untouched if it is already stored.
+func (s *State) RestoreClass(classHash *felt.Felt, class Class, declaredAt uint64) error {
+	return s.putClass(classHash, class, declaredAt)
+}
+
+// putClass stores a class that was declared at the given block, unless it was declared before.
+// Classes are maintained as follows:
+//
+// [db.Class](ClassHash) -> (DeclaredClass)
+func (s *State) putClass(classHash *felt.Felt, class Class, declaredAt uint64) error {
 	classKey := db.Class.Key(classHash.Marshal())
 
 	err := s.txn.Get(classKey, func(val []byte) error {
@@ -236,7 +401,10 @@ func (s *State) putClass(classHash *felt.Felt, class Class) error {
 	})
 
 	if errors.Is(err, db.ErrKeyNotFound) {
-		classEncoded, encErr := encoder.Marshal(class)
+		classEncoded, encErr := encoder.Marshal(DeclaredClass{
+			At:    declaredAt,
+			Class: class,
+		})
 		if encErr != nil {
 			return encErr
 		}
@@ -248,13 +416,17 @@ func (s *State) putClass(classHash *felt.Felt, class Class) error {
 
 // updateContractStorage applies the diff set to the Trie of the
 // contract at the given address in the given Txn context.
-func (s *State) updateContractStorage(addr *felt.Felt, diff []StorageDiff) error {
+func (s *State) updateContractStorage(addr *felt.Felt, diff []StorageDiff, blockNumber uint64) error {
 	contract, err := NewContract(addr, s.txn)
 	if err != nil {
 		return err
 	}
 
-	if err := contract.UpdateStorage(diff); err != nil {
+	logOldStorageValue := func(location, oldValue *felt.Felt) error {
+		return logOldValue(s.txn, storageHistoryPrefix(addr, location), oldValue, blockNumber)
+	}
+
+	if err := contract.UpdateStorage(diff, logOldStorageValue); err != nil {
 		return err
 	}
 
@@ -263,12 +435,21 @@ func (s *State) updateContractStorage(addr *felt.Felt, diff []StorageDiff) error
 
 // updateContractNonce updates nonce of the contract at the
 // given address in the given Txn context.
-func (s *State) updateContractNonce(addr, nonce *felt.Felt) error {
+func (s *State) updateContractNonce(addr, nonce *felt.Felt, blockNumber uint64) error {
 	contract, err := NewContract(addr, s.txn)
 	if err != nil {
 		return err
 	}
 
+	oldNonce, err := contract.Nonce()
+	if err != nil {
+		return err
+	}
+
+	if err = logOldValue(s.txn, nonceHistoryPrefix(addr), oldNonce, blockNumber); err != nil {
+		return err
+	}
+
 	if err := contract.UpdateNonce(nonce); err != nil {
 		return err
 	}
@@ -327,3 +508,202 @@ func (s *State) updateDeclaredClasses(declaredClasses []DeclaredV1Class) error {
 
 	return classesCloser()
 }
+
+// Revert undoes the given StateUpdate, which must be the last one applied to the State, at the given
+// block number. The previous values of all changed contract fields are restored from the history that
+// [State.Update] recorded. If the update's new or old root does not match the state's roots before or
+// after the operation, an error is returned.
+func (s *State) Revert(blockNumber uint64, update *StateUpdate) error {
+	currentRoot, err := s.Root()
+	if err != nil {
+		return err
+	} else if !update.NewRoot.Equal(currentRoot) {
+		return fmt.Errorf("state's current root: %s does not match state update's new root: %s", currentRoot, update.NewRoot)
+	}
+
+	if err = s.removeDeclaredClasses(blockNumber, update.StateDiff); err != nil {
+		return err
+	}
+
+	if err = s.revertContracts(blockNumber, update.StateDiff); err != nil {
+		return err
+	}
+
+	revertedRoot, err := s.Root()
+	if err != nil {
+		return err
+	} else if !update.OldRoot.Equal(revertedRoot) {
+		return fmt.Errorf("state's reverted root: %s does not match state update's old root: %s", revertedRoot, update.OldRoot)
+	}
+	return nil
+}
+
+// removeDeclaredClasses removes the classes that were first stored at the given block and
+// removes the declared v1 classes from the classes trie.
+func (s *State) removeDeclaredClasses(blockNumber uint64, diff *StateDiff) error {
+	classHashes := make([]*felt.Felt, 0, len(diff.DeclaredV0Classes)+len(diff.DeclaredV1Classes)+len(diff.DeployedContracts))
+	classHashes = append(classHashes, diff.DeclaredV0Classes...)
+	for _, declaredClass := range diff.DeclaredV1Classes {
+		classHashes = append(classHashes, declaredClass.ClassHash)
+	}
+	for _, deployedContract := range diff.DeployedContracts {
+		classHashes = append(classHashes, deployedContract.ClassHash)
+	}
+
+	for _, classHash := range classHashes {
+		declaredClass, err := s.Class(classHash)
+		if errors.Is(err, db.ErrKeyNotFound) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if declaredClass.At == blockNumber {
+			if err = s.txn.Delete(db.Class.Key(classHash.Marshal())); err != nil {
+				return err
+			}
+		}
+	}
+
+	classesTrie, classesCloser, err := s.classesTrie()
+	if err != nil {
+		return err
+	}
+
+	for _, declaredClass := range diff.DeclaredV1Classes {
+		if _, err = classesTrie.Put(declaredClass.ClassHash, &felt.Zero); err != nil {
+			return err
+		}
+	}
+
+	return classesCloser()
+}
+
+// revertContracts applies the inverse of updateContracts, in the reverse order.
+func (s *State) revertContracts(blockNumber uint64, diff *StateDiff) error {
+	for addr, storageDiff := range diff.StorageDiffs {
+		if err := s.revertContractStorage(&addr, storageDiff, blockNumber); err != nil {
+			return err
+		}
+	}
+
+	for addr := range diff.Nonces {
+		if err := s.revertContractNonce(&addr, blockNumber); err != nil {
+			return err
+		}
+	}
+
+	for i := len(diff.ReplacedClasses) - 1; i >= 0; i-- {
+		if err := s.revertReplacedContract(diff.ReplacedClasses[i].Address, blockNumber); err != nil {
+			return err
+		}
+	}
+
+	for _, contract := range diff.DeployedContracts {
+		if err := s.purgeContract(contract.Address); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *State) revertContractStorage(addr *felt.Felt, diff []StorageDiff, blockNumber uint64) error {
+	contract, err := NewContract(addr, s.txn)
+	if err != nil {
+		return err
+	}
+
+	reverseDiff := make([]StorageDiff, 0, len(diff))
+	for _, pair := range diff {
+		prefix := storageHistoryPrefix(addr, pair.Key)
+		oldValue, err := loggedValue(s.txn, prefix, blockNumber)
+		if err != nil {
+			return err
+		}
+
+		if err = deleteLog(s.txn, prefix, blockNumber); err != nil {
+			return err
+		}
+		reverseDiff = append(reverseDiff, StorageDiff{Key: pair.Key, Value: oldValue})
+	}
+
+	if err = contract.UpdateStorage(reverseDiff, nil); err != nil {
+		return err
+	}
+
+	return s.updateContractCommitment(contract)
+}
+
+func (s *State) revertContractNonce(addr *felt.Felt, blockNumber uint64) error {
+	contract, err := NewContract(addr, s.txn)
+	if err != nil {
+		return err
+	}
+
+	prefix := nonceHistoryPrefix(addr)
+	oldNonce, err := loggedValue(s.txn, prefix, blockNumber)
+	if err != nil {
+		return err
+	}
+
+	if err = deleteLog(s.txn, prefix, blockNumber); err != nil {
+		return err
+	}
+
+	if err = contract.UpdateNonce(oldNonce); err != nil {
+		return err
+	}
+
+	return s.updateContractCommitment(contract)
+}
+
+func (s *State) revertReplacedContract(addr *felt.Felt, blockNumber uint64) error {
+	contract, err := NewContract(addr, s.txn)
+	if err != nil {
+		return err
+	}
+
+	prefix := classHashHistoryPrefix(addr)
+	oldClassHash, err := loggedValue(s.txn, prefix, blockNumber)
+	if err != nil {
+		return err
+	}
+
+	if err = deleteLog(s.txn, prefix, blockNumber); err != nil {
+		return err
+	}
+
+	if err = contract.Replace(oldClassHash); err != nil {
+		return err
+	}
+
+	return s.updateContractCommitment(contract)
+}
+
+// purgeContract removes a contract that was deployed in the block that is being reverted.
+// Its storage must already be reverted, which leaves the storage trie empty.
+func (s *State) purgeContract(addr *felt.Felt) error {
+	addrBytes := addr.Marshal()
+	for _, key := range [][]byte{
+		db.ContractClassHash.Key(addrBytes),
+		db.ContractNonce.Key(addrBytes),
+		db.ContractRootKey.Key(addrBytes),
+		db.ContractDeploymentHeight.Key(addrBytes),
+	} {
+		if err := s.txn.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	state, storageCloser, err := s.storage()
+	if err != nil {
+		return err
+	}
+
+	if _, err = state.Put(addr, &felt.Zero); err != nil {
+		return err
+	}
+
+	return storageCloser()
+}
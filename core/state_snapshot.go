@@ -0,0 +1,86 @@
+package core
+
+import (
+	"errors"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
+)
+
+var _ StateReader = (*stateSnapshot)(nil)
+
+// stateSnapshot is a read-only view of the state as of a past block. Values that have not
+// changed since that block are read from the head state.
+type stateSnapshot struct {
+	blockNumber uint64
+	state       *State
+}
+
+// NewStateSnapshot returns a StateReader that reads the given state as it was after the block
+// with the given number was applied.
+func NewStateSnapshot(state *State, blockNumber uint64) StateReader {
+	return &stateSnapshot{
+		blockNumber: blockNumber,
+		state:       state,
+	}
+}
+
+func (s *stateSnapshot) ContractClassHash(addr *felt.Felt) (*felt.Felt, error) {
+	if err := s.checkDeployed(addr); err != nil {
+		return nil, err
+	}
+
+	value, err := s.state.ContractClassHashAt(addr, s.blockNumber)
+	if errors.Is(err, ErrCheckHeadState) {
+		return s.state.ContractClassHash(addr)
+	}
+	return value, err
+}
+
+func (s *stateSnapshot) ContractNonce(addr *felt.Felt) (*felt.Felt, error) {
+	if err := s.checkDeployed(addr); err != nil {
+		return nil, err
+	}
+
+	value, err := s.state.ContractNonceAt(addr, s.blockNumber)
+	if errors.Is(err, ErrCheckHeadState) {
+		return s.state.ContractNonce(addr)
+	}
+	return value, err
+}
+
+func (s *stateSnapshot) ContractStorage(addr, key *felt.Felt) (*felt.Felt, error) {
+	if err := s.checkDeployed(addr); err != nil {
+		return nil, err
+	}
+
+	value, err := s.state.ContractStorageAt(addr, key, s.blockNumber)
+	if errors.Is(err, ErrCheckHeadState) {
+		return s.state.ContractStorage(addr, key)
+	}
+	return value, err
+}
+
+func (s *stateSnapshot) Class(classHash *felt.Felt) (*DeclaredClass, error) {
+	declaredClass, err := s.state.Class(classHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if declaredClass.At > s.blockNumber {
+		return nil, db.ErrKeyNotFound
+	}
+	return declaredClass, nil
+}
+
+func (s *stateSnapshot) checkDeployed(addr *felt.Felt) error {
+	deployed, err := s.state.ContractIsAlreadyDeployedAt(addr, s.blockNumber)
+	if err != nil {
+		return err
+	}
+
+	if !deployed {
+		return ErrContractNotDeployed
+	}
+	return nil
+}
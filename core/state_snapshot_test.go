@@ -0,0 +1,131 @@
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/NethermindEth/juno/clients/feeder"
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db/pebble"
+	adaptfeeder "github.com/NethermindEth/juno/starknetdata/feeder"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateSnapshot(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+
+	gw := adaptfeeder.New(client)
+
+	testDB := pebble.NewMemTest()
+	txn := testDB.NewTransaction(true)
+	t.Cleanup(func() {
+		require.NoError(t, txn.Discard())
+	})
+
+	state := core.NewState(txn)
+
+	const numBlocks = 3
+	stateUpdates := make([]*core.StateUpdate, numBlocks)
+	for i := uint64(0); i < numBlocks; i++ {
+		su, err := gw.StateUpdate(context.Background(), i)
+		require.NoError(t, err)
+		require.NoError(t, state.Update(i, su, nil))
+		stateUpdates[i] = su
+	}
+
+	for i, su := range stateUpdates {
+		blockNumber := uint64(i)
+		snapshot := core.NewStateSnapshot(state, blockNumber)
+
+		t.Run(fmt.Sprintf("mainnet block %d", blockNumber), func(t *testing.T) {
+			for _, dc := range su.StateDiff.DeployedContracts {
+				classHash, err := snapshot.ContractClassHash(dc.Address)
+				require.NoError(t, err)
+				assert.Equal(t, dc.ClassHash, classHash)
+			}
+
+			for addr, diffs := range su.StateDiff.StorageDiffs {
+				addr := addr
+				for _, diff := range diffs {
+					value, err := snapshot.ContractStorage(&addr, diff.Key)
+					require.NoError(t, err)
+					assert.Equal(t, diff.Value, value)
+				}
+			}
+
+			for addr, expectedNonce := range su.StateDiff.Nonces {
+				addr := addr
+				nonce, err := snapshot.ContractNonce(&addr)
+				require.NoError(t, err)
+				assert.Equal(t, expectedNonce, nonce)
+			}
+
+			if blockNumber == 0 {
+				return
+			}
+
+			// contracts deployed in this block did not exist in the previous one
+			previous := core.NewStateSnapshot(state, blockNumber-1)
+			for _, dc := range su.StateDiff.DeployedContracts {
+				_, err := previous.ContractClassHash(dc.Address)
+				require.ErrorIs(t, err, core.ErrContractNotDeployed)
+				_, err = previous.ContractNonce(dc.Address)
+				require.ErrorIs(t, err, core.ErrContractNotDeployed)
+				_, err = previous.ContractStorage(dc.Address, &felt.Zero)
+				require.ErrorIs(t, err, core.ErrContractNotDeployed)
+			}
+		})
+	}
+}
+
+func TestStateSnapshotReplacedClass(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+
+	gw := adaptfeeder.New(client)
+
+	testDB := pebble.NewMemTest()
+	txn := testDB.NewTransaction(true)
+	t.Cleanup(func() {
+		require.NoError(t, txn.Discard())
+	})
+
+	state := core.NewState(txn)
+
+	su0, err := gw.StateUpdate(context.Background(), 0)
+	require.NoError(t, err)
+	require.NoError(t, state.Update(0, su0, nil))
+
+	su1, err := gw.StateUpdate(context.Background(), 1)
+	require.NoError(t, err)
+	require.NoError(t, state.Update(1, su1, nil))
+
+	addr := su1.StateDiff.DeployedContracts[0].Address
+	replaceUpdate := &core.StateUpdate{
+		OldRoot:   su1.NewRoot,
+		BlockHash: utils.HexToFelt(t, "0xDEADBEEF"),
+		NewRoot:   utils.HexToFelt(t, "0x484ff378143158f9af55a1210b380853ae155dfdd8cd4c228f9ece918bb982b"),
+		StateDiff: &core.StateDiff{
+			ReplacedClasses: []core.ReplacedClass{
+				{
+					Address:   addr,
+					ClassHash: utils.HexToFelt(t, "0x1337"),
+				},
+			},
+		},
+	}
+	require.NoError(t, state.Update(2, replaceUpdate, nil))
+
+	classHash, err := core.NewStateSnapshot(state, 1).ContractClassHash(addr)
+	require.NoError(t, err)
+	assert.Equal(t, su1.StateDiff.DeployedContracts[0].ClassHash, classHash)
+
+	classHash, err = core.NewStateSnapshot(state, 2).ContractClassHash(addr)
+	require.NoError(t, err)
+	assert.Equal(t, utils.HexToFelt(t, "0x1337"), classHash)
+}
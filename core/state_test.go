@@ -7,7 +7,10 @@ import (
 
 	"github.com/NethermindEth/juno/clients/feeder"
 	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/crypto"
 	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/core/trie"
+	"github.com/NethermindEth/juno/db"
 	"github.com/NethermindEth/juno/db/pebble"
 	adaptfeeder "github.com/NethermindEth/juno/starknetdata/feeder"
 	"github.com/NethermindEth/juno/utils"
@@ -39,7 +42,7 @@ func TestUpdate(t *testing.T) {
 	require.NoError(t, err)
 
 	t.Run("empty state updated with mainnet block 0 state update", func(t *testing.T) {
-		require.NoError(t, state.Update(su0, nil))
+		require.NoError(t, state.Update(0, su0, nil))
 		gotNewRoot, err := state.Root()
 		require.NoError(t, err)
 		assert.Equal(t, su0.NewRoot, gotNewRoot)
@@ -51,7 +54,7 @@ func TestUpdate(t *testing.T) {
 			OldRoot: oldRoot,
 		}
 		expectedErr := fmt.Sprintf("state's current root: %s does not match state update's old root: %s", su0.NewRoot, oldRoot)
-		require.EqualError(t, state.Update(su, nil), expectedErr)
+		require.EqualError(t, state.Update(1, su, nil), expectedErr)
 	})
 
 	t.Run("error when state new root doesn't match state update's new root", func(t *testing.T) {
@@ -62,16 +65,16 @@ func TestUpdate(t *testing.T) {
 			StateDiff: new(core.StateDiff),
 		}
 		expectedErr := fmt.Sprintf("state's new root: %s does not match state update's new root: %s", su0.NewRoot, newRoot)
-		require.EqualError(t, state.Update(su, nil), expectedErr)
+		require.EqualError(t, state.Update(1, su, nil), expectedErr)
 	})
 
 	t.Run("non-empty state updated multiple times", func(t *testing.T) {
-		require.NoError(t, state.Update(su1, nil))
+		require.NoError(t, state.Update(1, su1, nil))
 		gotNewRoot, err := state.Root()
 		require.NoError(t, err)
 		assert.Equal(t, su1.NewRoot, gotNewRoot)
 
-		require.NoError(t, state.Update(su2, nil))
+		require.NoError(t, state.Update(2, su2, nil))
 		gotNewRoot, err = state.Root()
 		require.NoError(t, err)
 		assert.Equal(t, su2.NewRoot, gotNewRoot)
@@ -91,7 +94,7 @@ func TestUpdate(t *testing.T) {
 			},
 		}
 
-		require.NoError(t, state.Update(su, nil))
+		require.NoError(t, state.Update(3, su, nil))
 		assert.NotEqual(t, su.NewRoot, su.OldRoot)
 	})
 }
@@ -116,8 +119,8 @@ func TestContractClassHash(t *testing.T) {
 	su1, err := gw.StateUpdate(context.Background(), 1)
 	require.NoError(t, err)
 
-	require.NoError(t, state.Update(su0, nil))
-	require.NoError(t, state.Update(su1, nil))
+	require.NoError(t, state.Update(0, su0, nil))
+	require.NoError(t, state.Update(1, su1, nil))
 
 	allDeployedContracts := make(map[felt.Felt]*felt.Felt)
 
@@ -151,7 +154,7 @@ func TestContractClassHash(t *testing.T) {
 			},
 		}
 
-		require.NoError(t, state.Update(replaceUpdate, nil))
+		require.NoError(t, state.Update(2, replaceUpdate, nil))
 
 		gotClassHash, err := state.ContractClassHash(su1.StateDiff.DeployedContracts[0].Address)
 		require.NoError(t, err)
@@ -185,7 +188,7 @@ func TestNonce(t *testing.T) {
 		},
 	}
 
-	require.NoError(t, state.Update(su, nil))
+	require.NoError(t, state.Update(0, su, nil))
 
 	t.Run("newly deployed contract has zero nonce", func(t *testing.T) {
 		nonce, err := state.ContractNonce(addr)
@@ -203,10 +206,308 @@ func TestNonce(t *testing.T) {
 			},
 		}
 
-		require.NoError(t, state.Update(su, nil))
+		require.NoError(t, state.Update(1, su, nil))
 
 		gotNonce, err := state.ContractNonce(addr)
 		require.NoError(t, err)
 		assert.Equal(t, expectedNonce, gotNonce)
 	})
 }
+
+func TestContractStorage(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+
+	gw := adaptfeeder.New(client)
+
+	testDB := pebble.NewMemTest()
+	txn := testDB.NewTransaction(true)
+	t.Cleanup(func() {
+		require.NoError(t, txn.Discard())
+	})
+
+	state := core.NewState(txn)
+
+	su0, err := gw.StateUpdate(context.Background(), 0)
+	require.NoError(t, err)
+	require.NoError(t, state.Update(0, su0, nil))
+
+	t.Run("contract is not deployed", func(t *testing.T) {
+		_, err := state.ContractStorage(utils.HexToFelt(t, "0xDEADBEEF"), &felt.Zero)
+		require.ErrorIs(t, err, core.ErrContractNotDeployed)
+	})
+
+	t.Run("values written by the state update", func(t *testing.T) {
+		for addr, diffs := range su0.StateDiff.StorageDiffs {
+			addr := addr
+			for _, diff := range diffs {
+				value, err := state.ContractStorage(&addr, diff.Key)
+				require.NoError(t, err)
+				assert.Equal(t, diff.Value, value)
+			}
+		}
+	})
+
+	t.Run("unset key holds zero", func(t *testing.T) {
+		addr := su0.StateDiff.DeployedContracts[0].Address
+		value, err := state.ContractStorage(addr, utils.HexToFelt(t, "0xDEADBEEF"))
+		require.NoError(t, err)
+		assert.Equal(t, &felt.Zero, value)
+	})
+}
+
+func TestProve(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+
+	gw := adaptfeeder.New(client)
+
+	testDB := pebble.NewMemTest()
+	txn := testDB.NewTransaction(true)
+	t.Cleanup(func() {
+		require.NoError(t, txn.Discard())
+	})
+
+	state := core.NewState(txn)
+
+	su0, err := gw.StateUpdate(context.Background(), 0)
+	require.NoError(t, err)
+	require.NoError(t, state.Update(0, su0, nil))
+
+	t.Run("contract is not deployed", func(t *testing.T) {
+		addr := utils.HexToFelt(t, "0xDEADBEEF")
+		proof, err := state.Prove(addr, []*felt.Felt{&felt.Zero})
+		require.NoError(t, err)
+
+		assert.Equal(t, su0.NewRoot, proof.ContractsRoot)
+		assert.Equal(t, &felt.Zero, proof.ClassesRoot)
+		assert.Nil(t, proof.Contract)
+		assert.True(t, trie.VerifyProof(proof.ContractsRoot, addr, &felt.Zero, proof.ContractProof, crypto.Pedersen))
+	})
+
+	t.Run("storage values of a contract", func(t *testing.T) {
+		for addr, diffs := range su0.StateDiff.StorageDiffs {
+			addr := addr
+			keys := make([]*felt.Felt, 0, len(diffs)+1)
+			for _, diff := range diffs {
+				keys = append(keys, diff.Key)
+			}
+			absentKey := utils.HexToFelt(t, "0xDEADBEEF")
+			keys = append(keys, absentKey)
+
+			proof, err := state.Prove(&addr, keys)
+			require.NoError(t, err)
+			require.NotNil(t, proof.Contract)
+
+			classHash, err := state.ContractClassHash(&addr)
+			require.NoError(t, err)
+			assert.Equal(t, classHash, proof.Contract.ClassHash)
+
+			commitment := crypto.Pedersen(crypto.Pedersen(crypto.Pedersen(proof.Contract.ClassHash,
+				proof.Contract.StorageRoot), proof.Contract.Nonce), &felt.Zero)
+			assert.True(t, trie.VerifyProof(proof.ContractsRoot, &addr, commitment, proof.ContractProof, crypto.Pedersen))
+
+			require.Len(t, proof.Contract.StorageProofs, len(keys))
+			for i, diff := range diffs {
+				assert.True(t, trie.VerifyProof(proof.Contract.StorageRoot, diff.Key, diff.Value,
+					proof.Contract.StorageProofs[i], crypto.Pedersen))
+			}
+			assert.True(t, trie.VerifyProof(proof.Contract.StorageRoot, absentKey, &felt.Zero,
+				proof.Contract.StorageProofs[len(diffs)], crypto.Pedersen))
+		}
+	})
+}
+
+func TestIterateContractStorage(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+
+	gw := adaptfeeder.New(client)
+
+	testDB := pebble.NewMemTest()
+	txn := testDB.NewTransaction(true)
+	t.Cleanup(func() {
+		require.NoError(t, txn.Discard())
+	})
+
+	state := core.NewState(txn)
+
+	su0, err := gw.StateUpdate(context.Background(), 0)
+	require.NoError(t, err)
+	require.NoError(t, state.Update(0, su0, nil))
+
+	t.Run("contract is not deployed", func(t *testing.T) {
+		err := state.IterateContractStorage(utils.HexToFelt(t, "0xDEADBEEF"), nil, func(key, value *felt.Felt) (bool, error) {
+			return true, nil
+		})
+		assert.ErrorIs(t, err, core.ErrContractNotDeployed)
+	})
+
+	t.Run("whole storage of every contract", func(t *testing.T) {
+		for addr, diffs := range su0.StateDiff.StorageDiffs {
+			addr := addr
+			expected := make(map[felt.Felt]*felt.Felt)
+			for _, diff := range diffs {
+				if !diff.Value.IsZero() {
+					expected[*diff.Key] = diff.Value
+				}
+			}
+
+			var prev *felt.Felt
+			stored := make(map[felt.Felt]*felt.Felt)
+			require.NoError(t, state.IterateContractStorage(&addr, nil, func(key, value *felt.Felt) (bool, error) {
+				if prev != nil {
+					assert.Equal(t, 1, key.Cmp(prev))
+				}
+				prev = key
+				stored[*key] = value
+				return true, nil
+			}))
+			assert.Equal(t, expected, stored)
+		}
+	})
+}
+
+func TestClass(t *testing.T) {
+	registerClassTypesToEncoder(t)
+
+	testDB := pebble.NewMemTest()
+	txn := testDB.NewTransaction(true)
+	t.Cleanup(func() {
+		require.NoError(t, txn.Discard())
+	})
+
+	goerliClient, closeFn := feeder.NewTestClient(utils.GOERLI)
+	t.Cleanup(closeFn)
+	integrationClient, closeFn := feeder.NewTestClient(utils.INTEGRATION)
+	t.Cleanup(closeFn)
+
+	cairo0Hash := utils.HexToFelt(t, "0x79e2d211e70594e687f9f788f71302e6eecb61d98efce48fbe8514948c8118")
+	cairo0Class, err := adaptfeeder.New(goerliClient).Class(context.Background(), cairo0Hash)
+	require.NoError(t, err)
+	cairo1Hash := utils.HexToFelt(t, "0x4e70b19333ae94bd958625f7b61ce9eec631653597e68645e13780061b2136c")
+	cairo1Class, err := adaptfeeder.New(integrationClient).Class(context.Background(), cairo1Hash)
+	require.NoError(t, err)
+
+	state := core.NewState(txn)
+	emptyUpdate := &core.StateUpdate{
+		OldRoot:   &felt.Zero,
+		NewRoot:   &felt.Zero,
+		StateDiff: new(core.StateDiff),
+	}
+	require.NoError(t, state.Update(0, emptyUpdate, map[felt.Felt]core.Class{
+		*cairo0Hash: cairo0Class,
+	}))
+	require.NoError(t, state.Update(1, emptyUpdate, map[felt.Felt]core.Class{
+		*cairo1Hash: cairo1Class,
+	}))
+
+	t.Run("cairo 0 class", func(t *testing.T) {
+		got, err := state.Class(cairo0Hash)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(0), got.At)
+		assert.Equal(t, cairo0Class, got.Class)
+	})
+
+	t.Run("cairo 1 class", func(t *testing.T) {
+		got, err := state.Class(cairo1Hash)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), got.At)
+		assert.Equal(t, cairo1Class, got.Class)
+	})
+
+	t.Run("unknown class", func(t *testing.T) {
+		_, err := state.Class(utils.HexToFelt(t, "0xDEADBEEF"))
+		require.ErrorIs(t, err, db.ErrKeyNotFound)
+	})
+
+	t.Run("snapshot does not see classes declared later", func(t *testing.T) {
+		_, err := core.NewStateSnapshot(state, 0).Class(cairo1Hash)
+		require.ErrorIs(t, err, db.ErrKeyNotFound)
+
+		got, err := core.NewStateSnapshot(state, 1).Class(cairo1Hash)
+		require.NoError(t, err)
+		assert.Equal(t, cairo1Class, got.Class)
+	})
+}
+
+func TestRevert(t *testing.T) {
+	registerClassTypesToEncoder(t)
+
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+
+	gw := adaptfeeder.New(client)
+
+	testDB := pebble.NewMemTest()
+	txn := testDB.NewTransaction(true)
+	t.Cleanup(func() {
+		require.NoError(t, txn.Discard())
+	})
+
+	state := core.NewState(txn)
+
+	su0, err := gw.StateUpdate(context.Background(), 0)
+	require.NoError(t, err)
+	require.NoError(t, state.Update(0, su0, nil))
+
+	su1, err := gw.StateUpdate(context.Background(), 1)
+	require.NoError(t, err)
+
+	t.Run("error when state current root doesn't match state update's new root", func(t *testing.T) {
+		require.Error(t, state.Revert(1, su1))
+	})
+
+	before := dumpTxn(t, txn)
+
+	declaredClass := &core.Cairo1Class{
+		Abi:         "abi",
+		AbiHash:     utils.HexToFelt(t, "0x1"),
+		Program:     []*felt.Felt{utils.HexToFelt(t, "0x2")},
+		ProgramHash: utils.HexToFelt(t, "0x3"),
+	}
+	declaredClassHash := su1.StateDiff.DeployedContracts[0].ClassHash
+	require.NoError(t, state.Update(1, su1, map[felt.Felt]core.Class{*declaredClassHash: declaredClass}))
+
+	_, err = state.Class(declaredClassHash)
+	require.NoError(t, err)
+
+	require.NoError(t, state.Revert(1, su1))
+
+	root, err := state.Root()
+	require.NoError(t, err)
+	assert.Equal(t, su0.NewRoot, root)
+
+	_, err = state.Class(declaredClassHash)
+	require.ErrorIs(t, err, db.ErrKeyNotFound)
+
+	for _, dc := range su1.StateDiff.DeployedContracts {
+		_, err = state.ContractClassHash(dc.Address)
+		require.ErrorIs(t, err, core.ErrContractNotDeployed)
+	}
+
+	assert.Equal(t, before, dumpTxn(t, txn), "reverting must leave no trace of the update")
+
+	t.Run("update can be applied again", func(t *testing.T) {
+		require.NoError(t, state.Update(1, su1, nil))
+	})
+}
+
+// dumpTxn returns all key-value pairs visible to the given transaction
+func dumpTxn(t *testing.T, txn db.Transaction) map[string]string {
+	t.Helper()
+
+	it, err := txn.NewIterator()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, it.Close())
+	})
+
+	kvs := make(map[string]string)
+	for it.Next() {
+		val, err := it.Value()
+		require.NoError(t, err)
+		kvs[string(it.Key())] = string(val)
+	}
+	return kvs
+}
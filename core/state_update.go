@@ -1,6 +1,11 @@
 package core
 
-import "github.com/NethermindEth/juno/core/felt"
+import (
+	"sort"
+
+	"github.com/NethermindEth/juno/core/crypto"
+	"github.com/NethermindEth/juno/core/felt"
+)
 
 type StateUpdate struct {
 	BlockHash *felt.Felt
@@ -37,3 +42,87 @@ type ReplacedClass struct {
 	Address   *felt.Felt
 	ClassHash *felt.Felt
 }
+
+// ClassHashes returns the hashes of all the classes that are referenced by the state diff.
+// A hash is returned once for every time it is referenced.
+func (d *StateDiff) ClassHashes() []*felt.Felt {
+	classHashes := make([]*felt.Felt, 0, len(d.DeployedContracts)+len(d.DeclaredV0Classes)+
+		len(d.DeclaredV1Classes)+len(d.ReplacedClasses))
+	for _, deployedContract := range d.DeployedContracts {
+		classHashes = append(classHashes, deployedContract.ClassHash)
+	}
+	classHashes = append(classHashes, d.DeclaredV0Classes...)
+	for _, declaredClass := range d.DeclaredV1Classes {
+		classHashes = append(classHashes, declaredClass.ClassHash)
+	}
+	for _, replacedClass := range d.ReplacedClasses {
+		classHashes = append(classHashes, replacedClass.ClassHash)
+	}
+	return classHashes
+}
+
+// Commitment returns the Poseidon commitment to the state diff that the sequencer signs along with the
+// block hash.
+func (d *StateDiff) Commitment() *felt.Felt {
+	contracts := make([]DeployedContract, 0, len(d.DeployedContracts)+len(d.ReplacedClasses))
+	contracts = append(contracts, d.DeployedContracts...)
+	for _, replaced := range d.ReplacedClasses {
+		contracts = append(contracts, DeployedContract(replaced))
+	}
+	sort.Slice(contracts, func(i, j int) bool { return contracts[i].Address.Cmp(contracts[j].Address) < 0 })
+	deployed := []*felt.Felt{new(felt.Felt).SetUint64(uint64(len(contracts)))}
+	for _, contract := range contracts {
+		deployed = append(deployed, contract.Address, contract.ClassHash)
+	}
+
+	declaredV1 := append([]DeclaredV1Class{}, d.DeclaredV1Classes...)
+	sort.Slice(declaredV1, func(i, j int) bool { return declaredV1[i].ClassHash.Cmp(declaredV1[j].ClassHash) < 0 })
+	declared := []*felt.Felt{new(felt.Felt).SetUint64(uint64(len(declaredV1)))}
+	for _, class := range declaredV1 {
+		declared = append(declared, class.ClassHash, class.CompiledClassHash)
+	}
+
+	declaredV0 := append([]*felt.Felt{}, d.DeclaredV0Classes...)
+	sort.Slice(declaredV0, func(i, j int) bool { return declaredV0[i].Cmp(declaredV0[j]) < 0 })
+	oldDeclared := append([]*felt.Felt{new(felt.Felt).SetUint64(uint64(len(declaredV0)))}, declaredV0...)
+
+	return crypto.PoseidonArray(
+		&felt.Zero, // version
+		crypto.PoseidonArray(deployed...),
+		crypto.PoseidonArray(declared...),
+		crypto.PoseidonArray(oldDeclared...),
+		new(felt.Felt).SetUint64(1), // number of data availability domains
+		&felt.Zero,                  // the L1 data availability domain
+		d.storageDomainHash(),
+	)
+}
+
+// storageDomainHash hashes the storage diffs and the nonces, sorted by address and key.
+func (d *StateDiff) storageDomainHash() *felt.Felt {
+	addresses := make([]felt.Felt, 0, len(d.StorageDiffs))
+	for address := range d.StorageDiffs {
+		addresses = append(addresses, address)
+	}
+	sort.Slice(addresses, func(i, j int) bool { return addresses[i].Cmp(&addresses[j]) < 0 })
+
+	elems := []*felt.Felt{new(felt.Felt).SetUint64(uint64(len(addresses)))}
+	for i := range addresses {
+		diffs := append([]StorageDiff{}, d.StorageDiffs[addresses[i]]...)
+		sort.Slice(diffs, func(a, b int) bool { return diffs[a].Key.Cmp(diffs[b].Key) < 0 })
+		elems = append(elems, &addresses[i], new(felt.Felt).SetUint64(uint64(len(diffs))))
+		for _, diff := range diffs {
+			elems = append(elems, diff.Key, diff.Value)
+		}
+	}
+
+	nonceAddresses := make([]felt.Felt, 0, len(d.Nonces))
+	for address := range d.Nonces {
+		nonceAddresses = append(nonceAddresses, address)
+	}
+	sort.Slice(nonceAddresses, func(i, j int) bool { return nonceAddresses[i].Cmp(&nonceAddresses[j]) < 0 })
+	elems = append(elems, new(felt.Felt).SetUint64(uint64(len(nonceAddresses))))
+	for i := range nonceAddresses {
+		elems = append(elems, &nonceAddresses[i], d.Nonces[nonceAddresses[i]])
+	}
+	return crypto.PoseidonArray(elems...)
+}
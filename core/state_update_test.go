@@ -0,0 +1,51 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateDiffCommitment(t *testing.T) {
+	one, two, three := new(felt.Felt).SetUint64(1), new(felt.Felt).SetUint64(2), new(felt.Felt).SetUint64(3)
+	newDiff := func() *core.StateDiff {
+		return &core.StateDiff{
+			StorageDiffs: map[felt.Felt][]core.StorageDiff{
+				*one: {{Key: one, Value: two}, {Key: two, Value: three}},
+				*two: {{Key: three, Value: one}},
+			},
+			Nonces:            map[felt.Felt]*felt.Felt{*one: two, *three: one},
+			DeployedContracts: []core.DeployedContract{{Address: two, ClassHash: one}, {Address: one, ClassHash: two}},
+			DeclaredV0Classes: []*felt.Felt{two, one},
+			DeclaredV1Classes: []core.DeclaredV1Class{{ClassHash: three, CompiledClassHash: one}},
+			ReplacedClasses:   []core.ReplacedClass{{Address: three, ClassHash: three}},
+		}
+	}
+	commitment := newDiff().Commitment()
+
+	t.Run("the order of the diffs does not matter", func(t *testing.T) {
+		diff := newDiff()
+		diff.StorageDiffs[*one] = []core.StorageDiff{{Key: two, Value: three}, {Key: one, Value: two}}
+		diff.DeployedContracts = []core.DeployedContract{{Address: one, ClassHash: two}, {Address: two, ClassHash: one}}
+		diff.DeclaredV0Classes = []*felt.Felt{one, two}
+		assert.Equal(t, commitment, diff.Commitment())
+	})
+
+	t.Run("every part of the diff is committed to", func(t *testing.T) {
+		changes := map[string]func(*core.StateDiff){
+			"storage value":     func(d *core.StateDiff) { d.StorageDiffs[*two][0].Value = two },
+			"nonce":             func(d *core.StateDiff) { d.Nonces[*three] = two },
+			"deployed contract": func(d *core.StateDiff) { d.DeployedContracts = d.DeployedContracts[1:] },
+			"legacy class":      func(d *core.StateDiff) { d.DeclaredV0Classes = nil },
+			"compiled class":    func(d *core.StateDiff) { d.DeclaredV1Classes[0].CompiledClassHash = two },
+			"replaced class":    func(d *core.StateDiff) { d.ReplacedClasses[0].ClassHash = one },
+		}
+		for name, change := range changes {
+			diff := newDiff()
+			change(diff)
+			assert.NotEqual(t, commitment, diff.Commitment(), name)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package trie
+
+import (
+	"math/big"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/bits-and-blooms/bitset"
+)
+
+// LeafFunc is called with the key and value of a leaf of a [Trie]. Returning false stops the iteration.
+type LeafFunc func(key, value *felt.Felt) (bool, error)
+
+// Iterate calls fn with the key and value of every leaf of the [Trie] whose key is within [start, end], in
+// ascending order of keys, until fn returns false or an error. A nil start or end leaves that side of the
+// range open. Subtrees that are out of range are not read from storage.
+func (t *Trie) Iterate(start, end *felt.Felt, fn LeafFunc) error {
+	if t.rootKey == nil {
+		return nil
+	}
+
+	var startInt, endInt *big.Int
+	if start != nil {
+		startInt = start.BigInt(new(big.Int))
+	}
+	if end != nil {
+		endInt = end.BigInt(new(big.Int))
+	}
+
+	_, err := t.iterate(t.rootKey, startInt, endInt, fn)
+	return err
+}
+
+// iterate visits the leaves under the node with the given key, left subtree first. It returns false once
+// fn asks to stop.
+func (t *Trie) iterate(key *bitset.BitSet, start, end *big.Int, fn LeafFunc) (bool, error) {
+	low, high := t.keyRange(key)
+	if (start != nil && high.Cmp(start) < 0) || (end != nil && low.Cmp(end) > 0) {
+		return true, nil
+	}
+
+	node, err := t.storage.Get(key)
+	if err != nil {
+		return false, err
+	}
+
+	if node.Left == nil {
+		return fn(pathToFelt(key), node.Value)
+	}
+
+	if next, err := t.iterate(node.Left, start, end, fn); err != nil || !next {
+		return next, err
+	}
+	return t.iterate(node.Right, start, end, fn)
+}
+
+// keyRange returns the lowest and the highest leaf key that can be under the node with the given key.
+func (t *Trie) keyRange(key *bitset.BitSet) (low, high *big.Int) {
+	free := t.height - key.Len()
+
+	low = pathToFelt(key).BigInt(new(big.Int))
+	low.Lsh(low, free)
+
+	high = new(big.Int).Lsh(big.NewInt(1), free)
+	high.Sub(high, big.NewInt(1))
+	return low, high.Add(high, low)
+}
@@ -0,0 +1,93 @@
+package trie_test
+
+import (
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/core/trie"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIterate(t *testing.T) {
+	collect := func(t *testing.T, tempTrie *trie.Trie, start, end *felt.Felt, limit int) []felt.Felt {
+		t.Helper()
+
+		var keys []felt.Felt
+		require.NoError(t, tempTrie.Iterate(start, end, func(key, value *felt.Felt) (bool, error) {
+			// every key is stored with itself as its value
+			assert.Equal(t, key, value)
+			keys = append(keys, *key)
+			return len(keys) < limit, nil
+		}))
+		return keys
+	}
+
+	t.Run("empty trie", func(t *testing.T) {
+		require.NoError(t, trie.RunOnTempTrie(251, func(tempTrie *trie.Trie) error {
+			assert.Empty(t, collect(t, tempTrie, nil, nil, 10))
+			return nil
+		}))
+	})
+
+	require.NoError(t, trie.RunOnTempTrie(251, func(tempTrie *trie.Trie) error {
+		var keys []felt.Felt
+		for i := uint64(1); i < 64; i++ {
+			keys = append(keys, *new(felt.Felt).SetUint64(i * i * 7919))
+		}
+		// a key with the most significant bit set
+		keys = append(keys, *utils.HexToFelt(t, "0x400000000000000000000000000000000000000000000000000000000000000"))
+
+		for _, key := range keys {
+			key := key
+			_, err := tempTrie.Put(&key, &key)
+			require.NoError(t, err)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i].Cmp(&keys[j]) < 0
+		})
+
+		t.Run("all leaves in order", func(t *testing.T) {
+			assert.Equal(t, keys, collect(t, tempTrie, nil, nil, len(keys)+1))
+		})
+
+		t.Run("stops when asked", func(t *testing.T) {
+			assert.Equal(t, keys[:5], collect(t, tempTrie, nil, nil, 5))
+		})
+
+		t.Run("bounds are inclusive", func(t *testing.T) {
+			assert.Equal(t, keys[3:11], collect(t, tempTrie, &keys[3], &keys[10], len(keys)))
+		})
+
+		t.Run("bounds between keys", func(t *testing.T) {
+			start := new(felt.Felt).SetUint64(1)
+			start.Add(start, &keys[3])
+			end := new(felt.Felt).SetUint64(1)
+			end.Sub(&keys[10], end)
+			assert.Equal(t, keys[4:10], collect(t, tempTrie, start, end, len(keys)))
+		})
+
+		t.Run("open ends", func(t *testing.T) {
+			assert.Equal(t, keys[:8], collect(t, tempTrie, nil, &keys[7], len(keys)))
+			assert.Equal(t, keys[60:], collect(t, tempTrie, &keys[60], nil, len(keys)))
+		})
+
+		t.Run("empty range", func(t *testing.T) {
+			assert.Empty(t, collect(t, tempTrie, &keys[10], &keys[3], len(keys)))
+			start := new(felt.Felt).SetUint64(1)
+			start.Add(start, &keys[len(keys)-1])
+			assert.Empty(t, collect(t, tempTrie, start, nil, len(keys)))
+		})
+
+		t.Run("errors are returned", func(t *testing.T) {
+			errStop := errors.New("stop")
+			assert.ErrorIs(t, tempTrie.Iterate(nil, nil, func(key, value *felt.Felt) (bool, error) {
+				return true, errStop
+			}), errStop)
+		})
+		return nil
+	}))
+}
@@ -20,6 +20,18 @@ func (n *Node) Hash(path *bitset.BitSet, hashFunc hashFunc) *felt.Felt {
 		return n.Value
 	}
 
+	// https://docs.starknet.io/documentation/develop/State/starknet-state/
+	return edgeHash(n.Value, pathToFelt(path), uint8(path.Len()), hashFunc)
+}
+
+// edgeHash calculates the hash of an edge of the given length and path to a child with the given hash
+func edgeHash(child, path *felt.Felt, length uint8, hashFunc hashFunc) *felt.Felt {
+	hash := hashFunc(child, path)
+	return hash.Add(hash, new(felt.Felt).SetUint64(uint64(length)))
+}
+
+// pathToFelt converts a path to the felt whose least significant bits are the path
+func pathToFelt(path *bitset.BitSet) *felt.Felt {
 	pathWords := path.Bytes()
 	if len(pathWords) > felt.Limbs {
 		panic("key too long to fit in Felt")
@@ -31,11 +43,5 @@ func (n *Node) Hash(path *bitset.BitSet, hashFunc hashFunc) *felt.Felt {
 		binary.BigEndian.PutUint64(pathBytes[startBytes:startBytes+8], word)
 	}
 
-	pathFelt := new(felt.Felt).SetBytes(pathBytes[:])
-
-	// https://docs.starknet.io/documentation/develop/State/starknet-state/
-	hash := hashFunc(n.Value, pathFelt)
-
-	pathFelt.SetUint64(uint64(path.Len()))
-	return hash.Add(hash, pathFelt)
+	return new(felt.Felt).SetBytes(pathBytes[:])
 }
@@ -0,0 +1,142 @@
+package trie
+
+import (
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/bits-and-blooms/bitset"
+)
+
+// verifiableHeight is the height of the tries whose proofs can be verified, all Starknet tries have it.
+const verifiableHeight = 251
+
+// ProofNode is a node on the path from the root of a [Trie] to a key, as the [specification] describes
+// them. Exactly one of Binary and Edge is set.
+//
+// [specification]: https://docs.starknet.io/documentation/develop/State/starknet-state/
+type ProofNode struct {
+	Binary *BinaryNode
+	Edge   *EdgeNode
+}
+
+// BinaryNode is a node with two children, it carries the hashes of both.
+type BinaryNode struct {
+	LeftHash  *felt.Felt
+	RightHash *felt.Felt
+}
+
+// EdgeNode is a node with a single child, the child is reached by following the Len least significant bits
+// of Path, most significant first.
+type EdgeNode struct {
+	Child *felt.Felt
+	Path  *felt.Felt
+	Len   uint8
+}
+
+// Hash calculates the hash of a [ProofNode]
+func (n *ProofNode) Hash(hashFunc hashFunc) *felt.Felt {
+	if n.Binary != nil {
+		return hashFunc(n.Binary.LeftHash, n.Binary.RightHash)
+	}
+	return edgeHash(n.Edge.Child, n.Edge.Path, n.Edge.Len, hashFunc)
+}
+
+// Prove returns the nodes on the path from the root to the given key, starting with the root. They prove
+// the value of the key against the root of the [Trie], or that the key has no value if the path ends in
+// an edge that leads away from the key. The proof of an empty [Trie] is empty.
+func (t *Trie) Prove(key *felt.Felt) ([]ProofNode, error) {
+	if t.rootKey == nil {
+		return nil, nil
+	}
+
+	nodes, err := t.nodesFromRoot(t.feltToBitSet(key))
+	if err != nil {
+		return nil, err
+	}
+
+	var proof []ProofNode
+	var parentKey *bitset.BitSet
+	for idx, sNode := range nodes {
+		if nodePath := path(sNode.key, parentKey); nodePath.Len() > 0 {
+			proof = append(proof, ProofNode{Edge: &EdgeNode{
+				Child: sNode.node.Value,
+				Path:  pathToFelt(nodePath),
+				Len:   uint8(nodePath.Len()),
+			}})
+		}
+
+		// the path ends at a leaf, or at a node the key does not go through
+		if sNode.node.Left == nil || idx == len(nodes)-1 {
+			break
+		}
+
+		left, err := t.storage.Get(sNode.node.Left)
+		if err != nil {
+			return nil, err
+		}
+
+		right, err := t.storage.Get(sNode.node.Right)
+		if err != nil {
+			return nil, err
+		}
+
+		proof = append(proof, ProofNode{Binary: &BinaryNode{
+			LeftHash:  left.Hash(path(sNode.node.Left, sNode.key), t.hash),
+			RightHash: right.Hash(path(sNode.node.Right, sNode.key), t.hash),
+		}})
+		parentKey = sNode.key
+	}
+	return proof, nil
+}
+
+// VerifyProof checks that the proof, as returned by [Trie.Prove], shows that the key has the given value
+// in a [Trie] with the given root. A zero value checks that the key has no value. Only proofs of tries of
+// height 251, which all Starknet tries have, can be verified.
+func VerifyProof(root, key, value *felt.Felt, proof []ProofNode, hashFunc hashFunc) bool {
+	if len(proof) == 0 {
+		return root.IsZero() && value.IsZero()
+	}
+
+	keyBits := key.Bits()
+	keyBitSet := bitset.FromWithLength(verifiableHeight, keyBits[:])
+	// remaining is the number of bits of the key that are still to be followed
+	remaining := uint(verifiableHeight)
+
+	expected := root
+	for idx := range proof {
+		node := &proof[idx]
+		if (node.Binary == nil) == (node.Edge == nil) || !node.Hash(hashFunc).Equal(expected) {
+			return false
+		}
+
+		if node.Binary != nil {
+			if remaining == 0 {
+				return false
+			}
+			remaining--
+
+			if keyBitSet.Test(remaining) {
+				expected = node.Binary.RightHash
+			} else {
+				expected = node.Binary.LeftHash
+			}
+			continue
+		}
+
+		length := uint(node.Edge.Len)
+		if length == 0 || length > remaining {
+			return false
+		}
+
+		pathBits := node.Edge.Path.Bits()
+		pathBitSet := bitset.FromWithLength(felt.Bits, pathBits[:])
+		for i := uint(1); i <= length; i++ {
+			if pathBitSet.Test(length-i) != keyBitSet.Test(remaining-i) {
+				// the edge leads away from the key, so the key has no value
+				return idx == len(proof)-1 && value.IsZero()
+			}
+		}
+		remaining -= length
+		expected = node.Edge.Child
+	}
+
+	return remaining == 0 && expected.Equal(value)
+}
@@ -0,0 +1,141 @@
+package trie_test
+
+import (
+	"testing"
+
+	"github.com/NethermindEth/juno/core/crypto"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/core/trie"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProve(t *testing.T) {
+	t.Run("empty trie", func(t *testing.T) {
+		require.NoError(t, trie.RunOnTempTrie(251, func(tempTrie *trie.Trie) error {
+			key := new(felt.Felt).SetUint64(1)
+			proof, err := tempTrie.Prove(key)
+			require.NoError(t, err)
+			assert.Empty(t, proof)
+
+			root, err := tempTrie.Root()
+			require.NoError(t, err)
+			assert.True(t, trie.VerifyProof(root, key, new(felt.Felt), proof, crypto.Pedersen))
+			assert.False(t, trie.VerifyProof(root, key, new(felt.Felt).SetUint64(1), proof, crypto.Pedersen))
+			return nil
+		}))
+	})
+
+	t.Run("single key", func(t *testing.T) {
+		require.NoError(t, trie.RunOnTempTrie(251, func(tempTrie *trie.Trie) error {
+			key := new(felt.Felt).SetUint64(42)
+			value := new(felt.Felt).SetUint64(1337)
+			_, err := tempTrie.Put(key, value)
+			require.NoError(t, err)
+
+			proof, err := tempTrie.Prove(key)
+			require.NoError(t, err)
+			require.Len(t, proof, 1)
+			require.NotNil(t, proof[0].Edge)
+			assert.Equal(t, uint8(251), proof[0].Edge.Len)
+
+			root, err := tempTrie.Root()
+			require.NoError(t, err)
+			assert.True(t, trie.VerifyProof(root, key, value, proof, crypto.Pedersen))
+			assert.False(t, trie.VerifyProof(root, key, new(felt.Felt), proof, crypto.Pedersen))
+
+			absent := new(felt.Felt).SetUint64(43)
+			proof, err = tempTrie.Prove(absent)
+			require.NoError(t, err)
+			assert.True(t, trie.VerifyProof(root, absent, new(felt.Felt), proof, crypto.Pedersen))
+			assert.False(t, trie.VerifyProof(root, absent, value, proof, crypto.Pedersen))
+			return nil
+		}))
+	})
+
+	t.Run("many keys", func(t *testing.T) {
+		require.NoError(t, trie.RunOnTempTrie(251, func(tempTrie *trie.Trie) error {
+			values := make(map[felt.Felt]*felt.Felt)
+			for i := uint64(1); i < 64; i++ {
+				values[*new(felt.Felt).SetUint64(i * i * 7919)] = new(felt.Felt).SetUint64(i)
+			}
+			// a key with the most significant bit set
+			values[*utils.HexToFelt(t, "0x400000000000000000000000000000000000000000000000000000000000000")] = new(felt.Felt).SetUint64(3)
+
+			for key, value := range values {
+				key := key
+				_, err := tempTrie.Put(&key, value)
+				require.NoError(t, err)
+			}
+
+			root, err := tempTrie.Root()
+			require.NoError(t, err)
+
+			for key, value := range values {
+				key := key
+				proof, err := tempTrie.Prove(&key)
+				require.NoError(t, err)
+				assert.True(t, trie.VerifyProof(root, &key, value, proof, crypto.Pedersen), "key %s", key.String())
+
+				// the value of the key is bound by the proof
+				assert.False(t, trie.VerifyProof(root, &key, new(felt.Felt).Add(value, value), proof, crypto.Pedersen))
+				// so is the key
+				otherKey := new(felt.Felt).Add(&key, new(felt.Felt).SetUint64(1))
+				assert.False(t, trie.VerifyProof(root, otherKey, value, proof, crypto.Pedersen))
+				// and so is the root
+				assert.False(t, trie.VerifyProof(new(felt.Felt).SetUint64(1), &key, value, proof, crypto.Pedersen))
+			}
+
+			for _, absent := range []uint64{2, 7920, 1 << 40} {
+				keyFelt := new(felt.Felt).SetUint64(absent)
+				proof, err := tempTrie.Prove(keyFelt)
+				require.NoError(t, err)
+				assert.True(t, trie.VerifyProof(root, keyFelt, new(felt.Felt), proof, crypto.Pedersen), "key %d", absent)
+			}
+			return nil
+		}))
+	})
+
+	t.Run("tampered proofs are rejected", func(t *testing.T) {
+		require.NoError(t, trie.RunOnTempTrie(251, func(tempTrie *trie.Trie) error {
+			for i := uint64(1); i < 8; i++ {
+				_, err := tempTrie.Put(new(felt.Felt).SetUint64(i), new(felt.Felt).SetUint64(i))
+				require.NoError(t, err)
+			}
+			root, err := tempTrie.Root()
+			require.NoError(t, err)
+
+			key := new(felt.Felt).SetUint64(5)
+			proof, err := tempTrie.Prove(key)
+			require.NoError(t, err)
+			require.True(t, trie.VerifyProof(root, key, key, proof, crypto.Pedersen))
+
+			// truncated
+			assert.False(t, trie.VerifyProof(root, key, key, proof[:len(proof)-1], crypto.Pedersen))
+
+			// node with a different hash
+			for idx := range proof {
+				tampered := make([]trie.ProofNode, len(proof))
+				copy(tampered, proof)
+				if node := proof[idx]; node.Binary != nil {
+					tampered[idx] = trie.ProofNode{Binary: &trie.BinaryNode{
+						LeftHash:  node.Binary.RightHash,
+						RightHash: node.Binary.LeftHash,
+					}}
+				} else {
+					tampered[idx] = trie.ProofNode{Edge: &trie.EdgeNode{
+						Child: node.Edge.Child,
+						Path:  node.Edge.Path,
+						Len:   node.Edge.Len + 1,
+					}}
+				}
+				assert.False(t, trie.VerifyProof(root, key, key, tampered, crypto.Pedersen), "node %d", idx)
+			}
+
+			// a node that is neither binary nor edge
+			assert.False(t, trie.VerifyProof(root, key, key, append([]trie.ProofNode{{}}, proof...), crypto.Pedersen))
+			return nil
+		}))
+	})
+}
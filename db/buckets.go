@@ -22,6 +22,14 @@ const (
 	ReceiptsByBlockNumberAndIndex           // maps block number and index to transaction receipt
 	StateUpdatesByBlockNumber
 	ClassesTrie
+	ContractNonceHistory     // maps contract addresses and block numbers to the nonce before that block
+	ContractClassHashHistory // maps contract addresses and block numbers to the class hash before that block
+	ContractStorageHistory   // maps contract addresses, storage locations and block numbers to the value before that block
+	ContractDeploymentHeight // maps contract addresses to the block number they were deployed at
+	EventsBloomByBlockNumber // maps block numbers to a bloom filter of the addresses and keys of the events in the block
+	SchemaVersion            // the number of migrations that were applied to the database
+	MigrationScratch         // intermediate data of the migration in progress, empty between migrations
+	L1Height                 // the number of the highest block that is known to be accepted on L1
 )
 
 // Key flattens a prefix and series of byte arrays into a single []byte.
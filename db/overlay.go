@@ -0,0 +1,189 @@
+package db
+
+import (
+	"errors"
+	"sort"
+)
+
+var (
+	_ Transaction = (*overlay)(nil)
+	_ Iterator    = (*overlayIterator)(nil)
+)
+
+var ErrOverlayCommit = errors.New("changes to an overlay can not be committed")
+
+// overlay keeps the changes made through it in memory, on top of a base transaction that is only read.
+type overlay struct {
+	base Transaction
+	// changes maps the changed keys to their values, nil if the key was deleted
+	changes map[string][]byte
+}
+
+// NewOverlay returns a transaction that reads through to the given base transaction, and keeps the changes
+// made through it in memory so that they are never committed. This allows, e.g., reverting the state in a
+// read-only transaction without holding up the writers. Discarding the overlay discards the base transaction.
+func NewOverlay(base Transaction) Transaction {
+	return &overlay{
+		base:    base,
+		changes: make(map[string][]byte),
+	}
+}
+
+// NewIterator : see db.Transaction.NewIterator
+func (o *overlay) NewIterator() (Iterator, error) {
+	baseIt, err := o.base.NewIterator()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(o.changes))
+	values := make(map[string][]byte, len(o.changes))
+	for key, value := range o.changes {
+		keys = append(keys, key)
+		values[key] = value
+	}
+	sort.Strings(keys)
+	return &overlayIterator{base: baseIt, keys: keys, values: values}, nil
+}
+
+// Discard : see db.Transaction.Discard
+func (o *overlay) Discard() error {
+	o.changes = nil
+	return o.base.Discard()
+}
+
+// Commit : see db.Transaction.Commit
+func (o *overlay) Commit() error {
+	return CloseAndWrapOnError(o.Discard, ErrOverlayCommit)
+}
+
+// Set : see db.Transaction.Set
+func (o *overlay) Set(key, val []byte) error {
+	if len(key) == 0 {
+		return errors.New("empty key")
+	}
+	o.changes[string(key)] = append(make([]byte, 0, len(val)), val...)
+	return nil
+}
+
+// Delete : see db.Transaction.Delete
+func (o *overlay) Delete(key []byte) error {
+	o.changes[string(key)] = nil
+	return nil
+}
+
+// Get : see db.Transaction.Get
+func (o *overlay) Get(key []byte, cb func([]byte) error) error {
+	if val, ok := o.changes[string(key)]; ok {
+		if val == nil {
+			return ErrKeyNotFound
+		}
+		return cb(val)
+	}
+	return o.base.Get(key, cb)
+}
+
+// Impl : see db.Transaction.Impl
+func (o *overlay) Impl() any {
+	return o.base.Impl()
+}
+
+// overlayIterator merges the changed keys of an overlay, as they were when it was created, with the keys
+// of the base transaction, skipping the deleted ones.
+type overlayIterator struct {
+	base Iterator
+	// baseValid is whether the base iterator is positioned at a valid key
+	baseValid bool
+	// keys are the changed keys in ascending order, next is the index of the first one not passed yet
+	keys       []string
+	next       int
+	values     map[string][]byte
+	positioned bool
+	// key and value are the current pair, key is nil if the iterator is not valid
+	key   []byte
+	value []byte
+}
+
+// Valid : see db.Iterator.Valid
+func (i *overlayIterator) Valid() bool {
+	return i.key != nil
+}
+
+// Key : see db.Iterator.Key
+func (i *overlayIterator) Key() []byte {
+	return i.key
+}
+
+// Value : see db.Iterator.Value
+func (i *overlayIterator) Value() ([]byte, error) {
+	if i.value != nil {
+		return i.value, nil
+	}
+	return i.base.Value()
+}
+
+// Next : see db.Iterator.Next
+func (i *overlayIterator) Next() bool {
+	if !i.positioned {
+		i.positioned = true
+		i.baseValid = i.base.Next()
+		return i.settle()
+	} else if i.key == nil {
+		return false
+	}
+
+	if i.baseValid && string(i.base.Key()) == string(i.key) {
+		i.baseValid = i.base.Next()
+	}
+	return i.settle()
+}
+
+// Seek : see db.Iterator.Seek
+func (i *overlayIterator) Seek(key []byte) bool {
+	i.positioned = true
+	i.baseValid = i.base.Seek(key)
+	i.next = sort.SearchStrings(i.keys, string(key))
+	i.key = nil
+	return i.settle()
+}
+
+// settle positions the iterator at the lowest key that is not passed yet and not deleted, the current key
+// is passed.
+func (i *overlayIterator) settle() bool {
+	if i.key != nil && i.next < len(i.keys) && i.keys[i.next] == string(i.key) {
+		i.next++
+	}
+
+	for {
+		var baseKey []byte
+		if i.baseValid {
+			baseKey = i.base.Key()
+		}
+
+		switch {
+		case i.next < len(i.keys) && (baseKey == nil || i.keys[i.next] <= string(baseKey)):
+			key := i.keys[i.next]
+			if baseKey != nil && key == string(baseKey) {
+				// the change shadows the value of the base
+				i.baseValid = i.base.Next()
+			}
+			if value := i.values[key]; value != nil {
+				i.key, i.value = []byte(key), value
+				return true
+			}
+			i.next++
+		case baseKey != nil:
+			// the key of the base is only valid until it moves
+			i.key, i.value = append([]byte(nil), baseKey...), nil
+			return true
+		default:
+			i.key, i.value = nil, nil
+			return false
+		}
+	}
+}
+
+// Close : see db.Iterator.Close
+func (i *overlayIterator) Close() error {
+	return i.base.Close()
+}
@@ -0,0 +1,90 @@
+package db_test
+
+import (
+	"testing"
+
+	"github.com/NethermindEth/juno/db"
+	"github.com/NethermindEth/juno/db/pebble"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverlay(t *testing.T) {
+	testDB := pebble.NewMemTest()
+	t.Cleanup(func() {
+		require.NoError(t, testDB.Close())
+	})
+
+	require.NoError(t, testDB.Update(func(txn db.Transaction) error {
+		for _, key := range []byte{1, 3, 5, 7} {
+			if err := txn.Set([]byte{key}, []byte{key}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+
+	overlay := db.NewOverlay(testDB.NewTransaction(false))
+	require.NoError(t, overlay.Set([]byte{2}, []byte{20}))
+	require.NoError(t, overlay.Set([]byte{3}, []byte{30}))
+	require.NoError(t, overlay.Delete([]byte{5}))
+	require.NoError(t, overlay.Set([]byte{8}, []byte{80}))
+
+	get := func(key byte) ([]byte, error) {
+		var value []byte
+		return value, overlay.Get([]byte{key}, func(val []byte) error {
+			value = append(value, val...)
+			return nil
+		})
+	}
+
+	t.Run("changes are read back", func(t *testing.T) {
+		for key, expected := range map[byte]byte{1: 1, 2: 20, 3: 30, 7: 7, 8: 80} {
+			value, err := get(key)
+			require.NoError(t, err)
+			assert.Equal(t, []byte{expected}, value)
+		}
+
+		_, err := get(5)
+		assert.ErrorIs(t, err, db.ErrKeyNotFound)
+	})
+
+	t.Run("iteration merges the changes with the base", func(t *testing.T) {
+		it, err := overlay.NewIterator()
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, it.Close())
+		})
+
+		var pairs [][2]byte
+		for it.Next() {
+			value, err := it.Value()
+			require.NoError(t, err)
+			pairs = append(pairs, [2]byte{it.Key()[0], value[0]})
+		}
+		assert.Equal(t, [][2]byte{{1, 1}, {2, 20}, {3, 30}, {7, 7}, {8, 80}}, pairs)
+
+		require.True(t, it.Seek([]byte{4}))
+		assert.Equal(t, []byte{7}, it.Key())
+		require.True(t, it.Seek([]byte{3}))
+		value, err := it.Value()
+		require.NoError(t, err)
+		assert.Equal(t, []byte{30}, value)
+		assert.False(t, it.Seek([]byte{9}))
+		assert.False(t, it.Valid())
+	})
+
+	t.Run("changes are never committed", func(t *testing.T) {
+		assert.ErrorIs(t, overlay.Commit(), db.ErrOverlayCommit)
+
+		require.NoError(t, testDB.View(func(txn db.Transaction) error {
+			return txn.Get([]byte{5}, func(val []byte) error {
+				assert.Equal(t, []byte{5}, val)
+				return nil
+			})
+		}))
+		require.ErrorIs(t, testDB.View(func(txn db.Transaction) error {
+			return txn.Get([]byte{2}, func([]byte) error { return nil })
+		}), db.ErrKeyNotFound)
+	})
+}
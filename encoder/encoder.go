@@ -17,6 +17,8 @@ var (
 	tagNum  uint64 = 65536
 	encMode cbor.EncMode
 	decMode cbor.DecMode
+	// decodes maps held in interface values as map[string]any
+	jsonMapsDecMode cbor.DecMode
 )
 
 var initialiseEncoder sync.Once
@@ -32,6 +34,13 @@ func initEncAndDecModes() {
 	if err != nil {
 		panic(err)
 	}
+
+	jsonMapsDecMode, err = cbor.DecOptions{
+		DefaultMapType: reflect.TypeOf(map[string]any(nil)),
+	}.DecModeWithTags(ts)
+	if err != nil {
+		panic(err)
+	}
 }
 
 func RegisterType(rType reflect.Type) error {
@@ -59,6 +68,14 @@ func Unmarshal(b []byte, v any) error {
 	return decMode.Unmarshal(b, v)
 }
 
+// UnmarshalWithJSONMaps decodes param v from []byte b like [Unmarshal], except that maps held in
+// interface values are decoded as map[string]any, so that they can be marshalled into JSON again. It
+// decodes anything that holds classes: the ABI of a Cairo 0 class is served as JSON.
+func UnmarshalWithJSONMaps(b []byte, v any) error {
+	initialiseEncoder.Do(initEncAndDecModes)
+	return jsonMapsDecMode.Unmarshal(b, v)
+}
+
 // TestSymmetry checks if a type can be marshalled and unmarshalled with no issues
 func TestSymmetry(t *testing.T, value any) {
 	t.Helper()
@@ -0,0 +1,36 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Conn is a client connection that the server can push notifications to, such as a WebSocket connection.
+type Conn interface {
+	// Notify sends a notification, a request without an id, to the client.
+	Notify(method string, params any) error
+	// Done is closed when the connection is closed.
+	Done() <-chan struct{}
+}
+
+type connKey struct{}
+
+// ContextWithConn returns a copy of the given context that carries the given connection.
+func ContextWithConn(ctx context.Context, conn Conn) context.Context {
+	return context.WithValue(ctx, connKey{}, conn)
+}
+
+// ConnFromContext returns the connection a request was received on. Only handlers that accept a context
+// and are called over a connection that supports notifications have one.
+func ConnFromContext(ctx context.Context) (Conn, bool) {
+	conn, ok := ctx.Value(connKey{}).(Conn)
+	return conn, ok
+}
+
+func marshalNotification(method string, params any) ([]byte, error) {
+	return json.Marshal(&request{
+		Version: "2.0",
+		Method:  method,
+		Params:  params,
+	})
+}
@@ -0,0 +1,40 @@
+package jsonrpc
+
+import "time"
+
+// EventListener is notified of the requests that a [Server] handles.
+type EventListener interface {
+	// OnNewRequest is called when a request for a registered method is received.
+	OnNewRequest(method string)
+	// OnRequestHandled is called when the handler of a method returns, whether it failed or not.
+	OnRequestHandled(method string, took time.Duration)
+	// OnRequestFailed is called when a request for a registered method fails.
+	OnRequestFailed(method string, err *Error)
+}
+
+var _ EventListener = (*SelectiveListener)(nil)
+
+// SelectiveListener is an [EventListener] that only calls the callbacks that are set.
+type SelectiveListener struct {
+	OnNewRequestCb     func(method string)
+	OnRequestHandledCb func(method string, took time.Duration)
+	OnRequestFailedCb  func(method string, err *Error)
+}
+
+func (l *SelectiveListener) OnNewRequest(method string) {
+	if l.OnNewRequestCb != nil {
+		l.OnNewRequestCb(method)
+	}
+}
+
+func (l *SelectiveListener) OnRequestHandled(method string, took time.Duration) {
+	if l.OnRequestHandledCb != nil {
+		l.OnRequestHandledCb(method, took)
+	}
+}
+
+func (l *SelectiveListener) OnRequestFailed(method string, err *Error) {
+	if l.OnRequestFailedCb != nil {
+		l.OnRequestFailedCb(method, err)
+	}
+}
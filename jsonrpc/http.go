@@ -21,10 +21,10 @@ type HTTP struct {
 	log  utils.SimpleLogger
 }
 
-func NewHTTP(port uint16, methods []Method, log utils.SimpleLogger) *HTTP {
+func NewHTTP(port uint16, rpc *Server, log utils.SimpleLogger) *HTTP {
 	headerTimeout := 1 * time.Second
 	h := &HTTP{
-		rpc: NewServer(),
+		rpc: rpc,
 		log: log,
 	}
 	h.http = &http.Server{
@@ -32,12 +32,6 @@ func NewHTTP(port uint16, methods []Method, log utils.SimpleLogger) *HTTP {
 		Handler:           h,
 		ReadHeaderTimeout: headerTimeout,
 	}
-	for _, method := range methods {
-		err := h.rpc.RegisterMethod(method)
-		if err != nil {
-			panic(err)
-		}
-	}
 	return h
 }
 
@@ -67,7 +61,7 @@ func (h *HTTP) ServeHTTP(writer http.ResponseWriter, req *http.Request) {
 	}
 
 	req.Body = http.MaxBytesReader(writer, req.Body, MaxRequestBodySize)
-	resp, err := h.rpc.HandleReader(req.Body)
+	resp, err := h.rpc.HandleReaderWithContext(req.Context(), req.Body)
 	writer.Header().Set("Content-Type", "application/json")
 	if err != nil {
 		writer.WriteHeader(http.StatusInternalServerError)
@@ -4,11 +4,13 @@ package jsonrpc
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
 	"reflect"
 	"strings"
+	"time"
 )
 
 const (
@@ -19,7 +21,11 @@ const (
 	InternalError  = -32603 // Internal JSON-RPC error.
 )
 
-var ErrInvalidID = errors.New("id should be a string or an integer")
+var (
+	ErrInvalidID = errors.New("id should be a string or an integer")
+
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+)
 
 type request struct {
 	Version string `json:"jsonrpc"`
@@ -41,7 +47,9 @@ type Error struct {
 	Data    any    `json:"data,omitempty"`
 }
 
-func rpcErr(code int, data any) *Error {
+// Err returns the standard JSON-RPC error for the given code, carrying data.
+// Codes that are not standard map to an [InternalError].
+func Err(code int, data any) *Error {
 	switch code {
 	case InvalidJSON:
 		return &Error{Code: InvalidJSON, Message: "Parse error", Data: data}
@@ -94,21 +102,31 @@ type Method struct {
 }
 
 type Server struct {
-	methods map[string]Method
+	methods  map[string]Method
+	listener EventListener
 }
 
 // NewServer instantiates a JSONRPC server
 func NewServer() *Server {
 	return &Server{
-		methods: make(map[string]Method),
+		methods:  make(map[string]Method),
+		listener: &SelectiveListener{},
 	}
 }
 
+// WithListener sets the listener that is notified of the handled requests.
+func (s *Server) WithListener(listener EventListener) *Server {
+	s.listener = listener
+	return s
+}
+
 // RegisterMethod verifies and creates an endpoint that the server recognises.
 //
 // - name is the method name
 // - handler is the function to be called when a request is received for the
-// associated method. It should have (any, *jsonrpc.Error) as its return type
+// associated method. It should have (any, *jsonrpc.Error) as its return type.
+// If its first parameter is a context.Context, it is passed the context of the
+// request, see [ConnFromContext]
 // - paramNames are the names of parameters in the order that they are expected
 // by the handler
 func (s *Server) RegisterMethod(method Method) error {
@@ -116,7 +134,7 @@ func (s *Server) RegisterMethod(method Method) error {
 	if handlerT.Kind() != reflect.Func {
 		return errors.New("handler must be a function")
 	}
-	if handlerT.NumIn() != len(method.Params) {
+	if handlerT.NumIn()-contextParams(handlerT) != len(method.Params) {
 		return errors.New("number of function params and param names must match")
 	}
 	if handlerT.NumOut() != 2 {
@@ -131,6 +149,25 @@ func (s *Server) RegisterMethod(method Method) error {
 	return nil
 }
 
+// RegisterMethods registers all the given methods, see [Server.RegisterMethod].
+func (s *Server) RegisterMethods(methods ...Method) error {
+	for _, method := range methods {
+		if err := s.RegisterMethod(method); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// contextParams returns 1 if the first parameter of the given handler type is a context.Context
+// and 0 otherwise.
+func contextParams(handlerT reflect.Type) int {
+	if handlerT.NumIn() > 0 && handlerT.In(0) == contextType {
+		return 1
+	}
+	return 0
+}
+
 // Handle processes a request to the server
 // It returns the response in a byte array, only returns an
 // error if it can not create the response byte array
@@ -142,6 +179,12 @@ func (s *Server) Handle(data []byte) ([]byte, error) {
 // It returns the response in a byte array, only returns an
 // error if it can not create the response byte array
 func (s *Server) HandleReader(reader io.Reader) ([]byte, error) {
+	return s.HandleReaderWithContext(context.Background(), reader)
+}
+
+// HandleReaderWithContext is like [Server.HandleReader], handlers that accept a context
+// are passed the given one.
+func (s *Server) HandleReaderWithContext(ctx context.Context, reader io.Reader) ([]byte, error) {
 	bufferedReader := bufio.NewReader(reader)
 	requestIsBatch := isBatch(bufferedReader)
 	res := &response{
@@ -154,12 +197,12 @@ func (s *Server) HandleReader(reader io.Reader) ([]byte, error) {
 	if !requestIsBatch {
 		req := new(request)
 		if jsonErr := dec.Decode(req); jsonErr != nil {
-			res.Error = rpcErr(InvalidJSON, jsonErr.Error())
-		} else if resObject, handleErr := s.handleRequest(req); handleErr != nil {
+			res.Error = Err(InvalidJSON, jsonErr.Error())
+		} else if resObject, handleErr := s.handleRequest(ctx, req); handleErr != nil {
 			if !errors.Is(handleErr, ErrInvalidID) {
 				res.ID = req.ID
 			}
-			res.Error = rpcErr(InvalidRequest, handleErr.Error())
+			res.Error = Err(InvalidRequest, handleErr.Error())
 		} else {
 			res = resObject
 		}
@@ -168,9 +211,9 @@ func (s *Server) HandleReader(reader io.Reader) ([]byte, error) {
 		var batchRes []json.RawMessage
 
 		if batchJSONErr := dec.Decode(&batchReq); batchJSONErr != nil {
-			res.Error = rpcErr(InvalidJSON, batchJSONErr.Error())
+			res.Error = Err(InvalidJSON, batchJSONErr.Error())
 		} else if len(batchReq) == 0 {
-			res.Error = rpcErr(InvalidRequest, "empty batch")
+			res.Error = Err(InvalidRequest, "empty batch")
 		} else {
 			for _, rawReq := range batchReq { // todo: handle async
 				var resObject *response
@@ -182,15 +225,15 @@ func (s *Server) HandleReader(reader io.Reader) ([]byte, error) {
 				if jsonErr := reqDec.Decode(req); jsonErr != nil {
 					resObject = &response{
 						Version: "2.0",
-						Error:   rpcErr(InvalidRequest, jsonErr.Error()),
+						Error:   Err(InvalidRequest, jsonErr.Error()),
 					}
 				} else {
 					var handleErr error
-					resObject, handleErr = s.handleRequest(req)
+					resObject, handleErr = s.handleRequest(ctx, req)
 					if handleErr != nil {
 						resObject = &response{
 							Version: "2.0",
-							Error:   rpcErr(InvalidRequest, handleErr.Error()),
+							Error:   Err(InvalidRequest, handleErr.Error()),
 						}
 						if !errors.Is(handleErr, ErrInvalidID) {
 							resObject.ID = req.ID
@@ -241,7 +284,7 @@ func isNil(i any) bool {
 	return i == nil || reflect.ValueOf(i).IsNil()
 }
 
-func (s *Server) handleRequest(req *request) (*response, error) {
+func (s *Server) handleRequest(ctx context.Context, req *request) (*response, error) {
 	if err := req.isSane(); err != nil {
 		return nil, err
 	}
@@ -253,23 +296,31 @@ func (s *Server) handleRequest(req *request) (*response, error) {
 
 	calledMethod, found := s.methods[req.Method]
 	if !found {
-		res.Error = rpcErr(MethodNotFound, nil)
+		res.Error = Err(MethodNotFound, nil)
 		return res, nil
 	}
 
-	args, err := buildArguments(req.Params, calledMethod.Handler, calledMethod.Params)
+	s.listener.OnNewRequest(req.Method)
+	args, err := buildArguments(ctx, req.Params, calledMethod.Handler, calledMethod.Params)
 	if err != nil {
-		res.Error = rpcErr(InvalidParams, err.Error())
+		res.Error = Err(InvalidParams, err.Error())
+		s.listener.OnRequestFailed(req.Method, res.Error)
 		return res, nil
 	}
 
+	start := time.Now()
 	tuple := reflect.ValueOf(calledMethod.Handler).Call(args)
+	s.listener.OnRequestHandled(req.Method, time.Since(start))
+
+	if errAny := tuple[1].Interface(); !isNil(errAny) {
+		res.Error = errAny.(*Error)
+		s.listener.OnRequestFailed(req.Method, res.Error)
+	}
 	if res.ID == nil { // notification
 		return nil, nil
 	}
 
-	if errAny := tuple[1].Interface(); !isNil(errAny) {
-		res.Error = errAny.(*Error)
+	if res.Error != nil {
 		return res, nil
 	}
 
@@ -277,8 +328,15 @@ func (s *Server) handleRequest(req *request) (*response, error) {
 	return res, nil
 }
 
-func buildArguments(params, handler any, configuredParams []Parameter) ([]reflect.Value, error) {
-	args := make([]reflect.Value, 0, len(configuredParams))
+func buildArguments(ctx context.Context, params, handler any, configuredParams []Parameter) ([]reflect.Value, error) {
+	handlerType := reflect.TypeOf(handler)
+	offset := contextParams(handlerType)
+
+	args := make([]reflect.Value, 0, offset+len(configuredParams))
+	if offset > 0 {
+		args = append(args, reflect.ValueOf(ctx))
+	}
+
 	if isNil(params) {
 		if len(configuredParams) > 0 {
 			return nil, errors.New("missing non-optional param field")
@@ -287,8 +345,6 @@ func buildArguments(params, handler any, configuredParams []Parameter) ([]reflec
 		return args, nil
 	}
 
-	handlerType := reflect.TypeOf(handler)
-
 	handlerParamValue := func(param any, t reflect.Type) (reflect.Value, error) {
 		handlerParam := reflect.New(t)
 		valueMarshaled, err := json.Marshal(param) // we have to marshal the value into JSON again
@@ -307,12 +363,12 @@ func buildArguments(params, handler any, configuredParams []Parameter) ([]reflec
 	case reflect.Slice:
 		paramsList := params.([]any)
 
-		if len(paramsList) != handlerType.NumIn() {
+		if len(paramsList) != handlerType.NumIn()-offset {
 			return nil, errors.New("missing/unexpected params in list")
 		}
 
 		for i, param := range paramsList {
-			v, err := handlerParamValue(param, handlerType.In(i))
+			v, err := handlerParamValue(param, handlerType.In(offset+i))
 			if err != nil {
 				return nil, err
 			}
@@ -325,13 +381,13 @@ func buildArguments(params, handler any, configuredParams []Parameter) ([]reflec
 			var v reflect.Value
 			if param, found := paramsMap[configuredParam.Name]; found {
 				var err error
-				v, err = handlerParamValue(param, handlerType.In(i))
+				v, err = handlerParamValue(param, handlerType.In(offset+i))
 				if err != nil {
 					return nil, err
 				}
 			} else if configuredParam.Optional {
 				// optional parameter
-				v = reflect.New(handlerType.In(i)).Elem()
+				v = reflect.New(handlerType.In(offset + i)).Elem()
 			} else {
 				return nil, errors.New("missing non-optional param")
 			}
@@ -1,7 +1,10 @@
 package jsonrpc_test
 
 import (
+	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/NethermindEth/juno/jsonrpc"
 	"github.com/stretchr/testify/assert"
@@ -343,3 +346,106 @@ func TestHandle(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleWithContext(t *testing.T) {
+	type ctxKey struct{}
+
+	server := jsonrpc.NewServer()
+	require.NoError(t, server.RegisterMethods(
+		jsonrpc.Method{
+			Name: "value",
+			Handler: func(ctx context.Context) (any, *jsonrpc.Error) {
+				return ctx.Value(ctxKey{}), nil
+			},
+		},
+		jsonrpc.Method{
+			Name:   "add",
+			Params: []jsonrpc.Parameter{{Name: "a"}, {Name: "b", Optional: true}},
+			Handler: func(ctx context.Context, a, b int) (int, *jsonrpc.Error) {
+				return ctx.Value(ctxKey{}).(int) + a + b, nil
+			},
+		},
+	))
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, 40)
+	tests := map[string]struct {
+		req string
+		res string
+	}{
+		"no params": {
+			req: `{"jsonrpc": "2.0", "method": "value", "id": 1}`,
+			res: `{"jsonrpc":"2.0","result":40,"id":1}`,
+		},
+		"positional params": {
+			req: `{"jsonrpc": "2.0", "method": "add", "params": [1, 1], "id": 2}`,
+			res: `{"jsonrpc":"2.0","result":42,"id":2}`,
+		},
+		"named params": {
+			req: `{"jsonrpc": "2.0", "method": "add", "params": {"a": 2}, "id": 3}`,
+			res: `{"jsonrpc":"2.0","result":42,"id":3}`,
+		},
+		"context is not a positional param": {
+			req: `{"jsonrpc": "2.0", "method": "add", "params": [1, 1, 1], "id": 4}`,
+			res: `{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid Params","data":"missing/unexpected params in list"},"id":4}`,
+		},
+	}
+
+	for desc, test := range tests {
+		test := test
+		t.Run(desc, func(t *testing.T) {
+			res, err := server.HandleReaderWithContext(ctx, strings.NewReader(test.req))
+			require.NoError(t, err)
+			assert.Equal(t, test.res, string(res))
+		})
+	}
+}
+
+func TestServerListener(t *testing.T) {
+	var newRequests, handled []string
+	failed := make(map[string]int)
+	listener := &jsonrpc.SelectiveListener{
+		OnNewRequestCb: func(method string) {
+			newRequests = append(newRequests, method)
+		},
+		OnRequestHandledCb: func(method string, took time.Duration) {
+			assert.GreaterOrEqual(t, took, time.Duration(0))
+			handled = append(handled, method)
+		},
+		OnRequestFailedCb: func(method string, err *jsonrpc.Error) {
+			failed[method] = err.Code
+		},
+	}
+
+	server := jsonrpc.NewServer().WithListener(listener)
+	require.NoError(t, server.RegisterMethods(
+		jsonrpc.Method{
+			Name:    "ok",
+			Handler: func() (int, *jsonrpc.Error) { return 1, nil },
+		},
+		jsonrpc.Method{
+			Name:    "fail",
+			Handler: func() (int, *jsonrpc.Error) { return 0, jsonrpc.Err(jsonrpc.InternalError, nil) },
+		},
+		jsonrpc.Method{
+			Name:    "param",
+			Params:  []jsonrpc.Parameter{{Name: "a"}},
+			Handler: func(a int) (int, *jsonrpc.Error) { return a, nil },
+		},
+	))
+
+	for _, req := range []string{
+		`{"jsonrpc": "2.0", "method": "ok", "id": 1}`,
+		`{"jsonrpc": "2.0", "method": "fail", "id": 2}`,
+		`{"jsonrpc": "2.0", "method": "param", "params": ["not an int"], "id": 3}`,
+		`{"jsonrpc": "2.0", "method": "unknown", "id": 4}`,
+		`{"jsonrpc": "2.0", "method": "fail"}`,
+	} {
+		_, err := server.Handle([]byte(req))
+		require.NoError(t, err)
+	}
+
+	// unknown methods are not reported, so that their names can not be made up by the clients
+	assert.Equal(t, []string{"ok", "fail", "param", "fail"}, newRequests)
+	assert.Equal(t, []string{"ok", "fail", "fail"}, handled)
+	assert.Equal(t, map[string]int{"fail": jsonrpc.InternalError, "param": jsonrpc.InvalidParams}, failed)
+}
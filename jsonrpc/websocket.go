@@ -0,0 +1,198 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/NethermindEth/juno/service"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// maxQueuedMessages is the number of outgoing messages a connection buffers before the client is
+	// considered too slow and disconnected.
+	maxQueuedMessages = 256
+	writeTimeout      = 10 * time.Second
+)
+
+var (
+	_ service.Service = (*Websocket)(nil)
+	_ Conn            = (*websocketConn)(nil)
+
+	ErrConnClosed   = errors.New("connection closed")
+	ErrSlowConsumer = errors.New("client is not reading messages fast enough")
+)
+
+// Websocket serves the methods of a [Server] over WebSocket connections. Unlike [HTTP], it lets
+// handlers push notifications to the client, see [ConnFromContext].
+type Websocket struct {
+	rpc      *Server
+	http     *http.Server
+	upgrader websocket.Upgrader
+	log      utils.SimpleLogger
+
+	// ctx is cancelled when the service stops, closing all connections
+	ctx context.Context
+}
+
+func NewWebsocket(port uint16, rpc *Server, log utils.SimpleLogger) *Websocket {
+	headerTimeout := 1 * time.Second
+	ws := &Websocket{
+		rpc: rpc,
+		upgrader: websocket.Upgrader{
+			// the node serves any origin, like the HTTP transport does
+			CheckOrigin: func(*http.Request) bool { return true },
+		},
+		log: log,
+		ctx: context.Background(),
+	}
+	ws.http = &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           ws,
+		ReadHeaderTimeout: headerTimeout,
+	}
+	return ws
+}
+
+// Run starts to listen for WebSocket connections
+func (ws *Websocket) Run(ctx context.Context) error {
+	ws.ctx = ctx
+	errCh := make(chan error)
+
+	go func() {
+		<-ctx.Done()
+		errCh <- ws.http.Shutdown(context.Background())
+		close(errCh)
+	}()
+
+	if err := ws.http.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return <-errCh
+}
+
+// ServeHTTP upgrades the request to a WebSocket connection and serves it until it is closed
+func (ws *Websocket) ServeHTTP(writer http.ResponseWriter, req *http.Request) {
+	conn, err := ws.upgrader.Upgrade(writer, req, nil)
+	if err != nil {
+		ws.log.Debugw("Failed upgrading to a WebSocket connection", "err", err)
+		return
+	}
+
+	wsConn := &websocketConn{
+		conn: conn,
+		send: make(chan []byte, maxQueuedMessages),
+		done: make(chan struct{}),
+	}
+	go wsConn.writeLoop()
+
+	select {
+	case <-ws.ctx.Done():
+		wsConn.close()
+		return
+	default:
+	}
+	go func() {
+		select {
+		case <-ws.ctx.Done():
+			wsConn.close()
+		case <-wsConn.Done():
+		}
+	}()
+
+	ws.readLoop(wsConn)
+}
+
+// readLoop handles the requests received on the connection one at a time
+func (ws *Websocket) readLoop(wsConn *websocketConn) {
+	defer wsConn.close()
+
+	wsConn.conn.SetReadLimit(MaxRequestBodySize)
+	ctx := ContextWithConn(ws.ctx, wsConn)
+	for {
+		_, reader, err := wsConn.conn.NextReader()
+		if err != nil {
+			return
+		}
+
+		resp, err := ws.rpc.HandleReaderWithContext(ctx, reader)
+		if err != nil {
+			ws.log.Warnw("Failed handling WebSocket request", "err", err)
+			return
+		}
+
+		if resp != nil {
+			if err = wsConn.queue(resp); err != nil {
+				return
+			}
+		}
+	}
+}
+
+type websocketConn struct {
+	conn      *websocket.Conn
+	send      chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Notify queues a notification for the client. A client that does not keep up with its notifications
+// is disconnected.
+func (c *websocketConn) Notify(method string, params any) error {
+	msg, err := marshalNotification(method, params)
+	if err != nil {
+		return err
+	}
+	return c.queue(msg)
+}
+
+func (c *websocketConn) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *websocketConn) queue(msg []byte) error {
+	select {
+	case <-c.done:
+		return ErrConnClosed
+	default:
+	}
+
+	select {
+	case c.send <- msg:
+		return nil
+	default:
+		c.close()
+		return ErrSlowConsumer
+	}
+}
+
+func (c *websocketConn) writeLoop() {
+	for {
+		select {
+		case <-c.done:
+			return
+		case msg := <-c.send:
+			if err := c.conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+				c.close()
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				c.close()
+				return
+			}
+		}
+	}
+}
+
+func (c *websocketConn) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
@@ -0,0 +1,130 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/juno/jsonrpc"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebsocket(t *testing.T) {
+	conns := make(chan jsonrpc.Conn, 1)
+
+	server := jsonrpc.NewServer()
+	require.NoError(t, server.RegisterMethods(
+		jsonrpc.Method{
+			Name:   "echo",
+			Params: []jsonrpc.Parameter{{Name: "msg"}},
+			Handler: func(msg string) (string, *jsonrpc.Error) {
+				return msg, nil
+			},
+		},
+		jsonrpc.Method{
+			Name: "subscribe",
+			Handler: func(ctx context.Context) (bool, *jsonrpc.Error) {
+				conn, ok := jsonrpc.ConnFromContext(ctx)
+				if ok {
+					conns <- conn
+				}
+				return ok, nil
+			},
+		},
+	))
+
+	srv := httptest.NewServer(jsonrpc.NewWebsocket(0, server, utils.NewNopZapLogger()))
+	t.Cleanup(srv.Close)
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil) //nolint:bodyclose
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, client.Close())
+	})
+
+	roundTrip := func(t *testing.T, req, res string) {
+		t.Helper()
+		require.NoError(t, client.WriteMessage(websocket.TextMessage, []byte(req)))
+		_, msg, err := client.ReadMessage()
+		require.NoError(t, err)
+		assert.Equal(t, res, string(msg))
+	}
+
+	t.Run("request", func(t *testing.T) {
+		roundTrip(t, `{"jsonrpc": "2.0", "method": "echo", "params": ["juno"], "id": 1}`,
+			`{"jsonrpc":"2.0","result":"juno","id":1}`)
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		roundTrip(t, `[{"jsonrpc": "2.0", "method": "echo", "params": ["a"], "id": 1},{"jsonrpc": "2.0", "method": "echo", "params": ["b"], "id": 2}]`,
+			`[{"jsonrpc":"2.0","result":"a","id":1},{"jsonrpc":"2.0","result":"b","id":2}]`)
+	})
+
+	var conn jsonrpc.Conn
+	t.Run("notification", func(t *testing.T) {
+		roundTrip(t, `{"jsonrpc": "2.0", "method": "subscribe", "id": 1}`, `{"jsonrpc":"2.0","result":true,"id":1}`)
+		conn = <-conns
+
+		require.NoError(t, conn.Notify("update", map[string]int{"value": 42}))
+		_, msg, err := client.ReadMessage()
+		require.NoError(t, err)
+		assert.Equal(t, `{"jsonrpc":"2.0","method":"update","params":{"value":42}}`, string(msg))
+	})
+
+	t.Run("client closes the connection", func(t *testing.T) {
+		require.NoError(t, client.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")))
+
+		select {
+		case <-conn.Done():
+		case <-time.After(time.Second):
+			require.Fail(t, "connection was not closed")
+		}
+		require.ErrorIs(t, conn.Notify("update", nil), jsonrpc.ErrConnClosed)
+	})
+}
+
+func TestWebsocketSlowConsumer(t *testing.T) {
+	conns := make(chan jsonrpc.Conn, 1)
+
+	server := jsonrpc.NewServer()
+	require.NoError(t, server.RegisterMethod(jsonrpc.Method{
+		Name: "subscribe",
+		Handler: func(ctx context.Context) (bool, *jsonrpc.Error) {
+			conn, ok := jsonrpc.ConnFromContext(ctx)
+			conns <- conn
+			return ok, nil
+		},
+	}))
+
+	srv := httptest.NewServer(jsonrpc.NewWebsocket(0, server, utils.NewNopZapLogger()))
+	t.Cleanup(srv.Close)
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil) //nolint:bodyclose
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		client.Close()
+	})
+
+	require.NoError(t, client.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc": "2.0", "method": "subscribe", "id": 1}`)))
+	conn := <-conns
+
+	// the client never reads, so the connection is eventually dropped
+	payload := strings.Repeat("x", 64*1024)
+	var notifyErr error
+	for i := 0; i < 10_000 && notifyErr == nil; i++ {
+		notifyErr = conn.Notify("update", payload)
+	}
+	require.Error(t, notifyErr)
+
+	select {
+	case <-conn.Done():
+	case <-time.After(time.Second):
+		require.Fail(t, "connection was not closed")
+	}
+}
@@ -0,0 +1,263 @@
+package l1
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/clients/ethereum"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
+	"github.com/NethermindEth/juno/service"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/crypto/sha3"
+)
+
+var _ service.Service = (*Verifier)(nil)
+
+const (
+	// maxBlockRange is the largest range of L1 blocks requested in a single eth_getLogs call.
+	maxBlockRange = 10_000
+	// maxLookback is how far back from the L1 head the most recent state update is searched for on startup.
+	maxLookback = 100_000
+
+	wordLen   = 32
+	uint64Len = 8
+	signBit   = 0x80
+)
+
+var (
+	// The core contract emits LogStateUpdate(uint256 globalRoot, int256 blockNumber, uint256 blockHash),
+	// older versions of it emitted LogStateUpdate(uint256 globalRoot, int256 blockNumber).
+	logStateUpdateTopics = []common.Hash{
+		eventTopic("LogStateUpdate(uint256,int256,uint256)"),
+		eventTopic("LogStateUpdate(uint256,int256)"),
+	}
+
+	errInvalidLog = errors.New("invalid LogStateUpdate log")
+
+	// ErrStateRootMismatch is returned by the Verifier when the state root of a stored block does not match the
+	// one posted on L1, the synced chain cannot be trusted then.
+	ErrStateRootMismatch = errors.New("state root does not match the one posted on L1")
+)
+
+func eventTopic(signature string) common.Hash {
+	h := sha3.NewLegacyKeccak256()
+	if _, err := h.Write([]byte(signature)); err != nil {
+		panic(err)
+	}
+	return common.BytesToHash(h.Sum(nil))
+}
+
+// StateUpdate is a Starknet state update as posted to the core contract on L1.
+type StateUpdate struct {
+	BlockNumber uint64
+	GlobalRoot  *felt.Felt
+}
+
+// Verifier checks the state roots of the stored blocks against the ones posted to the Starknet core
+// contract on L1. Blocks whose state root was posted are marked as accepted on L1.
+type Verifier struct {
+	client       *ethereum.Client
+	blockchain   *blockchain.Blockchain
+	coreContract common.Address
+	pollInterval time.Duration
+
+	// nextL1Block is the first L1 block that was not searched for state updates yet
+	nextL1Block uint64
+	// unverified are the state updates posted on L1 that were not checked yet, oldest first
+	unverified []*StateUpdate
+
+	log utils.SimpleLogger
+}
+
+func NewVerifier(client *ethereum.Client, bc *blockchain.Blockchain, log utils.SimpleLogger) *Verifier {
+	return &Verifier{
+		client:       client,
+		blockchain:   bc,
+		coreContract: bc.Network().CoreContractAddress(),
+		pollInterval: time.Minute,
+		log:          log,
+	}
+}
+
+// WithPollInterval sets how often the Verifier checks L1 for new state updates.
+func (v *Verifier) WithPollInterval(interval time.Duration) *Verifier {
+	v.pollInterval = interval
+	return v
+}
+
+// Run polls L1 for state updates until the context is cancelled. It stops with [ErrStateRootMismatch] if a
+// stored block does not match its state update.
+func (v *Verifier) Run(ctx context.Context) error {
+	ticker := time.NewTicker(v.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := v.poll(ctx); errors.Is(err, ErrStateRootMismatch) {
+			return err
+		} else if err != nil {
+			v.log.Warnw("Failed verifying state against L1", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll fetches the state updates posted on L1 since the last poll and verifies the ones of the stored blocks.
+func (v *Verifier) poll(ctx context.Context) error {
+	l1Head, err := v.client.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+
+	if v.nextL1Block == 0 {
+		err = v.findLatestStateUpdate(ctx, l1Head)
+	} else {
+		err = v.followStateUpdates(ctx, l1Head)
+	}
+	if err != nil {
+		return err
+	}
+	v.nextL1Block = l1Head + 1
+
+	return v.verify()
+}
+
+// findLatestStateUpdate searches backwards from the L1 head for the most recent state update.
+func (v *Verifier) findLatestStateUpdate(ctx context.Context, l1Head uint64) error {
+	oldest := uint64(0)
+	if l1Head > maxLookback {
+		oldest = l1Head - maxLookback
+	}
+
+	for to := l1Head; ; to -= maxBlockRange {
+		from := oldest
+		if to-oldest >= maxBlockRange {
+			from = to - maxBlockRange + 1
+		}
+
+		updates, err := v.stateUpdates(ctx, from, to)
+		if err != nil {
+			return err
+		}
+
+		if len(updates) > 0 {
+			v.unverified = updates[len(updates)-1:]
+			return nil
+		}
+
+		if from == oldest {
+			return nil
+		}
+	}
+}
+
+// followStateUpdates fetches the state updates posted since the last poll.
+func (v *Verifier) followStateUpdates(ctx context.Context, l1Head uint64) error {
+	for from := v.nextL1Block; from <= l1Head; from += maxBlockRange {
+		to := from + maxBlockRange - 1
+		if to > l1Head {
+			to = l1Head
+		}
+
+		updates, err := v.stateUpdates(ctx, from, to)
+		if err != nil {
+			return err
+		}
+
+		v.unverified = append(v.unverified, updates...)
+	}
+	return nil
+}
+
+func (v *Verifier) stateUpdates(ctx context.Context, from, to uint64) ([]*StateUpdate, error) {
+	logs, err := v.client.Logs(ctx, &ethereum.FilterQuery{
+		FromBlock: from,
+		ToBlock:   to,
+		Address:   v.coreContract,
+		Topics:    [][]common.Hash{logStateUpdateTopics},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make([]*StateUpdate, 0, len(logs))
+	for i := range logs {
+		if logs[i].Removed {
+			continue
+		}
+
+		var update *StateUpdate
+		if update, err = parseStateUpdate(&logs[i]); err != nil {
+			return nil, err
+		}
+
+		// the contract is initialised with block number -1
+		if update != nil {
+			updates = append(updates, update)
+		}
+	}
+	return updates, nil
+}
+
+// parseStateUpdate decodes the global root and block number of a LogStateUpdate log. It returns nil if the
+// block number is negative.
+func parseStateUpdate(log *ethereum.Log) (*StateUpdate, error) {
+	if len(log.Data) < 2*wordLen {
+		return nil, errInvalidLog
+	}
+
+	blockNumber := log.Data[wordLen : 2*wordLen]
+	// int256 is encoded in two's complement
+	if blockNumber[0]&signBit != 0 {
+		return nil, nil
+	}
+
+	for _, b := range blockNumber[:wordLen-uint64Len] {
+		if b != 0 {
+			return nil, errInvalidLog
+		}
+	}
+
+	return &StateUpdate{
+		BlockNumber: binary.BigEndian.Uint64(blockNumber[wordLen-uint64Len:]),
+		GlobalRoot:  new(felt.Felt).SetBytes(log.Data[:wordLen]),
+	}, nil
+}
+
+// verify checks the state updates posted on L1 against the stored blocks, in order. The state updates of the
+// blocks that are not synced yet are kept until they are.
+func (v *Verifier) verify() error {
+	for len(v.unverified) > 0 {
+		update := v.unverified[0]
+		header, err := v.blockchain.BlockHeaderByNumber(update.BlockNumber)
+		if errors.Is(err, db.ErrKeyNotFound) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if !header.GlobalStateRoot.Equal(update.GlobalRoot) {
+			v.log.Errorw("!!! State root does not match the one posted on L1, the synced chain cannot be trusted !!!",
+				"number", header.Number, "hash", header.Hash, "root", header.GlobalStateRoot, "l1Root", update.GlobalRoot)
+			return fmt.Errorf("block %d: %w", header.Number, ErrStateRootMismatch)
+		}
+
+		if err = v.blockchain.SetAcceptedOnL1(header.Number); err != nil {
+			return err
+		}
+
+		v.log.Infow("Verified state root against L1", "number", header.Number, "root", header.GlobalStateRoot.ShortString())
+		v.unverified = v.unverified[1:]
+	}
+	return nil
+}
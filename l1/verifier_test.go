@@ -0,0 +1,146 @@
+package l1_test
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/clients/ethereum"
+	"github.com/NethermindEth/juno/clients/feeder"
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db/pebble"
+	"github.com/NethermindEth/juno/l1"
+	adaptfeeder "github.com/NethermindEth/juno/starknetdata/feeder"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+)
+
+func logStateUpdate(t *testing.T, l1Block uint64, blockNumber int64, root *felt.Felt) ethereum.Log {
+	t.Helper()
+
+	h := sha3.NewLegacyKeccak256()
+	_, err := h.Write([]byte("LogStateUpdate(uint256,int256,uint256)"))
+	require.NoError(t, err)
+
+	rootBytes := root.Bytes()
+	data := make([]byte, 96)
+	copy(data[:32], rootBytes[:])
+	binary.BigEndian.PutUint64(data[56:64], uint64(blockNumber))
+	if blockNumber < 0 {
+		for i := 32; i < 56; i++ {
+			data[i] = 0xff
+		}
+	}
+
+	return ethereum.Log{
+		Address:     utils.MAINNET.CoreContractAddress(),
+		Topics:      []common.Hash{common.BytesToHash(h.Sum(nil))},
+		Data:        data,
+		BlockNumber: hexutil.Uint64(l1Block),
+	}
+}
+
+func TestVerifier(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET, utils.NewNopZapLogger())
+	const numBlocks = 3
+	headers := make([]*core.Header, numBlocks)
+	for i := uint64(0); i < numBlocks; i++ {
+		block, err := gw.BlockByNumber(context.Background(), i)
+		require.NoError(t, err)
+		su, err := gw.StateUpdate(context.Background(), i)
+		require.NoError(t, err)
+
+		classes := make(map[felt.Felt]core.Class)
+		for _, classHash := range su.StateDiff.ClassHashes() {
+			classes[*classHash], err = gw.Class(context.Background(), classHash)
+			require.NoError(t, err)
+		}
+
+		block.Status = core.BlockAcceptedOnL2
+		require.NoError(t, chain.Store(block, su, classes))
+		headers[i] = block.Header
+	}
+
+	node := new(ethereum.TestNode)
+	ethClient, closeEth := ethereum.NewTestClient(node)
+	t.Cleanup(closeEth)
+
+	verify := func(t *testing.T) error {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		verifier := l1.NewVerifier(ethClient, chain, utils.NewNopZapLogger()).WithPollInterval(50 * time.Millisecond)
+		return verifier.Run(ctx)
+	}
+
+	checkStatuses := func(t *testing.T, expected ...core.BlockStatus) {
+		t.Helper()
+		for i, status := range expected {
+			header, err := chain.BlockHeaderByNumber(uint64(i))
+			require.NoError(t, err)
+			assert.Equal(t, status, header.Status, "block %d", i)
+		}
+	}
+
+	t.Run("no state updates on L1", func(t *testing.T) {
+		node.AddLog(logStateUpdate(t, 1_000, -1, new(felt.Felt)))
+		require.NoError(t, verify(t))
+		checkStatuses(t, core.BlockAcceptedOnL2, core.BlockAcceptedOnL2, core.BlockAcceptedOnL2)
+	})
+
+	t.Run("blocks up to the state update are accepted on L1", func(t *testing.T) {
+		node.AddLog(logStateUpdate(t, 2_000, 0, headers[0].GlobalStateRoot))
+		node.AddLog(logStateUpdate(t, 3_000, 1, headers[1].GlobalStateRoot))
+		require.NoError(t, verify(t))
+		checkStatuses(t, core.BlockAcceptedOnL1, core.BlockAcceptedOnL1, core.BlockAcceptedOnL2)
+	})
+
+	t.Run("state root mismatch", func(t *testing.T) {
+		node.AddLog(logStateUpdate(t, 4_000, 2, new(felt.Felt).SetUint64(1337)))
+		require.ErrorIs(t, verify(t), l1.ErrStateRootMismatch)
+		checkStatuses(t, core.BlockAcceptedOnL1, core.BlockAcceptedOnL1, core.BlockAcceptedOnL2)
+	})
+
+	t.Run("block is not synced yet", func(t *testing.T) {
+		node.AddLog(logStateUpdate(t, 5_000, 3, new(felt.Felt).SetUint64(1337)))
+		require.NoError(t, verify(t))
+		checkStatuses(t, core.BlockAcceptedOnL1, core.BlockAcceptedOnL1, core.BlockAcceptedOnL2)
+	})
+
+	t.Run("state update beyond the initial lookback", func(t *testing.T) {
+		node.AddLog(logStateUpdate(t, 6_000, 2, headers[2].GlobalStateRoot))
+		node.SetHead(500_000)
+		require.NoError(t, verify(t))
+		checkStatuses(t, core.BlockAcceptedOnL1, core.BlockAcceptedOnL1, core.BlockAcceptedOnL2)
+	})
+
+	t.Run("state update found by following L1", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		defer cancel()
+		verifier := l1.NewVerifier(ethClient, chain, utils.NewNopZapLogger()).WithPollInterval(50 * time.Millisecond)
+		// the state update of block 2 is followed by the one of a block that is not synced yet
+		logs := []ethereum.Log{
+			logStateUpdate(t, 500_001, 2, headers[2].GlobalStateRoot),
+			logStateUpdate(t, 500_002, 3, new(felt.Felt).SetUint64(1337)),
+		}
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			for _, log := range logs {
+				node.AddLog(log)
+			}
+		}()
+		require.NoError(t, verifier.Run(ctx))
+		checkStatuses(t, core.BlockAcceptedOnL1, core.BlockAcceptedOnL1, core.BlockAcceptedOnL1)
+	})
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/NethermindEth/juno/service"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var _ service.Service = (*Metrics)(nil)
+
+// Metrics serves the metrics that a Prometheus gatherer collects at /metrics, in the Prometheus
+// exposition format.
+type Metrics struct {
+	log      utils.SimpleLogger
+	server   *http.Server
+	listener net.Listener
+}
+
+func New(port uint16, gatherer prometheus.Gatherer, log utils.SimpleLogger) *Metrics {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+	server := &http.Server{
+		Addr:              "localhost:" + strconv.Itoa(int(port)),
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	return &Metrics{
+		server: server,
+		log:    log,
+	}
+}
+
+// Listen starts listening for connections, so that the address is known before the server runs, e.g. when
+// the port is 0. Run listens by itself if Listen was not called.
+func (m *Metrics) Listen() (net.Addr, error) {
+	listener, err := net.Listen("tcp", m.server.Addr)
+	if err != nil {
+		return nil, err
+	}
+	m.listener = listener
+	return listener.Addr(), nil
+}
+
+func (m *Metrics) Run(ctx context.Context) error {
+	if m.listener == nil {
+		if _, err := m.Listen(); err != nil {
+			return err
+		}
+	}
+
+	go func() {
+		m.log.Infow("Starting metrics server...", "address", m.listener.Addr().String())
+		if err := m.server.Serve(m.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			m.log.Errorw("Metrics server error", "err", err)
+		}
+	}()
+
+	<-ctx.Done()
+	return m.server.Shutdown(context.Background())
+}
@@ -0,0 +1,56 @@
+package metrics_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/juno/metrics"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsServer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_counter_total", Help: "A test counter."})
+	registry.MustRegister(counter)
+	counter.Add(3)
+
+	server := metrics.New(0, registry, utils.NewNopZapLogger())
+	addr, err := server.Listen()
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	done := make(chan error)
+	go func() {
+		done <- server.Run(ctx)
+	}()
+
+	url := fmt.Sprintf("http://localhost:%d/metrics", addr.(*net.TCPAddr).Port)
+	var body string
+	require.Eventually(t, func() bool {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+		require.NoError(t, err)
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return false
+		}
+		defer res.Body.Close()
+
+		read, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		body = string(read)
+		return res.StatusCode == http.StatusOK
+	}, 5*time.Second, 100*time.Millisecond)
+	assert.Contains(t, body, "test_counter_total 3")
+
+	cancel()
+	require.NoError(t, <-done)
+}
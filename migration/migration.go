@@ -0,0 +1,243 @@
+// Package migration brings databases that were written by older versions of Juno up to date with the
+// current layout of the data, before the node starts using them.
+package migration
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
+	"github.com/NethermindEth/juno/encoder"
+	"github.com/NethermindEth/juno/utils"
+)
+
+// migration brings a database up to date with one change of the layout of the data. A migration may be
+// interrupted, so it must be able to resume from the progress it committed, or to start over.
+type migration func(database db.DB, network utils.Network, log utils.SimpleLogger) error
+
+// migrations are applied in order, a database records the number of them that were applied to it. New
+// migrations are only ever appended.
+var migrations = []migration{
+	backfillHistory,
+	declaredClasses,
+}
+
+// batchSize is the number of blocks or keys that a migration handles in one transaction.
+const batchSize = 1000
+
+// MigrateIfNeeded applies the migrations that were not applied to the database yet. An empty database
+// does not need any of them.
+func MigrateIfNeeded(database db.DB, network utils.Network, log utils.SimpleLogger) error {
+	version, err := SchemaVersion(database)
+	if err != nil {
+		return err
+	}
+	if version > uint64(len(migrations)) {
+		return fmt.Errorf("the database schema version %d is newer than the latest known one, %d", version, len(migrations))
+	}
+
+	empty, err := isEmpty(database)
+	if err != nil {
+		return err
+	}
+	if empty {
+		return setSchemaVersion(database, uint64(len(migrations)))
+	}
+
+	for ; version < uint64(len(migrations)); version++ {
+		log.Infow("Migrating the database", "from", version, "to", version+1)
+		if err = migrations[version](database, network, log); err != nil {
+			return fmt.Errorf("migration %d: %w", version+1, err)
+		}
+		if err = clearScratch(database); err != nil {
+			return err
+		}
+		if err = setSchemaVersion(database, version+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SchemaVersion returns the number of migrations that were applied to the database.
+func SchemaVersion(database db.DB) (uint64, error) {
+	var version uint64
+	err := database.View(func(txn db.Transaction) error {
+		return txn.Get(db.SchemaVersion.Key(), func(val []byte) error {
+			version = binary.BigEndian.Uint64(val)
+			return nil
+		})
+	})
+	if errors.Is(err, db.ErrKeyNotFound) {
+		return 0, nil
+	}
+	return version, err
+}
+
+func setSchemaVersion(database db.DB, version uint64) error {
+	return database.Update(func(txn db.Transaction) error {
+		return txn.Set(db.SchemaVersion.Key(), uint64Bytes(version))
+	})
+}
+
+// isEmpty returns whether no block was stored in the database.
+func isEmpty(database db.DB) (bool, error) {
+	err := database.View(func(txn db.Transaction) error {
+		return txn.Get(db.ChainHeight.Key(), func([]byte) error {
+			return nil
+		})
+	})
+	if errors.Is(err, db.ErrKeyNotFound) {
+		return true, nil
+	}
+	return false, err
+}
+
+// progress returns the progress that the migration in progress committed, or zero if it did not commit
+// any yet.
+func progress(txn db.Transaction) (uint64, error) {
+	var next uint64
+	err := txn.Get(db.MigrationScratch.Key(), func(val []byte) error {
+		next = binary.BigEndian.Uint64(val)
+		return nil
+	})
+	if errors.Is(err, db.ErrKeyNotFound) {
+		return 0, nil
+	}
+	return next, err
+}
+
+func setProgress(txn db.Transaction, next uint64) error {
+	return txn.Set(db.MigrationScratch.Key(), uint64Bytes(next))
+}
+
+// clearScratch deletes the intermediate data of the migration that completed.
+func clearScratch(database db.DB) error {
+	prefix := db.MigrationScratch.Key()
+	for {
+		var keys [][]byte
+		if err := database.View(func(txn db.Transaction) error {
+			it, err := txn.NewIterator()
+			if err != nil {
+				return err
+			}
+			for it.Seek(prefix); it.Valid() && bytes.HasPrefix(it.Key(), prefix) && len(keys) < batchSize; it.Next() {
+				keys = append(keys, append([]byte{}, it.Key()...))
+			}
+			return it.Close()
+		}); err != nil {
+			return err
+		}
+
+		if len(keys) == 0 {
+			return nil
+		}
+		if err := database.Update(func(txn db.Transaction) error {
+			for _, key := range keys {
+				if err := txn.Delete(key); err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+// backfillHistory records the history of the contract values for the blocks that were stored before the
+// history was, which the queries of the state at past blocks and the reverts of blocks rely on.
+func backfillHistory(database db.DB, network utils.Network, log utils.SimpleLogger) error {
+	return forEachStateUpdate(database, network, log, func(txn db.Transaction, number uint64, update *core.StateUpdate) error {
+		return core.BackfillHistory(txn, number, update.StateDiff)
+	})
+}
+
+// declaredClasses stores the classes, which were stored on their own, along with the number of the block
+// that first referenced them, which is the block they are reverted with.
+func declaredClasses(database db.DB, network utils.Network, log utils.SimpleLogger) error {
+	return forEachStateUpdate(database, network, log, func(txn db.Transaction, number uint64, update *core.StateUpdate) error {
+		for _, classHash := range update.StateDiff.ClassHashes() {
+			if err := declareClass(txn, classHash, number); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// forEachStateUpdate calls fn with the stored state updates in order, batchSize of them per transaction,
+// resuming after the last batch that was committed.
+func forEachStateUpdate(database db.DB, network utils.Network, log utils.SimpleLogger,
+	fn func(txn db.Transaction, number uint64, update *core.StateUpdate) error,
+) error {
+	chain := blockchain.New(database, network, log)
+	height, err := chain.Height()
+	if err != nil {
+		return err
+	}
+
+	for {
+		var next uint64
+		if err = database.Update(func(txn db.Transaction) error {
+			if next, err = progress(txn); err != nil {
+				return err
+			}
+			last := next + batchSize
+			for ; next <= height && next < last; next++ {
+				update, suErr := chain.StateUpdateByNumber(next)
+				if suErr != nil {
+					return suErr
+				}
+				if err = fn(txn, next, update); err != nil {
+					return err
+				}
+			}
+			return setProgress(txn, next)
+		}); err != nil {
+			return err
+		}
+
+		log.Infow("Migrated blocks", "count", next, "height", height)
+		if next > height {
+			return nil
+		}
+	}
+}
+
+// declareClass stores the class with the given hash along with the given block number, unless it is
+// missing or already stored along with one.
+func declareClass(txn db.Transaction, classHash *felt.Felt, blockNumber uint64) error {
+	key := db.Class.Key(classHash.Marshal())
+	var class core.Class
+	err := txn.Get(key, func(val []byte) error {
+		// a class on its own decodes into a declared class without one, as its fields are unknown to it
+		var declared core.DeclaredClass
+		if encoder.UnmarshalWithJSONMaps(val, &declared) == nil && declared.Class != nil {
+			return nil
+		}
+		return encoder.UnmarshalWithJSONMaps(val, &class)
+	})
+	if errors.Is(err, db.ErrKeyNotFound) || (err == nil && class == nil) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("class %s: %w", classHash, err)
+	}
+
+	encoded, err := encoder.Marshal(core.DeclaredClass{At: blockNumber, Class: class})
+	if err != nil {
+		return err
+	}
+	return txn.Set(key, encoded)
+}
+
+func uint64Bytes(n uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, n)
+	return b
+}
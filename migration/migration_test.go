@@ -0,0 +1,188 @@
+package migration_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/clients/feeder"
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
+	"github.com/NethermindEth/juno/db/pebble"
+	"github.com/NethermindEth/juno/encoder"
+	"github.com/NethermindEth/juno/migration"
+	adaptfeeder "github.com/NethermindEth/juno/starknetdata/feeder"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// storeBlocks stores the first blocks of mainnet.
+func storeBlocks(t *testing.T, chain *blockchain.Blockchain, count uint64) {
+	t.Helper()
+
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+	ctx := context.Background()
+	for number := uint64(0); number < count; number++ {
+		block, err := gw.BlockByNumber(ctx, number)
+		require.NoError(t, err)
+		update, err := gw.StateUpdate(ctx, number)
+		require.NoError(t, err)
+		classes := make(map[felt.Felt]core.Class)
+		for _, classHash := range update.StateDiff.ClassHashes() {
+			classes[*classHash], err = gw.Class(ctx, classHash)
+			require.NoError(t, err)
+		}
+		require.NoError(t, chain.Store(block, update, classes))
+	}
+}
+
+// bucketEntries returns the entries of the given buckets.
+func bucketEntries(t *testing.T, database db.DB, buckets ...db.Bucket) map[string][]byte {
+	t.Helper()
+
+	entries := make(map[string][]byte)
+	require.NoError(t, database.View(func(txn db.Transaction) error {
+		it, err := txn.NewIterator()
+		if err != nil {
+			return err
+		}
+		for _, bucket := range buckets {
+			prefix := bucket.Key()
+			for it.Seek(prefix); it.Valid() && bytes.HasPrefix(it.Key(), prefix); it.Next() {
+				value, vErr := it.Value()
+				if vErr != nil {
+					return db.CloseAndWrapOnError(it.Close, vErr)
+				}
+				entries[string(it.Key())] = append([]byte{}, value...)
+			}
+		}
+		return it.Close()
+	}))
+	return entries
+}
+
+func deleteEntries(t *testing.T, database db.DB, entries map[string][]byte) {
+	t.Helper()
+
+	require.NoError(t, database.Update(func(txn db.Transaction) error {
+		for key := range entries {
+			if err := txn.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+func TestMigrateIfNeeded(t *testing.T) {
+	log := utils.NewNopZapLogger()
+
+	t.Run("empty database", func(t *testing.T) {
+		database := pebble.NewMemTest()
+		require.NoError(t, migration.MigrateIfNeeded(database, utils.MAINNET, log))
+
+		version, err := migration.SchemaVersion(database)
+		require.NoError(t, err)
+		assert.NotZero(t, version)
+	})
+
+	t.Run("database of a newer version", func(t *testing.T) {
+		database := pebble.NewMemTest()
+		require.NoError(t, migration.MigrateIfNeeded(database, utils.MAINNET, log))
+		version, err := migration.SchemaVersion(database)
+		require.NoError(t, err)
+
+		newer := make([]byte, 8)
+		binary.BigEndian.PutUint64(newer, version+1)
+		require.NoError(t, database.Update(func(txn db.Transaction) error {
+			return txn.Set(db.SchemaVersion.Key(), newer)
+		}))
+		assert.Error(t, migration.MigrateIfNeeded(database, utils.MAINNET, log))
+	})
+}
+
+func TestBackfillHistory(t *testing.T) {
+	log := utils.NewNopZapLogger()
+	database := pebble.NewMemTest()
+	chain := blockchain.New(database, utils.MAINNET, log)
+	storeBlocks(t, chain, 3)
+
+	historyBuckets := []db.Bucket{
+		db.ContractNonceHistory, db.ContractClassHashHistory, db.ContractStorageHistory, db.ContractDeploymentHeight,
+	}
+	history := bucketEntries(t, database, historyBuckets...)
+	require.NotEmpty(t, history)
+
+	// a database of a version without the history has neither the history nor a schema version
+	deleteEntries(t, database, history)
+	deleteEntries(t, database, bucketEntries(t, database, db.SchemaVersion))
+
+	require.NoError(t, migration.MigrateIfNeeded(database, utils.MAINNET, log))
+	assert.Equal(t, history, bucketEntries(t, database, historyBuckets...))
+	assert.Empty(t, bucketEntries(t, database, db.MigrationScratch))
+
+	version, err := migration.SchemaVersion(database)
+	require.NoError(t, err)
+	assert.NotZero(t, version)
+
+	t.Run("the backfilled history reverts blocks", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			require.NoError(t, chain.RevertHead())
+		}
+		_, err := chain.Height()
+		assert.ErrorIs(t, err, db.ErrKeyNotFound)
+	})
+}
+
+func TestDeclaredClasses(t *testing.T) {
+	log := utils.NewNopZapLogger()
+	database := pebble.NewMemTest()
+	chain := blockchain.New(database, utils.MAINNET, log)
+	storeBlocks(t, chain, 3)
+	require.NoError(t, migration.MigrateIfNeeded(database, utils.MAINNET, log))
+
+	classes := bucketEntries(t, database, db.Class)
+	require.NotEmpty(t, classes)
+
+	// a database of a version that stored the classes on their own, after the one that had no history
+	require.NoError(t, database.Update(func(txn db.Transaction) error {
+		for key, value := range classes {
+			var declared core.DeclaredClass
+			if err := encoder.Unmarshal(value, &declared); err != nil {
+				return err
+			}
+			encoded, err := encoder.Marshal(declared.Class)
+			if err != nil {
+				return err
+			}
+			if err = txn.Set([]byte(key), encoded); err != nil {
+				return err
+			}
+		}
+
+		historyVersion := make([]byte, 8)
+		binary.BigEndian.PutUint64(historyVersion, 1)
+		return txn.Set(db.SchemaVersion.Key(), historyVersion)
+	}))
+
+	require.NoError(t, migration.MigrateIfNeeded(database, utils.MAINNET, log))
+	require.NoError(t, database.View(func(txn db.Transaction) error {
+		state := core.NewState(txn)
+		for key, value := range classes {
+			var want core.DeclaredClass
+			require.NoError(t, encoder.UnmarshalWithJSONMaps(value, &want))
+
+			classHash := new(felt.Felt).SetBytes([]byte(key)[len(db.Class.Key()):])
+			got, err := state.Class(classHash)
+			require.NoError(t, err)
+			assert.Equal(t, &want, got)
+		}
+		return nil
+	}))
+}
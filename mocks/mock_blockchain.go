@@ -7,8 +7,10 @@ package mocks
 import (
 	reflect "reflect"
 
+	blockchain "github.com/NethermindEth/juno/blockchain"
 	core "github.com/NethermindEth/juno/core"
 	felt "github.com/NethermindEth/juno/core/felt"
+	trie "github.com/NethermindEth/juno/core/trie"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -95,6 +97,22 @@ func (mr *MockReaderMockRecorder) BlockHeaderByNumber(arg0 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockHeaderByNumber", reflect.TypeOf((*MockReader)(nil).BlockHeaderByNumber), arg0)
 }
 
+// Events mocks base method.
+func (m *MockReader) Events(arg0 *blockchain.EventFilter, arg1 *blockchain.ContinuationToken, arg2 uint64) ([]*blockchain.FilteredEvent, *blockchain.ContinuationToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Events", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*blockchain.FilteredEvent)
+	ret1, _ := ret[1].(*blockchain.ContinuationToken)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Events indicates an expected call of Events.
+func (mr *MockReaderMockRecorder) Events(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Events", reflect.TypeOf((*MockReader)(nil).Events), arg0, arg1, arg2)
+}
+
 // Head mocks base method.
 func (m *MockReader) Head() (*core.Block, error) {
 	m.ctrl.T.Helper()
@@ -110,6 +128,37 @@ func (mr *MockReaderMockRecorder) Head() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Head", reflect.TypeOf((*MockReader)(nil).Head))
 }
 
+// HeadContractStorage mocks base method.
+func (m *MockReader) HeadContractStorage(arg0, arg1 *felt.Felt, arg2 trie.LeafFunc) (*core.Header, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HeadContractStorage", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*core.Header)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HeadContractStorage indicates an expected call of HeadContractStorage.
+func (mr *MockReaderMockRecorder) HeadContractStorage(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HeadContractStorage", reflect.TypeOf((*MockReader)(nil).HeadContractStorage), arg0, arg1, arg2)
+}
+
+// HeadState mocks base method.
+func (m *MockReader) HeadState() (core.StateReader, func() error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HeadState")
+	ret0, _ := ret[0].(core.StateReader)
+	ret1, _ := ret[1].(func() error)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// HeadState indicates an expected call of HeadState.
+func (mr *MockReaderMockRecorder) HeadState() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HeadState", reflect.TypeOf((*MockReader)(nil).HeadState))
+}
+
 // HeadsHeader mocks base method.
 func (m *MockReader) HeadsHeader() (*core.Header, error) {
 	m.ctrl.T.Helper()
@@ -140,6 +189,37 @@ func (mr *MockReaderMockRecorder) Height() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Height", reflect.TypeOf((*MockReader)(nil).Height))
 }
 
+// Pending mocks base method.
+func (m *MockReader) Pending() (*blockchain.Pending, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pending")
+	ret0, _ := ret[0].(*blockchain.Pending)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Pending indicates an expected call of Pending.
+func (mr *MockReaderMockRecorder) Pending() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pending", reflect.TypeOf((*MockReader)(nil).Pending))
+}
+
+// PendingState mocks base method.
+func (m *MockReader) PendingState() (core.StateReader, func() error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PendingState")
+	ret0, _ := ret[0].(core.StateReader)
+	ret1, _ := ret[1].(func() error)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// PendingState indicates an expected call of PendingState.
+func (mr *MockReaderMockRecorder) PendingState() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingState", reflect.TypeOf((*MockReader)(nil).PendingState))
+}
+
 // Receipt mocks base method.
 func (m *MockReader) Receipt(arg0 *felt.Felt) (*core.TransactionReceipt, *felt.Felt, uint64, error) {
 	m.ctrl.T.Helper()
@@ -157,6 +237,54 @@ func (mr *MockReaderMockRecorder) Receipt(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Receipt", reflect.TypeOf((*MockReader)(nil).Receipt), arg0)
 }
 
+// StateAtBlockHash mocks base method.
+func (m *MockReader) StateAtBlockHash(arg0 *felt.Felt) (core.StateReader, func() error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StateAtBlockHash", arg0)
+	ret0, _ := ret[0].(core.StateReader)
+	ret1, _ := ret[1].(func() error)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// StateAtBlockHash indicates an expected call of StateAtBlockHash.
+func (mr *MockReaderMockRecorder) StateAtBlockHash(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateAtBlockHash", reflect.TypeOf((*MockReader)(nil).StateAtBlockHash), arg0)
+}
+
+// StateAtBlockNumber mocks base method.
+func (m *MockReader) StateAtBlockNumber(arg0 uint64) (core.StateReader, func() error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StateAtBlockNumber", arg0)
+	ret0, _ := ret[0].(core.StateReader)
+	ret1, _ := ret[1].(func() error)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// StateAtBlockNumber indicates an expected call of StateAtBlockNumber.
+func (mr *MockReaderMockRecorder) StateAtBlockNumber(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateAtBlockNumber", reflect.TypeOf((*MockReader)(nil).StateAtBlockNumber), arg0)
+}
+
+// StateProof mocks base method.
+func (m *MockReader) StateProof(arg0 uint64, arg1 *felt.Felt, arg2 []*felt.Felt) (*core.StateProof, *core.Header, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StateProof", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*core.StateProof)
+	ret1, _ := ret[1].(*core.Header)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// StateProof indicates an expected call of StateProof.
+func (mr *MockReaderMockRecorder) StateProof(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateProof", reflect.TypeOf((*MockReader)(nil).StateProof), arg0, arg1, arg2)
+}
+
 // StateUpdateByHash mocks base method.
 func (m *MockReader) StateUpdateByHash(arg0 *felt.Felt) (*core.StateUpdate, error) {
 	m.ctrl.T.Helper()
@@ -51,6 +51,51 @@ func (mr *MockStarknetDataMockRecorder) BlockByNumber(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockByNumber", reflect.TypeOf((*MockStarknetData)(nil).BlockByNumber), arg0, arg1)
 }
 
+// BlockLatest mocks base method.
+func (m *MockStarknetData) BlockLatest(arg0 context.Context) (*core.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockLatest", arg0)
+	ret0, _ := ret[0].(*core.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockLatest indicates an expected call of BlockLatest.
+func (mr *MockStarknetDataMockRecorder) BlockLatest(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockLatest", reflect.TypeOf((*MockStarknetData)(nil).BlockLatest), arg0)
+}
+
+// BlockPending mocks base method.
+func (m *MockStarknetData) BlockPending(arg0 context.Context) (*core.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockPending", arg0)
+	ret0, _ := ret[0].(*core.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockPending indicates an expected call of BlockPending.
+func (mr *MockStarknetDataMockRecorder) BlockPending(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockPending", reflect.TypeOf((*MockStarknetData)(nil).BlockPending), arg0)
+}
+
+// BlockSignature mocks base method.
+func (m *MockStarknetData) BlockSignature(arg0 context.Context, arg1 uint64) (*core.BlockSignature, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockSignature", arg0, arg1)
+	ret0, _ := ret[0].(*core.BlockSignature)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockSignature indicates an expected call of BlockSignature.
+func (mr *MockStarknetDataMockRecorder) BlockSignature(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockSignature", reflect.TypeOf((*MockStarknetData)(nil).BlockSignature), arg0, arg1)
+}
+
 // Class mocks base method.
 func (m *MockStarknetData) Class(arg0 context.Context, arg1 *felt.Felt) (core.Class, error) {
 	m.ctrl.T.Helper()
@@ -66,6 +111,21 @@ func (mr *MockStarknetDataMockRecorder) Class(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Class", reflect.TypeOf((*MockStarknetData)(nil).Class), arg0, arg1)
 }
 
+// CompiledClass mocks base method.
+func (m *MockStarknetData) CompiledClass(arg0 context.Context, arg1 *felt.Felt) (*core.CompiledClass, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompiledClass", arg0, arg1)
+	ret0, _ := ret[0].(*core.CompiledClass)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompiledClass indicates an expected call of CompiledClass.
+func (mr *MockStarknetDataMockRecorder) CompiledClass(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompiledClass", reflect.TypeOf((*MockStarknetData)(nil).CompiledClass), arg0, arg1)
+}
+
 // StateUpdate mocks base method.
 func (m *MockStarknetData) StateUpdate(arg0 context.Context, arg1 uint64) (*core.StateUpdate, error) {
 	m.ctrl.T.Helper()
@@ -81,6 +141,21 @@ func (mr *MockStarknetDataMockRecorder) StateUpdate(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateUpdate", reflect.TypeOf((*MockStarknetData)(nil).StateUpdate), arg0, arg1)
 }
 
+// StateUpdatePending mocks base method.
+func (m *MockStarknetData) StateUpdatePending(arg0 context.Context) (*core.StateUpdate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StateUpdatePending", arg0)
+	ret0, _ := ret[0].(*core.StateUpdate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StateUpdatePending indicates an expected call of StateUpdatePending.
+func (mr *MockStarknetDataMockRecorder) StateUpdatePending(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateUpdatePending", reflect.TypeOf((*MockStarknetData)(nil).StateUpdatePending), arg0)
+}
+
 // Transaction mocks base method.
 func (m *MockStarknetData) Transaction(arg0 context.Context, arg1 *felt.Felt) (core.Transaction, error) {
 	m.ctrl.T.Helper()
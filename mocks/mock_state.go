@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/NethermindEth/juno/core (interfaces: StateReader)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	core "github.com/NethermindEth/juno/core"
+	felt "github.com/NethermindEth/juno/core/felt"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockStateReader is a mock of StateReader interface.
+type MockStateReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockStateReaderMockRecorder
+}
+
+// MockStateReaderMockRecorder is the mock recorder for MockStateReader.
+type MockStateReaderMockRecorder struct {
+	mock *MockStateReader
+}
+
+// NewMockStateReader creates a new mock instance.
+func NewMockStateReader(ctrl *gomock.Controller) *MockStateReader {
+	mock := &MockStateReader{ctrl: ctrl}
+	mock.recorder = &MockStateReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStateReader) EXPECT() *MockStateReaderMockRecorder {
+	return m.recorder
+}
+
+// Class mocks base method.
+func (m *MockStateReader) Class(arg0 *felt.Felt) (*core.DeclaredClass, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Class", arg0)
+	ret0, _ := ret[0].(*core.DeclaredClass)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Class indicates an expected call of Class.
+func (mr *MockStateReaderMockRecorder) Class(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Class", reflect.TypeOf((*MockStateReader)(nil).Class), arg0)
+}
+
+// ContractClassHash mocks base method.
+func (m *MockStateReader) ContractClassHash(arg0 *felt.Felt) (*felt.Felt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ContractClassHash", arg0)
+	ret0, _ := ret[0].(*felt.Felt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ContractClassHash indicates an expected call of ContractClassHash.
+func (mr *MockStateReaderMockRecorder) ContractClassHash(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContractClassHash", reflect.TypeOf((*MockStateReader)(nil).ContractClassHash), arg0)
+}
+
+// ContractNonce mocks base method.
+func (m *MockStateReader) ContractNonce(arg0 *felt.Felt) (*felt.Felt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ContractNonce", arg0)
+	ret0, _ := ret[0].(*felt.Felt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ContractNonce indicates an expected call of ContractNonce.
+func (mr *MockStateReaderMockRecorder) ContractNonce(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContractNonce", reflect.TypeOf((*MockStateReader)(nil).ContractNonce), arg0)
+}
+
+// ContractStorage mocks base method.
+func (m *MockStateReader) ContractStorage(arg0, arg1 *felt.Felt) (*felt.Felt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ContractStorage", arg0, arg1)
+	ret0, _ := ret[0].(*felt.Felt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ContractStorage indicates an expected call of ContractStorage.
+func (mr *MockStateReaderMockRecorder) ContractStorage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContractStorage", reflect.TypeOf((*MockStateReader)(nil).ContractStorage), arg0, arg1)
+}
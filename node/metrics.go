@@ -0,0 +1,195 @@
+package node
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/clients/feeder"
+	"github.com/NethermindEth/juno/db"
+	"github.com/NethermindEth/juno/jsonrpc"
+	"github.com/NethermindEth/juno/sync"
+	"github.com/cockroachdb/pebble"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const metricsNamespace = "juno"
+
+// makeMetrics makes the components of the node report their metrics to a new registry.
+func makeMetrics(database db.DB, chain *blockchain.Blockchain, synchronizer *sync.Synchronizer,
+	rpcServer *jsonrpc.Server, clients []*feeder.Client,
+) *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		&syncCollector{chain: chain, synchronizer: synchronizer},
+	)
+	if pebbleDB, ok := database.Impl().(*pebble.DB); ok {
+		registry.MustRegister(&pebbleCollector{db: pebbleDB})
+	}
+
+	synchronizer.WithListener(makeSyncMetrics(registry))
+	rpcServer.WithListener(makeJSONRPCMetrics(registry))
+	feederListener := makeFeederMetrics(registry)
+	for _, client := range clients {
+		client.WithListener(feederListener)
+	}
+	return registry
+}
+
+func makeSyncMetrics(registerer prometheus.Registerer) sync.EventListener {
+	stepDuration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "sync",
+		Name:      "step_duration_seconds",
+		Help:      "How long the steps of syncing a block take.",
+	}, []string{"op"})
+	registerer.MustRegister(stepDuration)
+
+	return &sync.SelectiveListener{
+		OnSyncStepDoneCb: func(op string, _ uint64, took time.Duration) {
+			stepDuration.WithLabelValues(op).Observe(took.Seconds())
+		},
+	}
+}
+
+func makeJSONRPCMetrics(registerer prometheus.Registerer) jsonrpc.EventListener {
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "rpc",
+		Name:      "requests_total",
+		Help:      "The number of requests per method.",
+	}, []string{"method"})
+	failedRequests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "rpc",
+		Name:      "failed_requests_total",
+		Help:      "The number of failed requests per method and error code.",
+	}, []string{"method", "code"})
+	requestDuration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "rpc",
+		Name:      "request_duration_seconds",
+		Help:      "How long the handlers of the methods take.",
+	}, []string{"method"})
+	registerer.MustRegister(requests, failedRequests, requestDuration)
+
+	return &jsonrpc.SelectiveListener{
+		OnNewRequestCb: func(method string) {
+			requests.WithLabelValues(method).Inc()
+		},
+		OnRequestHandledCb: func(method string, took time.Duration) {
+			requestDuration.WithLabelValues(method).Observe(took.Seconds())
+		},
+		OnRequestFailedCb: func(method string, err *jsonrpc.Error) {
+			failedRequests.WithLabelValues(method, strconv.Itoa(err.Code)).Inc()
+		},
+	}
+}
+
+func makeFeederMetrics(registerer prometheus.Registerer) feeder.EventListener {
+	responses := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "feeder",
+		Name:      "responses_total",
+		Help:      "The number of responses of the feeder per endpoint and status code, 0 if there was no response.",
+	}, []string{"endpoint", "status"})
+	retries := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "feeder",
+		Name:      "retries_total",
+		Help:      "The number of retried requests to the feeder per endpoint.",
+	}, []string{"endpoint"})
+	requestDuration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "feeder",
+		Name:      "request_duration_seconds",
+		Help:      "How long the requests to the feeder take.",
+	}, []string{"endpoint"})
+	registerer.MustRegister(responses, retries, requestDuration)
+
+	return &feeder.SelectiveListener{
+		OnResponseCb: func(endpoint string, status int, took time.Duration) {
+			responses.WithLabelValues(endpoint, strconv.Itoa(status)).Inc()
+			requestDuration.WithLabelValues(endpoint).Observe(took.Seconds())
+		},
+		OnRetryCb: func(endpoint string) {
+			retries.WithLabelValues(endpoint).Inc()
+		},
+	}
+}
+
+var (
+	blockchainHeightDesc = prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "sync", "blockchain_height"),
+		"The number of the latest stored block.", nil, nil)
+	highestBlockDesc = prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "sync", "highest_block_number"),
+		"The number of the latest block known to the network.", nil, nil)
+	blocksFetchedDesc = prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "sync", "blocks_fetched_total"),
+		"The number of fetched blocks, including the ones that were fetched again after a rollback.", nil, nil)
+	blocksVerifiedDesc = prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "sync", "blocks_verified_total"),
+		"The number of verified and stored blocks.", nil, nil)
+	rollbacksDesc = prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "sync", "rollbacks_total"),
+		"The number of times that syncing restarted from the local head.", nil, nil)
+)
+
+// syncCollector collects the progress of the synchronizer when the metrics are gathered.
+type syncCollector struct {
+	chain        *blockchain.Blockchain
+	synchronizer *sync.Synchronizer
+}
+
+func (c *syncCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{blockchainHeightDesc, highestBlockDesc, blocksFetchedDesc, blocksVerifiedDesc, rollbacksDesc} {
+		ch <- desc
+	}
+}
+
+func (c *syncCollector) Collect(ch chan<- prometheus.Metric) {
+	// the heights are only reported once they are known
+	if height, err := c.chain.Height(); err == nil {
+		ch <- prometheus.MustNewConstMetric(blockchainHeightDesc, prometheus.GaugeValue, float64(height))
+	}
+	if highest := c.synchronizer.HighestBlockHeader(); highest != nil {
+		ch <- prometheus.MustNewConstMetric(highestBlockDesc, prometheus.GaugeValue, float64(highest.Number))
+	}
+
+	stats := c.synchronizer.Stats()
+	ch <- prometheus.MustNewConstMetric(blocksFetchedDesc, prometheus.CounterValue, float64(stats.BlocksFetched))
+	ch <- prometheus.MustNewConstMetric(blocksVerifiedDesc, prometheus.CounterValue, float64(stats.BlocksVerified))
+	ch <- prometheus.MustNewConstMetric(rollbacksDesc, prometheus.CounterValue, float64(stats.Rollbacks))
+}
+
+var (
+	compactionsDesc = prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "db", "compactions_total"),
+		"The number of compactions of the database.", nil, nil)
+	diskUsageDesc = prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "db", "disk_usage_bytes"),
+		"The disk space that the database takes.", nil, nil)
+	cacheSizeDesc = prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "db", "block_cache_size_bytes"),
+		"The size of the block cache of the database.", nil, nil)
+	cacheHitsDesc = prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "db", "block_cache_hits_total"),
+		"The number of reads that were served by the block cache of the database.", nil, nil)
+	cacheMissesDesc = prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "db", "block_cache_misses_total"),
+		"The number of reads that missed the block cache of the database.", nil, nil)
+)
+
+// pebbleCollector collects the stats of a pebble database when the metrics are gathered.
+type pebbleCollector struct {
+	db *pebble.DB
+}
+
+func (c *pebbleCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{compactionsDesc, diskUsageDesc, cacheSizeDesc, cacheHitsDesc, cacheMissesDesc} {
+		ch <- desc
+	}
+}
+
+func (c *pebbleCollector) Collect(ch chan<- prometheus.Metric) {
+	m := c.db.Metrics()
+	ch <- prometheus.MustNewConstMetric(compactionsDesc, prometheus.CounterValue, float64(m.Compact.Count))
+	ch <- prometheus.MustNewConstMetric(diskUsageDesc, prometheus.GaugeValue, float64(m.DiskSpaceUsage()))
+	ch <- prometheus.MustNewConstMetric(cacheSizeDesc, prometheus.GaugeValue, float64(m.BlockCache.Size))
+	ch <- prometheus.MustNewConstMetric(cacheHitsDesc, prometheus.CounterValue, float64(m.BlockCache.Hits))
+	ch <- prometheus.MustNewConstMetric(cacheMissesDesc, prometheus.CounterValue, float64(m.BlockCache.Misses))
+}
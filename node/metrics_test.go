@@ -0,0 +1,97 @@
+package node
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/clients/feeder"
+	"github.com/NethermindEth/juno/db/pebble"
+	"github.com/NethermindEth/juno/jsonrpc"
+	adaptfeeder "github.com/NethermindEth/juno/starknetdata/feeder"
+	"github.com/NethermindEth/juno/sync"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	database := pebble.NewMemTest()
+	t.Cleanup(func() {
+		require.NoError(t, database.Close())
+	})
+	chain := blockchain.New(database, utils.MAINNET, utils.NewNopZapLogger())
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	synchronizer := sync.New(chain, adaptfeeder.New(client), utils.NewNopZapLogger())
+
+	rpcServer := jsonrpc.NewServer()
+	require.NoError(t, rpcServer.RegisterMethod(jsonrpc.Method{
+		Name:   "method",
+		Params: []jsonrpc.Parameter{{Name: "fail"}},
+		Handler: func(fail bool) (int, *jsonrpc.Error) {
+			if fail {
+				return 0, jsonrpc.Err(jsonrpc.InternalError, nil)
+			}
+			return 0, nil
+		},
+	}))
+
+	registry := makeMetrics(database, chain, synchronizer, rpcServer, []*feeder.Client{client})
+	count := func(t *testing.T, name string) int {
+		t.Helper()
+
+		n, err := testutil.GatherAndCount(registry, name)
+		require.NoError(t, err)
+		return n
+	}
+
+	t.Run("sync", func(t *testing.T) {
+		// nothing has been synced yet, so the height is not reported
+		assert.Equal(t, 0, count(t, "juno_sync_blockchain_height"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- synchronizer.Run(ctx)
+		}()
+		require.Eventually(t, func() bool {
+			height, err := chain.Height()
+			return err == nil && height >= 2
+		}, 30*time.Second, 10*time.Millisecond)
+		cancel()
+		require.NoError(t, <-done)
+
+		assert.Equal(t, 1, count(t, "juno_sync_blockchain_height"))
+		assert.Equal(t, 1, count(t, "juno_sync_rollbacks_total"))
+		assert.Equal(t, 3, count(t, "juno_sync_step_duration_seconds"))
+		assert.Positive(t, count(t, "juno_feeder_responses_total"))
+	})
+
+	t.Run("db", func(t *testing.T) {
+		assert.Equal(t, 1, count(t, "juno_db_disk_usage_bytes"))
+		assert.Equal(t, 1, count(t, "juno_db_block_cache_hits_total"))
+	})
+
+	t.Run("rpc", func(t *testing.T) {
+		for _, params := range []string{`[false]`, `[true]`, `[true]`} {
+			_, err := rpcServer.Handle([]byte(`{"jsonrpc":"2.0","method":"method","params":` + params + `,"id":1}`))
+			require.NoError(t, err)
+		}
+
+		expected := `
+# HELP juno_rpc_requests_total The number of requests per method.
+# TYPE juno_rpc_requests_total counter
+juno_rpc_requests_total{method="method"} 3
+# HELP juno_rpc_failed_requests_total The number of failed requests per method and error code.
+# TYPE juno_rpc_failed_requests_total counter
+juno_rpc_failed_requests_total{code="-32603",method="method"} 2
+`
+		require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected),
+			"juno_rpc_requests_total", "juno_rpc_failed_requests_total"))
+		assert.Equal(t, 1, count(t, "juno_rpc_request_duration_seconds"))
+	})
+}
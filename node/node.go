@@ -2,35 +2,79 @@ package node
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"reflect"
+	"time"
 
 	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/clients/ethereum"
 	"github.com/NethermindEth/juno/clients/feeder"
+	"github.com/NethermindEth/juno/clients/starknet"
 	"github.com/NethermindEth/juno/db"
 	"github.com/NethermindEth/juno/db/pebble"
 	"github.com/NethermindEth/juno/jsonrpc"
+	"github.com/NethermindEth/juno/l1"
+	"github.com/NethermindEth/juno/metrics"
+	"github.com/NethermindEth/juno/migration"
+	"github.com/NethermindEth/juno/p2p"
 	"github.com/NethermindEth/juno/pprof"
 	"github.com/NethermindEth/juno/rpc"
 	"github.com/NethermindEth/juno/service"
+	"github.com/NethermindEth/juno/starknetdata"
 	adaptfeeder "github.com/NethermindEth/juno/starknetdata/feeder"
+	"github.com/NethermindEth/juno/starknetdata/multi"
+	adaptrpc "github.com/NethermindEth/juno/starknetdata/rpc"
 	"github.com/NethermindEth/juno/sync"
 	"github.com/NethermindEth/juno/utils"
 	"github.com/sourcegraph/conc"
 )
 
 const (
-	defaultPprofPort = uint16(9080)
+	defaultPprofPort           = uint16(9080)
+	defaultPendingPollInterval = 5 * time.Second
+	defaultStatusPollInterval  = time.Minute
+	defaultLatestPollInterval  = 10 * time.Second
 )
 
 // Config is the top-level juno configuration.
 type Config struct {
 	LogLevel     utils.LogLevel `mapstructure:"log-level"`
 	RPCPort      uint16         `mapstructure:"rpc-port"`
+	WS           bool           `mapstructure:"ws"`
+	WSPort       uint16         `mapstructure:"ws-port"`
 	DatabasePath string         `mapstructure:"db-path"`
 	Network      utils.Network  `mapstructure:"network"`
 	Pprof        bool           `mapstructure:"pprof"`
+	Metrics      bool           `mapstructure:"metrics"`
+	MetricsPort  uint16         `mapstructure:"metrics-port"`
+
+	RepairClasses    bool   `mapstructure:"repair-classes"`
+	EthNode          string `mapstructure:"eth-node"`
+	VerifySignatures bool   `mapstructure:"verify-signatures"`
+	ReplayDir        string `mapstructure:"replay-dir"`
+	RecordDir        string `mapstructure:"record-dir"`
+
+	Upstreams       []Upstream    `mapstructure:"upstreams"`
+	UpstreamQuorum  int           `mapstructure:"upstream-quorum"`
+	UpstreamTimeout time.Duration `mapstructure:"upstream-timeout"`
+
+	P2P      bool     `mapstructure:"p2p"`
+	P2PPort  uint16   `mapstructure:"p2p-port"`
+	P2PPeers []string `mapstructure:"p2p-peers"`
+}
+
+// Upstream is a source of blocks that is synced from instead of the feeder gateway of the network: either a
+// feeder gateway, or the JSON-RPC endpoint of another Starknet node, such as another Juno.
+//
+// The JSON-RPC API does not serve all the data of the blocks, so an RPC upstream is only synced from if
+// AllowIncompleteBlocks accepts that the blocks are stored without the protocol version and the execution
+// resources of the transactions.
+type Upstream struct {
+	FeederURL             string `mapstructure:"feeder-url"`
+	RPCURL                string `mapstructure:"rpc-url"`
+	AllowIncompleteBlocks bool   `mapstructure:"allow-incomplete-blocks"`
 }
 
 type Node struct {
@@ -45,6 +89,15 @@ type Node struct {
 // New sets the config and logger to the StarknetNode.
 // Any errors while parsing the config on creating logger will be returned.
 func New(cfg *Config) (*Node, error) {
+	if cfg.EthNode != "" && cfg.Network.Custom() != nil {
+		return nil, errors.New("custom networks can not be verified against L1")
+	}
+	if cfg.VerifySignatures && cfg.Network.PublicKey() == nil {
+		return nil, fmt.Errorf("the public key of the %s sequencer is not known, so signatures can not be verified", cfg.Network)
+	}
+	if err := checkUpstreams(cfg); err != nil {
+		return nil, err
+	}
 	if cfg.DatabasePath == "" {
 		dirPrefix, err := utils.DefaultDataDir()
 		if err != nil {
@@ -62,12 +115,44 @@ func New(cfg *Config) (*Node, error) {
 	}, nil
 }
 
-func makeHTTP(port uint16, rpcHandler *rpc.Handler, log utils.SimpleLogger) *jsonrpc.HTTP {
-	return jsonrpc.NewHTTP(port, []jsonrpc.Method{
+// checkUpstreams checks that the upstreams can be synced from.
+func checkUpstreams(cfg *Config) error {
+	if len(cfg.Upstreams) > 0 && cfg.ReplayDir != "" {
+		return errors.New("upstreams can not be synced from while replaying")
+	}
+	for _, upstream := range cfg.Upstreams {
+		if (upstream.FeederURL == "") == (upstream.RPCURL == "") {
+			return errors.New("upstreams must have either a feeder URL or an RPC URL")
+		}
+		if upstream.RPCURL == "" {
+			continue
+		}
+		// JSON-RPC does not serve block signatures
+		if cfg.VerifySignatures {
+			return errors.New("signatures can not be verified when syncing from an RPC URL")
+		}
+		if !upstream.AllowIncompleteBlocks {
+			return fmt.Errorf("%s does not serve the protocol versions of the blocks or the execution resources of the "+
+				"transactions, allow incomplete blocks to sync from it", upstream.RPCURL)
+		}
+	}
+	if cfg.UpstreamQuorum > 1 && cfg.UpstreamQuorum > len(cfg.Upstreams) {
+		return fmt.Errorf("upstream quorum of %d is more than the %d upstreams", cfg.UpstreamQuorum, len(cfg.Upstreams))
+	}
+	return nil
+}
+
+func makeRPCServer(rpcHandler *rpc.Handler) (*jsonrpc.Server, error) {
+	server := jsonrpc.NewServer()
+	return server, server.RegisterMethods([]jsonrpc.Method{
 		{
 			Name:    "starknet_chainId",
 			Handler: rpcHandler.ChainID,
 		},
+		{
+			Name:    "starknet_syncing",
+			Handler: rpcHandler.Syncing,
+		},
 		{
 			Name:    "starknet_blockNumber",
 			Handler: rpcHandler.BlockNumber,
@@ -111,7 +196,105 @@ func makeHTTP(port uint16, rpcHandler *rpc.Handler, log utils.SimpleLogger) *jso
 			Params:  []jsonrpc.Parameter{{Name: "block_id"}},
 			Handler: rpcHandler.StateUpdate,
 		},
-	}, log)
+		{
+			Name:    "starknet_getStorageAt",
+			Params:  []jsonrpc.Parameter{{Name: "contract_address"}, {Name: "key"}, {Name: "block_id"}},
+			Handler: rpcHandler.StorageAt,
+		},
+		{
+			Name:    "starknet_getNonce",
+			Params:  []jsonrpc.Parameter{{Name: "block_id"}, {Name: "contract_address"}},
+			Handler: rpcHandler.Nonce,
+		},
+		{
+			Name:    "starknet_getClassHashAt",
+			Params:  []jsonrpc.Parameter{{Name: "block_id"}, {Name: "contract_address"}},
+			Handler: rpcHandler.ClassHashAt,
+		},
+		{
+			Name:    "starknet_getClass",
+			Params:  []jsonrpc.Parameter{{Name: "block_id"}, {Name: "class_hash"}},
+			Handler: rpcHandler.Class,
+		},
+		{
+			Name:    "starknet_getClassAt",
+			Params:  []jsonrpc.Parameter{{Name: "block_id"}, {Name: "contract_address"}},
+			Handler: rpcHandler.ClassAt,
+		},
+		{
+			Name:    "starknet_getEvents",
+			Params:  []jsonrpc.Parameter{{Name: "filter"}},
+			Handler: rpcHandler.Events,
+		},
+		{
+			Name:    "juno_getProof",
+			Params:  []jsonrpc.Parameter{{Name: "block_id"}, {Name: "contract_address"}, {Name: "keys"}},
+			Handler: rpcHandler.Proof,
+		},
+		{
+			Name:    "juno_getStorageRange",
+			Params:  []jsonrpc.Parameter{{Name: "contract_address"}, {Name: "start_key", Optional: true}, {Name: "limit"}},
+			Handler: rpcHandler.StorageRange,
+		},
+		{
+			Name:    "juno_syncStatus",
+			Handler: rpcHandler.SyncStatus,
+		},
+		{
+			Name:    "juno_subscribeNewHeads",
+			Handler: rpcHandler.SubscribeNewHeads,
+		},
+		{
+			Name:    "juno_subscribeEvents",
+			Params:  []jsonrpc.Parameter{{Name: "filter"}},
+			Handler: rpcHandler.SubscribeEvents,
+		},
+		{
+			Name:    "juno_subscribeTransactionStatus",
+			Params:  []jsonrpc.Parameter{{Name: "transaction_hash"}},
+			Handler: rpcHandler.SubscribeTransactionStatus,
+		},
+		{
+			Name:    "juno_unsubscribe",
+			Params:  []jsonrpc.Parameter{{Name: "subscription_id"}},
+			Handler: rpcHandler.Unsubscribe,
+		},
+	}...)
+}
+
+// makeStarknetData returns the source that blocks are synced from, along with the feeder clients that it
+// requests: the feeder gateway of the network, the configured upstreams, or the recorded responses of a
+// feeder gateway when replaying. Only the responses of feeder gateways are recorded.
+func (n *Node) makeStarknetData() (starknetdata.StarknetData, []*feeder.Client) {
+	var clients []*feeder.Client
+	var upstreams []starknetdata.StarknetData
+	addFeeder := func(client *feeder.Client) {
+		if n.cfg.RecordDir != "" {
+			client.WithRecorder(n.cfg.RecordDir)
+		}
+		clients = append(clients, client)
+		upstreams = append(upstreams, adaptfeeder.New(client))
+	}
+
+	switch {
+	case n.cfg.ReplayDir != "":
+		addFeeder(feeder.NewDirectoryClient(n.cfg.ReplayDir))
+	case len(n.cfg.Upstreams) > 0:
+		for _, upstream := range n.cfg.Upstreams {
+			if upstream.RPCURL != "" {
+				upstreams = append(upstreams, adaptrpc.New(starknet.NewClient(upstream.RPCURL)))
+			} else {
+				addFeeder(feeder.NewClient(upstream.FeederURL))
+			}
+		}
+	default:
+		addFeeder(feeder.NewClient(n.cfg.Network.URL()))
+	}
+
+	if len(upstreams) == 1 {
+		return upstreams[0], clients
+	}
+	return multi.New(upstreams, n.log).WithQuorum(n.cfg.UpstreamQuorum).WithTimeout(n.cfg.UpstreamTimeout), clients
 }
 
 // Run starts Juno node by opening the DB, initialising services.
@@ -138,27 +321,82 @@ func (n *Node) Run(ctx context.Context) {
 		}
 	}()
 
+	if err = migration.MigrateIfNeeded(n.db, n.cfg.Network, n.log); err != nil {
+		n.log.Errorw("Error migrating the DB", "err", err)
+		return
+	}
+
 	n.blockchain = blockchain.New(n.db, n.cfg.Network, n.log)
 
-	client := feeder.NewClient(n.cfg.Network.URL())
-	synchronizer := sync.New(n.blockchain, adaptfeeder.New(client), n.log)
+	starknetData, clients := n.makeStarknetData()
+	var p2pService *p2p.Service
+	if n.cfg.P2P {
+		p2pService = p2p.New(fmt.Sprintf(":%d", n.cfg.P2PPort), n.cfg.P2PPeers, n.blockchain, n.log)
+		// peers are not trusted, they are only asked for the blocks when the other sources fail
+		starknetData = multi.New([]starknetdata.StarknetData{starknetData, p2pService}, n.log).
+			WithTimeout(n.cfg.UpstreamTimeout).
+			WithStrictOrder()
+	}
+	synchronizer := sync.New(n.blockchain, starknetData, n.log).
+		WithPendingPolling(defaultPendingPollInterval).
+		WithLatestPolling(defaultLatestPollInterval)
+	// block statuses are taken from the feeder unless they can be verified against L1
+	if n.cfg.EthNode == "" {
+		synchronizer.WithStatusPolling(defaultStatusPollInterval)
+	}
+	if n.cfg.VerifySignatures {
+		synchronizer.WithSignatureVerification(n.cfg.Network.PublicKey())
+	}
 
-	http := makeHTTP(n.cfg.RPCPort, rpc.New(n.blockchain, n.cfg.Network), n.log)
+	if n.cfg.RepairClasses {
+		if err = synchronizer.RepairMissingClasses(ctx); err != nil {
+			n.log.Errorw("Error repairing missing classes", "err", err)
+			return
+		}
+	}
 
-	n.services = []service.Service{synchronizer, http}
+	rpcHandler := rpc.New(n.blockchain, n.cfg.Network, n.log).WithSyncReader(synchronizer)
+	rpcServer, err := makeRPCServer(rpcHandler)
+	if err != nil {
+		n.log.Errorw("Error registering RPC methods", "err", err)
+		return
+	}
+
+	n.services = []service.Service{
+		synchronizer,
+		jsonrpc.NewHTTP(n.cfg.RPCPort, rpcServer, n.log),
+		rpc.NewNotifier(rpcHandler, n.blockchain),
+	}
+
+	if n.cfg.WS {
+		n.services = append(n.services, jsonrpc.NewWebsocket(n.cfg.WSPort, rpcServer, n.log))
+	}
+
+	if n.cfg.EthNode != "" {
+		n.services = append(n.services, l1.NewVerifier(ethereum.NewClient(n.cfg.EthNode), n.blockchain, n.log))
+	}
+
+	if n.cfg.P2P {
+		n.services = append(n.services, p2pService)
+	}
 
 	if n.cfg.Pprof {
 		n.services = append(n.services, pprof.New(defaultPprofPort, n.log))
 	}
 
+	if n.cfg.Metrics {
+		registry := makeMetrics(n.db, n.blockchain, synchronizer, rpcServer, clients)
+		n.services = append(n.services, metrics.New(n.cfg.MetricsPort, registry, n.log))
+	}
+
 	ctx, cancel := context.WithCancel(ctx)
 
 	wg := conc.NewWaitGroup()
 	for _, s := range n.services {
 		s := s
 		wg.Go(func() {
-			if err = s.Run(ctx); err != nil {
-				n.log.Errorw("Service error", "name", reflect.TypeOf(s), "err", err)
+			if runErr := s.Run(ctx); runErr != nil {
+				n.log.Errorw("Service error", "name", reflect.TypeOf(s), "err", runErr)
 				cancel()
 			}
 		})
@@ -1,10 +1,17 @@
 package node_test
 
 import (
+	"context"
+	"fmt"
+	"net"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/NethermindEth/juno/clients/starknet"
+	"github.com/NethermindEth/juno/core/felt"
 	"github.com/NethermindEth/juno/node"
+	"github.com/NethermindEth/juno/rpc"
 	"github.com/NethermindEth/juno/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -30,3 +37,160 @@ func TestDefaultDbPath(t *testing.T) {
 		})
 	}
 }
+
+func TestCustomNetworkWithL1Verification(t *testing.T) {
+	n, err := utils.NewCustomNetwork(&utils.CustomNetwork{
+		Name:      "devnet",
+		FeederURL: "http://localhost:5050/feeder_gateway/",
+		ChainID:   "SN_DEVNET",
+	})
+	require.NoError(t, err)
+
+	_, err = node.New(&node.Config{Network: n, DatabasePath: t.TempDir(), EthNode: "http://localhost:8545"})
+	assert.Error(t, err)
+}
+
+func TestSignatureVerificationWithoutPublicKey(t *testing.T) {
+	_, err := node.New(&node.Config{Network: utils.GOERLI, DatabasePath: t.TempDir(), VerifySignatures: true})
+	assert.Error(t, err)
+
+	_, err = node.New(&node.Config{Network: utils.MAINNET, DatabasePath: t.TempDir(), VerifySignatures: true})
+	assert.NoError(t, err)
+}
+
+func TestUpstreams(t *testing.T) {
+	upstreams := []node.Upstream{
+		{FeederURL: "https://alpha-mainnet.starknet.io/"},
+		{FeederURL: "https://mirror.example.com/"},
+	}
+	rpcUpstream := node.Upstream{RPCURL: "http://localhost:6060", AllowIncompleteBlocks: true}
+
+	tests := map[string]struct {
+		cfg       node.Config
+		expectErr bool
+	}{
+		"quorum of the upstreams": {
+			cfg: node.Config{Upstreams: upstreams, UpstreamQuorum: 2},
+		},
+		"quorum of more than the upstreams": {
+			cfg:       node.Config{Upstreams: upstreams, UpstreamQuorum: 3},
+			expectErr: true,
+		},
+		"upstream without a URL": {
+			cfg:       node.Config{Upstreams: []node.Upstream{{}}},
+			expectErr: true,
+		},
+		"upstream with both a feeder and an RPC URL": {
+			cfg:       node.Config{Upstreams: []node.Upstream{{FeederURL: "https://alpha-mainnet.starknet.io/", RPCURL: "http://localhost:6060"}}},
+			expectErr: true,
+		},
+		"feeder and RPC upstreams": {
+			cfg: node.Config{Upstreams: append([]node.Upstream{rpcUpstream}, upstreams...)},
+		},
+		"RPC upstream with signature verification": {
+			cfg:       node.Config{Upstreams: []node.Upstream{rpcUpstream}, VerifySignatures: true},
+			expectErr: true,
+		},
+		"RPC upstream that does not allow incomplete blocks": {
+			cfg:       node.Config{Upstreams: []node.Upstream{{RPCURL: "http://localhost:6060"}}},
+			expectErr: true,
+		},
+		"upstreams while replaying": {
+			cfg:       node.Config{Upstreams: upstreams, ReplayDir: t.TempDir()},
+			expectErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tc.cfg.DatabasePath = t.TempDir()
+			_, err := node.New(&tc.cfg)
+			if tc.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSyncFromAnotherNode(t *testing.T) {
+	// run runs a node with the given config, and returns the URL of its JSON-RPC endpoint
+	run := func(t *testing.T, cfg *node.Config) string {
+		t.Helper()
+
+		listener, err := net.Listen("tcp", "localhost:0")
+		require.NoError(t, err)
+		cfg.RPCPort = uint16(listener.Addr().(*net.TCPAddr).Port)
+		require.NoError(t, listener.Close())
+
+		cfg.LogLevel = utils.ERROR
+		cfg.Network = utils.MAINNET
+		cfg.DatabasePath = t.TempDir()
+		snNode, err := node.New(cfg)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			snNode.Run(ctx)
+		}()
+		t.Cleanup(func() {
+			cancel()
+			<-done
+		})
+		return fmt.Sprintf("http://localhost:%d", cfg.RPCPort)
+	}
+
+	// the source replays the feeder test data, which has the first three blocks
+	sourceURL := run(t, &node.Config{ReplayDir: filepath.Join("..", "clients", "feeder", "testdata", "mainnet")})
+	targetURL := run(t, &node.Config{Upstreams: []node.Upstream{{RPCURL: sourceURL, AllowIncompleteBlocks: true}}})
+	source, target := starknet.NewClient(sourceURL), starknet.NewClient(targetURL)
+
+	ctx := context.Background()
+	const head = 2
+	require.Eventually(t, func() bool {
+		block, err := target.BlockWithTxs(ctx, &rpc.BlockID{Latest: true})
+		return err == nil && *block.Number == head
+	}, time.Minute, 100*time.Millisecond)
+
+	for number := uint64(0); number <= head; number++ {
+		id := &rpc.BlockID{Number: number}
+
+		expectedBlock, err := source.BlockWithTxs(ctx, id)
+		require.NoError(t, err)
+		block, err := target.BlockWithTxs(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, expectedBlock, block)
+
+		hashes := make([]*felt.Felt, len(block.Transactions))
+		for i, txn := range block.Transactions {
+			hashes[i] = txn.Hash
+		}
+		expectedReceipts, err := source.TransactionReceipts(ctx, hashes)
+		require.NoError(t, err)
+		receipts, err := target.TransactionReceipts(ctx, hashes)
+		require.NoError(t, err)
+		assert.Equal(t, expectedReceipts, receipts)
+
+		expectedUpdate, err := source.StateUpdate(ctx, id)
+		require.NoError(t, err)
+		update, err := target.StateUpdate(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, expectedUpdate.NewRoot, update.NewRoot)
+		// the diffs are listed in no particular order
+		assert.ElementsMatch(t, expectedUpdate.StateDiff.StorageDiffs, update.StateDiff.StorageDiffs)
+		assert.ElementsMatch(t, expectedUpdate.StateDiff.Nonces, update.StateDiff.Nonces)
+		assert.ElementsMatch(t, expectedUpdate.StateDiff.DeployedContracts, update.StateDiff.DeployedContracts)
+
+		for _, deployed := range update.StateDiff.DeployedContracts {
+			expectedClass, err := source.Class(ctx, id, deployed.ClassHash)
+			require.NoError(t, err)
+			class, err := target.Class(ctx, id, deployed.ClassHash)
+			require.NoError(t, err)
+			assert.Equal(t, expectedClass, class)
+		}
+	}
+}
@@ -0,0 +1,544 @@
+// Package p2p lets Juno nodes get blocks, state diffs and classes from each other instead of from the feeder
+// gateway. Nodes connect over TCP, learn of more nodes from the ones they are connected to, and poll them
+// for their head, so that new blocks spread from node to node as each of them syncs.
+package p2p
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/service"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/sourcegraph/conc"
+)
+
+var _ service.Service = (*Service)(nil)
+
+const (
+	defaultMaxPeers          = 16
+	defaultDiscoveryInterval = 10 * time.Second
+	defaultRequestTimeout    = 10 * time.Second
+	defaultIdleTimeout       = time.Minute
+	defaultBanDuration       = 10 * time.Minute
+
+	// maxInboundPerPeer bounds the connections that are accepted, as a multiple of the outbound ones.
+	maxInboundPerPeer = 4
+	// maxKnownPeers bounds the addresses that are remembered.
+	maxKnownPeers = 256
+)
+
+var errRemote = errors.New("peer failed the request")
+
+// Service connects to other nodes, serves their requests for the blocks of the local chain, and provides
+// the blocks of the other nodes as a [starknetdata.StarknetData].
+//
+// Only the node that dialed a connection makes requests over it. Nodes that are dialed learn the address
+// that the dialer listens on and dial it back, so both of them get blocks from each other.
+type Service struct {
+	listenAddr string
+	bootPeers  []string
+	chain      *blockchain.Blockchain
+	chainID    *felt.Felt
+	nodeID     uint64
+
+	listener   net.Listener
+	listenPort uint16
+
+	mu    sync.Mutex
+	peers map[string]*peer // the connections that this node dialed, by address
+	// known holds the addresses that are dialed when there are fewer peers than maxPeers
+	known   map[string]struct{}
+	banned  map[string]time.Time
+	inbound int
+	// headChanged is closed and replaced whenever the head of a peer changes
+	headChanged chan struct{}
+
+	rangesMu sync.Mutex
+	ranges   map[uint64]*blockRange // the fetched ranges, by their first block
+	fetching map[uint64]*blockRange // the ranges that are being fetched, by their first block
+
+	maxPeers          int
+	rangeSize         uint64
+	discoveryInterval time.Duration
+	requestTimeout    time.Duration
+	idleTimeout       time.Duration
+	banDuration       time.Duration
+
+	log utils.SimpleLogger
+}
+
+// New returns a Service that listens on the given address and starts off by dialing the boot peers.
+func New(listenAddr string, bootPeers []string, chain *blockchain.Blockchain, log utils.SimpleLogger) *Service {
+	var nodeID [8]byte
+	if _, err := rand.Read(nodeID[:]); err != nil {
+		panic(err)
+	}
+
+	return &Service{
+		listenAddr:        listenAddr,
+		bootPeers:         bootPeers,
+		chain:             chain,
+		chainID:           chain.Network().ChainID(),
+		nodeID:            binary.BigEndian.Uint64(nodeID[:]),
+		peers:             make(map[string]*peer),
+		known:             make(map[string]struct{}),
+		banned:            make(map[string]time.Time),
+		headChanged:       make(chan struct{}),
+		ranges:            make(map[uint64]*blockRange),
+		fetching:          make(map[uint64]*blockRange),
+		maxPeers:          defaultMaxPeers,
+		rangeSize:         defaultRangeSize,
+		discoveryInterval: defaultDiscoveryInterval,
+		requestTimeout:    defaultRequestTimeout,
+		idleTimeout:       defaultIdleTimeout,
+		banDuration:       defaultBanDuration,
+		log:               log,
+	}
+}
+
+// WithDiscoveryInterval sets how often new peers are dialed and the heads of the peers are polled.
+func (s *Service) WithDiscoveryInterval(interval time.Duration) *Service {
+	s.discoveryInterval = interval
+	return s
+}
+
+// WithRequestTimeout sets how long a peer is waited for before it is dropped.
+func (s *Service) WithRequestTimeout(timeout time.Duration) *Service {
+	s.requestTimeout = timeout
+	return s
+}
+
+// WithIdleTimeout sets how long a node that dialed this one may go without making a request before it is
+// dropped.
+func (s *Service) WithIdleTimeout(timeout time.Duration) *Service {
+	s.idleTimeout = timeout
+	return s
+}
+
+// Listen starts listening for connections, so that the address is known before the Service runs. Run
+// listens by itself if Listen was not called.
+func (s *Service) Listen() (net.Addr, error) {
+	listener, err := net.Listen("tcp", s.listenAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	tcpAddr, ok := listener.Addr().(*net.TCPAddr)
+	if !ok {
+		return nil, fmt.Errorf("not a TCP address: %s", listener.Addr())
+	}
+	s.listener = listener
+	s.listenPort = uint16(tcpAddr.Port)
+	return listener.Addr(), nil
+}
+
+// Run accepts connections from other nodes and dials the known ones until the context is cancelled.
+func (s *Service) Run(ctx context.Context) error {
+	if s.listener == nil {
+		if _, err := s.Listen(); err != nil {
+			return err
+		}
+	}
+	s.learn(s.bootPeers...)
+
+	wg := conc.NewWaitGroup()
+	wg.Go(func() {
+		s.accept(ctx, wg)
+	})
+	wg.Go(func() {
+		s.discoverLoop(ctx)
+	})
+
+	<-ctx.Done()
+	if err := s.listener.Close(); err != nil {
+		s.log.Debugw("Failed closing the p2p listener", "err", err)
+	}
+	s.mu.Lock()
+	for _, p := range s.peers {
+		p.close()
+	}
+	s.mu.Unlock()
+	wg.Wait()
+	return nil
+}
+
+func (s *Service) accept(ctx context.Context, wg *conc.WaitGroup) {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return
+			}
+			s.log.Debugw("Failed accepting a p2p connection", "err", err)
+			continue
+		}
+
+		s.mu.Lock()
+		full := s.inbound >= maxInboundPerPeer*s.maxPeers
+		if !full {
+			s.inbound++
+		}
+		s.mu.Unlock()
+		if full {
+			closeConn(conn, s.log)
+			continue
+		}
+
+		wg.Go(func() {
+			s.serve(ctx, conn)
+			s.mu.Lock()
+			s.inbound--
+			s.mu.Unlock()
+		})
+	}
+}
+
+// serve answers the requests that come over a connection that another node dialed.
+func (s *Service) serve(ctx context.Context, conn net.Conn) {
+	defer closeConn(conn, s.log)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			closeConn(conn, s.log)
+		case <-done:
+		}
+	}()
+
+	reader, writer := bufio.NewReader(conn), bufio.NewWriter(conn)
+	theirs, err := s.handshake(conn, reader, writer)
+	if err != nil {
+		s.log.Debugw("Rejected a p2p connection", "remote", conn.RemoteAddr(), "err", err)
+		return
+	}
+	if tcpAddr, ok := conn.RemoteAddr().(*net.TCPAddr); ok && theirs.ListenPort != 0 {
+		s.learn(net.JoinHostPort(tcpAddr.IP.String(), strconv.Itoa(int(theirs.ListenPort))))
+	}
+
+	for {
+		// the handshake cleared the deadline, the peer is only waited for until it becomes idle
+		if err = conn.SetReadDeadline(time.Now().Add(s.idleTimeout)); err != nil {
+			return
+		}
+		var req request
+		if err = readMessage(reader, &req, maxRequestSize); err != nil {
+			return
+		}
+
+		res := s.handle(&req)
+		if err = conn.SetWriteDeadline(time.Now().Add(s.requestTimeout)); err != nil {
+			return
+		}
+		if err = writeMessage(writer, res, maxResponseSize); err != nil {
+			s.log.Debugw("Failed answering a peer", "remote", conn.RemoteAddr(), "err", err)
+			return
+		}
+	}
+}
+
+// handshake exchanges hellos over a new connection and returns the one of the peer.
+func (s *Service) handshake(conn net.Conn, reader *bufio.Reader, writer *bufio.Writer) (*hello, error) {
+	if err := conn.SetDeadline(time.Now().Add(s.requestTimeout)); err != nil {
+		return nil, err
+	}
+
+	// both sides write first, the hellos are small enough to be buffered
+	if err := writeMessage(writer, &hello{
+		Version:    protocolVersion,
+		ChainID:    s.chainID,
+		NodeID:     s.nodeID,
+		ListenPort: s.listenPort,
+	}, maxHelloSize); err != nil {
+		return nil, err
+	}
+	var theirs hello
+	if err := readMessage(reader, &theirs, maxHelloSize); err != nil {
+		return nil, err
+	}
+	if err := theirs.check(s.chainID, s.nodeID); err != nil {
+		return nil, err
+	}
+	return &theirs, conn.SetDeadline(time.Time{})
+}
+
+func (s *Service) discoverLoop(ctx context.Context) {
+	ticker := time.NewTicker(s.discoveryInterval)
+	defer ticker.Stop()
+
+	for {
+		s.discover(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// discover dials the known addresses while there are fewer peers than maxPeers, then asks every peer
+// for its head and for the addresses it knows.
+func (s *Service) discover(ctx context.Context) {
+	for _, addr := range s.candidates() {
+		p, err := s.dial(ctx, addr)
+		if err != nil {
+			if errors.Is(err, errIncompatible) {
+				s.ban(addr, err)
+			} else {
+				s.log.Debugw("Failed dialing a peer", "addr", addr, "err", err)
+			}
+			continue
+		}
+		if !s.addPeer(p) {
+			p.close()
+		}
+	}
+
+	wg := conc.NewWaitGroup()
+	for _, p := range s.connected() {
+		p := p
+		wg.Go(func() {
+			res, err := s.ask(ctx, p, &request{Kind: kindStatus})
+			if err != nil {
+				return
+			}
+			s.setHead(p, res.Head)
+
+			if res, err = s.ask(ctx, p, &request{Kind: kindPeers}); err != nil {
+				return
+			}
+			s.learn(res.Peers...)
+		})
+	}
+	wg.Wait()
+}
+
+func (s *Service) dial(ctx context.Context, addr string) (*peer, error) {
+	dialer := net.Dialer{Timeout: s.requestTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := newPeer(addr, conn, s.log)
+	theirs, err := s.handshake(conn, p.reader, p.writer)
+	if err != nil {
+		p.close()
+		return nil, err
+	}
+	p.nodeID = theirs.NodeID
+	return p, nil
+}
+
+// ask makes a request to a peer, and drops the peer if it does not answer.
+func (s *Service) ask(ctx context.Context, p *peer, req *request) (*response, error) {
+	res, err := p.request(ctx, s.requestTimeout, req)
+	if err != nil && !errors.Is(err, errRemote) {
+		s.drop(p, err)
+	}
+	return res, err
+}
+
+// candidates returns the known addresses that are worth dialing.
+func (s *Service) candidates() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var addrs []string
+	for addr := range s.known {
+		if len(s.peers)+len(addrs) >= s.maxPeers {
+			break
+		}
+		if _, ok := s.peers[addr]; ok {
+			continue
+		}
+		if until, ok := s.banned[addr]; ok {
+			if time.Now().Before(until) {
+				continue
+			}
+			delete(s.banned, addr)
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+// learn remembers the given addresses of other nodes.
+func (s *Service) learn(addrs ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, addr := range addrs {
+		if len(s.known) >= maxKnownPeers {
+			return
+		}
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			continue
+		}
+		s.known[addr] = struct{}{}
+	}
+}
+
+// knownPeers returns up to maxPeers of the addresses that are not banned.
+func (s *Service) knownPeers() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var addrs []string
+	for addr := range s.known {
+		if len(addrs) >= s.maxPeers {
+			break
+		}
+		if _, ok := s.banned[addr]; !ok {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// addPeer keeps a new connection, unless there is one to the same node already.
+func (s *Service) addPeer(p *peer) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, other := range s.peers {
+		if other.nodeID == p.nodeID {
+			return false
+		}
+	}
+	s.peers[p.addr] = p
+	s.log.Debugw("Connected to a peer", "addr", p.addr)
+	return true
+}
+
+func (s *Service) connected() []*peer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	peers := make([]*peer, 0, len(s.peers))
+	for _, p := range s.peers {
+		peers = append(peers, p)
+	}
+	return peers
+}
+
+// drop closes the connection to a peer, which is dialed again by the next discovery.
+func (s *Service) drop(p *peer, err error) {
+	s.mu.Lock()
+	if s.peers[p.addr] == p {
+		delete(s.peers, p.addr)
+	}
+	s.mu.Unlock()
+
+	p.close()
+	s.log.Debugw("Dropped a peer", "addr", p.addr, "err", err)
+}
+
+// ban drops the peer at the given address, if connected, and keeps it from being dialed for banDuration.
+func (s *Service) ban(addr string, err error) {
+	s.mu.Lock()
+	p := s.peers[addr]
+	delete(s.peers, addr)
+	s.banned[addr] = time.Now().Add(s.banDuration)
+	s.mu.Unlock()
+
+	if p != nil {
+		p.close()
+	}
+	s.log.Warnw("Banned a peer", "addr", addr, "err", err)
+}
+
+func (s *Service) setHead(p *peer, head *uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if (p.head == nil) == (head == nil) && (head == nil || *p.head == *head) {
+		return
+	}
+	p.head = head
+	close(s.headChanged)
+	s.headChanged = make(chan struct{})
+}
+
+func closeConn(conn net.Conn, log utils.SimpleLogger) {
+	if err := conn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+		log.Debugw("Failed closing a p2p connection", "err", err)
+	}
+}
+
+// peer is a connection that this node dialed.
+type peer struct {
+	addr   string
+	nodeID uint64
+	head   *uint64 // guarded by the mutex of the Service
+
+	// mu makes sure that there is a single request in flight
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+	writer *bufio.Writer
+
+	log utils.SimpleLogger
+}
+
+func newPeer(addr string, conn net.Conn, log utils.SimpleLogger) *peer {
+	return &peer{
+		addr:   addr,
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+		writer: bufio.NewWriter(conn),
+		log:    log,
+	}
+}
+
+// request sends a request and waits for its response. The connection is closed if the context is
+// cancelled, since the response that is on the way can not be told apart from the next one.
+func (p *peer) request(ctx context.Context, timeout time.Duration, req *request) (*response, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			p.close()
+		case <-done:
+		}
+	}()
+
+	if err := p.conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+	err := writeMessage(p.writer, req, maxRequestSize)
+	var res response
+	if err == nil {
+		err = readMessage(p.reader, &res, maxResponseSize)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%s request to %s: %w", req.Kind, p.addr, err)
+	}
+
+	if res.Err != "" {
+		return nil, fmt.Errorf("%w: %s request to %s: %s", errRemote, req.Kind, p.addr, res.Err)
+	}
+	return &res, nil
+}
+
+func (p *peer) close() {
+	closeConn(p.conn, p.log)
+}
@@ -0,0 +1,394 @@
+package p2p
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/clients/feeder"
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db/pebble"
+	"github.com/NethermindEth/juno/starknetdata"
+	adaptfeeder "github.com/NethermindEth/juno/starknetdata/feeder"
+	"github.com/NethermindEth/juno/sync"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	head          = 2
+	testInterval  = 50 * time.Millisecond
+	testTimeout   = time.Second
+	testWaitLimit = 10 * time.Second
+)
+
+// newChain returns a chain of the first blocks of the network, or an empty one.
+func newChain(t *testing.T, network utils.Network, empty bool) *blockchain.Blockchain {
+	t.Helper()
+
+	chain := blockchain.New(pebble.NewMemTest(), network, utils.NewNopZapLogger())
+	if empty {
+		return chain
+	}
+
+	client, closeFn := feeder.NewTestClient(network)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+	ctx := context.Background()
+	for number := uint64(0); number <= head; number++ {
+		block, err := gw.BlockByNumber(ctx, number)
+		require.NoError(t, err)
+		update, err := gw.StateUpdate(ctx, number)
+		require.NoError(t, err)
+		classes := make(map[felt.Felt]core.Class)
+		for _, classHash := range update.StateDiff.ClassHashes() {
+			classes[*classHash], err = gw.Class(ctx, classHash)
+			require.NoError(t, err)
+		}
+		require.NoError(t, chain.Store(block, update, classes))
+	}
+	return chain
+}
+
+// runNode runs a Service on localhost until the test ends, and returns it along with its address.
+func runNode(t *testing.T, chain *blockchain.Blockchain, bootPeers ...string) (*Service, string) {
+	t.Helper()
+
+	s := New("127.0.0.1:0", bootPeers, chain, utils.NewNopZapLogger()).
+		WithDiscoveryInterval(testInterval).
+		WithRequestTimeout(testTimeout)
+	addr, err := s.Listen()
+	require.NoError(t, err)
+
+	runService(t, s.Run)
+	return s, addr.String()
+}
+
+func runService(t *testing.T, run func(context.Context) error) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, run(ctx))
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+// runTamperingPeer serves the given chain the way a Service would, except that tamper can change the
+// responses, and returns the address it listens on.
+func runTamperingPeer(t *testing.T, chain *blockchain.Blockchain, tamper func(*request, *response)) string {
+	t.Helper()
+
+	honest := New("", nil, chain, utils.NewNopZapLogger())
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, listener.Close())
+	})
+
+	go func() {
+		for {
+			conn, aErr := listener.Accept()
+			if aErr != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader, writer := bufio.NewReader(conn), bufio.NewWriter(conn)
+				ours := &hello{Version: protocolVersion, ChainID: honest.chainID, NodeID: honest.nodeID}
+				if writeMessage(writer, ours, maxHelloSize) != nil || readMessage(reader, new(hello), maxHelloSize) != nil {
+					return
+				}
+				for {
+					var req request
+					if readMessage(reader, &req, maxRequestSize) != nil {
+						return
+					}
+					res := honest.handle(&req)
+					tamper(&req, res)
+					if writeMessage(writer, res, maxResponseSize) != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func connectedTo(s *Service, addr string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.peers[addr]
+	return ok
+}
+
+func isBanned(s *Service, addr string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.banned[addr]
+	return ok
+}
+
+func TestSyncFromPeers(t *testing.T) {
+	source := newChain(t, utils.MAINNET, false)
+	_, sourceAddr := runNode(t, source)
+
+	// the second node only knows of the first one, and the third one only knows of the second one
+	var chains [2]*blockchain.Blockchain
+	var nodes [2]*Service
+	bootPeer := sourceAddr
+	for i := range nodes {
+		chains[i] = newChain(t, utils.MAINNET, true)
+		var addr string
+		nodes[i], addr = runNode(t, chains[i], bootPeer)
+		runService(t, sync.New(chains[i], nodes[i], utils.NewNopZapLogger()).Run)
+		bootPeer = addr
+	}
+
+	for _, chain := range chains {
+		chain := chain
+		require.Eventually(t, func() bool {
+			height, err := chain.Height()
+			return err == nil && height == head
+		}, testWaitLimit, testInterval)
+
+		for number := uint64(0); number <= head; number++ {
+			expected, err := source.BlockByNumber(number)
+			require.NoError(t, err)
+			block, err := chain.BlockByNumber(number)
+			require.NoError(t, err)
+			assert.Equal(t, expected, block)
+
+			expectedUpdate, err := source.StateUpdateByNumber(number)
+			require.NoError(t, err)
+			update, err := chain.StateUpdateByNumber(number)
+			require.NoError(t, err)
+			assert.Equal(t, expectedUpdate, update)
+		}
+	}
+
+	t.Run("peers are discovered through other peers", func(t *testing.T) {
+		assert.Eventually(t, func() bool {
+			return connectedTo(nodes[1], sourceAddr)
+		}, testWaitLimit, testInterval)
+	})
+
+	t.Run("classes", func(t *testing.T) {
+		update, err := source.StateUpdateByNumber(head)
+		require.NoError(t, err)
+		require.NotEmpty(t, update.StateDiff.ClassHashes())
+
+		state, closer, err := source.HeadState()
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, closer())
+		})
+
+		ctx := context.Background()
+		for _, classHash := range update.StateDiff.ClassHashes() {
+			expected, err := state.Class(classHash)
+			require.NoError(t, err)
+			class, err := nodes[1].Class(ctx, classHash)
+			require.NoError(t, err)
+			assert.Equal(t, expected.Class, class)
+
+			// the source only has Cairo 0 classes
+			_, err = nodes[1].CompiledClass(ctx, classHash)
+			assert.ErrorIs(t, err, starknetdata.ErrUnsupported)
+		}
+
+		_, err = nodes[1].Class(ctx, new(felt.Felt).SetUint64(1))
+		assert.ErrorIs(t, err, ErrNoPeer)
+	})
+
+	t.Run("latest block", func(t *testing.T) {
+		latest, err := nodes[1].BlockLatest(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, uint64(head), latest.Number)
+	})
+
+	t.Run("blocks that no peer has", func(t *testing.T) {
+		_, err := nodes[1].BlockByNumber(context.Background(), head+1)
+		assert.ErrorIs(t, err, ErrNoPeer)
+	})
+}
+
+func TestInvalidData(t *testing.T) {
+	source := newChain(t, utils.MAINNET, false)
+
+	tests := map[string]func(*request, *response){
+		"state root": func(req *request, res *response) {
+			if req.Kind == kindStateDiffs {
+				res.StateUpdates[head].NewRoot = new(felt.Felt).SetUint64(0xbad)
+			}
+		},
+		"transactions": func(req *request, res *response) {
+			if req.Kind == kindBodies {
+				res.Bodies[head].Transactions = res.Bodies[head].Transactions[1:]
+			}
+		},
+		"parent hash": func(req *request, res *response) {
+			if req.Kind == kindHeaders {
+				res.Headers[head].ParentHash = res.Headers[head-2].Hash
+			}
+		},
+		"block number": func(req *request, res *response) {
+			if req.Kind == kindHeaders {
+				res.Headers = res.Headers[1:]
+			}
+		},
+	}
+
+	for description, tamper := range tests {
+		tamper := tamper
+		t.Run(description, func(t *testing.T) {
+			badAddr := runTamperingPeer(t, source, tamper)
+			node, _ := runNode(t, newChain(t, utils.MAINNET, true), badAddr)
+
+			_, err := node.BlockByNumber(context.Background(), head)
+			require.ErrorIs(t, err, ErrNoPeer)
+			assert.True(t, isBanned(node, badAddr))
+			assert.False(t, connectedTo(node, badAddr))
+		})
+	}
+}
+
+func TestIncompatiblePeers(t *testing.T) {
+	_, mainnetAddr := runNode(t, newChain(t, utils.MAINNET, true))
+
+	t.Run("other chain", func(t *testing.T) {
+		node, _ := runNode(t, newChain(t, utils.GOERLI, true), mainnetAddr)
+		assert.Eventually(t, func() bool {
+			return isBanned(node, mainnetAddr)
+		}, testWaitLimit, testInterval)
+		assert.False(t, connectedTo(node, mainnetAddr))
+	})
+
+	t.Run("itself", func(t *testing.T) {
+		node := New("127.0.0.1:0", nil, newChain(t, utils.MAINNET, true), utils.NewNopZapLogger()).
+			WithDiscoveryInterval(testInterval).
+			WithRequestTimeout(testTimeout)
+		addr, err := node.Listen()
+		require.NoError(t, err)
+		node.bootPeers = []string{addr.String()}
+		runService(t, node.Run)
+
+		assert.Eventually(t, func() bool {
+			return isBanned(node, addr.String())
+		}, testWaitLimit, testInterval)
+	})
+}
+
+func TestHandle(t *testing.T) {
+	s := New("", []string{"127.0.0.1:1"}, newChain(t, utils.MAINNET, false), utils.NewNopZapLogger())
+	s.learn(s.bootPeers...)
+
+	t.Run("status", func(t *testing.T) {
+		res := s.handle(&request{Kind: kindStatus})
+		require.Empty(t, res.Err)
+		require.NotNil(t, res.Head)
+		assert.Equal(t, uint64(head), *res.Head)
+	})
+
+	t.Run("peers", func(t *testing.T) {
+		res := s.handle(&request{Kind: kindPeers})
+		assert.Equal(t, []string{"127.0.0.1:1"}, res.Peers)
+	})
+
+	t.Run("ranges are cut short at the head", func(t *testing.T) {
+		res := s.handle(&request{Kind: kindHeaders, From: 1, Count: maxRange})
+		require.Empty(t, res.Err)
+		require.Len(t, res.Headers, head)
+		assert.Equal(t, uint64(1), res.Headers[0].Number)
+	})
+
+	t.Run("ranges are bounded", func(t *testing.T) {
+		for _, req := range []*request{
+			{Kind: kindBodies, From: 0, Count: 0},
+			{Kind: kindBodies, From: 0, Count: maxRange + 1},
+			{Kind: kindStateDiffs, From: ^uint64(0), Count: 2},
+			{Kind: kindClasses, ClassHashes: make([]*felt.Felt, maxRange+1)},
+			{Kind: kind(0)},
+		} {
+			assert.NotEmpty(t, s.handle(req).Err, req.Kind.String())
+		}
+	})
+}
+
+func TestMessages(t *testing.T) {
+	conn, other := net.Pipe()
+	t.Cleanup(func() {
+		require.NoError(t, conn.Close())
+		require.NoError(t, other.Close())
+	})
+
+	sent := &request{Kind: kindClasses, ClassHashes: []*felt.Felt{new(felt.Felt).SetUint64(1)}}
+	go func() {
+		assert.NoError(t, writeMessage(bufio.NewWriter(conn), sent, maxRequestSize))
+	}()
+
+	var received request
+	require.NoError(t, readMessage(other, &received, maxRequestSize))
+	assert.Equal(t, sent, &received)
+}
+
+func TestServe(t *testing.T) {
+	// connect runs a node with the given idle timeout, shakes hands with it and returns the connection
+	connect := func(t *testing.T, idleTimeout time.Duration) (*bufio.Reader, *bufio.Writer) {
+		s := New("127.0.0.1:0", nil, newChain(t, utils.MAINNET, true), utils.NewNopZapLogger()).
+			WithDiscoveryInterval(testInterval).
+			WithRequestTimeout(testTimeout).
+			WithIdleTimeout(idleTimeout)
+		addr, err := s.Listen()
+		require.NoError(t, err)
+		runService(t, s.Run)
+
+		conn, err := net.Dial("tcp", addr.String())
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			conn.Close()
+		})
+		require.NoError(t, conn.SetDeadline(time.Now().Add(testWaitLimit)))
+
+		reader, writer := bufio.NewReader(conn), bufio.NewWriter(conn)
+		ours := &hello{Version: protocolVersion, ChainID: s.chainID, NodeID: s.nodeID + 1}
+		require.NoError(t, writeMessage(writer, ours, maxHelloSize))
+		require.NoError(t, readMessage(reader, new(hello), maxHelloSize))
+		return reader, writer
+	}
+
+	t.Run("idle peers are dropped", func(t *testing.T) {
+		reader, _ := connect(t, testInterval)
+		_, rErr := reader.ReadByte()
+		assert.ErrorIs(t, rErr, io.EOF)
+	})
+
+	t.Run("requests that are too large are refused", func(t *testing.T) {
+		reader, writer := connect(t, time.Minute)
+		require.NoError(t, binary.Write(writer, binary.BigEndian, uint32(maxRequestSize+1)))
+		require.NoError(t, writer.Flush())
+		_, rErr := reader.ReadByte()
+		assert.ErrorIs(t, rErr, io.EOF)
+	})
+
+	t.Run("requests are answered", func(t *testing.T) {
+		reader, writer := connect(t, time.Minute)
+		require.NoError(t, writeMessage(writer, &request{Kind: kindStatus}, maxRequestSize))
+		var res response
+		require.NoError(t, readMessage(reader, &res, maxResponseSize))
+		assert.Empty(t, res.Err)
+	})
+}
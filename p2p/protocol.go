@@ -0,0 +1,155 @@
+package p2p
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/encoder"
+)
+
+const (
+	protocolVersion = uint8(1)
+
+	// maxHelloSize, maxRequestSize and maxResponseSize bound the sizes of the messages. The payload of a
+	// message is read as it arrives, so a peer has to send the bytes that it claims a message has before
+	// the memory for them is allocated.
+	maxHelloSize    = 1 << 10
+	maxRequestSize  = 1 << 14
+	maxResponseSize = 1 << 28
+	// maxRange is the largest number of blocks that can be requested at once.
+	maxRange = 64
+)
+
+// kind is the kind of data that a request asks for.
+type kind uint8
+
+const (
+	// kindStatus asks for the number of the head block.
+	kindStatus kind = iota + 1
+	// kindPeers asks for the addresses of the peers that the peer knows of.
+	kindPeers
+	// kindHeaders asks for the headers of a range of blocks.
+	kindHeaders
+	// kindBodies asks for the transactions and receipts of a range of blocks.
+	kindBodies
+	// kindStateDiffs asks for the state updates of a range of blocks.
+	kindStateDiffs
+	// kindClasses asks for the classes with the given hashes or, if there are none, for the classes that the
+	// state updates of a range of blocks reference.
+	kindClasses
+)
+
+func (k kind) String() string {
+	switch k {
+	case kindStatus:
+		return "status"
+	case kindPeers:
+		return "peers"
+	case kindHeaders:
+		return "headers"
+	case kindBodies:
+		return "bodies"
+	case kindStateDiffs:
+		return "state diffs"
+	case kindClasses:
+		return "classes"
+	default:
+		return fmt.Sprintf("kind %d", uint8(k))
+	}
+}
+
+// hello is the first message that each side of a connection sends.
+type hello struct {
+	Version uint8
+	ChainID *felt.Felt
+	// NodeID is chosen at random when a node starts, so that a node that dials itself notices.
+	NodeID uint64
+	// ListenPort is the port that the sender accepts connections on.
+	ListenPort uint16
+}
+
+// request asks for data by block range. Only the node that dialed a connection sends requests over it,
+// one at a time, and every request gets a response.
+type request struct {
+	Kind        kind
+	From        uint64
+	Count       uint64
+	ClassHashes []*felt.Felt
+}
+
+// body is what a block holds besides its header.
+type body struct {
+	Transactions []core.Transaction
+	Receipts     []*core.TransactionReceipt
+}
+
+// response carries the data that a request asked for. A range is cut short at the head of the responding
+// node, so the responses to range requests may hold fewer items than requested.
+type response struct {
+	Err          string
+	Head         *uint64 // nil if the node has no blocks
+	Peers        []string
+	Headers      []*core.Header
+	Bodies       []*body
+	StateUpdates []*core.StateUpdate
+	Classes      map[felt.Felt]core.Class
+}
+
+// writeMessage writes the CBOR encoding of msg to w, preceded by its length, which must not exceed
+// maxSize.
+func writeMessage(w *bufio.Writer, msg any, maxSize uint32) error {
+	payload, err := encoder.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if len(payload) > int(maxSize) {
+		return fmt.Errorf("message of %d bytes is too large", len(payload))
+	}
+
+	if err = binary.Write(w, binary.BigEndian, uint32(len(payload))); err != nil {
+		return err
+	}
+	if _, err = w.Write(payload); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// readMessage reads a message that writeMessage wrote into msg, unless it is longer than maxSize.
+func readMessage(r io.Reader, msg any, maxSize uint32) error {
+	var length uint32
+	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return err
+	}
+	if length > maxSize {
+		return fmt.Errorf("message of %d bytes is too large", length)
+	}
+
+	var payload bytes.Buffer
+	if _, err := io.CopyN(&payload, r, int64(length)); err != nil {
+		return err
+	}
+	return encoder.UnmarshalWithJSONMaps(payload.Bytes(), msg)
+}
+
+var errIncompatible = errors.New("incompatible peer")
+
+// check checks that the peer that sent h can talk to a node with the given chain and id.
+func (h *hello) check(chainID *felt.Felt, nodeID uint64) error {
+	switch {
+	case h.Version != protocolVersion:
+		return fmt.Errorf("%w: protocol version %d", errIncompatible, h.Version)
+	case h.ChainID == nil || !chainID.Equal(h.ChainID):
+		return fmt.Errorf("%w: chain %s", errIncompatible, h.ChainID)
+	case h.NodeID == nodeID:
+		return fmt.Errorf("%w: the peer is this node", errIncompatible)
+	default:
+		return nil
+	}
+}
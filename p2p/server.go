@@ -0,0 +1,131 @@
+package p2p
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
+)
+
+// handle answers a request from the local chain.
+func (s *Service) handle(req *request) *response {
+	res := new(response)
+	var err error
+	switch req.Kind {
+	case kindStatus:
+		res.Head, err = s.head()
+	case kindPeers:
+		res.Peers = s.knownPeers()
+	case kindHeaders:
+		err = forRange(req, func(number uint64) error {
+			header, hErr := s.chain.BlockHeaderByNumber(number)
+			if hErr == nil {
+				res.Headers = append(res.Headers, header)
+			}
+			return hErr
+		})
+	case kindBodies:
+		err = forRange(req, func(number uint64) error {
+			block, bErr := s.chain.BlockByNumber(number)
+			if bErr == nil {
+				res.Bodies = append(res.Bodies, &body{Transactions: block.Transactions, Receipts: block.Receipts})
+			}
+			return bErr
+		})
+	case kindStateDiffs:
+		err = forRange(req, func(number uint64) error {
+			update, uErr := s.chain.StateUpdateByNumber(number)
+			if uErr == nil {
+				res.StateUpdates = append(res.StateUpdates, update)
+			}
+			return uErr
+		})
+	case kindClasses:
+		res.Classes, err = s.classes(req)
+	default:
+		err = fmt.Errorf("unknown request %s", req.Kind)
+	}
+
+	if err != nil {
+		s.log.Debugw("Failed answering a peer", "request", req.Kind, "err", err)
+		return &response{Err: err.Error()}
+	}
+	return res
+}
+
+func (s *Service) head() (*uint64, error) {
+	height, err := s.chain.Height()
+	if errors.Is(err, db.ErrKeyNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &height, nil
+}
+
+// forRange calls fn with the numbers of the requested range of blocks, until the first one that is not
+// in the chain.
+func forRange(req *request, fn func(number uint64) error) error {
+	if req.Count == 0 || req.Count > maxRange {
+		return fmt.Errorf("range of %d blocks, at most %d are served", req.Count, maxRange)
+	}
+	if req.From > math.MaxUint64-req.Count {
+		return fmt.Errorf("range from %d is out of bounds", req.From)
+	}
+
+	for number := req.From; number < req.From+req.Count; number++ {
+		if err := fn(number); errors.Is(err, db.ErrKeyNotFound) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// classes returns the requested classes that are in the head state. Without class hashes, the classes
+// that the state diffs of the requested range reference are returned.
+func (s *Service) classes(req *request) (map[felt.Felt]core.Class, error) {
+	classHashes := req.ClassHashes
+	if len(classHashes) > maxRange {
+		return nil, fmt.Errorf("%d classes, at most %d are served", len(classHashes), maxRange)
+	} else if len(classHashes) == 0 {
+		if err := forRange(req, func(number uint64) error {
+			update, uErr := s.chain.StateUpdateByNumber(number)
+			if uErr == nil {
+				classHashes = append(classHashes, update.StateDiff.ClassHashes()...)
+			}
+			return uErr
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	state, closer, err := s.chain.HeadState()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := closer(); closeErr != nil {
+			s.log.Debugw("Failed closing the head state", "err", closeErr)
+		}
+	}()
+
+	classes := make(map[felt.Felt]core.Class, len(classHashes))
+	for _, classHash := range classHashes {
+		if classHash == nil {
+			continue
+		}
+		declared, cErr := state.Class(classHash)
+		if errors.Is(cErr, db.ErrKeyNotFound) {
+			continue
+		} else if cErr != nil {
+			return nil, cErr
+		}
+		classes[*classHash] = declared.Class
+	}
+	return classes, nil
+}
@@ -0,0 +1,357 @@
+package p2p
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/starknetdata"
+)
+
+var _ starknetdata.StarknetData = (*Service)(nil)
+
+const (
+	// defaultRangeSize is the number of blocks that are fetched from a peer at once.
+	defaultRangeSize = 16
+	// maxCachedRanges bounds the ranges of blocks that are kept until the sync asks for them.
+	maxCachedRanges = 8
+)
+
+var (
+	ErrNoPeer      = errors.New("no peer has the data")
+	ErrInvalidData = errors.New("invalid data")
+)
+
+// blockRange is a range of consecutive blocks along with the classes that their state diffs reference.
+// It is fetched by the first caller that asks for one of its blocks, and the other callers wait for done
+// to be closed.
+type blockRange struct {
+	done    chan struct{}
+	blocks  []*core.Block
+	updates []*core.StateUpdate
+	classes map[felt.Felt]core.Class
+	err     error
+}
+
+func (s *Service) BlockByNumber(ctx context.Context, blockNumber uint64) (*core.Block, error) {
+	r, err := s.blockRange(ctx, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return r.blocks[blockNumber%s.rangeSize], nil
+}
+
+func (s *Service) StateUpdate(ctx context.Context, blockNumber uint64) (*core.StateUpdate, error) {
+	r, err := s.blockRange(ctx, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return r.updates[blockNumber%s.rangeSize], nil
+}
+
+// BlockLatest returns the block at the highest head of the peers.
+func (s *Service) BlockLatest(ctx context.Context) (*core.Block, error) {
+	var highest *uint64
+	s.mu.Lock()
+	for _, p := range s.peers {
+		if p.head != nil && (highest == nil || *p.head > *highest) {
+			highest = p.head
+		}
+	}
+	s.mu.Unlock()
+
+	if highest == nil {
+		return nil, ErrNoPeer
+	}
+	return s.BlockByNumber(ctx, *highest)
+}
+
+// Class returns a class that a fetched range references, or asks the peers for it.
+func (s *Service) Class(ctx context.Context, classHash *felt.Felt) (core.Class, error) {
+	s.rangesMu.Lock()
+	var class core.Class
+	for _, r := range s.ranges {
+		if c, ok := r.classes[*classHash]; ok {
+			class = c
+		}
+	}
+	s.rangesMu.Unlock()
+
+	if class == nil {
+		var err error
+		if class, err = s.fetchClass(ctx, classHash); err != nil {
+			return nil, err
+		}
+	}
+
+	// the sync sets the compiled class of the Sierra classes it gets, which must not race with other
+	// callers reading the cached class
+	if cairo1, ok := class.(*core.Cairo1Class); ok {
+		classCopy := *cairo1
+		return &classCopy, nil
+	}
+	return class, nil
+}
+
+// CompiledClass returns the compiled class that a peer sent along with a Sierra class, if it had one.
+func (s *Service) CompiledClass(ctx context.Context, classHash *felt.Felt) (*core.CompiledClass, error) {
+	class, err := s.Class(ctx, classHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if cairo1, ok := class.(*core.Cairo1Class); ok && cairo1.Compiled != nil {
+		return cairo1.Compiled, nil
+	}
+	return nil, fmt.Errorf("compiled class %s: %w", classHash, starknetdata.ErrUnsupported)
+}
+
+// Pending blocks, signatures and transactions by hash are not exchanged between peers.
+
+func (s *Service) Transaction(ctx context.Context, transactionHash *felt.Felt) (core.Transaction, error) {
+	return nil, fmt.Errorf("transaction by hash: %w", starknetdata.ErrUnsupported)
+}
+
+func (s *Service) BlockPending(ctx context.Context) (*core.Block, error) {
+	return nil, fmt.Errorf("pending block: %w", starknetdata.ErrUnsupported)
+}
+
+func (s *Service) StateUpdatePending(ctx context.Context) (*core.StateUpdate, error) {
+	return nil, fmt.Errorf("pending state update: %w", starknetdata.ErrUnsupported)
+}
+
+func (s *Service) BlockSignature(ctx context.Context, blockNumber uint64) (*core.BlockSignature, error) {
+	return nil, fmt.Errorf("block signature: %w", starknetdata.ErrUnsupported)
+}
+
+// blockRange returns the cached range that holds the given block. The range is fetched if it is not
+// cached, or if it was cut short before the block, in which case the cached one keeps serving the blocks
+// it holds until the fetch succeeds.
+func (s *Service) blockRange(ctx context.Context, number uint64) (*blockRange, error) {
+	start := number - number%s.rangeSize
+
+	s.rangesMu.Lock()
+	if r, ok := s.ranges[start]; ok && number-start < uint64(len(r.blocks)) {
+		s.rangesMu.Unlock()
+		return r, nil
+	}
+	r, fetching := s.fetching[start]
+	if !fetching {
+		r = &blockRange{done: make(chan struct{})}
+		s.fetching[start] = r
+	}
+	s.rangesMu.Unlock()
+
+	if !fetching {
+		r.err = s.fetchRange(ctx, r, start, number)
+		s.rangesMu.Lock()
+		delete(s.fetching, start)
+		if r.err == nil {
+			s.ranges[start] = r
+			s.evictRanges()
+		}
+		s.rangesMu.Unlock()
+		close(r.done)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-r.done:
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if number-start >= uint64(len(r.blocks)) {
+		return nil, fmt.Errorf("block %d: %w", number, ErrNoPeer)
+	}
+	return r, nil
+}
+
+// evictRanges drops the lowest ranges beyond maxCachedRanges.
+func (s *Service) evictRanges() {
+	for len(s.ranges) > maxCachedRanges {
+		first := true
+		var lowest uint64
+		for start := range s.ranges {
+			if first || start < lowest {
+				lowest, first = start, false
+			}
+		}
+		delete(s.ranges, lowest)
+	}
+}
+
+// fetchRange fetches the range that starts at the given block and includes the wanted one from the first
+// peer that has it and sends valid data. Peers that send invalid data are banned.
+func (s *Service) fetchRange(ctx context.Context, r *blockRange, start, wanted uint64) error {
+	peers, err := s.waitForPeers(ctx, wanted)
+	if err != nil {
+		return fmt.Errorf("block %d: %w", wanted, err)
+	}
+
+	for _, p := range peers {
+		if err = s.fetchRangeFrom(ctx, p, r, start, wanted); err == nil {
+			return nil
+		} else if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if errors.Is(err, ErrInvalidData) {
+			s.ban(p.addr, err)
+		} else {
+			s.log.Debugw("Failed fetching blocks from a peer", "addr", p.addr, "from", start, "err", err)
+		}
+	}
+	return fmt.Errorf("block %d: %w, the last one failed with: %v", wanted, ErrNoPeer, err)
+}
+
+// waitForPeers returns the peers whose head is at or after the given block, waiting for one of them to
+// get there until the next discovery.
+func (s *Service) waitForPeers(ctx context.Context, number uint64) ([]*peer, error) {
+	timer := time.NewTimer(s.discoveryInterval)
+	defer timer.Stop()
+
+	for {
+		s.mu.Lock()
+		var peers []*peer
+		for _, p := range s.peers {
+			if p.head != nil && *p.head >= number {
+				peers = append(peers, p)
+			}
+		}
+		headChanged := s.headChanged
+		s.mu.Unlock()
+
+		if len(peers) > 0 {
+			return peers, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+			return nil, ErrNoPeer
+		case <-headChanged:
+		}
+	}
+}
+
+// fetchRangeFrom fetches a range of blocks from a peer and verifies it, cutting it short at the head of the
+// peer.
+func (s *Service) fetchRangeFrom(ctx context.Context, p *peer, r *blockRange, start, wanted uint64) error {
+	req := request{From: start, Count: s.rangeSize}
+	var responses [4]*response
+	for i, k := range []kind{kindHeaders, kindBodies, kindStateDiffs, kindClasses} {
+		req.Kind = k
+		res, err := s.ask(ctx, p, &req)
+		if err != nil {
+			return err
+		}
+		responses[i] = res
+	}
+	headers, bodies, updates, classes := responses[0].Headers, responses[1].Bodies, responses[2].StateUpdates,
+		responses[3].Classes
+
+	// the peer may have moved its head in between the requests
+	count := int(s.rangeSize)
+	for _, n := range []int{len(headers), len(bodies), len(updates)} {
+		if n < count {
+			count = n
+		}
+	}
+
+	blocks := make([]*core.Block, count)
+	for i := range blocks {
+		if bodies[i] == nil {
+			return fmt.Errorf("%w: block %d has no body", ErrInvalidData, start+uint64(i))
+		}
+		blocks[i] = &core.Block{
+			Header:       headers[i],
+			Transactions: bodies[i].Transactions,
+			Receipts:     bodies[i].Receipts,
+		}
+	}
+	if err := s.verifyRange(start, blocks, updates[:count], classes); err != nil {
+		return err
+	}
+	if uint64(count) <= wanted-start {
+		return fmt.Errorf("peer sent %d blocks from %d, without %d", count, start, wanted)
+	}
+
+	r.blocks, r.updates, r.classes = blocks, updates[:count], classes
+	return nil
+}
+
+// verifyRange checks that the blocks are consecutive and build on each other, and runs the same sanity
+// checks on each of them as the sync does.
+func (s *Service) verifyRange(start uint64, blocks []*core.Block, updates []*core.StateUpdate,
+	classes map[felt.Felt]core.Class,
+) error {
+	for i, block := range blocks {
+		number := start + uint64(i)
+		update := updates[i]
+		if !complete(block, update) {
+			return fmt.Errorf("%w: block %d is incomplete", ErrInvalidData, number)
+		}
+		if block.Number != number {
+			return fmt.Errorf("%w: block %d was sent for block %d", ErrInvalidData, block.Number, number)
+		}
+		if i > 0 {
+			if !block.ParentHash.Equal(blocks[i-1].Hash) {
+				return fmt.Errorf("%w: block %d does not build on the block before it", ErrInvalidData, number)
+			}
+			if !update.OldRoot.Equal(updates[i-1].NewRoot) {
+				return fmt.Errorf("%w: state update %d does not build on the state before it", ErrInvalidData, number)
+			}
+		}
+
+		blockClasses := make(map[felt.Felt]core.Class)
+		for _, classHash := range update.StateDiff.ClassHashes() {
+			class, ok := classes[*classHash]
+			if !ok {
+				return fmt.Errorf("class %s of block %d is missing", classHash, number)
+			}
+			blockClasses[*classHash] = class
+		}
+
+		if err := s.chain.SanityCheckNewHeight(block, update, blockClasses); err != nil {
+			return fmt.Errorf("%w: block %d: %v", ErrInvalidData, number, err)
+		}
+	}
+	return nil
+}
+
+// fetchClass asks the peers for a class, one after the other, until one of them sends it.
+func (s *Service) fetchClass(ctx context.Context, classHash *felt.Felt) (core.Class, error) {
+	var err error
+	for _, p := range s.connected() {
+		var res *response
+		if res, err = s.ask(ctx, p, &request{Kind: kindClasses, ClassHashes: []*felt.Felt{classHash}}); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			continue
+		}
+
+		class, ok := res.Classes[*classHash]
+		if !ok {
+			continue
+		}
+		// legacy classes do not hash to their class hash, so a mismatch is only logged, as the sync does
+		if vErr := core.VerifyClassHashes(map[felt.Felt]core.Class{*classHash: class}); vErr != nil {
+			s.log.Debugw("Failed verifying a class from a peer", "addr", p.addr, "hash", classHash.ShortString(), "err", vErr)
+		}
+		return class, nil
+	}
+	return nil, fmt.Errorf("class %s: %w", classHash, ErrNoPeer)
+}
+
+// complete checks that the fields that the verification relies on are set.
+func complete(block *core.Block, update *core.StateUpdate) bool {
+	return block.Header != nil && block.Hash != nil && block.ParentHash != nil && block.GlobalStateRoot != nil &&
+		update != nil && update.BlockHash != nil && update.NewRoot != nil && update.OldRoot != nil && update.StateDiff != nil
+}
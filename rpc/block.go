@@ -32,6 +32,23 @@ func (s Status) MarshalJSON() ([]byte, error) {
 	}
 }
 
+func (s *Status) UnmarshalJSON(data []byte) error {
+	switch string(data) {
+	case "\"PENDING\"":
+		*s = StatusPending
+	case "\"ACCEPTED_ON_L2\"":
+		*s = StatusAcceptedL2
+	case "\"ACCEPTED_ON_L1\"":
+		*s = StatusAcceptedL1
+	// the blocks that never make it into the chain are also reported as aborted or reverted
+	case "\"REJECTED\"", "\"ABORTED\"", "\"REVERTED\"":
+		*s = StatusRejected
+	default:
+		return errors.New("unknown block status")
+	}
+	return nil
+}
+
 // https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L520-L534
 type BlockNumberAndHash struct {
 	Number uint64     `json:"block_number"`
@@ -72,12 +89,26 @@ func (b *BlockID) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+func (b *BlockID) MarshalJSON() ([]byte, error) {
+	switch {
+	case b.Latest:
+		return []byte(`"latest"`), nil
+	case b.Pending:
+		return []byte(`"pending"`), nil
+	case b.Hash != nil:
+		return json.Marshal(map[string]*felt.Felt{"block_hash": b.Hash})
+	default:
+		return json.Marshal(map[string]uint64{"block_number": b.Number})
+	}
+}
+
 // https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L1072
+// Hash, Number and NewRoot are not set for the pending block.
 type BlockHeader struct {
-	Hash             *felt.Felt `json:"block_hash"`
+	Hash             *felt.Felt `json:"block_hash,omitempty"`
 	ParentHash       *felt.Felt `json:"parent_hash"`
-	Number           uint64     `json:"block_number"`
-	NewRoot          *felt.Felt `json:"new_root"`
+	Number           *uint64    `json:"block_number,omitempty"`
+	NewRoot          *felt.Felt `json:"new_root,omitempty"`
 	Timestamp        uint64     `json:"timestamp"`
 	SequencerAddress *felt.Felt `json:"sequencer_address,omitempty"`
 }
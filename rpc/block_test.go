@@ -47,6 +47,12 @@ func TestBlockId(t *testing.T) {
 			var blockID rpc.BlockID
 			require.NoError(t, blockID.UnmarshalJSON([]byte(test.blockIDJSON)))
 			assert.Equal(t, test.expectedBlockID, blockID)
+
+			marshalled, err := blockID.MarshalJSON()
+			require.NoError(t, err)
+			var roundTripped rpc.BlockID
+			require.NoError(t, roundTripped.UnmarshalJSON(marshalled))
+			assert.Equal(t, blockID, roundTripped)
 		})
 	}
 
@@ -78,3 +84,21 @@ func TestBlockId(t *testing.T) {
 		})
 	}
 }
+
+func TestStatusUnmarshalJSON(t *testing.T) {
+	for statusJSON, expected := range map[string]rpc.Status{
+		`"PENDING"`:        rpc.StatusPending,
+		`"ACCEPTED_ON_L2"`: rpc.StatusAcceptedL2,
+		`"ACCEPTED_ON_L1"`: rpc.StatusAcceptedL1,
+		`"REJECTED"`:       rpc.StatusRejected,
+		`"ABORTED"`:        rpc.StatusRejected,
+		`"REVERTED"`:       rpc.StatusRejected,
+	} {
+		var status rpc.Status
+		require.NoError(t, status.UnmarshalJSON([]byte(statusJSON)), statusJSON)
+		assert.Equal(t, expected, status, statusJSON)
+	}
+
+	var status rpc.Status
+	assert.Error(t, status.UnmarshalJSON([]byte(`"UNKNOWN"`)))
+}
@@ -0,0 +1,29 @@
+package rpc
+
+import "github.com/NethermindEth/juno/core/felt"
+
+// Class represents both CONTRACT_CLASS and DEPRECATED_CONTRACT_CLASS of the spec.
+// Only the fields of the respective version are populated.
+//
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json
+type Class struct {
+	SierraProgram        []*felt.Felt `json:"sierra_program,omitempty"`
+	Program              string       `json:"program,omitempty"`
+	ContractClassVersion string       `json:"contract_class_version,omitempty"`
+	EntryPoints          EntryPoints  `json:"entry_points_by_type"`
+	Abi                  any          `json:"abi"`
+}
+
+type EntryPoints struct {
+	Constructor []EntryPoint `json:"CONSTRUCTOR"`
+	External    []EntryPoint `json:"EXTERNAL"`
+	L1Handler   []EntryPoint `json:"L1_HANDLER"`
+}
+
+// EntryPoint is either a SIERRA_ENTRY_POINT, which has an Index, or a
+// DEPRECATED_CAIRO_ENTRY_POINT, which has an Offset.
+type EntryPoint struct {
+	Index    *uint64    `json:"function_idx,omitempty"`
+	Offset   *felt.Felt `json:"offset,omitempty"`
+	Selector *felt.Felt `json:"selector"`
+}
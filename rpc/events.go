@@ -0,0 +1,33 @@
+package rpc
+
+import "github.com/NethermindEth/juno/core/felt"
+
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json
+type EventsArg struct {
+	EventFilter
+	ResultPageRequest
+}
+
+type EventFilter struct {
+	FromBlock *BlockID       `json:"from_block"`
+	ToBlock   *BlockID       `json:"to_block"`
+	Address   *felt.Felt     `json:"address"`
+	Keys      [][]*felt.Felt `json:"keys"`
+}
+
+type ResultPageRequest struct {
+	ContinuationToken string `json:"continuation_token"`
+	ChunkSize         uint64 `json:"chunk_size"`
+}
+
+type EmittedEvent struct {
+	*Event
+	BlockNumber     uint64     `json:"block_number"`
+	BlockHash       *felt.Felt `json:"block_hash"`
+	TransactionHash *felt.Felt `json:"transaction_hash"`
+}
+
+type EventsChunk struct {
+	Events            []*EmittedEvent `json:"events"`
+	ContinuationToken string          `json:"continuation_token,omitempty"`
+}
@@ -2,35 +2,64 @@ package rpc
 
 import (
 	"errors"
+	"math"
+	"time"
 
 	"github.com/NethermindEth/juno/blockchain"
 	"github.com/NethermindEth/juno/core"
 	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
 	"github.com/NethermindEth/juno/jsonrpc"
+	"github.com/NethermindEth/juno/sync"
 	"github.com/NethermindEth/juno/utils"
 )
 
-var (
-	ErrPendingNotSupported = errors.New("pending block is not supported yet")
+const (
+	maxEventChunkSize  = 10240
+	maxEventFilterKeys = 1024
+	maxProofKeys       = 100
+	maxStorageRange    = 1024
+)
 
-	ErrBlockNotFound   = &jsonrpc.Error{Code: 24, Message: "Block not found"}
-	ErrTxnHashNotFound = &jsonrpc.Error{Code: 25, Message: "Transaction hash not found"}
-	ErrNoBlock         = &jsonrpc.Error{Code: 32, Message: "There are no blocks"}
-	ErrInvalidTxIndex  = &jsonrpc.Error{Code: 27, Message: "Invalid transaction index in a block"}
+var (
+	ErrBlockNotFound            = &jsonrpc.Error{Code: 24, Message: "Block not found"}
+	ErrTxnHashNotFound          = &jsonrpc.Error{Code: 25, Message: "Transaction hash not found"}
+	ErrNoBlock                  = &jsonrpc.Error{Code: 32, Message: "There are no blocks"}
+	ErrInvalidTxIndex           = &jsonrpc.Error{Code: 27, Message: "Invalid transaction index in a block"}
+	ErrContractNotFound         = &jsonrpc.Error{Code: 20, Message: "Contract not found"}
+	ErrClassHashNotFound        = &jsonrpc.Error{Code: 28, Message: "Class hash not found"}
+	ErrPageSizeTooBig           = &jsonrpc.Error{Code: 31, Message: "Requested page size is too big"}
+	ErrInvalidContinuationToken = &jsonrpc.Error{Code: 33, Message: "Invalid continuation token"}
+	ErrTooManyKeysInFilter      = &jsonrpc.Error{Code: 34, Message: "Too many keys provided in a filter"}
+	ErrProofLimitExceeded       = &jsonrpc.Error{Code: 10000, Message: "Too many storage keys requested"}
+	ErrProofNotAvailable        = &jsonrpc.Error{Code: 10001, Message: "Proofs are only available for the recent blocks"}
 )
 
 type Handler struct {
-	bcReader blockchain.Reader
-	network  utils.Network
+	bcReader      blockchain.Reader
+	syncReader    sync.Reader
+	network       utils.Network
+	subscriptions subscriptions
+	log           utils.SimpleLogger
 }
 
-func New(bcReader blockchain.Reader, n utils.Network) *Handler {
+func New(bcReader blockchain.Reader, n utils.Network, log utils.SimpleLogger) *Handler {
 	return &Handler{
 		bcReader: bcReader,
 		network:  n,
+		subscriptions: subscriptions{
+			subs: make(map[uint64]*subscription),
+		},
+		log: log,
 	}
 }
 
+// WithSyncReader makes the Handler report the progress of the sync, it is reported as done without one.
+func (h *Handler) WithSyncReader(syncReader sync.Reader) *Handler {
+	h.syncReader = syncReader
+	return h
+}
+
 func (h *Handler) ChainID() (*felt.Felt, *jsonrpc.Error) {
 	return h.network.ChainID(), nil
 }
@@ -52,6 +81,53 @@ func (h *Handler) BlockNumberAndHash() (*BlockNumberAndHash, *jsonrpc.Error) {
 	return &BlockNumberAndHash{Number: block.Number, Hash: block.Hash}, nil
 }
 
+// Syncing returns the progress of the sync, or false if the node is not syncing.
+//
+// It follows the specification defined here:
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json
+func (h *Handler) Syncing() (*Sync, *jsonrpc.Error) {
+	notSyncing := &Sync{Syncing: false}
+	if h.syncReader == nil {
+		return notSyncing, nil
+	}
+
+	starting := h.syncReader.StartingBlockHeader()
+	highest := h.syncReader.HighestBlockHeader()
+	current, err := h.bcReader.HeadsHeader()
+	if starting == nil || highest == nil || err != nil || current.Number >= highest.Number {
+		return notSyncing, nil
+	}
+
+	return &Sync{
+		Syncing:           true,
+		StartingBlockHash: starting.Hash,
+		StartingBlockNum:  NumAsHex(starting.Number),
+		CurrentBlockHash:  current.Hash,
+		CurrentBlockNum:   NumAsHex(current.Number),
+		HighestBlockHash:  highest.Hash,
+		HighestBlockNum:   NumAsHex(highest.Number),
+	}, nil
+}
+
+// SyncStatus returns how much work the sync has done since the node started and how fast it is going.
+func (h *Handler) SyncStatus() (*SyncStatus, *jsonrpc.Error) {
+	if h.syncReader == nil {
+		return &SyncStatus{}, nil
+	}
+
+	stats := h.syncReader.Stats()
+	status := &SyncStatus{
+		BlocksFetched:  stats.BlocksFetched,
+		BlocksVerified: stats.BlocksVerified,
+		Rollbacks:      stats.Rollbacks,
+	}
+	if elapsed := time.Since(stats.StartedAt).Seconds(); !stats.StartedAt.IsZero() && elapsed > 0 {
+		status.FetchRate = float64(stats.BlocksFetched) / elapsed
+		status.VerifyRate = float64(stats.BlocksVerified) / elapsed
+	}
+	return status, nil
+}
+
 func (h *Handler) BlockWithTxHashes(id *BlockID) (*BlockWithTxHashes, *jsonrpc.Error) {
 	block, err := h.blockByID(id)
 	if block == nil || err != nil {
@@ -63,24 +139,47 @@ func (h *Handler) BlockWithTxHashes(id *BlockID) (*BlockWithTxHashes, *jsonrpc.E
 		txnHashes[index] = txn.Hash()
 	}
 
+	status, header := adaptBlockHeader(block.Header, id.Pending)
 	return &BlockWithTxHashes{
-		Status:      StatusAcceptedL2, // todo
-		BlockHeader: adaptBlockHeader(block.Header),
+		Status:      status,
+		BlockHeader: header,
 		TxnHashes:   txnHashes,
 	}, nil
 }
 
-func adaptBlockHeader(header *core.Header) BlockHeader {
-	return BlockHeader{
+func adaptBlockHeader(header *core.Header, pending bool) (Status, BlockHeader) {
+	if pending {
+		return StatusPending, BlockHeader{
+			ParentHash:       header.ParentHash,
+			Timestamp:        header.Timestamp,
+			SequencerAddress: header.SequencerAddress,
+		}
+	}
+
+	number := header.Number
+	return adaptBlockStatus(header.Status), BlockHeader{
 		Hash:             header.Hash,
 		ParentHash:       header.ParentHash,
-		Number:           header.Number,
+		Number:           &number,
 		NewRoot:          header.GlobalStateRoot,
 		Timestamp:        header.Timestamp,
 		SequencerAddress: header.SequencerAddress,
 	}
 }
 
+func adaptBlockStatus(status core.BlockStatus) Status {
+	switch status {
+	case core.BlockAcceptedOnL1:
+		return StatusAcceptedL1
+	case core.BlockPending:
+		return StatusPending
+	case core.BlockRejected:
+		return StatusRejected
+	default:
+		return StatusAcceptedL2
+	}
+}
+
 func (h *Handler) BlockWithTxs(id *BlockID) (*BlockWithTxs, *jsonrpc.Error) {
 	block, err := h.blockByID(id)
 	if block == nil || err != nil {
@@ -92,9 +191,10 @@ func (h *Handler) BlockWithTxs(id *BlockID) (*BlockWithTxs, *jsonrpc.Error) {
 		txs[index] = adaptTransaction(txn)
 	}
 
+	status, header := adaptBlockHeader(block.Header, id.Pending)
 	return &BlockWithTxs{
-		Status:       StatusAcceptedL2, // todo
-		BlockHeader:  adaptBlockHeader(block.Header),
+		Status:       status,
+		BlockHeader:  header,
 		Transactions: txs,
 	}, nil
 }
@@ -181,7 +281,7 @@ func adaptDeclareTransaction(t *core.DeclareTransaction) *Transaction {
 	}
 
 	if t.Version.Equal(new(felt.Felt).SetUint64(2)) {
-		txn.CompiledClassHash = nil // todo: add when we have support for Declare V2
+		txn.CompiledClassHash = t.CompiledClassHash
 	}
 
 	return txn
@@ -194,7 +294,11 @@ func (h *Handler) blockByID(id *BlockID) (*core.Block, error) {
 	case id.Hash != nil:
 		return h.bcReader.BlockByHash(id.Hash)
 	case id.Pending:
-		return nil, ErrPendingNotSupported
+		pending, err := h.bcReader.Pending()
+		if err != nil {
+			return nil, err
+		}
+		return pending.Block, nil
 	default:
 		return h.bcReader.BlockByNumber(id.Number)
 	}
@@ -207,17 +311,38 @@ func (h *Handler) blockHeaderByID(id *BlockID) (*core.Header, error) {
 	case id.Hash != nil:
 		return h.bcReader.BlockHeaderByHash(id.Hash)
 	case id.Pending:
-		return nil, ErrPendingNotSupported
+		pending, err := h.bcReader.Pending()
+		if err != nil {
+			return nil, err
+		}
+		return pending.Block.Header, nil
 	default:
 		return h.bcReader.BlockHeaderByNumber(id.Number)
 	}
 }
 
+// pendingTransaction returns the transaction with the given hash and its receipt if it is in the pending block.
+func (h *Handler) pendingTransaction(hash *felt.Felt) (core.Transaction, *core.TransactionReceipt, error) {
+	pending, err := h.bcReader.Pending()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i, txn := range pending.Block.Transactions {
+		if txn.Hash().Equal(hash) {
+			return txn, pending.Block.Receipts[i], nil
+		}
+	}
+	return nil, nil, db.ErrKeyNotFound
+}
+
 // TransactionByHash https://github.com/starkware-libs/starknet-specs/blob/master/api/starknet_api_openrpc.json#L158
 func (h *Handler) TransactionByHash(hash *felt.Felt) (*Transaction, *jsonrpc.Error) {
 	txn, err := h.bcReader.TransactionByHash(hash)
 	if err != nil {
-		return nil, ErrTxnHashNotFound
+		if txn, _, err = h.pendingTransaction(hash); err != nil {
+			return nil, ErrTxnHashNotFound
+		}
 	}
 	return adaptTransaction(txn), nil
 }
@@ -237,6 +362,10 @@ func (h *Handler) BlockTransactionCount(id *BlockID) (uint64, *jsonrpc.Error) {
 
 // TransactionByBlockIDAndIndex https://github.com/starkware-libs/starknet-specs/blob/master/api/starknet_api_openrpc.json#L184
 func (h *Handler) TransactionByBlockIDAndIndex(id *BlockID, txIndex int) (*Transaction, *jsonrpc.Error) {
+	if id.Pending {
+		return h.pendingTransactionByIndex(txIndex)
+	}
+
 	header, err := h.blockHeaderByID(id)
 	if header == nil || err != nil {
 		return nil, ErrBlockNotFound
@@ -254,15 +383,38 @@ func (h *Handler) TransactionByBlockIDAndIndex(id *BlockID, txIndex int) (*Trans
 	return adaptTransaction(txn), nil
 }
 
+func (h *Handler) pendingTransactionByIndex(txIndex int) (*Transaction, *jsonrpc.Error) {
+	pending, err := h.bcReader.Pending()
+	if err != nil {
+		return nil, ErrBlockNotFound
+	}
+
+	if txIndex < 0 || txIndex >= len(pending.Block.Transactions) {
+		return nil, ErrInvalidTxIndex
+	}
+	return adaptTransaction(pending.Block.Transactions[txIndex]), nil
+}
+
 // TransactionReceiptByHash https://github.com/starkware-libs/starknet-specs/blob/master/api/starknet_api_openrpc.json#L222
 func (h *Handler) TransactionReceiptByHash(hash *felt.Felt) (*TransactionReceipt, *jsonrpc.Error) {
 	txn, rpcErr := h.TransactionByHash(hash)
 	if rpcErr != nil {
 		return nil, rpcErr
 	}
-	receipt, blockHash, blockNumber, err := h.bcReader.Receipt(hash)
+	status := StatusPending
+	var blockNumber *uint64
+	receipt, blockHash, number, err := h.bcReader.Receipt(hash)
 	if err != nil {
-		return nil, ErrTxnHashNotFound
+		if _, receipt, err = h.pendingTransaction(hash); err != nil {
+			return nil, ErrTxnHashNotFound
+		}
+	} else {
+		var header *core.Header
+		if header, err = h.bcReader.BlockHeaderByNumber(number); err != nil {
+			return nil, ErrTxnHashNotFound
+		}
+		status = adaptBlockStatus(header.Status)
+		blockNumber = &number
 	}
 
 	messages := make([]*MsgToL1, len(receipt.L2ToL1Message))
@@ -288,7 +440,7 @@ func (h *Handler) TransactionReceiptByHash(hash *felt.Felt) (*TransactionReceipt
 	}
 
 	return &TransactionReceipt{
-		Status:          StatusAcceptedL2, // todo
+		Status:          status,
 		Type:            txn.Type,
 		Hash:            txn.Hash,
 		ActualFee:       receipt.Fee,
@@ -311,7 +463,10 @@ func (h *Handler) StateUpdate(id *BlockID) (*StateUpdate, *jsonrpc.Error) {
 			update, err = h.bcReader.StateUpdateByNumber(height)
 		}
 	} else if id.Pending {
-		err = ErrPendingNotSupported
+		var pending *blockchain.Pending
+		if pending, err = h.bcReader.Pending(); err == nil {
+			update = pending.StateUpdate
+		}
 	} else if id.Hash != nil {
 		update, err = h.bcReader.StateUpdateByHash(id.Hash)
 	} else {
@@ -376,3 +531,342 @@ func (h *Handler) StateUpdate(id *BlockID) (*StateUpdate, *jsonrpc.Error) {
 		},
 	}, nil
 }
+
+func (h *Handler) stateByBlockID(id *BlockID) (core.StateReader, blockchain.StateCloser, error) {
+	switch {
+	case id.Latest:
+		return h.bcReader.HeadState()
+	case id.Pending:
+		return h.bcReader.PendingState()
+	case id.Hash != nil:
+		return h.bcReader.StateAtBlockHash(id.Hash)
+	default:
+		return h.bcReader.StateAtBlockNumber(id.Number)
+	}
+}
+
+// StorageAt gets the value of the storage at the given address and key.
+//
+// It follows the specification defined here:
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json
+func (h *Handler) StorageAt(address, key *felt.Felt, id *BlockID) (*felt.Felt, *jsonrpc.Error) {
+	stateReader, stateCloser, err := h.stateByBlockID(id)
+	if err != nil {
+		return nil, ErrBlockNotFound
+	}
+	defer h.callAndLogErr(stateCloser, "Error closing state reader in getStorageAt")
+
+	value, err := stateReader.ContractStorage(address, key)
+	if err != nil {
+		return nil, ErrContractNotFound
+	}
+
+	return value, nil
+}
+
+// Proof returns a proof of the state of the contract at the given address and of the values of the given
+// storage keys against the state commitment of the given block, in the format of pathfinder_getProof.
+// Only the recent blocks can be proved.
+func (h *Handler) Proof(id *BlockID, address *felt.Felt, keys []*felt.Felt) (*Proof, *jsonrpc.Error) {
+	if len(keys) > maxProofKeys {
+		return nil, ErrProofLimitExceeded
+	}
+	if id.Pending {
+		return nil, ErrProofNotAvailable
+	}
+
+	header, err := h.blockHeaderByID(id)
+	if err != nil {
+		return nil, ErrBlockNotFound
+	}
+
+	proof, proved, err := h.bcReader.StateProof(header.Number, address, keys)
+	if err != nil {
+		switch {
+		case errors.Is(err, blockchain.ErrStateProofTooDeep):
+			return nil, ErrProofNotAvailable
+		case errors.Is(err, db.ErrKeyNotFound):
+			return nil, ErrBlockNotFound
+		default:
+			h.log.Errorw("Error proving the state", "number", header.Number, "err", err)
+			return nil, jsonrpc.Err(jsonrpc.InternalError, nil)
+		}
+	}
+	// the block may have been reorged away since it was looked up
+	if !proved.Hash.Equal(header.Hash) {
+		return nil, ErrBlockNotFound
+	}
+	return adaptProof(proved, proof), nil
+}
+
+// StorageRange returns up to limit storage locations of the contract at the given address that hold a
+// value in the latest block, starting at the given key, in ascending order of keys. A nil start key starts
+// at the beginning of the storage.
+func (h *Handler) StorageRange(address, startKey *felt.Felt, limit uint64) (*StorageRange, *jsonrpc.Error) {
+	if limit == 0 {
+		return nil, jsonrpc.Err(jsonrpc.InvalidParams, "limit must be greater than zero")
+	} else if limit > maxStorageRange {
+		return nil, ErrPageSizeTooBig
+	}
+
+	storageRange := &StorageRange{Storage: []StorageEntry{}}
+	header, err := h.bcReader.HeadContractStorage(address, startKey, func(key, value *felt.Felt) (bool, error) {
+		if uint64(len(storageRange.Storage)) == limit {
+			storageRange.NextKey = key
+			return false, nil
+		}
+
+		storageRange.Storage = append(storageRange.Storage, StorageEntry{Key: key, Value: value})
+		return true, nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrKeyNotFound):
+			return nil, ErrNoBlock
+		case errors.Is(err, core.ErrContractNotDeployed):
+			return nil, ErrContractNotFound
+		default:
+			h.log.Errorw("Error iterating the contract storage", "address", address, "err", err)
+			return nil, jsonrpc.Err(jsonrpc.InternalError, nil)
+		}
+	}
+
+	storageRange.BlockHash = header.Hash
+	storageRange.BlockNumber = header.Number
+	return storageRange, nil
+}
+
+// Nonce returns the nonce associated with the given address in the given block number
+//
+// It follows the specification defined here:
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json
+func (h *Handler) Nonce(id *BlockID, address *felt.Felt) (*felt.Felt, *jsonrpc.Error) {
+	stateReader, stateCloser, err := h.stateByBlockID(id)
+	if err != nil {
+		return nil, ErrBlockNotFound
+	}
+	defer h.callAndLogErr(stateCloser, "Error closing state reader in getNonce")
+
+	nonce, err := stateReader.ContractNonce(address)
+	if err != nil {
+		return nil, ErrContractNotFound
+	}
+
+	return nonce, nil
+}
+
+// ClassHashAt gets the class hash for the contract deployed at the given address in the given block.
+//
+// It follows the specification defined here:
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json
+func (h *Handler) ClassHashAt(id *BlockID, address *felt.Felt) (*felt.Felt, *jsonrpc.Error) {
+	stateReader, stateCloser, err := h.stateByBlockID(id)
+	if err != nil {
+		return nil, ErrBlockNotFound
+	}
+	defer h.callAndLogErr(stateCloser, "Error closing state reader in getClassHashAt")
+
+	classHash, err := stateReader.ContractClassHash(address)
+	if err != nil {
+		return nil, ErrContractNotFound
+	}
+
+	return classHash, nil
+}
+
+// Class gets the contract class definition in the given block associated with the given hash
+//
+// It follows the specification defined here:
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json
+func (h *Handler) Class(id *BlockID, classHash *felt.Felt) (*Class, *jsonrpc.Error) {
+	stateReader, stateCloser, err := h.stateByBlockID(id)
+	if err != nil {
+		return nil, ErrBlockNotFound
+	}
+	defer h.callAndLogErr(stateCloser, "Error closing state reader in getClass")
+
+	return h.class(stateReader, classHash)
+}
+
+// ClassAt gets the contract class definition in the given block instantiated by the given contract address
+//
+// It follows the specification defined here:
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json
+func (h *Handler) ClassAt(id *BlockID, address *felt.Felt) (*Class, *jsonrpc.Error) {
+	stateReader, stateCloser, err := h.stateByBlockID(id)
+	if err != nil {
+		return nil, ErrBlockNotFound
+	}
+	defer h.callAndLogErr(stateCloser, "Error closing state reader in getClassAt")
+
+	classHash, err := stateReader.ContractClassHash(address)
+	if err != nil {
+		return nil, ErrContractNotFound
+	}
+
+	return h.class(stateReader, classHash)
+}
+
+func (h *Handler) class(stateReader core.StateReader, classHash *felt.Felt) (*Class, *jsonrpc.Error) {
+	declared, err := stateReader.Class(classHash)
+	if err != nil {
+		return nil, ErrClassHashNotFound
+	}
+
+	switch c := declared.Class.(type) {
+	case *core.Cairo0Class:
+		return adaptCairo0Class(c), nil
+	case *core.Cairo1Class:
+		return adaptCairo1Class(c), nil
+	default:
+		h.log.Errorw("Unknown class type", "classHash", classHash)
+		return nil, jsonrpc.Err(jsonrpc.InternalError, nil)
+	}
+}
+
+func adaptCairo0Class(class *core.Cairo0Class) *Class {
+	adaptEntryPoints := func(entryPoints []core.EntryPoint) []EntryPoint {
+		adapted := make([]EntryPoint, 0, len(entryPoints))
+		for _, entryPoint := range entryPoints {
+			adapted = append(adapted, EntryPoint{
+				Offset:   entryPoint.Offset,
+				Selector: entryPoint.Selector,
+			})
+		}
+		return adapted
+	}
+
+	return &Class{
+		Program: class.Program,
+		EntryPoints: EntryPoints{
+			Constructor: adaptEntryPoints(class.Constructors),
+			External:    adaptEntryPoints(class.Externals),
+			L1Handler:   adaptEntryPoints(class.L1Handlers),
+		},
+		Abi: class.Abi,
+	}
+}
+
+func adaptCairo1Class(class *core.Cairo1Class) *Class {
+	adaptEntryPoints := func(entryPoints []core.SierraEntryPoint) []EntryPoint {
+		adapted := make([]EntryPoint, 0, len(entryPoints))
+		for _, entryPoint := range entryPoints {
+			index := entryPoint.Index
+			adapted = append(adapted, EntryPoint{
+				Index:    &index,
+				Selector: entryPoint.Selector,
+			})
+		}
+		return adapted
+	}
+
+	return &Class{
+		SierraProgram:        class.Program,
+		ContractClassVersion: class.SemanticVersion,
+		EntryPoints: EntryPoints{
+			Constructor: adaptEntryPoints(class.EntryPoints.Constructor),
+			External:    adaptEntryPoints(class.EntryPoints.External),
+			L1Handler:   adaptEntryPoints(class.EntryPoints.L1Handler),
+		},
+		Abi: class.Abi,
+	}
+}
+
+// Events gets the events matching a filter, in chunks of at most ChunkSize events.
+//
+// It follows the specification defined here:
+// https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json
+func (h *Handler) Events(args *EventsArg) (*EventsChunk, *jsonrpc.Error) {
+	if args.ChunkSize == 0 {
+		return nil, jsonrpc.Err(jsonrpc.InvalidParams, "chunk_size must be greater than zero")
+	} else if args.ChunkSize > maxEventChunkSize {
+		return nil, ErrPageSizeTooBig
+	}
+
+	var numKeys int
+	for _, keys := range args.Keys {
+		numKeys += len(keys)
+	}
+	if numKeys > maxEventFilterKeys {
+		return nil, ErrTooManyKeysInFilter
+	}
+
+	var token *blockchain.ContinuationToken
+	if args.ContinuationToken != "" {
+		var err error
+		if token, err = blockchain.ParseContinuationToken(args.ContinuationToken); err != nil {
+			return nil, ErrInvalidContinuationToken
+		}
+	}
+
+	filter := &blockchain.EventFilter{
+		ToBlock: math.MaxUint64,
+		Address: args.Address,
+		Keys:    args.Keys,
+	}
+
+	var err error
+	if args.FromBlock != nil {
+		if filter.FromBlock, err = h.eventsBlockNumber(args.FromBlock); err != nil {
+			return nil, ErrBlockNotFound
+		}
+	}
+	if args.ToBlock != nil {
+		if filter.ToBlock, err = h.eventsBlockNumber(args.ToBlock); err != nil {
+			return nil, ErrBlockNotFound
+		}
+	}
+
+	filteredEvents, nextToken, err := h.bcReader.Events(filter, token, args.ChunkSize)
+	if err != nil {
+		switch {
+		case errors.Is(err, blockchain.ErrInvalidContinuationToken):
+			return nil, ErrInvalidContinuationToken
+		case errors.Is(err, db.ErrKeyNotFound):
+			return nil, ErrBlockNotFound
+		default:
+			h.log.Errorw("Error filtering events", "err", err)
+			return nil, jsonrpc.Err(jsonrpc.InternalError, nil)
+		}
+	}
+
+	emittedEvents := make([]*EmittedEvent, 0, len(filteredEvents))
+	for _, filteredEvent := range filteredEvents {
+		emittedEvents = append(emittedEvents, &EmittedEvent{
+			Event: &Event{
+				From: filteredEvent.From,
+				Keys: filteredEvent.Keys,
+				Data: filteredEvent.Data,
+			},
+			BlockNumber:     filteredEvent.BlockNumber,
+			BlockHash:       filteredEvent.BlockHash,
+			TransactionHash: filteredEvent.TransactionHash,
+		})
+	}
+
+	chunk := &EventsChunk{Events: emittedEvents}
+	if nextToken != nil {
+		chunk.ContinuationToken = nextToken.String()
+	}
+	return chunk, nil
+}
+
+// eventsBlockNumber resolves the number of a block that bounds an event filter.
+func (h *Handler) eventsBlockNumber(id *BlockID) (uint64, error) {
+	// pending events are not available yet, so the pending block is treated as the latest one
+	if id.Pending {
+		id = &BlockID{Latest: true}
+	}
+
+	header, err := h.blockHeaderByID(id)
+	if err != nil {
+		return 0, err
+	}
+	return header.Number, nil
+}
+
+func (h *Handler) callAndLogErr(f func() error, msg string) {
+	if err := f(); err != nil {
+		h.log.Errorw(msg, "err", err)
+	}
+}
@@ -4,15 +4,22 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"math"
 	"math/rand"
 	"testing"
+	"time"
 
+	"github.com/NethermindEth/juno/blockchain"
 	"github.com/NethermindEth/juno/clients/feeder"
 	"github.com/NethermindEth/juno/core"
 	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/core/trie"
+	"github.com/NethermindEth/juno/db"
+	"github.com/NethermindEth/juno/jsonrpc"
 	"github.com/NethermindEth/juno/mocks"
 	"github.com/NethermindEth/juno/rpc"
 	adaptfeeder "github.com/NethermindEth/juno/starknetdata/feeder"
+	"github.com/NethermindEth/juno/sync"
 	"github.com/NethermindEth/juno/utils"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -26,13 +33,30 @@ func TestChainId(t *testing.T) {
 			t.Cleanup(mockCtrl.Finish)
 
 			mockReader := mocks.NewMockReader(mockCtrl)
-			handler := rpc.New(mockReader, n)
+			handler := rpc.New(mockReader, n, utils.NewNopZapLogger())
 
 			cID, err := handler.ChainID()
 			require.Nil(t, err)
 			assert.Equal(t, n.ChainID(), cID)
 		})
 	}
+
+	t.Run("custom network", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		t.Cleanup(mockCtrl.Finish)
+
+		n, err := utils.NewCustomNetwork(&utils.CustomNetwork{
+			Name:      "devnet",
+			FeederURL: "http://localhost:5050/feeder_gateway/",
+			ChainID:   "SN_DEVNET",
+		})
+		require.NoError(t, err)
+		handler := rpc.New(mocks.NewMockReader(mockCtrl), n, utils.NewNopZapLogger())
+
+		cID, rpcErr := handler.ChainID()
+		require.Nil(t, rpcErr)
+		assert.Equal(t, new(felt.Felt).SetBytes([]byte("SN_DEVNET")), cID)
+	})
 }
 
 func TestBlockNumber(t *testing.T) {
@@ -40,7 +64,7 @@ func TestBlockNumber(t *testing.T) {
 	t.Cleanup(mockCtrl.Finish)
 
 	mockReader := mocks.NewMockReader(mockCtrl)
-	handler := rpc.New(mockReader, utils.MAINNET)
+	handler := rpc.New(mockReader, utils.MAINNET, utils.NewNopZapLogger())
 
 	t.Run("empty blockchain", func(t *testing.T) {
 		expectedHeight := uint64(0)
@@ -66,7 +90,7 @@ func TestBlockNumberAndHash(t *testing.T) {
 	t.Cleanup(mockCtrl.Finish)
 
 	mockReader := mocks.NewMockReader(mockCtrl)
-	handler := rpc.New(mockReader, utils.MAINNET)
+	handler := rpc.New(mockReader, utils.MAINNET, utils.NewNopZapLogger())
 
 	t.Run("empty blockchain", func(t *testing.T) {
 		mockReader.EXPECT().Head().Return(nil, errors.New("empty blockchain"))
@@ -94,12 +118,107 @@ func TestBlockNumberAndHash(t *testing.T) {
 	})
 }
 
+type fakeSyncReader struct {
+	starting *core.Header
+	highest  *core.Header
+	stats    sync.Stats
+}
+
+func (r *fakeSyncReader) StartingBlockHeader() *core.Header { return r.starting }
+func (r *fakeSyncReader) HighestBlockHeader() *core.Header  { return r.highest }
+func (r *fakeSyncReader) Stats() sync.Stats                 { return r.stats }
+
+func TestSyncing(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mockReader := mocks.NewMockReader(mockCtrl)
+	syncReader := new(fakeSyncReader)
+	handler := rpc.New(mockReader, utils.MAINNET, utils.NewNopZapLogger())
+
+	starting := &core.Header{Number: 0, Hash: new(felt.Felt).SetUint64(1)}
+	current := &core.Header{Number: 1, Hash: new(felt.Felt).SetUint64(2)}
+	highest := &core.Header{Number: 2, Hash: new(felt.Felt).SetUint64(3)}
+
+	t.Run("no sync reader", func(t *testing.T) {
+		syncing, err := handler.Syncing()
+		require.Nil(t, err)
+		assert.False(t, syncing.Syncing)
+	})
+
+	handler = handler.WithSyncReader(syncReader)
+
+	t.Run("highest block is not known", func(t *testing.T) {
+		syncReader.starting = starting
+		mockReader.EXPECT().HeadsHeader().Return(current, nil)
+
+		syncing, err := handler.Syncing()
+		require.Nil(t, err)
+		assert.False(t, syncing.Syncing)
+	})
+
+	t.Run("syncing", func(t *testing.T) {
+		syncReader.highest = highest
+		mockReader.EXPECT().HeadsHeader().Return(current, nil)
+
+		syncing, err := handler.Syncing()
+		require.Nil(t, err)
+		assert.Equal(t, &rpc.Sync{
+			Syncing:           true,
+			StartingBlockHash: starting.Hash,
+			StartingBlockNum:  0,
+			CurrentBlockHash:  current.Hash,
+			CurrentBlockNum:   1,
+			HighestBlockHash:  highest.Hash,
+			HighestBlockNum:   2,
+		}, syncing)
+
+		syncingJSON, jsonErr := json.Marshal(syncing)
+		require.NoError(t, jsonErr)
+		assert.JSONEq(t, `{
+			"starting_block_hash": "0x1", "starting_block_num": "0x0",
+			"current_block_hash": "0x2", "current_block_num": "0x1",
+			"highest_block_hash": "0x3", "highest_block_num": "0x2"
+		}`, string(syncingJSON))
+	})
+
+	t.Run("synced", func(t *testing.T) {
+		mockReader.EXPECT().HeadsHeader().Return(highest, nil)
+
+		syncing, err := handler.Syncing()
+		require.Nil(t, err)
+		assert.False(t, syncing.Syncing)
+
+		syncingJSON, jsonErr := json.Marshal(syncing)
+		require.NoError(t, jsonErr)
+		assert.Equal(t, "false", string(syncingJSON))
+	})
+}
+
+func TestSyncStatus(t *testing.T) {
+	syncReader := &fakeSyncReader{stats: sync.Stats{
+		StartedAt:      time.Now().Add(-10 * time.Second),
+		BlocksFetched:  100,
+		BlocksVerified: 50,
+		Rollbacks:      2,
+	}}
+	handler := rpc.New(nil, utils.MAINNET, utils.NewNopZapLogger()).WithSyncReader(syncReader)
+
+	status, err := handler.SyncStatus()
+	require.Nil(t, err)
+	assert.Equal(t, uint64(100), status.BlocksFetched)
+	assert.Equal(t, uint64(50), status.BlocksVerified)
+	assert.Equal(t, uint64(2), status.Rollbacks)
+	assert.InDelta(t, 10, status.FetchRate, 1)
+	assert.InDelta(t, 5, status.VerifyRate, 1)
+}
+
 func TestBlockTransactionCount(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	t.Cleanup(mockCtrl.Finish)
 
 	mockReader := mocks.NewMockReader(mockCtrl)
-	handler := rpc.New(mockReader, utils.GOERLI)
+	handler := rpc.New(mockReader, utils.GOERLI, utils.NewNopZapLogger())
 
 	client, closeServer := feeder.NewTestClient(utils.GOERLI)
 	t.Cleanup(closeServer)
@@ -165,7 +284,7 @@ func TestBlockWithTxHashes(t *testing.T) {
 	t.Cleanup(mockCtrl.Finish)
 
 	mockReader := mocks.NewMockReader(mockCtrl)
-	handler := rpc.New(mockReader, utils.GOERLI)
+	handler := rpc.New(mockReader, utils.GOERLI, utils.NewNopZapLogger())
 
 	client, closeServer := feeder.NewTestClient(utils.GOERLI)
 	t.Cleanup(closeServer)
@@ -178,7 +297,8 @@ func TestBlockWithTxHashes(t *testing.T) {
 
 	checkLatestBlock := func(t *testing.T, b *rpc.BlockWithTxHashes) {
 		t.Helper()
-		assert.Equal(t, latestBlock.Number, b.Number)
+		assert.Equal(t, rpc.StatusAcceptedL1, b.Status)
+		assert.Equal(t, latestBlock.Number, *b.Number)
 		assert.Equal(t, latestBlock.Hash, b.Hash)
 		assert.Equal(t, latestBlock.GlobalStateRoot, b.NewRoot)
 		assert.Equal(t, latestBlock.ParentHash, b.ParentHash)
@@ -247,7 +367,7 @@ func TestBlockWithTxs(t *testing.T) {
 	t.Cleanup(mockCtrl.Finish)
 
 	mockReader := mocks.NewMockReader(mockCtrl)
-	handler := rpc.New(mockReader, utils.MAINNET)
+	handler := rpc.New(mockReader, utils.MAINNET, utils.NewNopZapLogger())
 
 	client, closeServer := feeder.NewTestClient(utils.MAINNET)
 	t.Cleanup(closeServer)
@@ -284,6 +404,7 @@ func TestBlockWithTxs(t *testing.T) {
 
 	checkLatestBlock := func(t *testing.T, blockWithTxHashes *rpc.BlockWithTxHashes, blockWithTxs *rpc.BlockWithTxs) {
 		t.Helper()
+		assert.Equal(t, blockWithTxHashes.Status, blockWithTxs.Status)
 		assert.Equal(t, blockWithTxHashes.BlockHeader, blockWithTxs.BlockHeader)
 		assert.Equal(t, len(blockWithTxHashes.TxnHashes), len(blockWithTxs.Transactions))
 
@@ -358,11 +479,12 @@ func TestTransactionByHash(t *testing.T) {
 	t.Cleanup(closeServer)
 	mainnetGw := adaptfeeder.New(client)
 
-	handler := rpc.New(mockReader, utils.MAINNET)
+	handler := rpc.New(mockReader, utils.MAINNET, utils.NewNopZapLogger())
 
 	t.Run("transaction not found", func(t *testing.T) {
 		txHash := new(felt.Felt).SetBytes([]byte("random hash"))
 		mockReader.EXPECT().TransactionByHash(txHash).Return(nil, errors.New("tx not found"))
+		mockReader.EXPECT().Pending().Return(nil, db.ErrKeyNotFound)
 
 		tx, rpcErr := handler.TransactionByHash(txHash)
 		assert.Nil(t, tx)
@@ -557,7 +679,7 @@ func TestTransactionByBlockIdAndIndex(t *testing.T) {
 	require.NoError(t, err)
 	latestBlockHash := latestBlock.Hash
 
-	handler := rpc.New(mockReader, utils.MAINNET)
+	handler := rpc.New(mockReader, utils.MAINNET, utils.NewNopZapLogger())
 
 	t.Run("empty blockchain", func(t *testing.T) {
 		mockReader.EXPECT().HeadsHeader().Return(nil, errors.New("empty blockchain"))
@@ -674,11 +796,12 @@ func TestTransactionReceiptByHash(t *testing.T) {
 	t.Cleanup(mockCtrl.Finish)
 
 	mockReader := mocks.NewMockReader(mockCtrl)
-	handler := rpc.New(mockReader, utils.MAINNET)
+	handler := rpc.New(mockReader, utils.MAINNET, utils.NewNopZapLogger())
 
 	t.Run("transaction not found", func(t *testing.T) {
 		txHash := new(felt.Felt).SetBytes([]byte("random hash"))
 		mockReader.EXPECT().TransactionByHash(txHash).Return(nil, errors.New("tx not found"))
+		mockReader.EXPECT().Pending().Return(nil, db.ErrKeyNotFound)
 
 		tx, rpcErr := handler.TransactionReceiptByHash(txHash)
 		assert.Nil(t, tx)
@@ -702,7 +825,7 @@ func TestTransactionReceiptByHash(t *testing.T) {
 					"type": "DEPLOY",
 					"transaction_hash": "0xe0a2e45a80bb827967e096bcf58874f6c01c191e0a0530624cba66a508ae75",
 					"actual_fee": "0x0",
-					"status": "ACCEPTED_ON_L2",
+					"status": "ACCEPTED_ON_L1",
 					"block_hash": "0x47c3637b57c2b079b93c61539950c17e868a28f46cdef28f88521067f21e943",
 					"block_number": 0,
 					"messages_sent": [],
@@ -716,7 +839,7 @@ func TestTransactionReceiptByHash(t *testing.T) {
 					"type": "INVOKE",
 					"transaction_hash": "0xce54bbc5647e1c1ea4276c01a708523f740db0ff5474c77734f73beec2624",
 					"actual_fee": "0x0",
-					"status": "ACCEPTED_ON_L2",
+					"status": "ACCEPTED_ON_L1",
 					"block_hash": "0x47c3637b57c2b079b93c61539950c17e868a28f46cdef28f88521067f21e943",
 					"block_number": 0,
 					"messages_sent": [
@@ -737,6 +860,7 @@ func TestTransactionReceiptByHash(t *testing.T) {
 			txHash := block0.Transactions[test.index].Hash()
 			mockReader.EXPECT().TransactionByHash(txHash).Return(block0.Transactions[test.index], nil)
 			mockReader.EXPECT().Receipt(txHash).Return(block0.Receipts[test.index], block0.Hash, block0.Number, nil)
+			mockReader.EXPECT().BlockHeaderByNumber(block0.Number).Return(block0.Header, nil)
 
 			expectedMap := make(map[string]any)
 			require.NoError(t, json.Unmarshal([]byte(test.expected), &expectedMap))
@@ -759,7 +883,7 @@ func TestStateUpdate(t *testing.T) {
 	t.Cleanup(mockCtrl.Finish)
 
 	mockReader := mocks.NewMockReader(mockCtrl)
-	handler := rpc.New(mockReader, utils.MAINNET)
+	handler := rpc.New(mockReader, utils.MAINNET, utils.NewNopZapLogger())
 
 	t.Run("empty blockchain", func(t *testing.T) {
 		mockReader.EXPECT().Height().Return(uint64(0), errors.New("empty blockchain"))
@@ -889,3 +1013,810 @@ func TestStateUpdate(t *testing.T) {
 		}
 	})
 }
+
+func TestStorageAt(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mockReader := mocks.NewMockReader(mockCtrl)
+	mockState := mocks.NewMockStateReader(mockCtrl)
+	handler := rpc.New(mockReader, utils.MAINNET, utils.NewNopZapLogger())
+
+	t.Run("empty blockchain", func(t *testing.T) {
+		mockReader.EXPECT().HeadState().Return(nil, nil, errors.New("empty blockchain"))
+
+		storage, rpcErr := handler.StorageAt(&felt.Zero, &felt.Zero, &rpc.BlockID{Latest: true})
+		require.Nil(t, storage)
+		assert.Equal(t, rpc.ErrBlockNotFound, rpcErr)
+	})
+
+	t.Run("non-existent block hash", func(t *testing.T) {
+		mockReader.EXPECT().StateAtBlockHash(&felt.Zero).Return(nil, nil, errors.New("block not found"))
+
+		storage, rpcErr := handler.StorageAt(&felt.Zero, &felt.Zero, &rpc.BlockID{Hash: &felt.Zero})
+		require.Nil(t, storage)
+		assert.Equal(t, rpc.ErrBlockNotFound, rpcErr)
+	})
+
+	t.Run("non-existent block number", func(t *testing.T) {
+		mockReader.EXPECT().StateAtBlockNumber(uint64(0)).Return(nil, nil, errors.New("block not found"))
+
+		storage, rpcErr := handler.StorageAt(&felt.Zero, &felt.Zero, &rpc.BlockID{Number: 0})
+		require.Nil(t, storage)
+		assert.Equal(t, rpc.ErrBlockNotFound, rpcErr)
+	})
+
+	t.Run("no pending block", func(t *testing.T) {
+		mockReader.EXPECT().PendingState().Return(nil, nil, db.ErrKeyNotFound)
+
+		storage, rpcErr := handler.StorageAt(&felt.Zero, &felt.Zero, &rpc.BlockID{Pending: true})
+		require.Nil(t, storage)
+		assert.Equal(t, rpc.ErrBlockNotFound, rpcErr)
+	})
+
+	t.Run("non-existent contract", func(t *testing.T) {
+		mockReader.EXPECT().HeadState().Return(mockState, nopCloser, nil)
+		mockState.EXPECT().ContractStorage(gomock.Any(), gomock.Any()).Return(nil, errors.New("non-existent contract"))
+
+		storage, rpcErr := handler.StorageAt(&felt.Zero, &felt.Zero, &rpc.BlockID{Latest: true})
+		require.Nil(t, storage)
+		assert.Equal(t, rpc.ErrContractNotFound, rpcErr)
+	})
+
+	expectedStorage := new(felt.Felt).SetUint64(1)
+
+	t.Run("blockID - latest", func(t *testing.T) {
+		mockReader.EXPECT().HeadState().Return(mockState, nopCloser, nil)
+		mockState.EXPECT().ContractStorage(gomock.Any(), gomock.Any()).Return(expectedStorage, nil)
+
+		storage, rpcErr := handler.StorageAt(&felt.Zero, &felt.Zero, &rpc.BlockID{Latest: true})
+		require.Nil(t, rpcErr)
+		assert.Equal(t, expectedStorage, storage)
+	})
+
+	t.Run("blockID - hash", func(t *testing.T) {
+		mockReader.EXPECT().StateAtBlockHash(&felt.Zero).Return(mockState, nopCloser, nil)
+		mockState.EXPECT().ContractStorage(gomock.Any(), gomock.Any()).Return(expectedStorage, nil)
+
+		storage, rpcErr := handler.StorageAt(&felt.Zero, &felt.Zero, &rpc.BlockID{Hash: &felt.Zero})
+		require.Nil(t, rpcErr)
+		assert.Equal(t, expectedStorage, storage)
+	})
+
+	t.Run("blockID - number", func(t *testing.T) {
+		mockReader.EXPECT().StateAtBlockNumber(uint64(1)).Return(mockState, nopCloser, nil)
+		mockState.EXPECT().ContractStorage(gomock.Any(), gomock.Any()).Return(expectedStorage, nil)
+
+		storage, rpcErr := handler.StorageAt(&felt.Zero, &felt.Zero, &rpc.BlockID{Number: 1})
+		require.Nil(t, rpcErr)
+		assert.Equal(t, expectedStorage, storage)
+	})
+
+	t.Run("blockID - pending", func(t *testing.T) {
+		mockReader.EXPECT().PendingState().Return(mockState, nopCloser, nil)
+		mockState.EXPECT().ContractStorage(&felt.Zero, &felt.Zero).Return(expectedStorage, nil)
+
+		storage, rpcErr := handler.StorageAt(&felt.Zero, &felt.Zero, &rpc.BlockID{Pending: true})
+		require.Nil(t, rpcErr)
+		assert.Equal(t, expectedStorage, storage)
+	})
+}
+
+func TestNonce(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mockReader := mocks.NewMockReader(mockCtrl)
+	mockState := mocks.NewMockStateReader(mockCtrl)
+	handler := rpc.New(mockReader, utils.MAINNET, utils.NewNopZapLogger())
+
+	t.Run("empty blockchain", func(t *testing.T) {
+		mockReader.EXPECT().HeadState().Return(nil, nil, errors.New("empty blockchain"))
+
+		nonce, rpcErr := handler.Nonce(&rpc.BlockID{Latest: true}, &felt.Zero)
+		require.Nil(t, nonce)
+		assert.Equal(t, rpc.ErrBlockNotFound, rpcErr)
+	})
+
+	t.Run("non-existent block hash", func(t *testing.T) {
+		mockReader.EXPECT().StateAtBlockHash(&felt.Zero).Return(nil, nil, errors.New("block not found"))
+
+		nonce, rpcErr := handler.Nonce(&rpc.BlockID{Hash: &felt.Zero}, &felt.Zero)
+		require.Nil(t, nonce)
+		assert.Equal(t, rpc.ErrBlockNotFound, rpcErr)
+	})
+
+	t.Run("non-existent block number", func(t *testing.T) {
+		mockReader.EXPECT().StateAtBlockNumber(uint64(0)).Return(nil, nil, errors.New("block not found"))
+
+		nonce, rpcErr := handler.Nonce(&rpc.BlockID{Number: 0}, &felt.Zero)
+		require.Nil(t, nonce)
+		assert.Equal(t, rpc.ErrBlockNotFound, rpcErr)
+	})
+
+	t.Run("no pending block", func(t *testing.T) {
+		mockReader.EXPECT().PendingState().Return(nil, nil, db.ErrKeyNotFound)
+
+		nonce, rpcErr := handler.Nonce(&rpc.BlockID{Pending: true}, &felt.Zero)
+		require.Nil(t, nonce)
+		assert.Equal(t, rpc.ErrBlockNotFound, rpcErr)
+	})
+
+	t.Run("non-existent contract", func(t *testing.T) {
+		mockReader.EXPECT().HeadState().Return(mockState, nopCloser, nil)
+		mockState.EXPECT().ContractNonce(&felt.Zero).Return(nil, errors.New("non-existent contract"))
+
+		nonce, rpcErr := handler.Nonce(&rpc.BlockID{Latest: true}, &felt.Zero)
+		require.Nil(t, nonce)
+		assert.Equal(t, rpc.ErrContractNotFound, rpcErr)
+	})
+
+	expectedNonce := new(felt.Felt).SetUint64(1)
+
+	t.Run("blockID - latest", func(t *testing.T) {
+		mockReader.EXPECT().HeadState().Return(mockState, nopCloser, nil)
+		mockState.EXPECT().ContractNonce(&felt.Zero).Return(expectedNonce, nil)
+
+		nonce, rpcErr := handler.Nonce(&rpc.BlockID{Latest: true}, &felt.Zero)
+		require.Nil(t, rpcErr)
+		assert.Equal(t, expectedNonce, nonce)
+	})
+
+	t.Run("blockID - hash", func(t *testing.T) {
+		mockReader.EXPECT().StateAtBlockHash(&felt.Zero).Return(mockState, nopCloser, nil)
+		mockState.EXPECT().ContractNonce(&felt.Zero).Return(expectedNonce, nil)
+
+		nonce, rpcErr := handler.Nonce(&rpc.BlockID{Hash: &felt.Zero}, &felt.Zero)
+		require.Nil(t, rpcErr)
+		assert.Equal(t, expectedNonce, nonce)
+	})
+
+	t.Run("blockID - number", func(t *testing.T) {
+		mockReader.EXPECT().StateAtBlockNumber(uint64(1)).Return(mockState, nopCloser, nil)
+		mockState.EXPECT().ContractNonce(&felt.Zero).Return(expectedNonce, nil)
+
+		nonce, rpcErr := handler.Nonce(&rpc.BlockID{Number: 1}, &felt.Zero)
+		require.Nil(t, rpcErr)
+		assert.Equal(t, expectedNonce, nonce)
+	})
+
+	t.Run("blockID - pending", func(t *testing.T) {
+		mockReader.EXPECT().PendingState().Return(mockState, nopCloser, nil)
+		mockState.EXPECT().ContractNonce(&felt.Zero).Return(expectedNonce, nil)
+
+		nonce, rpcErr := handler.Nonce(&rpc.BlockID{Pending: true}, &felt.Zero)
+		require.Nil(t, rpcErr)
+		assert.Equal(t, expectedNonce, nonce)
+	})
+}
+
+func TestClassHashAt(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mockReader := mocks.NewMockReader(mockCtrl)
+	mockState := mocks.NewMockStateReader(mockCtrl)
+	handler := rpc.New(mockReader, utils.MAINNET, utils.NewNopZapLogger())
+
+	t.Run("empty blockchain", func(t *testing.T) {
+		mockReader.EXPECT().HeadState().Return(nil, nil, errors.New("empty blockchain"))
+
+		classHash, rpcErr := handler.ClassHashAt(&rpc.BlockID{Latest: true}, &felt.Zero)
+		require.Nil(t, classHash)
+		assert.Equal(t, rpc.ErrBlockNotFound, rpcErr)
+	})
+
+	t.Run("non-existent block hash", func(t *testing.T) {
+		mockReader.EXPECT().StateAtBlockHash(&felt.Zero).Return(nil, nil, errors.New("block not found"))
+
+		classHash, rpcErr := handler.ClassHashAt(&rpc.BlockID{Hash: &felt.Zero}, &felt.Zero)
+		require.Nil(t, classHash)
+		assert.Equal(t, rpc.ErrBlockNotFound, rpcErr)
+	})
+
+	t.Run("non-existent block number", func(t *testing.T) {
+		mockReader.EXPECT().StateAtBlockNumber(uint64(0)).Return(nil, nil, errors.New("block not found"))
+
+		classHash, rpcErr := handler.ClassHashAt(&rpc.BlockID{Number: 0}, &felt.Zero)
+		require.Nil(t, classHash)
+		assert.Equal(t, rpc.ErrBlockNotFound, rpcErr)
+	})
+
+	t.Run("no pending block", func(t *testing.T) {
+		mockReader.EXPECT().PendingState().Return(nil, nil, db.ErrKeyNotFound)
+
+		classHash, rpcErr := handler.ClassHashAt(&rpc.BlockID{Pending: true}, &felt.Zero)
+		require.Nil(t, classHash)
+		assert.Equal(t, rpc.ErrBlockNotFound, rpcErr)
+	})
+
+	t.Run("non-existent contract", func(t *testing.T) {
+		mockReader.EXPECT().HeadState().Return(mockState, nopCloser, nil)
+		mockState.EXPECT().ContractClassHash(&felt.Zero).Return(nil, errors.New("non-existent contract"))
+
+		classHash, rpcErr := handler.ClassHashAt(&rpc.BlockID{Latest: true}, &felt.Zero)
+		require.Nil(t, classHash)
+		assert.Equal(t, rpc.ErrContractNotFound, rpcErr)
+	})
+
+	expectedClassHash := new(felt.Felt).SetUint64(1)
+
+	t.Run("blockID - latest", func(t *testing.T) {
+		mockReader.EXPECT().HeadState().Return(mockState, nopCloser, nil)
+		mockState.EXPECT().ContractClassHash(&felt.Zero).Return(expectedClassHash, nil)
+
+		classHash, rpcErr := handler.ClassHashAt(&rpc.BlockID{Latest: true}, &felt.Zero)
+		require.Nil(t, rpcErr)
+		assert.Equal(t, expectedClassHash, classHash)
+	})
+
+	t.Run("blockID - hash", func(t *testing.T) {
+		mockReader.EXPECT().StateAtBlockHash(&felt.Zero).Return(mockState, nopCloser, nil)
+		mockState.EXPECT().ContractClassHash(&felt.Zero).Return(expectedClassHash, nil)
+
+		classHash, rpcErr := handler.ClassHashAt(&rpc.BlockID{Hash: &felt.Zero}, &felt.Zero)
+		require.Nil(t, rpcErr)
+		assert.Equal(t, expectedClassHash, classHash)
+	})
+
+	t.Run("blockID - number", func(t *testing.T) {
+		mockReader.EXPECT().StateAtBlockNumber(uint64(1)).Return(mockState, nopCloser, nil)
+		mockState.EXPECT().ContractClassHash(&felt.Zero).Return(expectedClassHash, nil)
+
+		classHash, rpcErr := handler.ClassHashAt(&rpc.BlockID{Number: 1}, &felt.Zero)
+		require.Nil(t, rpcErr)
+		assert.Equal(t, expectedClassHash, classHash)
+	})
+
+	t.Run("blockID - pending", func(t *testing.T) {
+		mockReader.EXPECT().PendingState().Return(mockState, nopCloser, nil)
+		mockState.EXPECT().ContractClassHash(&felt.Zero).Return(expectedClassHash, nil)
+
+		classHash, rpcErr := handler.ClassHashAt(&rpc.BlockID{Pending: true}, &felt.Zero)
+		require.Nil(t, rpcErr)
+		assert.Equal(t, expectedClassHash, classHash)
+	})
+}
+
+func TestClass(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mockReader := mocks.NewMockReader(mockCtrl)
+	mockState := mocks.NewMockStateReader(mockCtrl)
+	handler := rpc.New(mockReader, utils.GOERLI, utils.NewNopZapLogger())
+
+	goerliClient, closeFn := feeder.NewTestClient(utils.GOERLI)
+	t.Cleanup(closeFn)
+	integrationClient, closeFn := feeder.NewTestClient(utils.INTEGRATION)
+	t.Cleanup(closeFn)
+
+	cairo0Hash := utils.HexToFelt(t, "0x79e2d211e70594e687f9f788f71302e6eecb61d98efce48fbe8514948c8118")
+	cairo0Class, err := adaptfeeder.New(goerliClient).Class(context.Background(), cairo0Hash)
+	require.NoError(t, err)
+	cairo1Hash := utils.HexToFelt(t, "0x4e70b19333ae94bd958625f7b61ce9eec631653597e68645e13780061b2136c")
+	cairo1Class, err := adaptfeeder.New(integrationClient).Class(context.Background(), cairo1Hash)
+	require.NoError(t, err)
+
+	t.Run("non-existent block", func(t *testing.T) {
+		mockReader.EXPECT().StateAtBlockNumber(uint64(0)).Return(nil, nil, errors.New("block not found"))
+
+		class, rpcErr := handler.Class(&rpc.BlockID{Number: 0}, cairo0Hash)
+		require.Nil(t, class)
+		assert.Equal(t, rpc.ErrBlockNotFound, rpcErr)
+	})
+
+	t.Run("non-existent class", func(t *testing.T) {
+		mockReader.EXPECT().HeadState().Return(mockState, nopCloser, nil)
+		mockState.EXPECT().Class(cairo0Hash).Return(nil, errors.New("class not found"))
+
+		class, rpcErr := handler.Class(&rpc.BlockID{Latest: true}, cairo0Hash)
+		require.Nil(t, class)
+		assert.Equal(t, rpc.ErrClassHashNotFound, rpcErr)
+	})
+
+	t.Run("cairo 0 class", func(t *testing.T) {
+		mockReader.EXPECT().HeadState().Return(mockState, nopCloser, nil)
+		mockState.EXPECT().Class(cairo0Hash).Return(&core.DeclaredClass{Class: cairo0Class}, nil)
+
+		class, rpcErr := handler.Class(&rpc.BlockID{Latest: true}, cairo0Hash)
+		require.Nil(t, rpcErr)
+
+		coreClass := cairo0Class.(*core.Cairo0Class)
+		assert.Equal(t, coreClass.Program, class.Program)
+		assert.Equal(t, coreClass.Abi, class.Abi)
+		assert.Empty(t, class.SierraProgram)
+		assert.Empty(t, class.ContractClassVersion)
+		assertEntryPoints := func(t *testing.T, expected []core.EntryPoint, got []rpc.EntryPoint) {
+			t.Helper()
+			require.Len(t, got, len(expected))
+			for i := range expected {
+				assert.Nil(t, got[i].Index)
+				assert.Equal(t, expected[i].Offset, got[i].Offset)
+				assert.Equal(t, expected[i].Selector, got[i].Selector)
+			}
+		}
+		assertEntryPoints(t, coreClass.Constructors, class.EntryPoints.Constructor)
+		assertEntryPoints(t, coreClass.Externals, class.EntryPoints.External)
+		assertEntryPoints(t, coreClass.L1Handlers, class.EntryPoints.L1Handler)
+	})
+
+	t.Run("cairo 1 class", func(t *testing.T) {
+		mockReader.EXPECT().HeadState().Return(mockState, nopCloser, nil)
+		mockState.EXPECT().Class(cairo1Hash).Return(&core.DeclaredClass{Class: cairo1Class}, nil)
+
+		class, rpcErr := handler.Class(&rpc.BlockID{Latest: true}, cairo1Hash)
+		require.Nil(t, rpcErr)
+
+		coreClass := cairo1Class.(*core.Cairo1Class)
+		assert.Equal(t, coreClass.Program, class.SierraProgram)
+		assert.Equal(t, coreClass.SemanticVersion, class.ContractClassVersion)
+		assert.Equal(t, coreClass.Abi, class.Abi)
+		assert.Empty(t, class.Program)
+		assertEntryPoints := func(t *testing.T, expected []core.SierraEntryPoint, got []rpc.EntryPoint) {
+			t.Helper()
+			require.Len(t, got, len(expected))
+			for i := range expected {
+				assert.Nil(t, got[i].Offset)
+				assert.Equal(t, expected[i].Index, *got[i].Index)
+				assert.Equal(t, expected[i].Selector, got[i].Selector)
+			}
+		}
+		assertEntryPoints(t, coreClass.EntryPoints.Constructor, class.EntryPoints.Constructor)
+		assertEntryPoints(t, coreClass.EntryPoints.External, class.EntryPoints.External)
+		assertEntryPoints(t, coreClass.EntryPoints.L1Handler, class.EntryPoints.L1Handler)
+	})
+}
+
+func TestClassAt(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mockReader := mocks.NewMockReader(mockCtrl)
+	mockState := mocks.NewMockStateReader(mockCtrl)
+	handler := rpc.New(mockReader, utils.GOERLI, utils.NewNopZapLogger())
+
+	client, closeFn := feeder.NewTestClient(utils.GOERLI)
+	t.Cleanup(closeFn)
+
+	classHash := utils.HexToFelt(t, "0x79e2d211e70594e687f9f788f71302e6eecb61d98efce48fbe8514948c8118")
+	coreClass, err := adaptfeeder.New(client).Class(context.Background(), classHash)
+	require.NoError(t, err)
+
+	t.Run("non-existent contract", func(t *testing.T) {
+		mockReader.EXPECT().HeadState().Return(mockState, nopCloser, nil)
+		mockState.EXPECT().ContractClassHash(&felt.Zero).Return(nil, errors.New("contract not found"))
+
+		class, rpcErr := handler.ClassAt(&rpc.BlockID{Latest: true}, &felt.Zero)
+		require.Nil(t, class)
+		assert.Equal(t, rpc.ErrContractNotFound, rpcErr)
+	})
+
+	t.Run("class of the contract", func(t *testing.T) {
+		mockReader.EXPECT().StateAtBlockHash(&felt.Zero).Return(mockState, nopCloser, nil)
+		mockState.EXPECT().ContractClassHash(&felt.Zero).Return(classHash, nil)
+		mockState.EXPECT().Class(classHash).Return(&core.DeclaredClass{Class: coreClass}, nil)
+
+		class, rpcErr := handler.ClassAt(&rpc.BlockID{Hash: &felt.Zero}, &felt.Zero)
+		require.Nil(t, rpcErr)
+		assert.Equal(t, coreClass.(*core.Cairo0Class).Program, class.Program)
+	})
+}
+
+func TestEvents(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mockReader := mocks.NewMockReader(mockCtrl)
+	handler := rpc.New(mockReader, utils.MAINNET, utils.NewNopZapLogger())
+
+	t.Run("chunk size too big", func(t *testing.T) {
+		args := &rpc.EventsArg{ResultPageRequest: rpc.ResultPageRequest{ChunkSize: math.MaxUint64}}
+		chunk, rpcErr := handler.Events(args)
+		require.Nil(t, chunk)
+		assert.Equal(t, rpc.ErrPageSizeTooBig, rpcErr)
+	})
+
+	t.Run("too many keys", func(t *testing.T) {
+		args := &rpc.EventsArg{
+			EventFilter:       rpc.EventFilter{Keys: [][]*felt.Felt{make([]*felt.Felt, 1025)}},
+			ResultPageRequest: rpc.ResultPageRequest{ChunkSize: 1},
+		}
+		chunk, rpcErr := handler.Events(args)
+		require.Nil(t, chunk)
+		assert.Equal(t, rpc.ErrTooManyKeysInFilter, rpcErr)
+	})
+
+	t.Run("malformed continuation token", func(t *testing.T) {
+		args := &rpc.EventsArg{ResultPageRequest: rpc.ResultPageRequest{ChunkSize: 1, ContinuationToken: "token"}}
+		chunk, rpcErr := handler.Events(args)
+		require.Nil(t, chunk)
+		assert.Equal(t, rpc.ErrInvalidContinuationToken, rpcErr)
+	})
+
+	t.Run("non-existent block", func(t *testing.T) {
+		mockReader.EXPECT().BlockHeaderByHash(&felt.Zero).Return(nil, errors.New("block not found"))
+
+		args := &rpc.EventsArg{
+			EventFilter:       rpc.EventFilter{FromBlock: &rpc.BlockID{Hash: &felt.Zero}},
+			ResultPageRequest: rpc.ResultPageRequest{ChunkSize: 1},
+		}
+		chunk, rpcErr := handler.Events(args)
+		require.Nil(t, chunk)
+		assert.Equal(t, rpc.ErrBlockNotFound, rpcErr)
+	})
+
+	t.Run("continuation token rejected by the blockchain", func(t *testing.T) {
+		mockReader.EXPECT().Events(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, nil, blockchain.ErrInvalidContinuationToken)
+
+		args := &rpc.EventsArg{ResultPageRequest: rpc.ResultPageRequest{ChunkSize: 1, ContinuationToken: "5-0"}}
+		chunk, rpcErr := handler.Events(args)
+		require.Nil(t, chunk)
+		assert.Equal(t, rpc.ErrInvalidContinuationToken, rpcErr)
+	})
+
+	t.Run("filtered events", func(t *testing.T) {
+		address := utils.HexToFelt(t, "0x1")
+		keys := [][]*felt.Felt{{utils.HexToFelt(t, "0x2")}}
+		mockReader.EXPECT().HeadsHeader().Return(&core.Header{Number: 7}, nil)
+		mockReader.EXPECT().BlockHeaderByNumber(uint64(3)).Return(&core.Header{Number: 3}, nil)
+
+		filteredEvent := &blockchain.FilteredEvent{
+			Event:           &core.Event{From: address, Keys: keys[0], Data: []*felt.Felt{utils.HexToFelt(t, "0x3")}},
+			BlockNumber:     4,
+			BlockHash:       utils.HexToFelt(t, "0x4"),
+			TransactionHash: utils.HexToFelt(t, "0x5"),
+		}
+		mockReader.EXPECT().Events(&blockchain.EventFilter{
+			FromBlock: 3,
+			ToBlock:   7,
+			Address:   address,
+			Keys:      keys,
+		}, &blockchain.ContinuationToken{BlockNumber: 4, Offset: 1}, uint64(1)).
+			Return([]*blockchain.FilteredEvent{filteredEvent}, &blockchain.ContinuationToken{BlockNumber: 5}, nil)
+
+		args := &rpc.EventsArg{
+			EventFilter: rpc.EventFilter{
+				FromBlock: &rpc.BlockID{Number: 3},
+				ToBlock:   &rpc.BlockID{Pending: true},
+				Address:   address,
+				Keys:      keys,
+			},
+			ResultPageRequest: rpc.ResultPageRequest{ChunkSize: 1, ContinuationToken: "4-1"},
+		}
+		chunk, rpcErr := handler.Events(args)
+		require.Nil(t, rpcErr)
+		assert.Equal(t, &rpc.EventsChunk{
+			Events: []*rpc.EmittedEvent{{
+				Event: &rpc.Event{
+					From: filteredEvent.From,
+					Keys: filteredEvent.Keys,
+					Data: filteredEvent.Data,
+				},
+				BlockNumber:     filteredEvent.BlockNumber,
+				BlockHash:       filteredEvent.BlockHash,
+				TransactionHash: filteredEvent.TransactionHash,
+			}},
+			ContinuationToken: "5-0",
+		}, chunk)
+	})
+}
+
+func nopCloser() error {
+	return nil
+}
+
+func TestPending(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mockReader := mocks.NewMockReader(mockCtrl)
+	handler := rpc.New(mockReader, utils.MAINNET, utils.NewNopZapLogger())
+
+	client, closer := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closer)
+	gw := adaptfeeder.New(client)
+
+	pendingBlock, err := gw.BlockPending(context.Background())
+	require.NoError(t, err)
+	pendingUpdate, err := gw.StateUpdatePending(context.Background())
+	require.NoError(t, err)
+	pending := &blockchain.Pending{
+		Block:       pendingBlock,
+		StateUpdate: pendingUpdate,
+	}
+	pendingID := &rpc.BlockID{Pending: true}
+
+	t.Run("no pending block", func(t *testing.T) {
+		mockReader.EXPECT().Pending().Return(nil, db.ErrKeyNotFound)
+
+		block, rpcErr := handler.BlockWithTxHashes(pendingID)
+		assert.Nil(t, block)
+		assert.Equal(t, rpc.ErrBlockNotFound, rpcErr)
+	})
+
+	t.Run("block with tx hashes", func(t *testing.T) {
+		mockReader.EXPECT().Pending().Return(pending, nil)
+
+		block, rpcErr := handler.BlockWithTxHashes(pendingID)
+		require.Nil(t, rpcErr)
+		assert.Equal(t, rpc.StatusPending, block.Status)
+		assert.Equal(t, pendingBlock.ParentHash, block.ParentHash)
+		assert.Len(t, block.TxnHashes, len(pendingBlock.Transactions))
+
+		blockJSON, err := json.Marshal(block)
+		require.NoError(t, err)
+		blockMap := make(map[string]any)
+		require.NoError(t, json.Unmarshal(blockJSON, &blockMap))
+		assert.NotContains(t, blockMap, "block_hash")
+		assert.NotContains(t, blockMap, "block_number")
+		assert.NotContains(t, blockMap, "new_root")
+	})
+
+	t.Run("block with txs", func(t *testing.T) {
+		mockReader.EXPECT().Pending().Return(pending, nil)
+
+		block, rpcErr := handler.BlockWithTxs(pendingID)
+		require.Nil(t, rpcErr)
+		assert.Equal(t, rpc.StatusPending, block.Status)
+		assert.Nil(t, block.Hash)
+		assert.Nil(t, block.Number)
+		assert.Len(t, block.Transactions, len(pendingBlock.Transactions))
+	})
+
+	t.Run("transaction count", func(t *testing.T) {
+		mockReader.EXPECT().Pending().Return(pending, nil)
+
+		count, rpcErr := handler.BlockTransactionCount(pendingID)
+		require.Nil(t, rpcErr)
+		assert.Equal(t, pendingBlock.TransactionCount, count)
+	})
+
+	t.Run("transaction by index", func(t *testing.T) {
+		mockReader.EXPECT().Pending().Return(pending, nil)
+
+		txn, rpcErr := handler.TransactionByBlockIDAndIndex(pendingID, 1)
+		require.Nil(t, rpcErr)
+		assert.Equal(t, pendingBlock.Transactions[1].Hash(), txn.Hash)
+
+		mockReader.EXPECT().Pending().Return(pending, nil)
+
+		_, rpcErr = handler.TransactionByBlockIDAndIndex(pendingID, len(pendingBlock.Transactions))
+		assert.Equal(t, rpc.ErrInvalidTxIndex, rpcErr)
+	})
+
+	txHash := pendingBlock.Transactions[1].Hash()
+
+	t.Run("transaction by hash", func(t *testing.T) {
+		mockReader.EXPECT().TransactionByHash(txHash).Return(nil, db.ErrKeyNotFound)
+		mockReader.EXPECT().Pending().Return(pending, nil)
+
+		txn, rpcErr := handler.TransactionByHash(txHash)
+		require.Nil(t, rpcErr)
+		assert.Equal(t, txHash, txn.Hash)
+	})
+
+	t.Run("transaction receipt", func(t *testing.T) {
+		mockReader.EXPECT().TransactionByHash(txHash).Return(nil, db.ErrKeyNotFound)
+		mockReader.EXPECT().Receipt(txHash).Return(nil, nil, uint64(0), db.ErrKeyNotFound)
+		mockReader.EXPECT().Pending().Return(pending, nil).Times(2)
+
+		receipt, rpcErr := handler.TransactionReceiptByHash(txHash)
+		require.Nil(t, rpcErr)
+		assert.Equal(t, rpc.StatusPending, receipt.Status)
+		assert.Equal(t, txHash, receipt.Hash)
+		assert.Nil(t, receipt.BlockHash)
+		assert.Nil(t, receipt.BlockNumber)
+	})
+
+	t.Run("state update", func(t *testing.T) {
+		mockReader.EXPECT().Pending().Return(pending, nil)
+
+		update, rpcErr := handler.StateUpdate(pendingID)
+		require.Nil(t, rpcErr)
+		assert.Nil(t, update.BlockHash)
+		assert.Nil(t, update.NewRoot)
+		assert.Equal(t, pendingUpdate.OldRoot, update.OldRoot)
+		assert.Len(t, update.StateDiff.DeployedContracts, len(pendingUpdate.StateDiff.DeployedContracts))
+	})
+}
+
+func TestProof(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mockReader := mocks.NewMockReader(mockCtrl)
+	handler := rpc.New(mockReader, utils.MAINNET, utils.NewNopZapLogger())
+
+	address := utils.HexToFelt(t, "0x1")
+	keys := []*felt.Felt{utils.HexToFelt(t, "0x2")}
+	head := &core.Header{Number: 5, Hash: utils.HexToFelt(t, "0x55"), GlobalStateRoot: utils.HexToFelt(t, "0x66")}
+
+	t.Run("too many keys", func(t *testing.T) {
+		proof, rpcErr := handler.Proof(&rpc.BlockID{Latest: true}, address, make([]*felt.Felt, 101))
+		assert.Nil(t, proof)
+		assert.Equal(t, rpc.ErrProofLimitExceeded, rpcErr)
+	})
+
+	t.Run("pending block", func(t *testing.T) {
+		proof, rpcErr := handler.Proof(&rpc.BlockID{Pending: true}, address, keys)
+		assert.Nil(t, proof)
+		assert.Equal(t, rpc.ErrProofNotAvailable, rpcErr)
+	})
+
+	t.Run("block not found", func(t *testing.T) {
+		mockReader.EXPECT().BlockHeaderByNumber(uint64(7)).Return(nil, db.ErrKeyNotFound)
+
+		proof, rpcErr := handler.Proof(&rpc.BlockID{Number: 7}, address, keys)
+		assert.Nil(t, proof)
+		assert.Equal(t, rpc.ErrBlockNotFound, rpcErr)
+	})
+
+	stateProof := &core.StateProof{
+		ContractsRoot: utils.HexToFelt(t, "0x66"),
+		ClassesRoot:   new(felt.Felt),
+		ContractProof: []trie.ProofNode{
+			{Binary: &trie.BinaryNode{LeftHash: utils.HexToFelt(t, "0x3"), RightHash: utils.HexToFelt(t, "0x4")}},
+			{Edge: &trie.EdgeNode{Child: utils.HexToFelt(t, "0x5"), Path: utils.HexToFelt(t, "0x1"), Len: 250}},
+		},
+		Contract: &core.ContractProof{
+			ClassHash:   utils.HexToFelt(t, "0x7"),
+			Nonce:       utils.HexToFelt(t, "0x8"),
+			StorageRoot: utils.HexToFelt(t, "0x9"),
+			StorageProofs: [][]trie.ProofNode{{
+				{Edge: &trie.EdgeNode{Child: utils.HexToFelt(t, "0xa"), Path: utils.HexToFelt(t, "0x2"), Len: 251}},
+			}},
+		},
+	}
+
+	t.Run("block behind the head", func(t *testing.T) {
+		header := &core.Header{Number: 4, Hash: utils.HexToFelt(t, "0x44"), GlobalStateRoot: utils.HexToFelt(t, "0x66")}
+		mockReader.EXPECT().BlockHeaderByNumber(uint64(4)).Return(header, nil)
+		mockReader.EXPECT().StateProof(uint64(4), address, keys).Return(stateProof, header, nil)
+
+		proof, rpcErr := handler.Proof(&rpc.BlockID{Number: 4}, address, keys)
+		require.Nil(t, rpcErr)
+		assert.Equal(t, stateProof.ContractsRoot, proof.StateCommitment)
+	})
+
+	t.Run("block too far behind the head", func(t *testing.T) {
+		mockReader.EXPECT().BlockHeaderByNumber(uint64(1)).Return(&core.Header{Number: 1, Hash: utils.HexToFelt(t, "0x11")}, nil)
+		mockReader.EXPECT().StateProof(uint64(1), address, keys).Return(nil, nil, blockchain.ErrStateProofTooDeep)
+
+		proof, rpcErr := handler.Proof(&rpc.BlockID{Number: 1}, address, keys)
+		assert.Nil(t, proof)
+		assert.Equal(t, rpc.ErrProofNotAvailable, rpcErr)
+	})
+
+	t.Run("internal errors are not leaked", func(t *testing.T) {
+		mockReader.EXPECT().HeadsHeader().Return(head, nil)
+		mockReader.EXPECT().StateProof(head.Number, address, keys).Return(nil, nil, errors.New("corrupt trie node at /secret/path"))
+
+		proof, rpcErr := handler.Proof(&rpc.BlockID{Latest: true}, address, keys)
+		assert.Nil(t, proof)
+		assert.Equal(t, jsonrpc.Err(jsonrpc.InternalError, nil), rpcErr)
+	})
+
+	t.Run("latest block", func(t *testing.T) {
+		mockReader.EXPECT().HeadsHeader().Return(head, nil)
+		mockReader.EXPECT().StateProof(head.Number, address, keys).Return(stateProof, head, nil)
+
+		proof, rpcErr := handler.Proof(&rpc.BlockID{Latest: true}, address, keys)
+		require.Nil(t, rpcErr)
+
+		proofJSON, err := json.Marshal(proof)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"state_commitment": "0x66",
+			"class_commitment": "0x0",
+			"contract_proof": [
+				{"binary": {"left": "0x3", "right": "0x4"}},
+				{"edge": {"child": "0x5", "path": {"value": "0x1", "len": 250}}}
+			],
+			"contract_data": {
+				"class_hash": "0x7",
+				"nonce": "0x8",
+				"root": "0x9",
+				"contract_state_hash_version": "0x0",
+				"storage_proofs": [[{"edge": {"child": "0xa", "path": {"value": "0x2", "len": 251}}}]]
+			}
+		}`, string(proofJSON))
+	})
+
+	t.Run("no contract at the address", func(t *testing.T) {
+		mockReader.EXPECT().HeadsHeader().Return(head, nil)
+		mockReader.EXPECT().StateProof(head.Number, address, keys).Return(&core.StateProof{
+			ContractsRoot: stateProof.ContractsRoot,
+			ClassesRoot:   stateProof.ClassesRoot,
+			ContractProof: stateProof.ContractProof,
+		}, head, nil)
+
+		proof, rpcErr := handler.Proof(&rpc.BlockID{Latest: true}, address, keys)
+		require.Nil(t, rpcErr)
+		assert.Nil(t, proof.ContractData)
+		assert.Len(t, proof.ContractProof, 2)
+	})
+}
+
+func TestStorageRange(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mockReader := mocks.NewMockReader(mockCtrl)
+	handler := rpc.New(mockReader, utils.MAINNET, utils.NewNopZapLogger())
+
+	address := utils.HexToFelt(t, "0x1")
+	head := &core.Header{Number: 5, Hash: utils.HexToFelt(t, "0x55")}
+
+	t.Run("limit is zero", func(t *testing.T) {
+		storageRange, rpcErr := handler.StorageRange(address, nil, 0)
+		assert.Nil(t, storageRange)
+		assert.Equal(t, jsonrpc.InvalidParams, rpcErr.Code)
+	})
+
+	t.Run("limit is too big", func(t *testing.T) {
+		storageRange, rpcErr := handler.StorageRange(address, nil, 1025)
+		assert.Nil(t, storageRange)
+		assert.Equal(t, rpc.ErrPageSizeTooBig, rpcErr)
+	})
+
+	t.Run("empty blockchain", func(t *testing.T) {
+		mockReader.EXPECT().HeadContractStorage(address, nil, gomock.Any()).Return(nil, db.ErrKeyNotFound)
+
+		storageRange, rpcErr := handler.StorageRange(address, nil, 10)
+		assert.Nil(t, storageRange)
+		assert.Equal(t, rpc.ErrNoBlock, rpcErr)
+	})
+
+	t.Run("contract is not deployed", func(t *testing.T) {
+		mockReader.EXPECT().HeadContractStorage(address, nil, gomock.Any()).Return(nil, core.ErrContractNotDeployed)
+
+		storageRange, rpcErr := handler.StorageRange(address, nil, 10)
+		assert.Nil(t, storageRange)
+		assert.Equal(t, rpc.ErrContractNotFound, rpcErr)
+	})
+
+	// iterate serves the storage locations 0x10, 0x11, ... 0x14 from the given start key
+	iterate := func(_, start *felt.Felt, fn trie.LeafFunc) (*core.Header, error) {
+		for i := uint64(0x10); i < 0x15; i++ {
+			key := new(felt.Felt).SetUint64(i)
+			if start != nil && key.Cmp(start) < 0 {
+				continue
+			}
+			if next, err := fn(key, new(felt.Felt).SetUint64(i*2)); err != nil || !next {
+				return head, err
+			}
+		}
+		return head, nil
+	}
+
+	t.Run("first page", func(t *testing.T) {
+		mockReader.EXPECT().HeadContractStorage(address, nil, gomock.Any()).DoAndReturn(iterate)
+
+		storageRange, rpcErr := handler.StorageRange(address, nil, 2)
+		require.Nil(t, rpcErr)
+
+		storageRangeJSON, err := json.Marshal(storageRange)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"block_hash": "0x55",
+			"block_number": 5,
+			"storage": [{"key": "0x10", "value": "0x20"}, {"key": "0x11", "value": "0x22"}],
+			"next_key": "0x12"
+		}`, string(storageRangeJSON))
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		start := utils.HexToFelt(t, "0x13")
+		mockReader.EXPECT().HeadContractStorage(address, start, gomock.Any()).DoAndReturn(iterate)
+
+		storageRange, rpcErr := handler.StorageRange(address, start, 2)
+		require.Nil(t, rpcErr)
+		assert.Equal(t, []rpc.StorageEntry{
+			{Key: utils.HexToFelt(t, "0x13"), Value: utils.HexToFelt(t, "0x26")},
+			{Key: utils.HexToFelt(t, "0x14"), Value: utils.HexToFelt(t, "0x28")},
+		}, storageRange.Storage)
+		assert.Nil(t, storageRange.NextKey)
+	})
+}
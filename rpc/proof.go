@@ -0,0 +1,92 @@
+package rpc
+
+import (
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/core/trie"
+)
+
+// The types below follow the format of pathfinder_getProof, so that its clients can use juno_getProof too.
+
+type ProofNode struct {
+	Binary *BinaryNode `json:"binary,omitempty"`
+	Edge   *EdgeNode   `json:"edge,omitempty"`
+}
+
+type BinaryNode struct {
+	Left  *felt.Felt `json:"left"`
+	Right *felt.Felt `json:"right"`
+}
+
+type EdgeNode struct {
+	Child *felt.Felt `json:"child"`
+	Path  EdgePath   `json:"path"`
+}
+
+type EdgePath struct {
+	Value *felt.Felt `json:"value"`
+	Len   uint8      `json:"len"`
+}
+
+type ContractData struct {
+	ClassHash *felt.Felt `json:"class_hash"`
+	Nonce     *felt.Felt `json:"nonce"`
+	Root      *felt.Felt `json:"root"`
+	// ContractStateHashVersion is the version of the contract commitment, it is always zero
+	ContractStateHashVersion *felt.Felt    `json:"contract_state_hash_version"`
+	StorageProofs            [][]ProofNode `json:"storage_proofs"`
+}
+
+// Proof proves the state of a contract against the state commitment of a block.
+// The contract commitment is proved against the root of the contracts trie, which the state commitment
+// is derived from along with the class commitment.
+type Proof struct {
+	StateCommitment *felt.Felt  `json:"state_commitment"`
+	ClassCommitment *felt.Felt  `json:"class_commitment"`
+	ContractProof   []ProofNode `json:"contract_proof"`
+	// ContractData is nil if there is no contract at the address
+	ContractData *ContractData `json:"contract_data"`
+}
+
+func adaptProof(header *core.Header, proof *core.StateProof) *Proof {
+	adapted := &Proof{
+		StateCommitment: header.GlobalStateRoot,
+		ClassCommitment: proof.ClassesRoot,
+		ContractProof:   adaptProofNodes(proof.ContractProof),
+	}
+
+	if proof.Contract != nil {
+		adapted.ContractData = &ContractData{
+			ClassHash:                proof.Contract.ClassHash,
+			Nonce:                    proof.Contract.Nonce,
+			Root:                     proof.Contract.StorageRoot,
+			ContractStateHashVersion: new(felt.Felt),
+			StorageProofs:            make([][]ProofNode, len(proof.Contract.StorageProofs)),
+		}
+		for i, storageProof := range proof.Contract.StorageProofs {
+			adapted.ContractData.StorageProofs[i] = adaptProofNodes(storageProof)
+		}
+	}
+	return adapted
+}
+
+func adaptProofNodes(nodes []trie.ProofNode) []ProofNode {
+	adapted := make([]ProofNode, len(nodes))
+	for i, node := range nodes {
+		if node.Binary != nil {
+			adapted[i].Binary = &BinaryNode{
+				Left:  node.Binary.LeftHash,
+				Right: node.Binary.RightHash,
+			}
+		} else {
+			adapted[i].Edge = &EdgeNode{
+				Child: node.Edge.Child,
+				Path: EdgePath{
+					Value: node.Edge.Path,
+					Len:   node.Edge.Len,
+				},
+			}
+		}
+	}
+	return adapted
+}
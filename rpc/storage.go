@@ -0,0 +1,18 @@
+package rpc
+
+import "github.com/NethermindEth/juno/core/felt"
+
+type StorageEntry struct {
+	Key   *felt.Felt `json:"key"`
+	Value *felt.Felt `json:"value"`
+}
+
+// StorageRange is a page of the storage of a contract, as returned by juno_getStorageRange. The storage is
+// read from the state of the given block, which is the latest block at the time of the request.
+type StorageRange struct {
+	BlockHash   *felt.Felt     `json:"block_hash"`
+	BlockNumber uint64         `json:"block_number"`
+	Storage     []StorageEntry `json:"storage"`
+	// NextKey is the key to request the next page from, it is nil on the last page
+	NextKey *felt.Felt `json:"next_key"`
+}
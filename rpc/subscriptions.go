@@ -0,0 +1,274 @@
+package rpc
+
+import (
+	"context"
+	"sync"
+
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/jsonrpc"
+	"github.com/NethermindEth/juno/service"
+)
+
+const (
+	// SubscriptionMethod is the method of the notifications sent to subscribers.
+	SubscriptionMethod = "juno_subscription"
+	// notifierBufferSize is the number of stored blocks the Notifier can fall behind by before it starts
+	// skipping them.
+	notifierBufferSize = 64
+)
+
+var _ service.Service = (*Notifier)(nil)
+
+var ErrSubscriptionsNotSupported = jsonrpc.Err(jsonrpc.InvalidRequest, "subscriptions require a WebSocket connection")
+
+// SubscriptionNotification is the params object of a notification sent to a subscriber.
+type SubscriptionNotification struct {
+	Subscription uint64 `json:"subscription"`
+	Result       any    `json:"result"`
+}
+
+// EventSubscriptionFilter selects the events that are sent to a subscriber, it is matched like an [EventFilter].
+type EventSubscriptionFilter struct {
+	Address *felt.Felt     `json:"address"`
+	Keys    [][]*felt.Felt `json:"keys"`
+}
+
+// TransactionStatus is sent to the subscribers of a transaction when its status changes.
+type TransactionStatus struct {
+	TransactionHash *felt.Felt `json:"transaction_hash"`
+	Status          Status     `json:"status"`
+}
+
+type subscription struct {
+	conn jsonrpc.Conn
+	// onBlock returns the results to send to the subscriber for a newly stored block
+	onBlock func(block *core.Block) []any
+	// onStatus returns the results to send to the subscriber when the status of a stored block changes,
+	// it is nil if the subscriber is not interested in them
+	onStatus func(header *core.Header) []any
+	done     chan struct{}
+}
+
+type subscriptions struct {
+	mu     sync.Mutex
+	nextID uint64
+	subs   map[uint64]*subscription
+}
+
+// SubscribeNewHeads subscribes the caller to the headers of the blocks that are stored from now on.
+func (h *Handler) SubscribeNewHeads(ctx context.Context) (uint64, *jsonrpc.Error) {
+	return h.subscribe(ctx, &subscription{
+		onBlock: func(block *core.Block) []any {
+			_, header := adaptBlockHeader(block.Header, false)
+			return []any{header}
+		},
+	})
+}
+
+// SubscribeEvents subscribes the caller to the events matching the given filter that are emitted in the
+// blocks stored from now on.
+func (h *Handler) SubscribeEvents(ctx context.Context, filter *EventSubscriptionFilter) (uint64, *jsonrpc.Error) {
+	var numKeys int
+	for _, keys := range filter.Keys {
+		numKeys += len(keys)
+	}
+	if numKeys > maxEventFilterKeys {
+		return 0, ErrTooManyKeysInFilter
+	}
+
+	eventFilter := &blockchain.EventFilter{
+		Address: filter.Address,
+		Keys:    filter.Keys,
+	}
+	return h.subscribe(ctx, &subscription{onBlock: func(block *core.Block) []any {
+		var events []any
+		for _, receipt := range block.Receipts {
+			for _, event := range receipt.Events {
+				if !eventFilter.Matches(event) {
+					continue
+				}
+
+				events = append(events, &EmittedEvent{
+					Event: &Event{
+						From: event.From,
+						Keys: event.Keys,
+						Data: event.Data,
+					},
+					BlockNumber:     block.Number,
+					BlockHash:       block.Hash,
+					TransactionHash: receipt.TransactionHash,
+				})
+			}
+		}
+		return events
+	}})
+}
+
+// SubscribeTransactionStatus subscribes the caller to the status changes of the transaction with the given
+// hash. The status is checked every time a block is stored or the status of a block changes.
+func (h *Handler) SubscribeTransactionStatus(ctx context.Context, hash *felt.Felt) (uint64, *jsonrpc.Error) {
+	var notified *Status
+	check := func() []any {
+		_, _, number, err := h.bcReader.Receipt(hash)
+		if err != nil {
+			return nil
+		}
+
+		header, err := h.bcReader.BlockHeaderByNumber(number)
+		if err != nil {
+			return nil
+		}
+
+		status := adaptBlockStatus(header.Status)
+		if notified != nil && *notified == status {
+			return nil
+		}
+		notified = &status
+		return []any{&TransactionStatus{TransactionHash: hash, Status: status}}
+	}
+	return h.subscribe(ctx, &subscription{
+		onBlock: func(*core.Block) []any {
+			return check()
+		},
+		onStatus: func(*core.Header) []any {
+			return check()
+		},
+	})
+}
+
+// Unsubscribe cancels a subscription of the caller.
+func (h *Handler) Unsubscribe(ctx context.Context, id uint64) (bool, *jsonrpc.Error) {
+	conn, ok := jsonrpc.ConnFromContext(ctx)
+	if !ok {
+		return false, ErrSubscriptionsNotSupported
+	}
+
+	h.subscriptions.mu.Lock()
+	sub, found := h.subscriptions.subs[id]
+	h.subscriptions.mu.Unlock()
+	if !found || sub.conn != conn {
+		return false, nil
+	}
+
+	h.unsubscribe(id)
+	return true, nil
+}
+
+// NotifyNewBlock sends the notifications for a newly stored block to the subscribers.
+func (h *Handler) NotifyNewBlock(block *core.Block) {
+	h.notify(func(sub *subscription) []any {
+		return sub.onBlock(block)
+	})
+}
+
+// NotifyBlockStatus sends the notifications for a change of the status of a stored block to the
+// subscribers, the header carries the new status.
+func (h *Handler) NotifyBlockStatus(header *core.Header) {
+	h.notify(func(sub *subscription) []any {
+		if sub.onStatus == nil {
+			return nil
+		}
+		return sub.onStatus(header)
+	})
+}
+
+// notify sends the results that the given function returns for each subscriber to it.
+func (h *Handler) notify(results func(sub *subscription) []any) {
+	h.subscriptions.mu.Lock()
+	subs := make(map[uint64]*subscription, len(h.subscriptions.subs))
+	for id, sub := range h.subscriptions.subs {
+		subs[id] = sub
+	}
+	h.subscriptions.mu.Unlock()
+
+	for id, sub := range subs {
+		for _, result := range results(sub) {
+			if err := sub.conn.Notify(SubscriptionMethod, &SubscriptionNotification{
+				Subscription: id,
+				Result:       result,
+			}); err != nil {
+				h.log.Debugw("Failed notifying subscriber", "subscription", id, "err", err)
+				h.unsubscribe(id)
+				break
+			}
+		}
+	}
+}
+
+// Notifier is a service that sends the notifications to the subscribers of a [Handler] as the blocks are
+// stored in the blockchain and their statuses change.
+type Notifier struct {
+	handler *Handler
+	sub     *blockchain.Subscription
+}
+
+// NewNotifier subscribes to the events of the given blockchain, the blocks stored from now on are
+// notified once the Notifier runs.
+func NewNotifier(handler *Handler, chain *blockchain.Blockchain) *Notifier {
+	return &Notifier{
+		handler: handler,
+		sub:     chain.Subscribe(notifierBufferSize, blockchain.SlowConsumerDrop),
+	}
+}
+
+// Run sends the notifications until the context is cancelled
+func (n *Notifier) Run(ctx context.Context) error {
+	defer n.sub.Unsubscribe()
+
+	var dropped uint64
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-n.sub.Events():
+			switch event := event.(type) {
+			case *blockchain.BlockStored:
+				n.handler.NotifyNewBlock(event.Block)
+			case *blockchain.BlockStatusChanged:
+				n.handler.NotifyBlockStatus(event.Header)
+			}
+
+			if total := n.sub.Dropped(); total > dropped {
+				n.handler.log.Warnw("Subscribers missed blocks", "count", total-dropped)
+				dropped = total
+			}
+		}
+	}
+}
+
+// subscribe registers the subscription, whose callbacks are set, for the connection of the caller.
+func (h *Handler) subscribe(ctx context.Context, sub *subscription) (uint64, *jsonrpc.Error) {
+	conn, ok := jsonrpc.ConnFromContext(ctx)
+	if !ok {
+		return 0, ErrSubscriptionsNotSupported
+	}
+	sub.conn = conn
+	sub.done = make(chan struct{})
+
+	h.subscriptions.mu.Lock()
+	id := h.subscriptions.nextID
+	h.subscriptions.nextID++
+	h.subscriptions.subs[id] = sub
+	h.subscriptions.mu.Unlock()
+
+	go func() {
+		select {
+		case <-conn.Done():
+			h.unsubscribe(id)
+		case <-sub.done:
+		}
+	}()
+	return id, nil
+}
+
+func (h *Handler) unsubscribe(id uint64) {
+	h.subscriptions.mu.Lock()
+	defer h.subscriptions.mu.Unlock()
+
+	if sub, found := h.subscriptions.subs[id]; found {
+		close(sub.done)
+		delete(h.subscriptions.subs, id)
+	}
+}
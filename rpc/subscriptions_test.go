@@ -0,0 +1,283 @@
+package rpc_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/clients/feeder"
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db/pebble"
+	"github.com/NethermindEth/juno/jsonrpc"
+	"github.com/NethermindEth/juno/mocks"
+	"github.com/NethermindEth/juno/rpc"
+	adaptfeeder "github.com/NethermindEth/juno/starknetdata/feeder"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type notification struct {
+	method string
+	params *rpc.SubscriptionNotification
+}
+
+type fakeConn struct {
+	mu            sync.Mutex
+	notifications []notification
+	err           error
+	done          chan struct{}
+}
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{done: make(chan struct{})}
+}
+
+func (c *fakeConn) Notify(method string, params any) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.notifications = append(c.notifications, notification{method: method, params: params.(*rpc.SubscriptionNotification)})
+	return nil
+}
+
+func (c *fakeConn) Done() <-chan struct{} {
+	return c.done
+}
+
+// results returns the results sent to the given subscription and forgets all notifications
+func (c *fakeConn) results(t *testing.T, id uint64) []any {
+	t.Helper()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var results []any
+	for _, n := range c.notifications {
+		assert.Equal(t, rpc.SubscriptionMethod, n.method)
+		if n.params.Subscription == id {
+			results = append(results, n.params.Result)
+		}
+	}
+	c.notifications = nil
+	return results
+}
+
+func TestSubscriptions(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mockReader := mocks.NewMockReader(mockCtrl)
+	handler := rpc.New(mockReader, utils.MAINNET, utils.NewNopZapLogger())
+
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	block0, err := gw.BlockByNumber(context.Background(), 0)
+	require.NoError(t, err)
+	block1, err := gw.BlockByNumber(context.Background(), 1)
+	require.NoError(t, err)
+	// the first block with events in the test data
+	block1059, err := gw.BlockByNumber(context.Background(), 1059)
+	require.NoError(t, err)
+
+	t.Run("subscriptions require a connection", func(t *testing.T) {
+		_, rpcErr := handler.SubscribeNewHeads(context.Background())
+		assert.Equal(t, rpc.ErrSubscriptionsNotSupported, rpcErr)
+		_, rpcErr = handler.Unsubscribe(context.Background(), 0)
+		assert.Equal(t, rpc.ErrSubscriptionsNotSupported, rpcErr)
+	})
+
+	t.Run("new heads", func(t *testing.T) {
+		conn := newFakeConn()
+		ctx := jsonrpc.ContextWithConn(context.Background(), conn)
+
+		id, rpcErr := handler.SubscribeNewHeads(ctx)
+		require.Nil(t, rpcErr)
+
+		handler.NotifyNewBlock(block0)
+		handler.NotifyNewBlock(block1)
+
+		results := conn.results(t, id)
+		require.Len(t, results, 2)
+		for i, block := range []*core.Block{block0, block1} {
+			header := results[i].(rpc.BlockHeader)
+			assert.Equal(t, block.Hash, header.Hash)
+			assert.Equal(t, block.Number, *header.Number)
+		}
+
+		unsubscribed, rpcErr := handler.Unsubscribe(ctx, id)
+		require.Nil(t, rpcErr)
+		assert.True(t, unsubscribed)
+
+		handler.NotifyNewBlock(block1)
+		assert.Empty(t, conn.results(t, id))
+	})
+
+	t.Run("events", func(t *testing.T) {
+		conn := newFakeConn()
+		ctx := jsonrpc.ContextWithConn(context.Background(), conn)
+
+		var event *core.Event
+		for _, receipt := range block1059.Receipts {
+			if len(receipt.Events) > 0 {
+				event = receipt.Events[0]
+				break
+			}
+		}
+		require.NotNil(t, event)
+
+		id, rpcErr := handler.SubscribeEvents(ctx, &rpc.EventSubscriptionFilter{Address: event.From})
+		require.Nil(t, rpcErr)
+
+		handler.NotifyNewBlock(block1059)
+
+		var expected []any
+		for _, receipt := range block1059.Receipts {
+			for _, e := range receipt.Events {
+				if e.From.Equal(event.From) {
+					expected = append(expected, &rpc.EmittedEvent{
+						Event:           &rpc.Event{From: e.From, Keys: e.Keys, Data: e.Data},
+						BlockNumber:     block1059.Number,
+						BlockHash:       block1059.Hash,
+						TransactionHash: receipt.TransactionHash,
+					})
+				}
+			}
+		}
+		assert.Equal(t, expected, conn.results(t, id))
+
+		_, rpcErr = handler.SubscribeEvents(ctx, &rpc.EventSubscriptionFilter{
+			Keys: [][]*felt.Felt{make([]*felt.Felt, 1025)},
+		})
+		assert.Equal(t, rpc.ErrTooManyKeysInFilter, rpcErr)
+	})
+
+	t.Run("transaction status", func(t *testing.T) {
+		conn := newFakeConn()
+		ctx := jsonrpc.ContextWithConn(context.Background(), conn)
+
+		txHash := block1.Transactions[0].Hash()
+		id, rpcErr := handler.SubscribeTransactionStatus(ctx, txHash)
+		require.Nil(t, rpcErr)
+
+		// the transaction is not in a block yet
+		mockReader.EXPECT().Receipt(txHash).Return(nil, nil, uint64(0), errors.New("not found"))
+		handler.NotifyNewBlock(block0)
+		assert.Empty(t, conn.results(t, id))
+
+		header := *block1.Header
+		header.Status = core.BlockAcceptedOnL2
+		mockReader.EXPECT().Receipt(txHash).Return(block1.Receipts[0], block1.Hash, block1.Number, nil).Times(2)
+		mockReader.EXPECT().BlockHeaderByNumber(block1.Number).Return(&header, nil).Times(2)
+		handler.NotifyNewBlock(block1)
+		// the status did not change
+		handler.NotifyNewBlock(block1)
+		assert.Equal(t, []any{&rpc.TransactionStatus{TransactionHash: txHash, Status: rpc.StatusAcceptedL2}}, conn.results(t, id))
+
+		mockReader.EXPECT().Receipt(txHash).Return(block1.Receipts[0], block1.Hash, block1.Number, nil)
+		mockReader.EXPECT().BlockHeaderByNumber(block1.Number).Return(block1.Header, nil)
+		handler.NotifyBlockStatus(block1.Header)
+		assert.Equal(t, []any{&rpc.TransactionStatus{TransactionHash: txHash, Status: rpc.StatusAcceptedL1}}, conn.results(t, id))
+
+		// unsubscribed when the connection is closed
+		close(conn.done)
+		assert.Eventually(t, func() bool {
+			unsubscribed, rpcErr := handler.Unsubscribe(ctx, id)
+			return rpcErr == nil && !unsubscribed
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("subscriber that can not be notified is unsubscribed", func(t *testing.T) {
+		conn := newFakeConn()
+		conn.err = jsonrpc.ErrSlowConsumer
+		ctx := jsonrpc.ContextWithConn(context.Background(), conn)
+
+		id, rpcErr := handler.SubscribeNewHeads(ctx)
+		require.Nil(t, rpcErr)
+
+		handler.NotifyNewBlock(block0)
+		unsubscribed, rpcErr := handler.Unsubscribe(ctx, id)
+		require.Nil(t, rpcErr)
+		assert.False(t, unsubscribed)
+	})
+
+	t.Run("subscriptions of other connections can not be cancelled", func(t *testing.T) {
+		id, rpcErr := handler.SubscribeNewHeads(jsonrpc.ContextWithConn(context.Background(), newFakeConn()))
+		require.Nil(t, rpcErr)
+
+		unsubscribed, rpcErr := handler.Unsubscribe(jsonrpc.ContextWithConn(context.Background(), newFakeConn()), id)
+		require.Nil(t, rpcErr)
+		assert.False(t, unsubscribed)
+	})
+}
+
+func TestNotifier(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET, utils.NewNopZapLogger())
+	handler := rpc.New(chain, utils.MAINNET, utils.NewNopZapLogger())
+	notifier := rpc.NewNotifier(handler, chain)
+
+	block0, err := gw.BlockByNumber(context.Background(), 0)
+	require.NoError(t, err)
+	block0.Status = core.BlockAcceptedOnL2
+
+	conn, statusConn := newFakeConn(), newFakeConn()
+	id, rpcErr := handler.SubscribeNewHeads(jsonrpc.ContextWithConn(context.Background(), conn))
+	require.Nil(t, rpcErr)
+	txHash := block0.Transactions[0].Hash()
+	statusID, rpcErr := handler.SubscribeTransactionStatus(jsonrpc.ContextWithConn(context.Background(), statusConn), txHash)
+	require.Nil(t, rpcErr)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error)
+	go func() {
+		errCh <- notifier.Run(ctx)
+	}()
+
+	su0, err := gw.StateUpdate(context.Background(), 0)
+	require.NoError(t, err)
+	classes := make(map[felt.Felt]core.Class)
+	for _, classHash := range su0.StateDiff.ClassHashes() {
+		classes[*classHash], err = gw.Class(context.Background(), classHash)
+		require.NoError(t, err)
+	}
+	require.NoError(t, chain.Store(block0, su0, classes))
+
+	// waitForStatus waits for the subscriber of the transaction status to be notified of the given status
+	waitForStatus := func(status rpc.Status) {
+		var statuses []any
+		assert.Eventually(t, func() bool {
+			statuses = append(statuses, statusConn.results(t, statusID)...)
+			return len(statuses) > 0
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, []any{&rpc.TransactionStatus{TransactionHash: txHash, Status: status}}, statuses)
+	}
+	waitForStatus(rpc.StatusAcceptedL2)
+	require.NoError(t, chain.SetBlockStatus(0, core.BlockAcceptedOnL1))
+	waitForStatus(rpc.StatusAcceptedL1)
+
+	// reverts are not notified
+	require.NoError(t, chain.RevertHead())
+
+	var results []any
+	assert.Eventually(t, func() bool {
+		results = append(results, conn.results(t, id)...)
+		return len(results) > 0
+	}, time.Second, 10*time.Millisecond)
+	require.Len(t, results, 1)
+	assert.Equal(t, block0.Hash, results[0].(rpc.BlockHeader).Hash)
+
+	cancel()
+	require.NoError(t, <-errCh)
+}
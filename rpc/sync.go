@@ -0,0 +1,46 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/NethermindEth/juno/core/felt"
+)
+
+// NumAsHex is a number that is marshalled as a hex string, as the spec requires for some fields.
+type NumAsHex uint64
+
+func (n NumAsHex) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fmt.Sprintf("0x%x", uint64(n)))
+}
+
+// Sync is the result of starknet_syncing, it is marshalled as false when the node is not syncing.
+// See the SYNC_STATUS object in the spec.
+type Sync struct {
+	Syncing           bool       `json:"-"`
+	StartingBlockHash *felt.Felt `json:"starting_block_hash"`
+	StartingBlockNum  NumAsHex   `json:"starting_block_num"`
+	CurrentBlockHash  *felt.Felt `json:"current_block_hash"`
+	CurrentBlockNum   NumAsHex   `json:"current_block_num"`
+	HighestBlockHash  *felt.Felt `json:"highest_block_hash"`
+	HighestBlockNum   NumAsHex   `json:"highest_block_num"`
+}
+
+func (s *Sync) MarshalJSON() ([]byte, error) {
+	if !s.Syncing {
+		return json.Marshal(false)
+	}
+
+	type syncStatus Sync
+	return json.Marshal((*syncStatus)(s))
+}
+
+// SyncStatus is the result of juno_syncStatus, it describes the work done by the sync since the node
+// started. Rates are the average number of blocks per second.
+type SyncStatus struct {
+	BlocksFetched  uint64  `json:"blocks_fetched"`
+	BlocksVerified uint64  `json:"blocks_verified"`
+	FetchRate      float64 `json:"fetch_rate"`
+	VerifyRate     float64 `json:"verify_rate"`
+	Rollbacks      uint64  `json:"rollbacks"`
+}
@@ -34,6 +34,24 @@ func (t TransactionType) MarshalJSON() ([]byte, error) {
 	}
 }
 
+func (t *TransactionType) UnmarshalJSON(data []byte) error {
+	switch string(data) {
+	case "\"DECLARE\"":
+		*t = TxnDeclare
+	case "\"DEPLOY\"":
+		*t = TxnDeploy
+	case "\"DEPLOY_ACCOUNT\"":
+		*t = TxnDeployAccount
+	case "\"INVOKE\"":
+		*t = TxnInvoke
+	case "\"L1_HANDLER\"":
+		*t = TxnL1Handler
+	default:
+		return errors.New("unknown TransactionType")
+	}
+	return nil
+}
+
 // https://github.com/starkware-libs/starknet-specs/blob/a789ccc3432c57777beceaa53a34a7ae2f25fda0/api/starknet_api_openrpc.json#L1252
 type Transaction struct {
 	Hash                *felt.Felt      `json:"transaction_hash,omitempty"`
@@ -64,13 +82,14 @@ type Event struct {
 }
 
 // https://github.com/starkware-libs/starknet-specs/blob/master/api/starknet_api_openrpc.json#L1871
+// BlockHash and BlockNumber are not set for transactions in the pending block.
 type TransactionReceipt struct {
 	Type            TransactionType `json:"type"`
 	Hash            *felt.Felt      `json:"transaction_hash"`
 	ActualFee       *felt.Felt      `json:"actual_fee"`
 	Status          Status          `json:"status"`
-	BlockHash       *felt.Felt      `json:"block_hash"`
-	BlockNumber     uint64          `json:"block_number"`
+	BlockHash       *felt.Felt      `json:"block_hash,omitempty"`
+	BlockNumber     *uint64         `json:"block_number,omitempty"`
 	MessagesSent    []*MsgToL1      `json:"messages_sent"`
 	Events          []*Event        `json:"events"`
 	ContractAddress *felt.Felt      `json:"contract_address,omitempty"`
@@ -2,13 +2,17 @@ package feeder
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math/big"
 
 	"github.com/NethermindEth/juno/clients/feeder"
 	"github.com/NethermindEth/juno/core"
 	"github.com/NethermindEth/juno/core/crypto"
 	"github.com/NethermindEth/juno/core/felt"
 	"github.com/NethermindEth/juno/starknetdata"
+	"github.com/NethermindEth/juno/utils"
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -35,6 +39,26 @@ func (f *Feeder) BlockByNumber(ctx context.Context, blockNumber uint64) (*core.B
 	return adaptBlock(response)
 }
 
+// BlockPending gets the pending block from the feeder, which has no hash, number or state root.
+func (f *Feeder) BlockPending(ctx context.Context) (*core.Block, error) {
+	response, err := f.client.PendingBlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return adaptBlock(response)
+}
+
+// BlockLatest gets the latest block accepted on L2 from the feeder.
+func (f *Feeder) BlockLatest(ctx context.Context) (*core.Block, error) {
+	response, err := f.client.LatestBlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return adaptBlock(response)
+}
+
 func adaptBlock(response *feeder.Block) (*core.Block, error) {
 	if response == nil {
 		return nil, errors.New("nil client block")
@@ -53,6 +77,11 @@ func adaptBlock(response *feeder.Block) (*core.Block, error) {
 		eventCount += uint64(len(response.Receipts[i].Events))
 	}
 
+	status, err := adaptBlockStatus(response.Status)
+	if err != nil {
+		return nil, err
+	}
+
 	return &core.Block{
 		Header: &core.Header{
 			Hash:             response.Hash,
@@ -65,12 +94,29 @@ func adaptBlock(response *feeder.Block) (*core.Block, error) {
 			SequencerAddress: response.SequencerAddress,
 			TransactionCount: uint64(len(response.Transactions)),
 			EventCount:       eventCount,
+			Status:           status,
 		},
 		Transactions: txns,
 		Receipts:     receipts,
 	}, nil
 }
 
+func adaptBlockStatus(status string) (core.BlockStatus, error) {
+	switch status {
+	case "ACCEPTED_ON_L2":
+		return core.BlockAcceptedOnL2, nil
+	case "ACCEPTED_ON_L1":
+		return core.BlockAcceptedOnL1, nil
+	case "PENDING":
+		return core.BlockPending, nil
+	// the blocks that the sequencer gave up on, or that failed to be proven, never make it into the chain
+	case "REJECTED", "ABORTED", "REVERTED":
+		return core.BlockRejected, nil
+	default:
+		return 0, errors.New("unknown block status " + status)
+	}
+}
+
 func adaptTransactionReceipt(response *feeder.TransactionReceipt) *core.TransactionReceipt {
 	if response == nil {
 		return nil
@@ -297,6 +343,58 @@ func adaptCairo1Class(response *feeder.SierraDefinition) (core.Class, error) {
 	return class, nil
 }
 
+// CompiledClass gets the Cairo assembly that the Sierra class with the given hash compiles to from the feeder.
+func (f *Feeder) CompiledClass(ctx context.Context, classHash *felt.Felt) (*core.CompiledClass, error) {
+	response, err := f.client.CompiledClassDefinition(ctx, classHash)
+	if err != nil {
+		return nil, err
+	}
+	return adaptCompiledClass(response)
+}
+
+func adaptCompiledClass(response *feeder.CompiledClass) (*core.CompiledClass, error) {
+	prime, ok := new(big.Int).SetString(response.Prime, 0)
+	if !ok {
+		return nil, fmt.Errorf("invalid prime %q", response.Prime)
+	}
+
+	return &core.CompiledClass{
+		Bytecode:        response.Bytecode,
+		PythonicHints:   response.PythonicHints,
+		CompilerVersion: response.CompilerVersion,
+		Hints:           response.Hints,
+		Prime:           prime,
+		External:        adaptCompiledEntryPoints(response.EntryPoints.External),
+		L1Handler:       adaptCompiledEntryPoints(response.EntryPoints.L1Handler),
+		Constructor:     adaptCompiledEntryPoints(response.EntryPoints.Constructor),
+
+		BytecodeSegmentLengths: adaptSegmentLengths(response.BytecodeSegmentLengths),
+	}, nil
+}
+
+func adaptSegmentLengths(lengths *feeder.SegmentLengths) *core.SegmentLengths {
+	if lengths == nil {
+		return nil
+	}
+
+	adapted := &core.SegmentLengths{Length: lengths.Length}
+	if lengths.Children != nil {
+		adapted.Children = make([]core.SegmentLengths, len(lengths.Children))
+		for i := range lengths.Children {
+			adapted.Children[i] = *adaptSegmentLengths(&lengths.Children[i])
+		}
+	}
+	return adapted
+}
+
+func adaptCompiledEntryPoints(entryPoints []feeder.CompiledEntryPoint) []core.CompiledEntryPoint {
+	result := make([]core.CompiledEntryPoint, len(entryPoints))
+	for index, v := range entryPoints {
+		result[index] = core.CompiledEntryPoint{Offset: v.Offset, Builtins: v.Builtins, Selector: v.Selector}
+	}
+	return result
+}
+
 func adaptCairo0Class(response *feeder.Cairo0Definition) (core.Class, error) {
 	class := new(core.Cairo0Class)
 	class.Abi = response.Abi
@@ -328,6 +426,16 @@ func adaptCairo0Class(response *feeder.Cairo0Definition) (core.Class, error) {
 		return nil, err
 	}
 
+	programJSON, err := json.Marshal(response.Program)
+	if err != nil {
+		return nil, err
+	}
+
+	class.Program, err = utils.Gzip64Encode(programJSON)
+	if err != nil {
+		return nil, err
+	}
+
 	class.Bytecode = []*felt.Felt{}
 	for _, v := range response.Program.Data {
 		datum, err := new(felt.Felt).SetString(v)
@@ -352,6 +460,17 @@ func (f *Feeder) StateUpdate(ctx context.Context, blockNumber uint64) (*core.Sta
 	return adaptStateUpdate(response)
 }
 
+// StateUpdatePending gets the state update of the pending block from the feeder, which has no block hash
+// or new root.
+func (f *Feeder) StateUpdatePending(ctx context.Context) (*core.StateUpdate, error) {
+	response, err := f.client.PendingStateUpdate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return adaptStateUpdate(response)
+}
+
 func adaptStateUpdate(response *feeder.StateUpdate) (*core.StateUpdate, error) {
 	stateDiff := new(core.StateDiff)
 	stateDiff.DeclaredV0Classes = response.StateDiff.OldDeclaredContracts
@@ -412,3 +531,26 @@ func adaptStateUpdate(response *feeder.StateUpdate) (*core.StateUpdate, error) {
 		StateDiff: stateDiff,
 	}, nil
 }
+
+// BlockSignature gets the signature of the sequencer over the block with the given number from the feeder.
+func (f *Feeder) BlockSignature(ctx context.Context, blockNumber uint64) (*core.BlockSignature, error) {
+	response, err := f.client.Signature(ctx, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	return adaptSignature(response)
+}
+
+func adaptSignature(response *feeder.Signature) (*core.BlockSignature, error) {
+	if len(response.Signature) != 2 {
+		return nil, fmt.Errorf("expected a signature of 2 elements, got %d", len(response.Signature))
+	}
+
+	return &core.BlockSignature{
+		BlockHash:           response.SignatureInput.BlockHash,
+		StateDiffCommitment: response.SignatureInput.StateDiffCommitment,
+		R:                   response.Signature[0],
+		S:                   response.Signature[1],
+	}, nil
+}
@@ -2,6 +2,7 @@ package feeder_test
 
 import (
 	"context"
+	"encoding/json"
 	"strconv"
 	"testing"
 
@@ -55,11 +56,80 @@ func TestGetBlockByNumber(t *testing.T) {
 			assert.Equal(t, len(response.Receipts), len(block.Receipts))
 			assert.Equal(t, expectedEventCount, block.EventCount)
 			assert.Equal(t, test.protocolVersion, block.ProtocolVersion)
+			assert.Equal(t, response.Status, block.Status.String())
 			assert.Nil(t, block.ExtraData)
 		})
 	}
 }
 
+func TestBlockPending(t *testing.T) {
+	client, serverClose := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(serverClose)
+	adapter := adaptfeeder.New(client)
+	ctx := context.Background()
+
+	response, err := client.PendingBlock(ctx)
+	require.NoError(t, err)
+	block, err := adapter.BlockPending(ctx)
+	require.NoError(t, err)
+
+	assert.Nil(t, block.Hash)
+	assert.Nil(t, block.GlobalStateRoot)
+	assert.True(t, block.ParentHash.Equal(response.ParentHash))
+	assert.Equal(t, response.Timestamp, block.Timestamp)
+	assert.Equal(t, len(response.Transactions), len(block.Transactions))
+	assert.Equal(t, len(response.Receipts), len(block.Receipts))
+	assert.Equal(t, core.BlockPending, block.Status)
+}
+
+func TestBlockLatest(t *testing.T) {
+	client, serverClose := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(serverClose)
+	adapter := adaptfeeder.New(client)
+	ctx := context.Background()
+
+	block, err := adapter.BlockLatest(ctx)
+	require.NoError(t, err)
+	block2, err := adapter.BlockByNumber(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, block2, block)
+}
+
+func TestBlockSignature(t *testing.T) {
+	client, serverClose := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(serverClose)
+	adapter := adaptfeeder.New(client)
+	ctx := context.Background()
+
+	response, err := client.Signature(ctx, 0)
+	require.NoError(t, err)
+
+	signature, err := adapter.BlockSignature(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, response.SignatureInput.BlockHash, signature.BlockHash)
+	assert.Equal(t, response.SignatureInput.StateDiffCommitment, signature.StateDiffCommitment)
+	assert.Equal(t, response.Signature[0], signature.R)
+	assert.Equal(t, response.Signature[1], signature.S)
+}
+
+func TestStateUpdatePending(t *testing.T) {
+	client, serverClose := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(serverClose)
+	adapter := adaptfeeder.New(client)
+	ctx := context.Background()
+
+	response, err := client.PendingStateUpdate(ctx)
+	require.NoError(t, err)
+	update, err := adapter.StateUpdatePending(ctx)
+	require.NoError(t, err)
+
+	assert.Nil(t, update.BlockHash)
+	assert.Nil(t, update.NewRoot)
+	assert.True(t, response.OldRoot.Equal(update.OldRoot))
+	assert.Equal(t, len(response.StateDiff.DeployedContracts), len(update.StateDiff.DeployedContracts))
+	assert.Equal(t, len(response.StateDiff.StorageDiffs), len(update.StateDiff.StorageDiffs))
+}
+
 func TestStateUpdate(t *testing.T) {
 	numbers := []uint64{0, 1, 2, 21656}
 
@@ -201,10 +271,54 @@ func TestClassV0(t *testing.T) {
 			programHash, err := feeder.ProgramHash(response.V0)
 			require.NoError(t, err)
 			assert.Equal(t, programHash, class.ProgramHash)
+
+			programJSON, err := utils.Gzip64Decode(class.Program)
+			require.NoError(t, err)
+			var program feeder.Program
+			require.NoError(t, json.Unmarshal(programJSON, &program))
+			assert.Equal(t, response.V0.Program.Data, program.Data)
+			assert.Equal(t, response.V0.Program.Builtins, program.Builtins)
 		})
 	}
 }
 
+func TestCompiledClass(t *testing.T) {
+	client, serverClose := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(serverClose)
+	adapter := adaptfeeder.New(client)
+	ctx := context.Background()
+
+	classHash := utils.HexToFelt(t, "0xc0de")
+	response, err := client.CompiledClassDefinition(ctx, classHash)
+	require.NoError(t, err)
+
+	compiled, err := adapter.CompiledClass(ctx, classHash)
+	require.NoError(t, err)
+	assert.Equal(t, response.Bytecode, compiled.Bytecode)
+	assert.Equal(t, response.CompilerVersion, compiled.CompilerVersion)
+	assert.Equal(t, response.Prime, "0x"+compiled.Prime.Text(16))
+	assert.Equal(t, response.Hints, compiled.Hints)
+	assert.Equal(t, response.PythonicHints, compiled.PythonicHints)
+	assert.Equal(t, []core.CompiledEntryPoint{
+		{Offset: 0, Builtins: []string{"range_check"}, Selector: response.EntryPoints.External[0].Selector},
+	}, compiled.External)
+	assert.Empty(t, compiled.L1Handler)
+	assert.Equal(t, []core.CompiledEntryPoint{
+		{Offset: 7, Builtins: []string{}, Selector: response.EntryPoints.Constructor[0].Selector},
+	}, compiled.Constructor)
+	assert.Equal(t, &core.SegmentLengths{Children: []core.SegmentLengths{
+		{Length: 3},
+		{Children: []core.SegmentLengths{{Length: 2}, {Length: 2}}},
+		{Length: 3},
+	}}, compiled.BytecodeSegmentLengths)
+
+	t.Run("class compiled without bytecode segments", func(t *testing.T) {
+		unsegmented, err := adapter.CompiledClass(ctx, utils.HexToFelt(t, "0xc0df"))
+		require.NoError(t, err)
+		assert.Nil(t, unsegmented.BytecodeSegmentLengths)
+	})
+}
+
 func TestTransaction(t *testing.T) {
 	clientGoerli, serverClose := feeder.NewTestClient(utils.GOERLI)
 	t.Cleanup(serverClose)
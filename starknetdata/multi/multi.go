@@ -0,0 +1,335 @@
+// Package multi provides a StarknetData that gets its data from several upstream sources, such as feeder
+// gateway mirrors, so that an outage or a misbehaving upstream does not stall or poison the sync.
+package multi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/starknetdata"
+	"github.com/NethermindEth/juno/utils"
+)
+
+var (
+	_ starknetdata.StarknetData = (*Multi)(nil)
+	_ starknetdata.Rejecter     = (*Multi)(nil)
+)
+
+const (
+	// maxSources bounds the number of blocks that the upstreams which provided them are remembered for.
+	maxSources = 1024
+	// defaultPrimaryRetry is how long another upstream is preferred before the first one is asked first again.
+	defaultPrimaryRetry = 5 * time.Minute
+)
+
+var (
+	ErrAllUpstreamsFailed = errors.New("all upstreams failed")
+	ErrNoQuorum           = errors.New("not enough upstreams agree")
+)
+
+// Multi gets data from the first of its upstreams that answers. The upstream that answered last is asked
+// first, so an upstream that fails or is slower than the timeout is only asked again once the ones after
+// it fail too. An upstream that provided a block that was rejected stops being the preferred one. The
+// first upstream is the primary one: once another upstream has been preferred for a while, the first one
+// is asked first again, so that a recovered primary is not left behind for good.
+//
+// Blocks and state updates can also be cross-checked: with a quorum of N, all the upstreams are asked
+// at once, and the answer is the first block hash and state root that N of them agree on.
+type Multi struct {
+	upstreams []starknetdata.StarknetData
+	preferred uint64
+	// preferredSince is when the preferred upstream became the preferred one, in Unix nanoseconds
+	preferredSince int64
+	primaryRetry   time.Duration
+	strictOrder    bool
+	quorum         int
+	timeout        time.Duration
+	log            utils.SimpleLogger
+
+	sourcesMu sync.Mutex
+	sources   map[uint64][]int // the upstreams that provided the recent blocks and state updates, by block
+}
+
+// New returns a Multi of the given upstreams, which must not be empty. By default, answers are not
+// cross-checked and the upstreams are not timed out.
+func New(upstreams []starknetdata.StarknetData, log utils.SimpleLogger) *Multi {
+	return &Multi{
+		upstreams:    upstreams,
+		primaryRetry: defaultPrimaryRetry,
+		quorum:       1,
+		log:          log,
+		sources:      make(map[uint64][]int),
+	}
+}
+
+// WithPrimaryRetry sets how long another upstream is preferred before the first one is asked first again.
+func (m *Multi) WithPrimaryRetry(interval time.Duration) *Multi {
+	m.primaryRetry = interval
+	return m
+}
+
+// WithQuorum sets the number of upstreams that must agree on a block hash and state root.
+func (m *Multi) WithQuorum(quorum int) *Multi {
+	m.quorum = quorum
+	return m
+}
+
+// WithTimeout sets how long an upstream is waited for before it is considered failed.
+func (m *Multi) WithTimeout(timeout time.Duration) *Multi {
+	m.timeout = timeout
+	return m
+}
+
+// WithStrictOrder makes the upstreams always be asked in their order, so that an upstream is only asked
+// when the ones before it fail, however the previous requests went.
+func (m *Multi) WithStrictOrder() *Multi {
+	m.strictOrder = true
+	return m
+}
+
+func (m *Multi) BlockByNumber(ctx context.Context, blockNumber uint64) (*core.Block, error) {
+	block, sources, err := agreed(ctx, m, func(ctx context.Context, upstream starknetdata.StarknetData) (*core.Block, error) {
+		return upstream.BlockByNumber(ctx, blockNumber)
+	}, func(block *core.Block) string {
+		return stateKey(block.Hash, block.GlobalStateRoot)
+	})
+	m.remember(blockNumber, sources)
+	return block, err
+}
+
+func (m *Multi) StateUpdate(ctx context.Context, blockNumber uint64) (*core.StateUpdate, error) {
+	update, sources, err := agreed(ctx, m, func(ctx context.Context, upstream starknetdata.StarknetData) (*core.StateUpdate, error) {
+		return upstream.StateUpdate(ctx, blockNumber)
+	}, func(update *core.StateUpdate) string {
+		return stateKey(update.BlockHash, update.NewRoot)
+	})
+	m.remember(blockNumber, sources)
+	return update, err
+}
+
+// RejectBlock makes the next upstream the preferred one if the preferred one provided the block or the
+// state update of the given block, and passes the rejection on to the upstreams that provided them.
+func (m *Multi) RejectBlock(blockNumber uint64) {
+	m.sourcesMu.Lock()
+	sources := m.sources[blockNumber]
+	delete(m.sources, blockNumber)
+	m.sourcesMu.Unlock()
+
+	for _, index := range sources {
+		next := uint64((index + 1) % len(m.upstreams))
+		if len(m.upstreams) > 1 && m.setPreferred(uint64(index), next) {
+			m.log.Warnw("Failing over from an upstream that provided a rejected block", "upstream", index,
+				"number", blockNumber)
+		}
+		if rejecter, ok := m.upstreams[index].(starknetdata.Rejecter); ok {
+			rejecter.RejectBlock(blockNumber)
+		}
+	}
+}
+
+// setPreferred makes the upstream at index the preferred one, if the one at from still is. With a strict
+// order, the first upstream stays the preferred one.
+func (m *Multi) setPreferred(from, index uint64) bool {
+	if m.strictOrder || !atomic.CompareAndSwapUint64(&m.preferred, from, index) {
+		return false
+	}
+	atomic.StoreInt64(&m.preferredSince, time.Now().UnixNano())
+	return true
+}
+
+// first returns the index of the upstream to ask first: the preferred one, unless another upstream than
+// the first one has been preferred for longer than the primary retry interval.
+func (m *Multi) first() uint64 {
+	preferred := atomic.LoadUint64(&m.preferred)
+	if preferred == 0 || time.Since(time.Unix(0, atomic.LoadInt64(&m.preferredSince))) < m.primaryRetry {
+		return preferred
+	}
+	if m.setPreferred(preferred, 0) {
+		m.log.Infow("Retrying the first upstream", "preferred", preferred)
+	}
+	return atomic.LoadUint64(&m.preferred)
+}
+
+// remember records the upstreams that provided data of the given block, and forgets the ones of the
+// block that is maxSources blocks older.
+func (m *Multi) remember(blockNumber uint64, sources []int) {
+	m.sourcesMu.Lock()
+	defer m.sourcesMu.Unlock()
+
+	for _, index := range sources {
+		if !containsIndex(m.sources[blockNumber], index) {
+			m.sources[blockNumber] = append(m.sources[blockNumber], index)
+		}
+	}
+	if blockNumber >= maxSources {
+		delete(m.sources, blockNumber-maxSources)
+	}
+}
+
+func containsIndex(indices []int, index int) bool {
+	for _, i := range indices {
+		if i == index {
+			return true
+		}
+	}
+	return false
+}
+
+// The rest of the data is either checked against its hash by the sync, or changes too fast for the
+// upstreams to agree on it, so it is not cross-checked.
+
+func (m *Multi) Transaction(ctx context.Context, transactionHash *felt.Felt) (core.Transaction, error) {
+	return failover(ctx, m, func(ctx context.Context, upstream starknetdata.StarknetData) (core.Transaction, error) {
+		return upstream.Transaction(ctx, transactionHash)
+	})
+}
+
+func (m *Multi) Class(ctx context.Context, classHash *felt.Felt) (core.Class, error) {
+	return failover(ctx, m, func(ctx context.Context, upstream starknetdata.StarknetData) (core.Class, error) {
+		return upstream.Class(ctx, classHash)
+	})
+}
+
+func (m *Multi) CompiledClass(ctx context.Context, classHash *felt.Felt) (*core.CompiledClass, error) {
+	return failover(ctx, m, func(ctx context.Context, upstream starknetdata.StarknetData) (*core.CompiledClass, error) {
+		return upstream.CompiledClass(ctx, classHash)
+	})
+}
+
+func (m *Multi) BlockPending(ctx context.Context) (*core.Block, error) {
+	return failover(ctx, m, func(ctx context.Context, upstream starknetdata.StarknetData) (*core.Block, error) {
+		return upstream.BlockPending(ctx)
+	})
+}
+
+func (m *Multi) BlockLatest(ctx context.Context) (*core.Block, error) {
+	return failover(ctx, m, func(ctx context.Context, upstream starknetdata.StarknetData) (*core.Block, error) {
+		return upstream.BlockLatest(ctx)
+	})
+}
+
+func (m *Multi) StateUpdatePending(ctx context.Context) (*core.StateUpdate, error) {
+	return failover(ctx, m, func(ctx context.Context, upstream starknetdata.StarknetData) (*core.StateUpdate, error) {
+		return upstream.StateUpdatePending(ctx)
+	})
+}
+
+func (m *Multi) BlockSignature(ctx context.Context, blockNumber uint64) (*core.BlockSignature, error) {
+	return failover(ctx, m, func(ctx context.Context, upstream starknetdata.StarknetData) (*core.BlockSignature, error) {
+		return upstream.BlockSignature(ctx, blockNumber)
+	})
+}
+
+type request[T any] func(ctx context.Context, upstream starknetdata.StarknetData) (T, error)
+
+// ask makes the request to the upstream at the given index, within the timeout.
+func ask[T any](ctx context.Context, m *Multi, index int, req request[T]) (T, error) {
+	if m.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.timeout)
+		defer cancel()
+	}
+	return req(ctx, m.upstreams[index])
+}
+
+// failover makes the request to the upstreams one after the other, starting with the preferred one,
+// until one of them answers. The upstream that answers becomes the preferred one.
+func failover[T any](ctx context.Context, m *Multi, req request[T]) (T, error) {
+	answer, _, err := failoverFrom(ctx, m, req)
+	return answer, err
+}
+
+// failoverFrom is failover that also returns the index of the upstream that answered.
+func failoverFrom[T any](ctx context.Context, m *Multi, req request[T]) (T, int, error) {
+	var zero T
+	var err error
+	first := m.first()
+	for i := range m.upstreams {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return zero, 0, ctxErr
+		}
+
+		index := (int(first) + i) % len(m.upstreams)
+		var answer T
+		if answer, err = ask(ctx, m, index, req); err == nil {
+			if i > 0 && m.setPreferred(first, uint64(index)) {
+				m.log.Warnw("Failing over to another upstream", "upstream", index)
+			}
+			return answer, index, nil
+		}
+		m.log.Debugw("Upstream failed", "upstream", index, "err", err)
+	}
+	return zero, 0, fmt.Errorf("%w, the last one with: %v", ErrAllUpstreamsFailed, err)
+}
+
+// agreed makes the request to all the upstreams at once and returns the first answer that the quorum
+// of them agree on, where answers that have the same key agree, along with the indices of the upstreams
+// that gave it. Without a quorum, it fails over.
+func agreed[T any](ctx context.Context, m *Multi, req request[T], key func(T) string) (T, []int, error) {
+	var zero T
+	if m.quorum <= 1 {
+		answer, index, err := failoverFrom(ctx, m, req)
+		if err != nil {
+			return zero, nil, err
+		}
+		return answer, []int{index}, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		index  int
+		answer T
+		err    error
+	}
+	results := make(chan result, len(m.upstreams))
+	for i := range m.upstreams {
+		i := i
+		go func() {
+			answer, err := ask(ctx, m, i, req)
+			results <- result{index: i, answer: answer, err: err}
+		}()
+	}
+
+	votes := make(map[string][]int)
+	for range m.upstreams {
+		r := <-results
+		if r.err != nil {
+			m.log.Debugw("Upstream failed", "upstream", r.index, "err", r.err)
+			continue
+		}
+
+		k := key(r.answer)
+		votes[k] = append(votes[k], r.index)
+		if len(votes[k]) >= m.quorum {
+			if len(votes) > 1 {
+				m.log.Warnw("Upstreams disagree", "answers", votes)
+			}
+			return r.answer, votes[k], nil
+		}
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return zero, nil, ctxErr
+	}
+	return zero, nil, fmt.Errorf("%w: %d upstreams must agree, but the answers were %v", ErrNoQuorum, m.quorum, votes)
+}
+
+// stateKey identifies a block by its hash and the state root after it.
+func stateKey(blockHash, stateRoot *felt.Felt) string {
+	key := ""
+	for _, f := range []*felt.Felt{blockHash, stateRoot} {
+		if f != nil {
+			key += f.String()
+		}
+		key += "/"
+	}
+	return key
+}
@@ -0,0 +1,281 @@
+package multi_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/mocks"
+	"github.com/NethermindEth/juno/starknetdata"
+	"github.com/NethermindEth/juno/starknetdata/multi"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailover(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	upstreams := []*mocks.MockStarknetData{mocks.NewMockStarknetData(mockCtrl), mocks.NewMockStarknetData(mockCtrl)}
+	m := multi.New([]starknetdata.StarknetData{upstreams[0], upstreams[1]}, utils.NewNopZapLogger()).
+		WithTimeout(50 * time.Millisecond)
+	ctx := context.Background()
+	classHash := new(felt.Felt).SetUint64(1)
+	class := &core.Cairo0Class{Program: "program"}
+
+	t.Run("the preferred upstream answers", func(t *testing.T) {
+		upstreams[0].EXPECT().Class(gomock.Any(), classHash).Return(class, nil)
+
+		got, err := m.Class(ctx, classHash)
+		require.NoError(t, err)
+		assert.Equal(t, class, got)
+	})
+
+	t.Run("the next upstream answers when the preferred one fails", func(t *testing.T) {
+		upstreams[0].EXPECT().Class(gomock.Any(), classHash).Return(nil, errors.New("outage"))
+		upstreams[1].EXPECT().Class(gomock.Any(), classHash).Return(class, nil)
+
+		got, err := m.Class(ctx, classHash)
+		require.NoError(t, err)
+		assert.Equal(t, class, got)
+	})
+
+	t.Run("the upstream that answered is preferred", func(t *testing.T) {
+		upstreams[1].EXPECT().Class(gomock.Any(), classHash).Return(class, nil)
+
+		got, err := m.Class(ctx, classHash)
+		require.NoError(t, err)
+		assert.Equal(t, class, got)
+	})
+
+	t.Run("the next upstream answers when the preferred one is too slow", func(t *testing.T) {
+		upstreams[1].EXPECT().Class(gomock.Any(), classHash).DoAndReturn(func(ctx context.Context, _ *felt.Felt) (core.Class, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		upstreams[0].EXPECT().Class(gomock.Any(), classHash).Return(class, nil)
+
+		got, err := m.Class(ctx, classHash)
+		require.NoError(t, err)
+		assert.Equal(t, class, got)
+	})
+
+	t.Run("all upstreams fail", func(t *testing.T) {
+		upstreams[0].EXPECT().Class(gomock.Any(), classHash).Return(nil, errors.New("outage"))
+		upstreams[1].EXPECT().Class(gomock.Any(), classHash).Return(nil, errors.New("outage"))
+
+		_, err := m.Class(ctx, classHash)
+		require.ErrorIs(t, err, multi.ErrAllUpstreamsFailed)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := m.Class(canceled, classHash)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestPrimaryRetry(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	upstreams := []*mocks.MockStarknetData{mocks.NewMockStarknetData(mockCtrl), mocks.NewMockStarknetData(mockCtrl)}
+	retry := 50 * time.Millisecond
+	m := multi.New([]starknetdata.StarknetData{upstreams[0], upstreams[1]}, utils.NewNopZapLogger()).
+		WithPrimaryRetry(retry)
+	ctx := context.Background()
+	block := &core.Block{Header: &core.Header{Number: 1}}
+
+	upstreams[0].EXPECT().BlockLatest(gomock.Any()).Return(nil, errors.New("outage"))
+	upstreams[1].EXPECT().BlockLatest(gomock.Any()).Return(block, nil).Times(2)
+	for i := 0; i < 2; i++ {
+		_, err := m.BlockLatest(ctx)
+		require.NoError(t, err)
+	}
+
+	t.Run("the first upstream is preferred again once it recovers", func(t *testing.T) {
+		time.Sleep(retry)
+		upstreams[0].EXPECT().BlockLatest(gomock.Any()).Return(block, nil).Times(2)
+		for i := 0; i < 2; i++ {
+			got, err := m.BlockLatest(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, block, got)
+		}
+	})
+}
+
+func TestStrictOrder(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	// e.g. the feeder gateway, and the untrusted peers that are only asked when it fails
+	feeder, peers := mocks.NewMockStarknetData(mockCtrl), mocks.NewMockStarknetData(mockCtrl)
+	m := multi.New([]starknetdata.StarknetData{feeder, peers}, utils.NewNopZapLogger()).WithStrictOrder()
+	ctx := context.Background()
+	block := &core.Block{Header: &core.Header{Number: 1}}
+
+	t.Run("the first upstream is asked first again after it failed", func(t *testing.T) {
+		feeder.EXPECT().BlockLatest(gomock.Any()).Return(nil, errors.New("outage"))
+		peers.EXPECT().BlockLatest(gomock.Any()).Return(block, nil)
+		_, err := m.BlockLatest(ctx)
+		require.NoError(t, err)
+
+		feeder.EXPECT().BlockLatest(gomock.Any()).Return(block, nil)
+		got, err := m.BlockLatest(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, block, got)
+	})
+
+	t.Run("a rejected block does not change the order", func(t *testing.T) {
+		feeder.EXPECT().BlockByNumber(gomock.Any(), uint64(1)).Return(block, nil)
+		_, err := m.BlockByNumber(ctx, 1)
+		require.NoError(t, err)
+		m.RejectBlock(1)
+
+		feeder.EXPECT().BlockByNumber(gomock.Any(), uint64(1)).Return(block, nil)
+		_, err = m.BlockByNumber(ctx, 1)
+		require.NoError(t, err)
+	})
+}
+
+func TestQuorum(t *testing.T) {
+	// the upstreams that are not needed for the quorum may be asked after the answer is returned, so
+	// every test gets its own upstreams
+	newMulti := func(t *testing.T) ([]*mocks.MockStarknetData, *multi.Multi) {
+		t.Helper()
+
+		mockCtrl := gomock.NewController(t)
+		t.Cleanup(mockCtrl.Finish)
+		upstreams := []*mocks.MockStarknetData{
+			mocks.NewMockStarknetData(mockCtrl),
+			mocks.NewMockStarknetData(mockCtrl),
+			mocks.NewMockStarknetData(mockCtrl),
+		}
+		m := multi.New([]starknetdata.StarknetData{upstreams[0], upstreams[1], upstreams[2]}, utils.NewNopZapLogger())
+		return upstreams, m.WithQuorum(2)
+	}
+	ctx := context.Background()
+
+	block := &core.Block{Header: &core.Header{
+		Number:          1,
+		Hash:            new(felt.Felt).SetUint64(0xb10c),
+		GlobalStateRoot: new(felt.Felt).SetUint64(0x2007),
+	}}
+	poisoned := &core.Block{Header: &core.Header{
+		Number:          1,
+		Hash:            block.Hash,
+		GlobalStateRoot: new(felt.Felt).SetUint64(0xbad),
+	}}
+	update := &core.StateUpdate{BlockHash: block.Hash, NewRoot: block.GlobalStateRoot}
+	poisonedUpdate := &core.StateUpdate{BlockHash: block.Hash, NewRoot: poisoned.GlobalStateRoot}
+
+	t.Run("the answer that the quorum agrees on is returned", func(t *testing.T) {
+		upstreams, m := newMulti(t)
+		upstreams[0].EXPECT().BlockByNumber(gomock.Any(), uint64(1)).Return(poisoned, nil).MaxTimes(1)
+		upstreams[1].EXPECT().BlockByNumber(gomock.Any(), uint64(1)).Return(block, nil).MaxTimes(1)
+		upstreams[2].EXPECT().BlockByNumber(gomock.Any(), uint64(1)).Return(block, nil).MaxTimes(1)
+
+		got, err := m.BlockByNumber(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, block, got)
+
+		upstreams, m = newMulti(t)
+		upstreams[0].EXPECT().StateUpdate(gomock.Any(), uint64(1)).Return(update, nil).MaxTimes(1)
+		upstreams[1].EXPECT().StateUpdate(gomock.Any(), uint64(1)).Return(poisonedUpdate, nil).MaxTimes(1)
+		upstreams[2].EXPECT().StateUpdate(gomock.Any(), uint64(1)).Return(update, nil).MaxTimes(1)
+
+		gotUpdate, err := m.StateUpdate(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, update, gotUpdate)
+	})
+
+	t.Run("failed upstreams do not count", func(t *testing.T) {
+		upstreams, m := newMulti(t)
+		upstreams[0].EXPECT().BlockByNumber(gomock.Any(), uint64(1)).Return(nil, errors.New("outage")).MaxTimes(1)
+		upstreams[1].EXPECT().BlockByNumber(gomock.Any(), uint64(1)).Return(block, nil).MaxTimes(1)
+		upstreams[2].EXPECT().BlockByNumber(gomock.Any(), uint64(1)).Return(block, nil).MaxTimes(1)
+
+		got, err := m.BlockByNumber(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, block, got)
+	})
+
+	t.Run("no quorum", func(t *testing.T) {
+		upstreams, m := newMulti(t)
+		upstreams[0].EXPECT().BlockByNumber(gomock.Any(), uint64(1)).Return(poisoned, nil)
+		upstreams[1].EXPECT().BlockByNumber(gomock.Any(), uint64(1)).Return(block, nil)
+		upstreams[2].EXPECT().BlockByNumber(gomock.Any(), uint64(1)).Return(nil, errors.New("outage"))
+
+		_, err := m.BlockByNumber(ctx, 1)
+		require.ErrorIs(t, err, multi.ErrNoQuorum)
+	})
+
+	t.Run("data that is not cross-checked fails over", func(t *testing.T) {
+		upstreams, m := newMulti(t)
+		upstreams[0].EXPECT().BlockLatest(gomock.Any()).Return(block, nil)
+
+		got, err := m.BlockLatest(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, block, got)
+	})
+}
+
+// rejecter is an upstream that records the blocks that were rejected.
+type rejecter struct {
+	*mocks.MockStarknetData
+	rejected []uint64
+}
+
+func (r *rejecter) RejectBlock(blockNumber uint64) {
+	r.rejected = append(r.rejected, blockNumber)
+}
+
+func TestRejectBlock(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	upstreams := []*rejecter{
+		{MockStarknetData: mocks.NewMockStarknetData(mockCtrl)},
+		{MockStarknetData: mocks.NewMockStarknetData(mockCtrl)},
+	}
+	m := multi.New([]starknetdata.StarknetData{upstreams[0], upstreams[1]}, utils.NewNopZapLogger())
+	ctx := context.Background()
+	block := &core.Block{Header: &core.Header{Number: 1}}
+
+	upstreams[0].EXPECT().BlockByNumber(gomock.Any(), uint64(1)).Return(block, nil)
+	_, err := m.BlockByNumber(ctx, 1)
+	require.NoError(t, err)
+
+	t.Run("a block that the preferred upstream did not provide does not change the preferred one", func(t *testing.T) {
+		m.RejectBlock(2)
+		upstreams[0].EXPECT().BlockByNumber(gomock.Any(), uint64(1)).Return(block, nil)
+
+		_, err := m.BlockByNumber(ctx, 1)
+		require.NoError(t, err)
+		assert.Empty(t, upstreams[0].rejected)
+	})
+
+	t.Run("the upstream that provided a rejected block is not preferred anymore", func(t *testing.T) {
+		m.RejectBlock(1)
+		assert.Equal(t, []uint64{1}, upstreams[0].rejected)
+		assert.Empty(t, upstreams[1].rejected)
+
+		upstreams[1].EXPECT().BlockByNumber(gomock.Any(), uint64(1)).Return(block, nil)
+		_, err := m.BlockByNumber(ctx, 1)
+		require.NoError(t, err)
+	})
+
+	t.Run("the rejected upstream is asked when the others fail", func(t *testing.T) {
+		upstreams[1].EXPECT().BlockByNumber(gomock.Any(), uint64(1)).Return(nil, errors.New("outage"))
+		upstreams[0].EXPECT().BlockByNumber(gomock.Any(), uint64(1)).Return(block, nil)
+		_, err := m.BlockByNumber(ctx, 1)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,441 @@
+// Package rpc provides a StarknetData that gets its data from another Starknet node, such as another Juno,
+// over the Starknet JSON-RPC API.
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/NethermindEth/juno/clients/feeder"
+	"github.com/NethermindEth/juno/clients/starknet"
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/crypto"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/rpc"
+	"github.com/NethermindEth/juno/starknetdata"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var _ starknetdata.StarknetData = (*RPC)(nil)
+
+// RPC adapts the responses of a Starknet node to the core types.
+//
+// The API does not carry everything that the feeder gateway does: blocks have no protocol version, receipts
+// have no execution resources or sender of their L2 to L1 messages, and compiled classes and block
+// signatures are not available at all. The L1 to L2 messages are taken from the L1 handler transactions
+// that consumed them. None of the missing data is needed to verify the blocks, which are checked against
+// their hashes as the ones from the feeder gateway are, but the blocks are stored without it.
+type RPC struct {
+	client *starknet.Client
+}
+
+func New(client *starknet.Client) *RPC {
+	return &RPC{
+		client: client,
+	}
+}
+
+// BlockByNumber gets the block for a given block number, along with the receipts of its transactions,
+// then adapts it to the core.Block type.
+func (r *RPC) BlockByNumber(ctx context.Context, blockNumber uint64) (*core.Block, error) {
+	return r.block(ctx, &rpc.BlockID{Number: blockNumber})
+}
+
+// BlockPending gets the pending block, which has no hash, number or state root.
+func (r *RPC) BlockPending(ctx context.Context) (*core.Block, error) {
+	return r.block(ctx, &rpc.BlockID{Pending: true})
+}
+
+// BlockLatest gets the latest block of the node.
+func (r *RPC) BlockLatest(ctx context.Context) (*core.Block, error) {
+	return r.block(ctx, &rpc.BlockID{Latest: true})
+}
+
+func (r *RPC) block(ctx context.Context, id *rpc.BlockID) (*core.Block, error) {
+	response, err := r.client.BlockWithTxs(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]*felt.Felt, len(response.Transactions))
+	for i, txn := range response.Transactions {
+		hashes[i] = txn.Hash
+	}
+	receipts, err := r.client.TransactionReceipts(ctx, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	return adaptBlock(response, receipts)
+}
+
+func adaptBlock(response *rpc.BlockWithTxs, receipts []*rpc.TransactionReceipt) (*core.Block, error) {
+	txns := make([]core.Transaction, len(response.Transactions))
+	coreReceipts := make([]*core.TransactionReceipt, len(receipts))
+	eventCount := uint64(0)
+	for i, txn := range response.Transactions {
+		var err error
+		txns[i], err = adaptTransaction(txn)
+		if err != nil {
+			return nil, err
+		}
+		coreReceipts[i] = adaptTransactionReceipt(receipts[i])
+		if l1Handler, ok := txns[i].(*core.L1HandlerTransaction); ok {
+			coreReceipts[i].L1ToL2Message = l1ToL2Message(l1Handler)
+		}
+		eventCount += uint64(len(receipts[i].Events))
+	}
+
+	status, err := adaptBlockStatus(response.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	header := &core.Header{
+		Hash:             response.Hash,
+		ParentHash:       response.ParentHash,
+		GlobalStateRoot:  response.NewRoot,
+		Timestamp:        response.Timestamp,
+		SequencerAddress: response.SequencerAddress,
+		TransactionCount: uint64(len(response.Transactions)),
+		EventCount:       eventCount,
+		Status:           status,
+	}
+	if response.Number != nil {
+		header.Number = *response.Number
+	}
+
+	return &core.Block{
+		Header:       header,
+		Transactions: txns,
+		Receipts:     coreReceipts,
+	}, nil
+}
+
+// l1ToL2Message returns the message that an L1 handler transaction consumed, whose sender is the first
+// value of the calldata and whose payload is the rest of it.
+func l1ToL2Message(txn *core.L1HandlerTransaction) *core.L1ToL2Message {
+	if len(txn.CallData) == 0 {
+		return nil
+	}
+
+	return &core.L1ToL2Message{
+		From:     common.BytesToAddress(txn.CallData[0].Marshal()),
+		Nonce:    txn.Nonce,
+		Payload:  txn.CallData[1:],
+		Selector: txn.EntryPointSelector,
+		To:       txn.ContractAddress,
+	}
+}
+
+func adaptBlockStatus(status rpc.Status) (core.BlockStatus, error) {
+	switch status {
+	case rpc.StatusAcceptedL2:
+		return core.BlockAcceptedOnL2, nil
+	case rpc.StatusAcceptedL1:
+		return core.BlockAcceptedOnL1, nil
+	case rpc.StatusPending:
+		return core.BlockPending, nil
+	case rpc.StatusRejected:
+		return core.BlockRejected, nil
+	default:
+		return 0, fmt.Errorf("unknown block status %d", status)
+	}
+}
+
+func adaptTransactionReceipt(response *rpc.TransactionReceipt) *core.TransactionReceipt {
+	events := make([]*core.Event, len(response.Events))
+	for i, event := range response.Events {
+		events[i] = &core.Event{
+			Data: event.Data,
+			From: event.From,
+			Keys: event.Keys,
+		}
+	}
+
+	l2ToL1Messages := make([]*core.L2ToL1Message, len(response.MessagesSent))
+	for i, msg := range response.MessagesSent {
+		l2ToL1Messages[i] = &core.L2ToL1Message{
+			Payload: msg.Payload,
+			To:      msg.To,
+		}
+	}
+
+	return &core.TransactionReceipt{
+		Fee:             response.ActualFee,
+		TransactionHash: response.Hash,
+		Events:          events,
+		L2ToL1Message:   l2ToL1Messages,
+	}
+}
+
+// Transaction gets the transaction for a given transaction hash,
+// then adapts it to the appropriate core.Transaction types.
+func (r *RPC) Transaction(ctx context.Context, transactionHash *felt.Felt) (core.Transaction, error) {
+	response, err := r.client.TransactionByHash(ctx, transactionHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return adaptTransaction(response)
+}
+
+func adaptTransaction(t *rpc.Transaction) (core.Transaction, error) {
+	switch t.Type {
+	case rpc.TxnDeclare:
+		return &core.DeclareTransaction{
+			TransactionHash:      t.Hash,
+			SenderAddress:        t.SenderAddress,
+			MaxFee:               t.MaxFee,
+			TransactionSignature: feltsOrNil(t.Signature),
+			Nonce:                t.Nonce,
+			Version:              t.Version,
+			ClassHash:            t.ClassHash,
+			CompiledClassHash:    t.CompiledClassHash,
+		}, nil
+	case rpc.TxnDeploy:
+		return adaptDeployTransaction(t), nil
+	case rpc.TxnInvoke:
+		return &core.InvokeTransaction{
+			TransactionHash:      t.Hash,
+			ContractAddress:      t.ContractAddress,
+			EntryPointSelector:   t.EntryPointSelector,
+			Nonce:                t.Nonce,
+			CallData:             feltsOrNil(t.Calldata),
+			TransactionSignature: feltsOrNil(t.Signature),
+			MaxFee:               t.MaxFee,
+			Version:              t.Version,
+			SenderAddress:        t.SenderAddress,
+		}, nil
+	case rpc.TxnDeployAccount:
+		return &core.DeployAccountTransaction{
+			DeployTransaction:    *adaptDeployTransaction(t),
+			MaxFee:               t.MaxFee,
+			TransactionSignature: feltsOrNil(t.Signature),
+			Nonce:                t.Nonce,
+		}, nil
+	case rpc.TxnL1Handler:
+		return &core.L1HandlerTransaction{
+			TransactionHash:    t.Hash,
+			ContractAddress:    t.ContractAddress,
+			EntryPointSelector: t.EntryPointSelector,
+			Nonce:              t.Nonce,
+			CallData:           feltsOrNil(t.Calldata),
+			Version:            t.Version,
+		}, nil
+	default:
+		return nil, errors.New("unknown transaction")
+	}
+}
+
+func adaptDeployTransaction(t *rpc.Transaction) *core.DeployTransaction {
+	return &core.DeployTransaction{
+		TransactionHash:     t.Hash,
+		ContractAddressSalt: t.ContractAddressSalt,
+		ContractAddress:     t.ContractAddress,
+		ClassHash:           t.ClassHash,
+		ConstructorCallData: t.ConstructorCalldata,
+		Version:             t.Version,
+	}
+}
+
+func feltsOrNil(felts *[]*felt.Felt) []*felt.Felt {
+	if felts == nil {
+		return nil
+	}
+	return *felts
+}
+
+// Class gets the class for a given class hash, as of the pending block if the node has one, so that the
+// classes that the pending block declares are found too, then adapts it to the core.Class type.
+func (r *RPC) Class(ctx context.Context, classHash *felt.Felt) (core.Class, error) {
+	response, err := r.client.Class(ctx, &rpc.BlockID{Pending: true}, classHash)
+	if errors.Is(err, starknet.ErrBlockNotFound) {
+		response, err = r.client.Class(ctx, &rpc.BlockID{Latest: true}, classHash)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if response.SierraProgram != nil {
+		return adaptCairo1Class(response)
+	}
+	return adaptCairo0Class(response)
+}
+
+func adaptCairo1Class(response *rpc.Class) (core.Class, error) {
+	var err error
+
+	class := new(core.Cairo1Class)
+	class.SemanticVersion = response.ContractClassVersion
+	class.Program = response.SierraProgram
+	class.ProgramHash = crypto.PoseidonArray(class.Program...)
+
+	abi, ok := response.Abi.(string)
+	if !ok {
+		return nil, fmt.Errorf("sierra class abi is %T, not a string", response.Abi)
+	}
+	class.Abi = abi
+	class.AbiHash, err = crypto.StarknetKeccak([]byte(class.Abi))
+	if err != nil {
+		return nil, err
+	}
+
+	if class.EntryPoints.External, err = adaptSierraEntryPoints(response.EntryPoints.External); err != nil {
+		return nil, err
+	}
+	if class.EntryPoints.L1Handler, err = adaptSierraEntryPoints(response.EntryPoints.L1Handler); err != nil {
+		return nil, err
+	}
+	if class.EntryPoints.Constructor, err = adaptSierraEntryPoints(response.EntryPoints.Constructor); err != nil {
+		return nil, err
+	}
+
+	return class, nil
+}
+
+func adaptSierraEntryPoints(entryPoints []rpc.EntryPoint) ([]core.SierraEntryPoint, error) {
+	result := make([]core.SierraEntryPoint, len(entryPoints))
+	for index, v := range entryPoints {
+		if v.Index == nil {
+			return nil, fmt.Errorf("sierra entry point %s has no function index", v.Selector)
+		}
+		result[index] = core.SierraEntryPoint{Index: *v.Index, Selector: v.Selector}
+	}
+	return result, nil
+}
+
+// adaptCairo0Class adapts a Cairo 0 class, whose program comes compressed in the same way as it is kept in
+// core.Cairo0Class. The program is decompressed only to hash it and to extract its builtins and bytecode.
+func adaptCairo0Class(response *rpc.Class) (core.Class, error) {
+	class := new(core.Cairo0Class)
+	class.Abi = response.Abi
+	class.Program = response.Program
+	class.Externals = adaptEntryPoints(response.EntryPoints.External)
+	class.L1Handlers = adaptEntryPoints(response.EntryPoints.L1Handler)
+	class.Constructors = adaptEntryPoints(response.EntryPoints.Constructor)
+
+	programJSON, err := utils.Gzip64Decode(response.Program)
+	if err != nil {
+		return nil, err
+	}
+	definition := &feeder.Cairo0Definition{Abi: response.Abi}
+	if err = json.Unmarshal(programJSON, &definition.Program); err != nil {
+		return nil, err
+	}
+
+	class.Builtins = []*felt.Felt{}
+	for _, v := range definition.Program.Builtins {
+		builtin := new(felt.Felt).SetBytes([]byte(v))
+		class.Builtins = append(class.Builtins, builtin)
+	}
+
+	class.Bytecode = []*felt.Felt{}
+	for _, v := range definition.Program.Data {
+		var datum *felt.Felt
+		if datum, err = new(felt.Felt).SetString(v); err != nil {
+			return nil, err
+		}
+
+		class.Bytecode = append(class.Bytecode, datum)
+	}
+
+	class.ProgramHash, err = feeder.ProgramHash(definition)
+	if err != nil {
+		return nil, err
+	}
+
+	return class, nil
+}
+
+func adaptEntryPoints(entryPoints []rpc.EntryPoint) []core.EntryPoint {
+	result := []core.EntryPoint{}
+	for _, v := range entryPoints {
+		result = append(result, core.EntryPoint{Selector: v.Selector, Offset: v.Offset})
+	}
+	return result
+}
+
+// CompiledClass is not available over the JSON-RPC API.
+func (r *RPC) CompiledClass(ctx context.Context, classHash *felt.Felt) (*core.CompiledClass, error) {
+	return nil, fmt.Errorf("compiled class %s: %w", classHash, starknetdata.ErrUnsupported)
+}
+
+// StateUpdate gets the state update for a given block number,
+// then adapts it to the core.StateUpdate type.
+func (r *RPC) StateUpdate(ctx context.Context, blockNumber uint64) (*core.StateUpdate, error) {
+	response, err := r.client.StateUpdate(ctx, &rpc.BlockID{Number: blockNumber})
+	if err != nil {
+		return nil, err
+	}
+
+	return adaptStateUpdate(response), nil
+}
+
+// StateUpdatePending gets the state update of the pending block, which has no block hash or new root.
+func (r *RPC) StateUpdatePending(ctx context.Context) (*core.StateUpdate, error) {
+	response, err := r.client.StateUpdate(ctx, &rpc.BlockID{Pending: true})
+	if err != nil {
+		return nil, err
+	}
+
+	return adaptStateUpdate(response), nil
+}
+
+func adaptStateUpdate(response *rpc.StateUpdate) *core.StateUpdate {
+	stateDiff := new(core.StateDiff)
+	stateDiff.DeclaredV0Classes = response.StateDiff.DeprecatedDeclaredClasses
+
+	stateDiff.DeclaredV1Classes = make([]core.DeclaredV1Class, len(response.StateDiff.DeclaredClasses))
+	for index, declaredV1Class := range response.StateDiff.DeclaredClasses {
+		stateDiff.DeclaredV1Classes[index] = core.DeclaredV1Class{
+			ClassHash:         declaredV1Class.ClassHash,
+			CompiledClassHash: declaredV1Class.CompiledClassHash,
+		}
+	}
+
+	stateDiff.ReplacedClasses = make([]core.ReplacedClass, len(response.StateDiff.ReplacedClasses))
+	for index, replacedClass := range response.StateDiff.ReplacedClasses {
+		stateDiff.ReplacedClasses[index] = core.ReplacedClass{
+			Address:   replacedClass.ContractAddress,
+			ClassHash: replacedClass.ClassHash,
+		}
+	}
+
+	stateDiff.DeployedContracts = make([]core.DeployedContract, len(response.StateDiff.DeployedContracts))
+	for index, deployedContract := range response.StateDiff.DeployedContracts {
+		stateDiff.DeployedContracts[index] = core.DeployedContract{
+			Address:   deployedContract.Address,
+			ClassHash: deployedContract.ClassHash,
+		}
+	}
+
+	stateDiff.Nonces = make(map[felt.Felt]*felt.Felt, len(response.StateDiff.Nonces))
+	for _, nonce := range response.StateDiff.Nonces {
+		stateDiff.Nonces[*nonce.ContractAddress] = nonce.Nonce
+	}
+
+	stateDiff.StorageDiffs = make(map[felt.Felt][]core.StorageDiff, len(response.StateDiff.StorageDiffs))
+	for _, storageDiff := range response.StateDiff.StorageDiffs {
+		diffs := make([]core.StorageDiff, len(storageDiff.StorageEntries))
+		for index, entry := range storageDiff.StorageEntries {
+			diffs[index] = core.StorageDiff{Key: entry.Key, Value: entry.Value}
+		}
+		stateDiff.StorageDiffs[*storageDiff.Address] = diffs
+	}
+
+	return &core.StateUpdate{
+		BlockHash: response.BlockHash,
+		NewRoot:   response.NewRoot,
+		OldRoot:   response.OldRoot,
+		StateDiff: stateDiff,
+	}
+}
+
+// BlockSignature is not available over the JSON-RPC API.
+func (r *RPC) BlockSignature(ctx context.Context, blockNumber uint64) (*core.BlockSignature, error) {
+	return nil, fmt.Errorf("signature of block %d: %w", blockNumber, starknetdata.ErrUnsupported)
+}
@@ -0,0 +1,235 @@
+package rpc_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NethermindEth/juno/blockchain"
+	"github.com/NethermindEth/juno/clients/feeder"
+	"github.com/NethermindEth/juno/clients/starknet"
+	"github.com/NethermindEth/juno/core"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db/pebble"
+	"github.com/NethermindEth/juno/jsonrpc"
+	"github.com/NethermindEth/juno/rpc"
+	"github.com/NethermindEth/juno/starknetdata"
+	adaptfeeder "github.com/NethermindEth/juno/starknetdata/feeder"
+	adaptrpc "github.com/NethermindEth/juno/starknetdata/rpc"
+	"github.com/NethermindEth/juno/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const head = 2
+
+// newTestAdapter returns an adapter over the JSON-RPC API of a chain of the first blocks of mainnet, along
+// with the feeder adapter that the chain was synced with.
+func newTestAdapter(t *testing.T) (*adaptrpc.RPC, *adaptfeeder.Feeder) {
+	t.Helper()
+
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+	ctx := context.Background()
+
+	log := utils.NewNopZapLogger()
+	chain := blockchain.New(pebble.NewMemTest(), utils.MAINNET, log)
+	for number := uint64(0); number <= head; number++ {
+		block, err := gw.BlockByNumber(ctx, number)
+		require.NoError(t, err)
+		update, err := gw.StateUpdate(ctx, number)
+		require.NoError(t, err)
+		classes := make(map[felt.Felt]core.Class)
+		for _, classHash := range update.StateDiff.ClassHashes() {
+			classes[*classHash], err = gw.Class(ctx, classHash)
+			require.NoError(t, err)
+		}
+		require.NoError(t, chain.Store(block, update, classes))
+	}
+
+	handler := rpc.New(chain, utils.MAINNET, log)
+	server := jsonrpc.NewServer()
+	require.NoError(t, server.RegisterMethods([]jsonrpc.Method{
+		{
+			Name:    "starknet_getBlockWithTxs",
+			Params:  []jsonrpc.Parameter{{Name: "block_id"}},
+			Handler: handler.BlockWithTxs,
+		},
+		{
+			Name:    "starknet_getTransactionByHash",
+			Params:  []jsonrpc.Parameter{{Name: "transaction_hash"}},
+			Handler: handler.TransactionByHash,
+		},
+		{
+			Name:    "starknet_getTransactionReceipt",
+			Params:  []jsonrpc.Parameter{{Name: "transaction_hash"}},
+			Handler: handler.TransactionReceiptByHash,
+		},
+		{
+			Name:    "starknet_getStateUpdate",
+			Params:  []jsonrpc.Parameter{{Name: "block_id"}},
+			Handler: handler.StateUpdate,
+		},
+		{
+			Name:    "starknet_getClass",
+			Params:  []jsonrpc.Parameter{{Name: "block_id"}, {Name: "class_hash"}},
+			Handler: handler.Class,
+		},
+	}...))
+	srv := httptest.NewServer(jsonrpc.NewHTTP(0, server, log))
+	t.Cleanup(srv.Close)
+
+	return adaptrpc.New(starknet.NewClient(srv.URL)), gw
+}
+
+func TestBlockByNumber(t *testing.T) {
+	adapter, gw := newTestAdapter(t)
+	ctx := context.Background()
+
+	for number := uint64(0); number <= head; number++ {
+		expected, err := gw.BlockByNumber(ctx, number)
+		require.NoError(t, err)
+		block, err := adapter.BlockByNumber(ctx, number)
+		require.NoError(t, err)
+
+		// the API has no protocol version
+		expected.ProtocolVersion = ""
+		assert.Equal(t, expected.Header, block.Header)
+		assert.Equal(t, expected.Transactions, block.Transactions)
+		require.Len(t, block.Receipts, len(expected.Receipts))
+		for i, receipt := range block.Receipts {
+			assert.Equal(t, expected.Receipts[i].TransactionHash, receipt.TransactionHash)
+			assert.Equal(t, expected.Receipts[i].Fee, receipt.Fee)
+			assert.Equal(t, expected.Receipts[i].Events, receipt.Events)
+			assert.Equal(t, expected.Receipts[i].L1ToL2Message, receipt.L1ToL2Message)
+		}
+		assert.NoError(t, core.VerifyBlockHash(block, utils.MAINNET))
+	}
+
+	latest, err := adapter.BlockLatest(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(head), latest.Number)
+
+	_, err = adapter.BlockByNumber(ctx, head+1)
+	assert.ErrorIs(t, err, starknet.ErrBlockNotFound)
+}
+
+func TestTransaction(t *testing.T) {
+	adapter, gw := newTestAdapter(t)
+	ctx := context.Background()
+
+	block, err := gw.BlockByNumber(ctx, head)
+	require.NoError(t, err)
+	for _, expected := range block.Transactions {
+		txn, err := adapter.Transaction(ctx, expected.Hash())
+		require.NoError(t, err)
+		assert.Equal(t, expected, txn)
+	}
+}
+
+func TestStateUpdate(t *testing.T) {
+	adapter, gw := newTestAdapter(t)
+	ctx := context.Background()
+
+	for number := uint64(0); number <= head; number++ {
+		expected, err := gw.StateUpdate(ctx, number)
+		require.NoError(t, err)
+		update, err := adapter.StateUpdate(ctx, number)
+		require.NoError(t, err)
+		assert.Equal(t, expected, update)
+	}
+}
+
+func TestClass(t *testing.T) {
+	adapter, gw := newTestAdapter(t)
+	ctx := context.Background()
+
+	update, err := gw.StateUpdate(ctx, head)
+	require.NoError(t, err)
+	require.NotEmpty(t, update.StateDiff.ClassHashes())
+	for _, classHash := range update.StateDiff.ClassHashes() {
+		expected, err := gw.Class(ctx, classHash)
+		require.NoError(t, err)
+		class, err := adapter.Class(ctx, classHash)
+		require.NoError(t, err)
+		assert.Equal(t, expected, class)
+		assert.Equal(t, classHash, class.Hash())
+	}
+
+	_, err = adapter.Class(ctx, new(felt.Felt).SetUint64(1))
+	assert.ErrorIs(t, err, starknet.ErrClassHashNotFound)
+}
+
+func TestUnsupported(t *testing.T) {
+	adapter, _ := newTestAdapter(t)
+	ctx := context.Background()
+
+	_, err := adapter.CompiledClass(ctx, new(felt.Felt).SetUint64(1))
+	assert.ErrorIs(t, err, starknetdata.ErrUnsupported)
+	_, err = adapter.BlockSignature(ctx, 0)
+	assert.ErrorIs(t, err, starknetdata.ErrUnsupported)
+}
+
+func TestL1ToL2Messages(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.GOERLI)
+	t.Cleanup(closeFn)
+	expected, err := adaptfeeder.New(client).BlockByNumber(context.Background(), 156000)
+	require.NoError(t, err)
+
+	// a node that serves the L1 handler transactions of the block, along with their receipts
+	block := &rpc.BlockWithTxs{Status: rpc.StatusAcceptedL2}
+	receipts := make(map[felt.Felt]*rpc.TransactionReceipt)
+	var messages []*core.L1ToL2Message
+	for i, txn := range expected.Transactions {
+		l1Handler, ok := txn.(*core.L1HandlerTransaction)
+		if !ok {
+			continue
+		}
+		calldata := l1Handler.CallData
+		block.Transactions = append(block.Transactions, &rpc.Transaction{
+			Hash:               l1Handler.TransactionHash,
+			Type:               rpc.TxnL1Handler,
+			Version:            l1Handler.Version,
+			Nonce:              l1Handler.Nonce,
+			ContractAddress:    l1Handler.ContractAddress,
+			Calldata:           &calldata,
+			EntryPointSelector: l1Handler.EntryPointSelector,
+		})
+		receipts[*l1Handler.TransactionHash] = &rpc.TransactionReceipt{
+			Type:   rpc.TxnL1Handler,
+			Hash:   l1Handler.TransactionHash,
+			Status: rpc.StatusAcceptedL2,
+		}
+		messages = append(messages, expected.Receipts[i].L1ToL2Message)
+	}
+	require.NotEmpty(t, messages)
+
+	log := utils.NewNopZapLogger()
+	server := jsonrpc.NewServer()
+	require.NoError(t, server.RegisterMethods([]jsonrpc.Method{
+		{
+			Name:   "starknet_getBlockWithTxs",
+			Params: []jsonrpc.Parameter{{Name: "block_id"}},
+			Handler: func(*rpc.BlockID) (*rpc.BlockWithTxs, *jsonrpc.Error) {
+				return block, nil
+			},
+		},
+		{
+			Name:   "starknet_getTransactionReceipt",
+			Params: []jsonrpc.Parameter{{Name: "transaction_hash"}},
+			Handler: func(hash *felt.Felt) (*rpc.TransactionReceipt, *jsonrpc.Error) {
+				return receipts[*hash], nil
+			},
+		},
+	}...))
+	srv := httptest.NewServer(jsonrpc.NewHTTP(0, server, log))
+	t.Cleanup(srv.Close)
+
+	got, err := adaptrpc.New(starknet.NewClient(srv.URL)).BlockByNumber(context.Background(), 156000)
+	require.NoError(t, err)
+	require.Len(t, got.Receipts, len(messages))
+	for i, receipt := range got.Receipts {
+		assert.Equal(t, messages[i], receipt.L1ToL2Message)
+	}
+}
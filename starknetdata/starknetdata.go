@@ -2,11 +2,15 @@ package starknetdata
 
 import (
 	"context"
+	"errors"
 
 	"github.com/NethermindEth/juno/core"
 	"github.com/NethermindEth/juno/core/felt"
 )
 
+// ErrUnsupported is returned for the data that a source has no way to provide.
+var ErrUnsupported = errors.New("not supported by this source")
+
 // StarknetData defines the function which are required to retrieve Starknet's state
 //
 //go:generate mockgen -destination=../mocks/mock_starknetdata.go -package=mocks github.com/NethermindEth/juno/starknetdata StarknetData
@@ -14,5 +18,17 @@ type StarknetData interface {
 	BlockByNumber(ctx context.Context, blockNumber uint64) (*core.Block, error)
 	Transaction(ctx context.Context, transactionHash *felt.Felt) (core.Transaction, error)
 	Class(ctx context.Context, classHash *felt.Felt) (core.Class, error)
+	CompiledClass(ctx context.Context, classHash *felt.Felt) (*core.CompiledClass, error)
 	StateUpdate(ctx context.Context, blockNumber uint64) (*core.StateUpdate, error)
+	BlockPending(ctx context.Context) (*core.Block, error)
+	BlockLatest(ctx context.Context) (*core.Block, error)
+	StateUpdatePending(ctx context.Context) (*core.StateUpdate, error)
+	BlockSignature(ctx context.Context, blockNumber uint64) (*core.BlockSignature, error)
+}
+
+// Rejecter is implemented by the sources that can stop trusting the upstream that provided them with a
+// block that failed verification.
+type Rejecter interface {
+	// RejectBlock is called with the number of a block whose block or state update failed verification.
+	RejectBlock(blockNumber uint64)
 }
@@ -0,0 +1,29 @@
+package sync
+
+import "time"
+
+// The steps that a block goes through to be synced.
+const (
+	OpFetch  = "fetch"
+	OpVerify = "verify"
+	OpStore  = "store"
+)
+
+// EventListener is notified of the progress of a [Synchronizer].
+type EventListener interface {
+	// OnSyncStepDone is called when one of the steps of syncing the block with the given number is done.
+	OnSyncStepDone(op string, blockNum uint64, took time.Duration)
+}
+
+var _ EventListener = (*SelectiveListener)(nil)
+
+// SelectiveListener is an [EventListener] that only calls the callbacks that are set.
+type SelectiveListener struct {
+	OnSyncStepDoneCb func(op string, blockNum uint64, took time.Duration)
+}
+
+func (l *SelectiveListener) OnSyncStepDone(op string, blockNum uint64, took time.Duration) {
+	if l.OnSyncStepDoneCb != nil {
+		l.OnSyncStepDoneCb(op, blockNum, took)
+	}
+}
@@ -2,44 +2,426 @@ package sync
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/NethermindEth/juno/blockchain"
 	"github.com/NethermindEth/juno/core"
 	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
 	"github.com/NethermindEth/juno/service"
 	"github.com/NethermindEth/juno/starknetdata"
 	"github.com/NethermindEth/juno/utils"
+	"github.com/sourcegraph/conc"
 	"github.com/sourcegraph/conc/stream"
 )
 
-var _ service.Service = (*Synchronizer)(nil)
+var (
+	_ service.Service = (*Synchronizer)(nil)
+	_ Reader          = (*Synchronizer)(nil)
+)
+
+const (
+	maxClassFetchAttempts     = 3
+	maxSignatureFetchAttempts = 3
+
+	// the delay between the attempts to fetch a block doubles from minRetryDelay up to maxRetryDelay, so that
+	// the sources that fail fast, such as the ones that do not have the block yet, are not asked in a loop
+	minRetryDelay = 10 * time.Millisecond
+	maxRetryDelay = 2 * time.Second
+
+	// blocks get accepted on L1 within hours, so the statuses of the blocks further behind the head than that
+	// are not refreshed
+	defaultStatusLookback = 1024
+)
+
+// Reader provides access to the progress of the sync
+type Reader interface {
+	// StartingBlockHeader returns the head when the sync started, or the first synced block if the
+	// blockchain was empty. It is nil until known.
+	StartingBlockHeader() *core.Header
+	// HighestBlockHeader returns the latest block known to the network. It is nil until known.
+	HighestBlockHeader() *core.Header
+	Stats() Stats
+}
+
+// Stats describes the work done by the Synchronizer since it started.
+type Stats struct {
+	StartedAt time.Time
+	// BlocksFetched includes the blocks that were fetched again after a rollback
+	BlocksFetched  uint64
+	BlocksVerified uint64
+	Rollbacks      uint64
+}
 
 // Synchronizer manages a list of StarknetData to fetch the latest blockchain updates
 type Synchronizer struct {
 	Blockchain   *blockchain.Blockchain
 	StarknetData starknetdata.StarknetData
 
-	log utils.SimpleLogger
+	pendingPollInterval time.Duration
+	statusPollInterval  time.Duration
+	latestPollInterval  time.Duration
+	statusLookback      uint64
+
+	// the public key of the sequencer, nil if signatures are not verified
+	publicKey *felt.Felt
+
+	progressLock  sync.RWMutex
+	startingBlock *core.Header
+	highestBlock  *core.Header
+
+	startedAt      time.Time
+	blocksFetched  uint64
+	blocksVerified uint64
+	rollbacks      uint64
+
+	// the classes fetched for the pending blocks built on top of pendingParent, so that they are not fetched
+	// again every time the pending block is polled. They are only used by the goroutine that polls it.
+	pendingParent  *felt.Felt
+	pendingClasses map[felt.Felt]core.Class
+
+	listener EventListener
+	log      utils.SimpleLogger
 }
 
 func New(bc *blockchain.Blockchain, starkNetData starknetdata.StarknetData, log utils.SimpleLogger) *Synchronizer {
 	return &Synchronizer{
-		Blockchain:   bc,
-		StarknetData: starkNetData,
-		log:          log,
+		Blockchain:     bc,
+		StarknetData:   starkNetData,
+		statusLookback: defaultStatusLookback,
+		listener:       &SelectiveListener{},
+		log:            log,
+	}
+}
+
+// WithListener sets the listener that is notified of the progress of the Synchronizer.
+func (s *Synchronizer) WithListener(listener EventListener) *Synchronizer {
+	s.listener = listener
+	return s
+}
+
+// WithPendingPolling makes the Synchronizer fetch the pending block at the given interval.
+func (s *Synchronizer) WithPendingPolling(interval time.Duration) *Synchronizer {
+	s.pendingPollInterval = interval
+	return s
+}
+
+// WithStatusPolling makes the Synchronizer refresh the status of the blocks that are not accepted on L1
+// yet at the given interval.
+func (s *Synchronizer) WithStatusPolling(interval time.Duration) *Synchronizer {
+	s.statusPollInterval = interval
+	return s
+}
+
+// WithLatestPolling makes the Synchronizer fetch the latest block at the given interval, to know the
+// highest block of the network.
+func (s *Synchronizer) WithLatestPolling(interval time.Duration) *Synchronizer {
+	s.latestPollInterval = interval
+	return s
+}
+
+// WithSignatureVerification makes the Synchronizer reject the blocks whose signature by the sequencer does
+// not verify against the given public key of the sequencer.
+func (s *Synchronizer) WithSignatureVerification(publicKey *felt.Felt) *Synchronizer {
+	s.publicKey = publicKey
+	return s
+}
+
+// StartingBlockHeader implements [Reader]
+func (s *Synchronizer) StartingBlockHeader() *core.Header {
+	s.progressLock.RLock()
+	defer s.progressLock.RUnlock()
+	return s.startingBlock
+}
+
+// HighestBlockHeader implements [Reader]
+func (s *Synchronizer) HighestBlockHeader() *core.Header {
+	s.progressLock.RLock()
+	defer s.progressLock.RUnlock()
+	return s.highestBlock
+}
+
+// Stats implements [Reader]
+func (s *Synchronizer) Stats() Stats {
+	s.progressLock.RLock()
+	startedAt := s.startedAt
+	s.progressLock.RUnlock()
+
+	return Stats{
+		StartedAt:      startedAt,
+		BlocksFetched:  atomic.LoadUint64(&s.blocksFetched),
+		BlocksVerified: atomic.LoadUint64(&s.blocksVerified),
+		Rollbacks:      atomic.LoadUint64(&s.rollbacks),
 	}
 }
 
 // Run starts the Synchronizer, returns an error if the loop is already running
 func (s *Synchronizer) Run(ctx context.Context) error {
+	// the first synced block becomes the starting block if the blockchain is empty
+	startingBlock, err := s.Blockchain.HeadsHeader()
+	if err != nil {
+		startingBlock = nil
+	}
+	s.progressLock.Lock()
+	s.startingBlock = startingBlock
+	s.startedAt = time.Now()
+	s.progressLock.Unlock()
+
+	wg := conc.NewWaitGroup()
+	if s.latestPollInterval > 0 {
+		wg.Go(func() {
+			s.pollLatest(ctx)
+		})
+	}
+	if s.pendingPollInterval > 0 {
+		wg.Go(func() {
+			s.pollPending(ctx)
+		})
+	}
+	if s.statusPollInterval > 0 {
+		wg.Go(func() {
+			s.pollStatuses(ctx)
+		})
+	}
+
 	s.syncBlocks(ctx)
+	wg.Wait()
+	return nil
+}
+
+func (s *Synchronizer) pollPending(ctx context.Context) {
+	ticker := time.NewTicker(s.pendingPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.fetchPending(ctx); err != nil {
+				s.log.Debugw("Failed fetching pending block", "err", err)
+			}
+		}
+	}
+}
+
+// fetchPending fetches the pending block and keeps it in the blockchain if it is built on top of the head.
+func (s *Synchronizer) fetchPending(ctx context.Context) error {
+	block, err := s.StarknetData.BlockPending(ctx)
+	if err != nil {
+		return err
+	}
+
+	// there is no point in fetching the rest while catching up
+	parentRoot := &felt.Zero
+	head, err := s.Blockchain.HeadsHeader()
+	if err == nil {
+		if !block.ParentHash.Equal(head.Hash) {
+			return blockchain.ErrParentDoesNotMatchHead
+		}
+		parentRoot = head.GlobalStateRoot
+	}
+
+	stateUpdate, err := s.StarknetData.StateUpdatePending(ctx)
+	if err != nil {
+		return err
+	}
+
+	// the sequencer may have moved on to the next block between the two requests
+	if !stateUpdate.OldRoot.Equal(parentRoot) {
+		return errors.New("pending state update is not built on top of the parent of the pending block")
+	}
+
+	newClasses, err := s.fetchPendingClasses(ctx, block.ParentHash, stateUpdate.StateDiff)
+	if err != nil {
+		return err
+	}
+
+	if err = s.Blockchain.StorePending(&blockchain.Pending{
+		Block:       block,
+		StateUpdate: stateUpdate,
+		NewClasses:  newClasses,
+	}); err != nil {
+		return err
+	}
+
+	s.log.Debugw("Stored pending block", "parent", block.ParentHash.ShortString(), "transactions", len(block.Transactions))
+	return nil
+}
+
+// fetchPendingClasses fetches the definitions of the classes referenced by the state diff of a pending block
+// that are not stored yet. The classes fetched for the previous pending blocks on top of the same parent
+// are not fetched again.
+func (s *Synchronizer) fetchPendingClasses(ctx context.Context, parentHash *felt.Felt,
+	stateDiff *core.StateDiff,
+) (map[felt.Felt]core.Class, error) {
+	if s.pendingParent == nil || !s.pendingParent.Equal(parentHash) {
+		s.pendingParent = parentHash
+		s.pendingClasses = make(map[felt.Felt]core.Class)
+	}
+
+	// nothing is stored before the genesis block
+	state, closer, err := s.Blockchain.HeadState()
+	if err != nil && !errors.Is(err, db.ErrKeyNotFound) {
+		return nil, err
+	} else if err == nil {
+		defer func() {
+			if closeErr := closer(); closeErr != nil {
+				s.log.Debugw("Failed closing the head state", "err", closeErr)
+			}
+		}()
+	}
+
+	declaredV1 := make(map[felt.Felt]bool, len(stateDiff.DeclaredV1Classes))
+	for _, declared := range stateDiff.DeclaredV1Classes {
+		declaredV1[*declared.ClassHash] = true
+	}
+
+	newClasses := make(map[felt.Felt]core.Class)
+	for _, classHash := range stateDiff.ClassHashes() {
+		if class, ok := s.pendingClasses[*classHash]; ok {
+			newClasses[*classHash] = class
+			continue
+		}
+		if state != nil {
+			if _, cErr := state.Class(classHash); cErr == nil {
+				continue
+			} else if !errors.Is(cErr, db.ErrKeyNotFound) {
+				return nil, cErr
+			}
+		}
+
+		var class core.Class
+		if class, err = s.fetchClass(ctx, classHash); err != nil {
+			return nil, err
+		}
+		if declaredV1[*classHash] {
+			sierra, ok := class.(*core.Cairo1Class)
+			if !ok {
+				return nil, fmt.Errorf("declared class %s is not a Sierra class", classHash)
+			}
+			if sierra.Compiled, err = s.fetchCompiledClass(ctx, classHash); err != nil {
+				return nil, err
+			}
+		}
+		s.pendingClasses[*classHash] = class
+		newClasses[*classHash] = class
+	}
+	return newClasses, nil
+}
+
+func (s *Synchronizer) pollLatest(ctx context.Context) {
+	ticker := time.NewTicker(s.latestPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.fetchLatest(ctx); err != nil {
+			s.log.Debugw("Failed fetching latest block", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Synchronizer) fetchLatest(ctx context.Context) error {
+	block, err := s.StarknetData.BlockLatest(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.updateHighestBlock(block.Header)
+	return nil
+}
+
+// updateHighestBlock keeps the given header as the highest block, unless a higher one is known already.
+func (s *Synchronizer) updateHighestBlock(header *core.Header) {
+	s.progressLock.Lock()
+	defer s.progressLock.Unlock()
+	if s.highestBlock == nil || s.highestBlock.Number <= header.Number {
+		s.highestBlock = header
+	}
+}
+
+func (s *Synchronizer) pollStatuses(ctx context.Context) {
+	ticker := time.NewTicker(s.statusPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.refreshStatuses(ctx); err != nil {
+				s.log.Debugw("Failed refreshing block statuses", "err", err)
+			}
+		}
+	}
+}
+
+// refreshStatuses updates the status of the stored blocks that got accepted on L1 since they were synced.
+// Blocks are accepted on L1 in order, so only the blocks after the highest one known to be accepted on L1
+// are checked, oldest first, until one that is still only accepted on L2 is found.
+func (s *Synchronizer) refreshStatuses(ctx context.Context) error {
+	head, err := s.Blockchain.HeadsHeader()
+	if err != nil {
+		return err
+	}
+
+	var oldest uint64
+	if l1Height, l1Err := s.Blockchain.L1Height(); l1Err == nil {
+		oldest = l1Height + 1
+	} else if !errors.Is(l1Err, db.ErrKeyNotFound) {
+		return l1Err
+	}
+	if head.Number >= s.statusLookback && oldest <= head.Number-s.statusLookback {
+		oldest = head.Number - s.statusLookback + 1
+	}
+
+	for number := oldest; number <= head.Number; number++ {
+		var block *core.Block
+		if block, err = s.StarknetData.BlockByNumber(ctx, number); err != nil {
+			return err
+		}
+
+		var header *core.Header
+		if header, err = s.Blockchain.BlockHeaderByNumber(number); err != nil {
+			return err
+		}
+
+		// the block got reorged away, the sync loop takes care of it
+		if !block.Hash.Equal(header.Hash) {
+			return nil
+		}
+
+		if block.Status != header.Status {
+			if err = s.Blockchain.SetBlockStatus(number, block.Status); err != nil {
+				return err
+			}
+			s.log.Debugw("Updated block status", "number", number, "status", block.Status)
+		}
+
+		// the blocks after it are not accepted on L1 either
+		if block.Status != core.BlockAcceptedOnL1 {
+			return nil
+		}
+	}
 	return nil
 }
 
 func (s *Synchronizer) fetcherTask(ctx context.Context, height uint64, verifiers *stream.Stream,
 	resetStreams context.CancelFunc,
 ) stream.Callback {
+	start := time.Now()
+	retryDelay := minRetryDelay
 	for {
 		select {
 		case <-ctx.Done():
@@ -47,43 +429,204 @@ func (s *Synchronizer) fetcherTask(ctx context.Context, height uint64, verifiers
 		default:
 			block, err := s.StarknetData.BlockByNumber(ctx, height)
 			if err != nil {
+				retryDelay = backOff(ctx, retryDelay)
 				continue
 			}
 			stateUpdate, err := s.StarknetData.StateUpdate(ctx, height)
 			if err != nil {
+				retryDelay = backOff(ctx, retryDelay)
 				continue
 			}
 
-			// There are classes in deployed transactions which refer to class hash that are no present in declared
-			// classes. Thus, we need to fetch all the classes which are referenced in deployed contracts
-			referencedClasses := make(map[felt.Felt]core.Class)
-			for _, deployedContract := range stateUpdate.StateDiff.DeployedContracts {
-				referencedClasses[*deployedContract.ClassHash] = nil
-			}
-			for _, classHash := range stateUpdate.StateDiff.DeclaredV0Classes { // todo: fetch v1 classes as well
-				referencedClasses[*classHash] = nil
+			var signature *core.BlockSignature
+			if s.publicKey != nil {
+				if signature, err = s.fetchSignature(ctx, height); err != nil {
+					return func() {
+						select {
+						case <-ctx.Done():
+						default:
+							s.log.Warnw("Failed fetching the block signature", "number", height, "err", err)
+							resetStreams()
+						}
+					}
+				}
 			}
-			for classHash := range referencedClasses {
-				class, err := s.StarknetData.Class(ctx, &classHash)
-				if err != nil {
-					continue
+
+			referencedClasses, err := s.fetchReferencedClasses(ctx, stateUpdate.StateDiff)
+			if err != nil {
+				return func() {
+					select {
+					case <-ctx.Done():
+					default:
+						s.log.Warnw("Failed fetching classes", "number", height, "err", err)
+						resetStreams()
+					}
 				}
-				referencedClasses[classHash] = class
 			}
 
+			atomic.AddUint64(&s.blocksFetched, 1)
+			s.listener.OnSyncStepDone(OpFetch, height, time.Since(start))
 			return func() {
 				verifiers.Go(func() stream.Callback {
-					return s.verifierTask(ctx, block, stateUpdate, referencedClasses, resetStreams)
+					return s.verifierTask(ctx, block, stateUpdate, signature, referencedClasses, resetStreams)
 				})
 			}
 		}
 	}
 }
 
+// backOff waits for the given delay, or until ctx is done, and returns the delay to wait after the next
+// failure.
+func backOff(ctx context.Context, delay time.Duration) time.Duration {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+
+	if delay *= 2; delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
+
+// fetchReferencedClasses fetches the definitions of all the classes referenced by the given state diff.
+func (s *Synchronizer) fetchReferencedClasses(ctx context.Context, stateDiff *core.StateDiff) (map[felt.Felt]core.Class, error) {
+	// There are classes in deployed transactions which refer to class hash that are no present in declared
+	// classes. Thus, we need to fetch all the classes which are referenced in deployed contracts
+	referencedClasses := make(map[felt.Felt]core.Class)
+	for _, classHash := range stateDiff.ClassHashes() {
+		referencedClasses[*classHash] = nil
+	}
+	for classHash := range referencedClasses {
+		classHash := classHash
+		class, err := s.fetchClass(ctx, &classHash)
+		if err != nil {
+			return nil, err
+		}
+		referencedClasses[classHash] = class
+	}
+
+	// the compiled classes of the declared Sierra classes are stored along with them, so that the compiled
+	// class hashes that the state diff commits to can be verified
+	for _, declared := range stateDiff.DeclaredV1Classes {
+		class, ok := referencedClasses[*declared.ClassHash].(*core.Cairo1Class)
+		if !ok {
+			return nil, fmt.Errorf("declared class %s is not a Sierra class", declared.ClassHash)
+		}
+
+		var err error
+		if class.Compiled, err = s.fetchCompiledClass(ctx, declared.ClassHash); err != nil {
+			return nil, err
+		}
+	}
+	return referencedClasses, nil
+}
+
+// fetchClass fetches the definition of a class, giving up after maxClassFetchAttempts failed attempts.
+func (s *Synchronizer) fetchClass(ctx context.Context, classHash *felt.Felt) (core.Class, error) {
+	var err error
+	retryDelay := minRetryDelay
+	for attempt := 0; attempt < maxClassFetchAttempts; attempt++ {
+		if attempt > 0 {
+			retryDelay = backOff(ctx, retryDelay)
+		}
+
+		var class core.Class
+		class, err = s.StarknetData.Class(ctx, classHash)
+		if err == nil {
+			return class, nil
+		} else if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+	return nil, fmt.Errorf("fetch class %s: %w", classHash, err)
+}
+
+// fetchSignature fetches the signature of a block, giving up after maxSignatureFetchAttempts failed
+// attempts.
+func (s *Synchronizer) fetchSignature(ctx context.Context, blockNumber uint64) (*core.BlockSignature, error) {
+	var err error
+	retryDelay := minRetryDelay
+	for attempt := 0; attempt < maxSignatureFetchAttempts; attempt++ {
+		if attempt > 0 {
+			retryDelay = backOff(ctx, retryDelay)
+		}
+
+		var signature *core.BlockSignature
+		signature, err = s.StarknetData.BlockSignature(ctx, blockNumber)
+		if err == nil {
+			return signature, nil
+		} else if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+	return nil, fmt.Errorf("fetch signature of block %d: %w", blockNumber, err)
+}
+
+// fetchCompiledClass fetches the compiled class of a Sierra class, giving up after maxClassFetchAttempts
+// failed attempts. The compiled class is nil if the source can not provide it.
+func (s *Synchronizer) fetchCompiledClass(ctx context.Context, classHash *felt.Felt) (*core.CompiledClass, error) {
+	var err error
+	retryDelay := minRetryDelay
+	for attempt := 0; attempt < maxClassFetchAttempts; attempt++ {
+		if attempt > 0 {
+			retryDelay = backOff(ctx, retryDelay)
+		}
+
+		var compiled *core.CompiledClass
+		compiled, err = s.StarknetData.CompiledClass(ctx, classHash)
+		if err == nil {
+			return compiled, nil
+		} else if errors.Is(err, starknetdata.ErrUnsupported) {
+			// the class is stored without it, which only means that its compiled class hash is not verified
+			return nil, nil
+		} else if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+	return nil, fmt.Errorf("fetch compiled class %s: %w", classHash, err)
+}
+
+// RepairMissingClasses fetches and stores the definitions of the classes that are referenced by stored
+// state updates but missing from the database.
+func (s *Synchronizer) RepairMissingClasses(ctx context.Context) error {
+	missing, err := s.Blockchain.MissingClasses()
+	if err != nil {
+		return err
+	}
+
+	if len(missing) > 0 {
+		s.log.Infow("Repairing missing classes", "count", len(missing))
+	}
+
+	for classHash, declaredAt := range missing {
+		classHash := classHash
+		var class core.Class
+		class, err = s.fetchClass(ctx, &classHash)
+		if err != nil {
+			return err
+		}
+
+		if err = s.Blockchain.StoreMissingClass(&classHash, class, declaredAt); err != nil {
+			return err
+		}
+		s.log.Infow("Stored missing class", "hash", classHash.ShortString(), "declaredAt", declaredAt)
+	}
+	return nil
+}
+
 func (s *Synchronizer) verifierTask(ctx context.Context, block *core.Block, stateUpdate *core.StateUpdate,
-	declaredClasses map[felt.Felt]core.Class, resetStreams context.CancelFunc,
+	signature *core.BlockSignature, declaredClasses map[felt.Felt]core.Class, resetStreams context.CancelFunc,
 ) stream.Callback {
+	start := time.Now()
 	err := s.Blockchain.SanityCheckNewHeight(block, stateUpdate, declaredClasses)
+	var signatureErr error
+	if err == nil && signature != nil {
+		signatureErr = core.VerifyBlockSignature(block, stateUpdate, signature, s.publicKey)
+	}
+	s.listener.OnSyncStepDone(OpVerify, block.Number, time.Since(start))
 	return func() {
 		select {
 		case <-ctx.Done():
@@ -91,24 +634,70 @@ func (s *Synchronizer) verifierTask(ctx context.Context, block *core.Block, stat
 		default:
 			if err != nil {
 				s.log.Warnw("Sanity checks failed", "number", block.Number, "hash", block.Hash.ShortString())
+				s.rejectBlock(block.Number)
+				resetStreams()
+				return
+			}
+			if signatureErr != nil {
+				s.log.Warnw("Signature verification failed", "number", block.Number,
+					"hash", block.Hash.ShortString(), "err", signatureErr)
+				s.rejectBlock(block.Number)
 				resetStreams()
 				return
 			}
 
+			storeStart := time.Now()
 			err := s.Blockchain.Store(block, stateUpdate, declaredClasses)
 			if err != nil {
-				s.log.Warnw("Failed storing Block", "number", block.Number,
-					"hash", block.Hash.ShortString(), "err", err.Error())
+				if errors.Is(err, blockchain.ErrParentDoesNotMatchHead) {
+					// revert the head and restart the sync process, hoping that the reorg is not deep
+					// if the reorg is deeper, we will end up here again and revert more blocks
+					s.revertHead(block)
+				} else {
+					s.log.Warnw("Failed storing Block", "number", block.Number,
+						"hash", block.Hash.ShortString(), "err", err.Error())
+				}
 				resetStreams()
 				return
 			}
 
+			s.listener.OnSyncStepDone(OpStore, block.Number, time.Since(storeStart))
 			s.log.Infow("Stored Block", "number", block.Number, "hash",
 				block.Hash.ShortString(), "root", block.GlobalStateRoot.ShortString())
+			atomic.AddUint64(&s.blocksVerified, 1)
+			s.progressLock.Lock()
+			if s.startingBlock == nil {
+				s.startingBlock = block.Header
+			}
+			s.progressLock.Unlock()
 		}
 	}
 }
 
+// rejectBlock tells the source of the blocks, if it wants to know, that a block it provided failed
+// verification.
+func (s *Synchronizer) rejectBlock(blockNumber uint64) {
+	if rejecter, ok := s.StarknetData.(starknetdata.Rejecter); ok {
+		rejecter.RejectBlock(blockNumber)
+	}
+}
+
+func (s *Synchronizer) revertHead(forkBlock *core.Block) {
+	localHead, err := s.Blockchain.HeadsHeader()
+	if err != nil {
+		s.log.Warnw("Failed getting the local head", "err", err)
+		return
+	}
+
+	s.log.Infow("Reorg detected", "localHead", localHead.Hash.ShortString(), "forkHead", forkBlock.Hash.ShortString())
+
+	if err = s.Blockchain.RevertHead(); err != nil {
+		s.log.Warnw("Failed reverting HEAD", "reverted", localHead.Number, "err", err)
+	} else {
+		s.log.Infow("Reverted HEAD", "reverted", localHead.Number)
+	}
+}
+
 func (s *Synchronizer) nextHeight() uint64 {
 	nextHeight := uint64(0)
 	if h, err := s.Blockchain.Height(); err == nil {
@@ -136,6 +725,7 @@ func (s *Synchronizer) syncBlocks(syncCtx context.Context) {
 			default:
 				streamCtx, streamCancel = context.WithCancel(syncCtx)
 				nextHeight = s.nextHeight()
+				atomic.AddUint64(&s.rollbacks, 1)
 				s.log.Warnw("Rolling back sync process", "height", nextHeight)
 			}
 		default:
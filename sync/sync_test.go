@@ -3,6 +3,8 @@ package sync
 import (
 	"context"
 	"errors"
+	"runtime"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -11,9 +13,12 @@ import (
 	"github.com/NethermindEth/juno/clients/feeder"
 	"github.com/NethermindEth/juno/core"
 	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/db"
 	"github.com/NethermindEth/juno/db/pebble"
 	"github.com/NethermindEth/juno/mocks"
+	"github.com/NethermindEth/juno/starknetdata"
 	adaptfeeder "github.com/NethermindEth/juno/starknetdata/feeder"
+	"github.com/NethermindEth/juno/starknetdata/multi"
 	"github.com/NethermindEth/juno/utils"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -54,25 +59,85 @@ func TestSyncBlocks(t *testing.T) {
 	t.Run("sync multiple blocks in an empty db", func(t *testing.T) {
 		testDB := pebble.NewMemTest()
 		bc := blockchain.New(testDB, utils.MAINNET, log)
-		synchronizer := New(bc, gw, log)
+
+		var stepsLock sync.Mutex
+		steps := make(map[string]map[uint64]bool)
+		listener := &SelectiveListener{
+			OnSyncStepDoneCb: func(op string, blockNum uint64, took time.Duration) {
+				stepsLock.Lock()
+				defer stepsLock.Unlock()
+				if steps[op] == nil {
+					steps[op] = make(map[uint64]bool)
+				}
+				steps[op][blockNum] = true
+			},
+		}
+		synchronizer := New(bc, gw, log).WithListener(listener)
 		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 
 		require.NoError(t, synchronizer.Run(ctx))
 		cancel()
 
 		testBlockchain(t, bc)
+
+		b0, err := gw.BlockByNumber(context.Background(), 0)
+		require.NoError(t, err)
+		assert.Equal(t, b0.Header, synchronizer.StartingBlockHeader())
+
+		stats := synchronizer.Stats()
+		assert.False(t, stats.StartedAt.IsZero())
+		assert.Equal(t, uint64(3), stats.BlocksVerified)
+		assert.GreaterOrEqual(t, stats.BlocksFetched, stats.BlocksVerified)
+		assert.Zero(t, stats.Rollbacks)
+
+		stepsLock.Lock()
+		defer stepsLock.Unlock()
+		for _, op := range []string{OpFetch, OpVerify, OpStore} {
+			assert.Equal(t, map[uint64]bool{0: true, 1: true, 2: true}, steps[op], op)
+		}
 	})
 
 	t.Run("sync multiple blocks in a non-empty db", func(t *testing.T) {
 		testDB := pebble.NewMemTest()
 		bc := blockchain.New(testDB, utils.MAINNET, log)
+		synchronizer := New(bc, gw, log)
 		b0, err := gw.BlockByNumber(context.Background(), 0)
 		require.NoError(t, err)
 		s0, err := gw.StateUpdate(context.Background(), 0)
 		require.NoError(t, err)
-		require.NoError(t, bc.Store(b0, s0, nil))
+		classes, err := synchronizer.fetchReferencedClasses(context.Background(), s0.StateDiff)
+		require.NoError(t, err)
+		require.NoError(t, bc.Store(b0, s0, classes))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 
+		require.NoError(t, synchronizer.Run(ctx))
+		cancel()
+
+		testBlockchain(t, bc)
+		assert.Equal(t, b0.Header, synchronizer.StartingBlockHeader())
+	})
+
+	t.Run("revert head when the local chain forks", func(t *testing.T) {
+		testDB := pebble.NewMemTest()
+		bc := blockchain.New(testDB, utils.MAINNET, log)
 		synchronizer := New(bc, gw, log)
+		b0, err := gw.BlockByNumber(context.Background(), 0)
+		require.NoError(t, err)
+		s0, err := gw.StateUpdate(context.Background(), 0)
+		require.NoError(t, err)
+		classes, err := synchronizer.fetchReferencedClasses(context.Background(), s0.StateDiff)
+		require.NoError(t, err)
+		require.NoError(t, bc.Store(b0, s0, classes))
+
+		// a block 1 that is not on the canonical chain
+		forkBlock1, err := gw.BlockByNumber(context.Background(), 1)
+		require.NoError(t, err)
+		forkBlock1.Hash = new(felt.Felt).SetUint64(1337)
+		s1, err := gw.StateUpdate(context.Background(), 1)
+		require.NoError(t, err)
+		require.NoError(t, bc.Store(forkBlock1, s1, nil))
+
 		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 
 		require.NoError(t, synchronizer.Run(ctx))
@@ -134,5 +199,504 @@ func TestSyncBlocks(t *testing.T) {
 		cancel()
 
 		testBlockchain(t, bc)
+		assert.NotZero(t, synchronizer.Stats().Rollbacks)
+	})
+
+	t.Run("sync multiple blocks, failing over from an upstream that provides bad blocks", func(t *testing.T) {
+		bc := blockchain.New(pebble.NewMemTest(), utils.MAINNET, log)
+
+		poisoned := mocks.NewMockStarknetData(mockCtrl)
+		poisoned.EXPECT().BlockByNumber(gomock.Any(), gomock.Any()).DoAndReturn(gw.BlockByNumber).AnyTimes()
+		poisoned.EXPECT().StateUpdate(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, height uint64) (*core.StateUpdate, error) {
+			update, err := gw.StateUpdate(ctx, height)
+			if err == nil {
+				update.BlockHash = new(felt.Felt) // fail sanity checks
+			}
+			return update, err
+		}).AnyTimes()
+		poisoned.EXPECT().Class(gomock.Any(), gomock.Any()).DoAndReturn(gw.Class).AnyTimes()
+
+		synchronizer := New(bc, multi.New([]starknetdata.StarknetData{poisoned, gw}, log), log)
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+
+		require.NoError(t, synchronizer.Run(ctx))
+		cancel()
+
+		testBlockchain(t, bc)
+		assert.NotZero(t, synchronizer.Stats().Rollbacks)
+	})
+}
+
+func TestRetryDelay(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	// a source that has no blocks, such as a replayed response tree past its end, fails fast
+	var requests uint64
+	mockSNData := mocks.NewMockStarknetData(mockCtrl)
+	mockSNData.EXPECT().BlockByNumber(gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, uint64) (*core.Block, error) {
+		atomic.AddUint64(&requests, 1)
+		return nil, errors.New("404 Not Found")
+	}).AnyTimes()
+
+	log := utils.NewNopZapLogger()
+	synchronizer := New(blockchain.New(pebble.NewMemTest(), utils.MAINNET, log), mockSNData, log)
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	require.NoError(t, synchronizer.Run(ctx))
+	cancel()
+
+	// every fetcher waits 10, 20, 40, 80, 160 and 320ms between its first requests
+	assert.LessOrEqual(t, atomic.LoadUint64(&requests), uint64(6*runtime.NumCPU()))
+}
+
+func TestFetchReferencedClasses(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	client, closeFn := feeder.NewTestClient(utils.INTEGRATION)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+
+	// there is no compiled class in the test data
+	compiled := &core.CompiledClass{Bytecode: []*felt.Felt{new(felt.Felt).SetUint64(1)}}
+	mockSNData := mocks.NewMockStarknetData(mockCtrl)
+	mockSNData.EXPECT().Class(gomock.Any(), gomock.Any()).DoAndReturn(gw.Class).AnyTimes()
+
+	log := utils.NewNopZapLogger()
+	synchronizer := New(blockchain.New(pebble.NewMemTest(), utils.INTEGRATION, log), mockSNData, log)
+
+	stateUpdate, err := gw.StateUpdate(context.Background(), 283364)
+	require.NoError(t, err)
+	require.Len(t, stateUpdate.StateDiff.DeclaredV1Classes, 1)
+
+	// only the Sierra class is in the test data
+	stateDiff := &core.StateDiff{DeclaredV1Classes: stateUpdate.StateDiff.DeclaredV1Classes}
+	classHash := stateUpdate.StateDiff.DeclaredV1Classes[0].ClassHash
+
+	t.Run("declared Sierra classes are fetched with their compiled class", func(t *testing.T) {
+		gomock.InOrder(
+			mockSNData.EXPECT().CompiledClass(gomock.Any(), classHash).Return(nil, errors.New("try again")),
+			mockSNData.EXPECT().CompiledClass(gomock.Any(), classHash).Return(compiled, nil),
+		)
+
+		classes, err := synchronizer.fetchReferencedClasses(context.Background(), stateDiff)
+		require.NoError(t, err)
+		require.Contains(t, classes, *classHash)
+
+		class, ok := classes[*classHash].(*core.Cairo1Class)
+		require.True(t, ok)
+		assert.Equal(t, classHash, class.Hash())
+		assert.NoError(t, core.VerifyClassHashes(map[felt.Felt]core.Class{*classHash: class}))
+		assert.Equal(t, compiled, class.Compiled)
+	})
+
+	t.Run("compiled class fetch gives up after too many failures", func(t *testing.T) {
+		mockSNData.EXPECT().CompiledClass(gomock.Any(), classHash).Return(nil, errors.New("gone")).Times(maxClassFetchAttempts)
+
+		_, err := synchronizer.fetchReferencedClasses(context.Background(), stateDiff)
+		require.Error(t, err)
+	})
+
+	t.Run("declared Sierra classes are kept without a compiled class the source can not provide", func(t *testing.T) {
+		mockSNData.EXPECT().CompiledClass(gomock.Any(), classHash).Return(nil, starknetdata.ErrUnsupported)
+
+		classes, err := synchronizer.fetchReferencedClasses(context.Background(), stateDiff)
+		require.NoError(t, err)
+		class, ok := classes[*classHash].(*core.Cairo1Class)
+		require.True(t, ok)
+		assert.Nil(t, class.Compiled)
+	})
+}
+
+func TestClassFetchFailures(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+	log := utils.NewNopZapLogger()
+
+	stateUpdate, err := gw.StateUpdate(context.Background(), 0)
+	require.NoError(t, err)
+
+	t.Run("class fetch is retried", func(t *testing.T) {
+		mockSNData := mocks.NewMockStarknetData(mockCtrl)
+		synchronizer := New(blockchain.New(pebble.NewMemTest(), utils.MAINNET, log), mockSNData, log)
+
+		gomock.InOrder(
+			mockSNData.EXPECT().Class(gomock.Any(), gomock.Any()).Return(nil, errors.New("try again")).Times(maxClassFetchAttempts-1),
+			mockSNData.EXPECT().Class(gomock.Any(), gomock.Any()).DoAndReturn(gw.Class),
+		)
+
+		classes, err := synchronizer.fetchReferencedClasses(context.Background(), stateUpdate.StateDiff)
+		require.NoError(t, err)
+		assert.Len(t, classes, 1)
+	})
+
+	t.Run("class fetch gives up after too many failures", func(t *testing.T) {
+		mockSNData := mocks.NewMockStarknetData(mockCtrl)
+		synchronizer := New(blockchain.New(pebble.NewMemTest(), utils.MAINNET, log), mockSNData, log)
+
+		mockSNData.EXPECT().Class(gomock.Any(), gomock.Any()).Return(nil, errors.New("gone")).Times(maxClassFetchAttempts)
+
+		start := time.Now()
+		_, err := synchronizer.fetchReferencedClasses(context.Background(), stateUpdate.StateDiff)
+		require.Error(t, err)
+		// the attempts are spread out by the back off
+		assert.GreaterOrEqual(t, time.Since(start), 3*minRetryDelay)
+	})
+
+	t.Run("blocks are not stored without their classes", func(t *testing.T) {
+		mockSNData := mocks.NewMockStarknetData(mockCtrl)
+		bc := blockchain.New(pebble.NewMemTest(), utils.MAINNET, log)
+		synchronizer := New(bc, mockSNData, log)
+
+		block, err := gw.BlockByNumber(context.Background(), 0)
+		require.NoError(t, err)
+		mockSNData.EXPECT().BlockByNumber(gomock.Any(), uint64(0)).Return(block, nil).AnyTimes()
+		mockSNData.EXPECT().StateUpdate(gomock.Any(), uint64(0)).Return(stateUpdate, nil).AnyTimes()
+		mockSNData.EXPECT().BlockByNumber(gomock.Any(), gomock.Any()).Return(nil, errors.New("not yet")).AnyTimes()
+		mockSNData.EXPECT().Class(gomock.Any(), gomock.Any()).Return(nil, errors.New("gone")).AnyTimes()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		require.NoError(t, synchronizer.Run(ctx))
+		cancel()
+
+		_, err = bc.Height()
+		require.ErrorIs(t, err, db.ErrKeyNotFound)
+	})
+}
+
+func TestRepairMissingClasses(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+	log := utils.NewNopZapLogger()
+
+	testDB := pebble.NewMemTest()
+	bc := blockchain.New(testDB, utils.MAINNET, log)
+	synchronizer := New(bc, gw, log)
+
+	b0, err := gw.BlockByNumber(context.Background(), 0)
+	require.NoError(t, err)
+	s0, err := gw.StateUpdate(context.Background(), 0)
+	require.NoError(t, err)
+	classes, err := synchronizer.fetchReferencedClasses(context.Background(), s0.StateDiff)
+	require.NoError(t, err)
+	require.NoError(t, bc.Store(b0, s0, classes))
+
+	// simulate a database synced while classes were being dropped
+	require.NoError(t, testDB.Update(func(txn db.Transaction) error {
+		for classHash := range classes {
+			if err := txn.Delete(db.Class.Key(classHash.Marshal())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+
+	missing, err := bc.MissingClasses()
+	require.NoError(t, err)
+	require.Len(t, missing, len(classes))
+
+	require.NoError(t, synchronizer.RepairMissingClasses(context.Background()))
+
+	missing, err = bc.MissingClasses()
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+}
+
+func TestPollPending(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+	log := utils.NewNopZapLogger()
+
+	mockSNData := mocks.NewMockStarknetData(mockCtrl)
+	// the pending block in the test data is built on top of mainnet block 1
+	mockSNData.EXPECT().BlockByNumber(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, height uint64) (*core.Block, error) {
+		if height > 1 {
+			return nil, errors.New("not yet")
+		}
+		return gw.BlockByNumber(ctx, height)
+	}).AnyTimes()
+	mockSNData.EXPECT().StateUpdate(gomock.Any(), gomock.Any()).DoAndReturn(gw.StateUpdate).AnyTimes()
+	mockSNData.EXPECT().Class(gomock.Any(), gomock.Any()).DoAndReturn(gw.Class).AnyTimes()
+	mockSNData.EXPECT().BlockPending(gomock.Any()).DoAndReturn(gw.BlockPending).AnyTimes()
+	mockSNData.EXPECT().StateUpdatePending(gomock.Any()).DoAndReturn(gw.StateUpdatePending).AnyTimes()
+
+	bc := blockchain.New(pebble.NewMemTest(), utils.MAINNET, log)
+	synchronizer := New(bc, mockSNData, log).WithPendingPolling(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	require.NoError(t, synchronizer.Run(ctx))
+	cancel()
+
+	head, err := bc.HeadsHeader()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), head.Number)
+
+	pending, err := bc.Pending()
+	require.NoError(t, err)
+	assert.Equal(t, head.Hash, pending.Block.ParentHash)
+
+	expectedUpdate, err := gw.StateUpdatePending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, expectedUpdate, pending.StateUpdate)
+	// the classes that the pending block references are stored already
+	assert.Empty(t, pending.NewClasses)
+}
+
+func TestFetchPending(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+	log := utils.NewNopZapLogger()
+
+	// the pending block in the test data is built on top of mainnet block 1
+	testDB := pebble.NewMemTest()
+	bc := blockchain.New(testDB, utils.MAINNET, log)
+	for number := uint64(0); number <= 1; number++ {
+		block, err := gw.BlockByNumber(context.Background(), number)
+		require.NoError(t, err)
+		su, err := gw.StateUpdate(context.Background(), number)
+		require.NoError(t, err)
+		classes, err := New(bc, gw, log).fetchReferencedClasses(context.Background(), su.StateDiff)
+		require.NoError(t, err)
+		require.NoError(t, bc.Store(block, su, classes))
+	}
+
+	t.Run("stored classes are not fetched", func(t *testing.T) {
+		mockSNData := mocks.NewMockStarknetData(mockCtrl)
+		mockSNData.EXPECT().BlockPending(gomock.Any()).DoAndReturn(gw.BlockPending)
+		mockSNData.EXPECT().StateUpdatePending(gomock.Any()).DoAndReturn(gw.StateUpdatePending)
+
+		require.NoError(t, New(bc, mockSNData, log).fetchPending(context.Background()))
+		pending, err := bc.Pending()
+		require.NoError(t, err)
+		assert.Empty(t, pending.NewClasses)
+	})
+
+	t.Run("classes are fetched once for the same parent", func(t *testing.T) {
+		su, err := gw.StateUpdatePending(context.Background())
+		require.NoError(t, err)
+		classHashes := make(map[felt.Felt]struct{})
+		for _, classHash := range su.StateDiff.ClassHashes() {
+			classHashes[*classHash] = struct{}{}
+		}
+		require.NotEmpty(t, classHashes)
+		// the pending block references the classes of the blocks before it
+		require.NoError(t, testDB.Update(func(txn db.Transaction) error {
+			for classHash := range classHashes {
+				if err := txn.Delete(db.Class.Key(classHash.Marshal())); err != nil {
+					return err
+				}
+			}
+			return nil
+		}))
+
+		mockSNData := mocks.NewMockStarknetData(mockCtrl)
+		mockSNData.EXPECT().BlockPending(gomock.Any()).DoAndReturn(gw.BlockPending).Times(3)
+		mockSNData.EXPECT().StateUpdatePending(gomock.Any()).DoAndReturn(gw.StateUpdatePending).Times(3)
+		mockSNData.EXPECT().Class(gomock.Any(), gomock.Any()).DoAndReturn(gw.Class).Times(len(classHashes))
+
+		synchronizer := New(bc, mockSNData, log)
+		for i := 0; i < 3; i++ {
+			require.NoError(t, synchronizer.fetchPending(context.Background()))
+		}
+
+		pending, err := bc.Pending()
+		require.NoError(t, err)
+		assert.Len(t, pending.NewClasses, len(classHashes))
+	})
+
+	t.Run("state update of another block is rejected", func(t *testing.T) {
+		su, err := gw.StateUpdatePending(context.Background())
+		require.NoError(t, err)
+		su.OldRoot = new(felt.Felt).SetUint64(1)
+
+		mockSNData := mocks.NewMockStarknetData(mockCtrl)
+		mockSNData.EXPECT().BlockPending(gomock.Any()).DoAndReturn(gw.BlockPending)
+		mockSNData.EXPECT().StateUpdatePending(gomock.Any()).Return(su, nil)
+
+		assert.Error(t, New(bc, mockSNData, log).fetchPending(context.Background()))
+	})
+}
+
+func TestPollLatest(t *testing.T) {
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+	log := utils.NewNopZapLogger()
+
+	bc := blockchain.New(pebble.NewMemTest(), utils.MAINNET, log)
+	synchronizer := New(bc, gw, log).WithLatestPolling(50 * time.Millisecond)
+	assert.Nil(t, synchronizer.HighestBlockHeader())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	require.NoError(t, synchronizer.Run(ctx))
+	cancel()
+
+	latest, err := gw.BlockLatest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, latest.Header, synchronizer.HighestBlockHeader())
+}
+
+func TestSignatureVerification(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+	log := utils.NewNopZapLogger()
+	// the fixtures are signed by the key that the test feeder serves, rather than by the mainnet sequencer
+	publicKey, err := client.PublicKey(context.Background())
+	require.NoError(t, err)
+
+	t.Run("blocks with valid signatures are stored", func(t *testing.T) {
+		bc := blockchain.New(pebble.NewMemTest(), utils.MAINNET, log)
+		synchronizer := New(bc, gw, log).WithSignatureVerification(publicKey)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		require.NoError(t, synchronizer.Run(ctx))
+		cancel()
+
+		// there is no signature of block 2 to fetch
+		height, err := bc.Height()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), height)
+	})
+
+	t.Run("blocks with invalid signatures are not stored", func(t *testing.T) {
+		mockSNData := mocks.NewMockStarknetData(mockCtrl)
+		mockSNData.EXPECT().BlockByNumber(gomock.Any(), gomock.Any()).DoAndReturn(gw.BlockByNumber).AnyTimes()
+		mockSNData.EXPECT().StateUpdate(gomock.Any(), gomock.Any()).DoAndReturn(gw.StateUpdate).AnyTimes()
+		mockSNData.EXPECT().Class(gomock.Any(), gomock.Any()).DoAndReturn(gw.Class).AnyTimes()
+		mockSNData.EXPECT().BlockSignature(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, blockNumber uint64) (*core.BlockSignature, error) {
+				signature, err := gw.BlockSignature(ctx, blockNumber)
+				if err == nil && blockNumber == 1 {
+					signature.StateDiffCommitment = new(felt.Felt).SetUint64(1)
+				}
+				return signature, err
+			}).AnyTimes()
+
+		bc := blockchain.New(pebble.NewMemTest(), utils.MAINNET, log)
+		synchronizer := New(bc, mockSNData, log).WithSignatureVerification(publicKey)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		require.NoError(t, synchronizer.Run(ctx))
+		cancel()
+
+		height, err := bc.Height()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(0), height)
+		assert.NotZero(t, synchronizer.Stats().Rollbacks)
+	})
+
+	t.Run("blocks whose signature can not be fetched roll the sync back", func(t *testing.T) {
+		mockSNData := mocks.NewMockStarknetData(mockCtrl)
+		mockSNData.EXPECT().BlockByNumber(gomock.Any(), gomock.Any()).DoAndReturn(gw.BlockByNumber).AnyTimes()
+		mockSNData.EXPECT().StateUpdate(gomock.Any(), gomock.Any()).DoAndReturn(gw.StateUpdate).AnyTimes()
+		mockSNData.EXPECT().BlockSignature(gomock.Any(), gomock.Any()).Return(nil, errors.New("outage")).AnyTimes()
+
+		bc := blockchain.New(pebble.NewMemTest(), utils.MAINNET, log)
+		synchronizer := New(bc, mockSNData, log).WithSignatureVerification(publicKey)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		require.NoError(t, synchronizer.Run(ctx))
+		cancel()
+
+		_, err := bc.Height()
+		require.ErrorIs(t, err, db.ErrKeyNotFound)
+		assert.NotZero(t, synchronizer.Stats().Rollbacks)
+	})
+}
+
+func TestRefreshStatuses(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	client, closeFn := feeder.NewTestClient(utils.MAINNET)
+	t.Cleanup(closeFn)
+	gw := adaptfeeder.New(client)
+	log := utils.NewNopZapLogger()
+
+	bc := blockchain.New(pebble.NewMemTest(), utils.MAINNET, log)
+	mockSNData := mocks.NewMockStarknetData(mockCtrl)
+	mockSNData.EXPECT().Class(gomock.Any(), gomock.Any()).DoAndReturn(gw.Class).AnyTimes()
+	synchronizer := New(bc, mockSNData, log)
+
+	const numBlocks = 3
+	blocks := make([]*core.Block, numBlocks)
+	for i := uint64(0); i < numBlocks; i++ {
+		block, err := gw.BlockByNumber(context.Background(), i)
+		require.NoError(t, err)
+		su, err := gw.StateUpdate(context.Background(), i)
+		require.NoError(t, err)
+		classes, err := synchronizer.fetchReferencedClasses(context.Background(), su.StateDiff)
+		require.NoError(t, err)
+
+		// the blocks were synced before they were accepted on L1
+		storedBlock := *block
+		storedHeader := *block.Header
+		storedHeader.Status = core.BlockAcceptedOnL2
+		storedBlock.Header = &storedHeader
+		require.NoError(t, bc.Store(&storedBlock, su, classes))
+		blocks[i] = block
+	}
+
+	checkStatuses := func(t *testing.T, expected ...core.BlockStatus) {
+		t.Helper()
+		for i, status := range expected {
+			header, err := bc.BlockHeaderByNumber(uint64(i))
+			require.NoError(t, err)
+			assert.Equal(t, status, header.Status)
+		}
+	}
+
+	head := *blocks[2]
+	headHeader := *blocks[2].Header
+	headHeader.Status = core.BlockAcceptedOnL2
+	head.Header = &headHeader
+
+	t.Run("blocks beyond the lookback are not refreshed", func(t *testing.T) {
+		synchronizer.statusLookback = 1
+		t.Cleanup(func() {
+			synchronizer.statusLookback = defaultStatusLookback
+		})
+		mockSNData.EXPECT().BlockByNumber(gomock.Any(), uint64(2)).Return(&head, nil)
+
+		require.NoError(t, synchronizer.refreshStatuses(context.Background()))
+		checkStatuses(t, core.BlockAcceptedOnL2, core.BlockAcceptedOnL2, core.BlockAcceptedOnL2)
+	})
+
+	t.Run("only the head is not accepted on L1 yet", func(t *testing.T) {
+
+		mockSNData.EXPECT().BlockByNumber(gomock.Any(), uint64(0)).Return(blocks[0], nil)
+		mockSNData.EXPECT().BlockByNumber(gomock.Any(), uint64(1)).Return(blocks[1], nil)
+		mockSNData.EXPECT().BlockByNumber(gomock.Any(), uint64(2)).Return(&head, nil)
+
+		require.NoError(t, synchronizer.refreshStatuses(context.Background()))
+		checkStatuses(t, core.BlockAcceptedOnL1, core.BlockAcceptedOnL1, core.BlockAcceptedOnL2)
+	})
+
+	t.Run("blocks accepted on L1 are not fetched again", func(t *testing.T) {
+		mockSNData.EXPECT().BlockByNumber(gomock.Any(), uint64(2)).Return(blocks[2], nil)
+
+		require.NoError(t, synchronizer.refreshStatuses(context.Background()))
+		checkStatuses(t, core.BlockAcceptedOnL1, core.BlockAcceptedOnL1, core.BlockAcceptedOnL1)
+	})
+
+	t.Run("nothing to refresh", func(t *testing.T) {
+		require.NoError(t, synchronizer.refreshStatuses(context.Background()))
 	})
 }
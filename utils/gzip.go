@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+)
+
+// Gzip64Encode compresses data with gzip and encodes the result in base64.
+func Gzip64Encode(data []byte) (string, error) {
+	var compressed bytes.Buffer
+	gzipWriter := gzip.NewWriter(&compressed)
+	if _, err := gzipWriter.Write(data); err != nil {
+		return "", err
+	}
+
+	if err := gzipWriter.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(compressed.Bytes()), nil
+}
+
+// Gzip64Decode reverses [Gzip64Encode].
+func Gzip64Decode(data string) ([]byte, error) {
+	compressed, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, err
+	}
+
+	gzipReader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+
+	decompressed, err := io.ReadAll(gzipReader)
+	if err != nil {
+		return nil, err
+	}
+	return decompressed, gzipReader.Close()
+}
@@ -0,0 +1,25 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/NethermindEth/juno/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGzip64(t *testing.T) {
+	data := []byte(`{"builtins": ["pedersen", "range_check"], "data": ["0x1", "0x2"]}`)
+
+	encoded, err := utils.Gzip64Encode(data)
+	require.NoError(t, err)
+
+	decoded, err := utils.Gzip64Decode(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, data, decoded)
+
+	t.Run("invalid base64", func(t *testing.T) {
+		_, err := utils.Gzip64Decode("not base64!")
+		assert.Error(t, err)
+	})
+}
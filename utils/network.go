@@ -3,14 +3,39 @@ package utils
 import (
 	"encoding"
 	"errors"
+	"fmt"
+	"net/url"
 
 	"github.com/NethermindEth/juno/core/felt"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/spf13/pflag"
 )
 
-var ErrUnknownNetwork = errors.New("unknown network (known: mainnet, goerli, goerli2, integration)")
+var ErrUnknownNetwork = errors.New("unknown network (known: mainnet, goerli, goerli2, integration, custom)")
 
-type Network int
+// Network is one of the public Starknet networks or a [CustomNetwork].
+type Network struct {
+	public publicNetwork
+	custom *CustomNetwork
+}
+
+// CustomNetwork describes a Starknet-compatible network that is not one of the public ones, such as a
+// private chain or a devnet.
+type CustomNetwork struct {
+	Name      string
+	FeederURL string
+	ChainID   string
+	// First block that uses the post-0.7.0 block hash algorithm
+	First07Block uint64
+	// Range of blocks whose hashes are not verifiable, empty if all of them are
+	UnverifiableRange []uint64
+	// The sequencer address to hash blocks that do not have one with, the default one if nil
+	FallBackSequencerAddress *felt.Felt
+	// The public key that the sequencer signs blocks with, nil if it is unknown
+	PublicKey *felt.Felt
+}
+
+type publicNetwork int
 
 // The following are necessary for Cobra and Viper, respectively, to unmarshal log level
 // CLI/config parameters properly.
@@ -20,21 +45,68 @@ var (
 )
 
 const (
-	MAINNET Network = iota
-	GOERLI
-	GOERLI2
-	INTEGRATION
+	mainnet publicNetwork = iota
+	goerli
+	goerli2
+	integration
+)
+
+var (
+	MAINNET     = Network{public: mainnet}
+	GOERLI      = Network{public: goerli}
+	GOERLI2     = Network{public: goerli2}
+	INTEGRATION = Network{public: integration}
 )
 
+// NewCustomNetwork returns the network that the given description describes, or an error if the
+// description is incomplete.
+func NewCustomNetwork(custom *CustomNetwork) (Network, error) {
+	if custom.Name == "" {
+		return Network{}, errors.New("custom network has no name")
+	}
+	if err := new(Network).Set(custom.Name); err == nil {
+		return Network{}, fmt.Errorf("custom network name %q is taken by a public network", custom.Name)
+	}
+	if _, err := url.ParseRequestURI(custom.FeederURL); err != nil {
+		return Network{}, fmt.Errorf("custom network feeder URL: %w", err)
+	}
+	if custom.ChainID == "" {
+		return Network{}, errors.New("custom network has no chain ID")
+	}
+	if len(custom.ChainID) > felt.Bytes {
+		return Network{}, fmt.Errorf("custom network chain ID %q is longer than %d bytes", custom.ChainID, felt.Bytes)
+	}
+	if n := len(custom.UnverifiableRange); n != 0 && (n != 2 || custom.UnverifiableRange[0] > custom.UnverifiableRange[1]) {
+		return Network{}, fmt.Errorf("custom network unverifiable range %v is not a [first, last] range", custom.UnverifiableRange)
+	}
+
+	description := *custom
+	// block hash verification tells a network without an unverifiable range by the range being nil
+	description.UnverifiableRange = nil
+	if len(custom.UnverifiableRange) != 0 {
+		description.UnverifiableRange = append([]uint64{}, custom.UnverifiableRange...)
+	}
+	return Network{custom: &description}, nil
+}
+
+// Custom returns the description of a custom network, or nil if the network is a public one.
+func (n Network) Custom() *CustomNetwork {
+	return n.custom
+}
+
 func (n Network) String() string {
-	switch n {
-	case MAINNET:
+	if n.custom != nil {
+		return n.custom.Name
+	}
+
+	switch n.public {
+	case mainnet:
 		return "mainnet"
-	case GOERLI:
+	case goerli:
 		return "goerli"
-	case GOERLI2:
+	case goerli2:
 		return "goerli2"
-	case INTEGRATION:
+	case integration:
 		return "integration"
 	default:
 		// Should not happen.
@@ -42,6 +114,8 @@ func (n Network) String() string {
 	}
 }
 
+// Set sets the network to the public network with the given name. A custom network is only marked as
+// such, under the name "custom", as its description needs to be set with [NewCustomNetwork].
 func (n *Network) Set(s string) error {
 	switch s {
 	case "MAINNET", "mainnet":
@@ -52,6 +126,8 @@ func (n *Network) Set(s string) error {
 		*n = GOERLI2
 	case "INTEGRATION", "integration":
 		*n = INTEGRATION
+	case "CUSTOM", "custom":
+		*n = Network{custom: &CustomNetwork{Name: "custom"}}
 	default:
 		return ErrUnknownNetwork
 	}
@@ -67,6 +143,10 @@ func (n *Network) UnmarshalText(text []byte) error {
 }
 
 func (n Network) URL() string {
+	if n.custom != nil {
+		return n.custom.FeederURL
+	}
+
 	switch n {
 	case GOERLI:
 		return "https://alpha4.starknet.io/feeder_gateway/"
@@ -83,6 +163,10 @@ func (n Network) URL() string {
 }
 
 func (n Network) ChainID() *felt.Felt {
+	if n.custom != nil {
+		return new(felt.Felt).SetBytes([]byte(n.custom.ChainID))
+	}
+
 	switch n {
 	case GOERLI:
 		return new(felt.Felt).SetBytes([]byte("SN_GOERLI"))
@@ -97,3 +181,48 @@ func (n Network) ChainID() *felt.Felt {
 		panic(ErrUnknownNetwork)
 	}
 }
+
+// PublicKey returns the public key that the sequencer of the network signs blocks with, or nil if it is
+// not known. It is pinned rather than fetched, so that a compromised upstream cannot replace it.
+func (n Network) PublicKey() *felt.Felt {
+	if n.custom != nil {
+		return n.custom.PublicKey
+	}
+
+	switch n {
+	case MAINNET:
+		publicKey, err := new(felt.Felt).SetString("0x48253ff2c3bed7af18bde0b611b083b39445959102d4947c51c4db6aa4f4e58")
+		if err != nil {
+			panic(fmt.Sprintf("Error while creating the mainnet public key %s", err))
+		}
+		return publicKey
+	case GOERLI, GOERLI2, INTEGRATION:
+		return nil
+	default:
+		// Should not happen.
+		panic(ErrUnknownNetwork)
+	}
+}
+
+// CoreContractAddress returns the address of the Starknet core contract on L1, which the
+// network posts its state updates to. Custom networks have no known core contract, so the zero
+// address is returned for them.
+func (n Network) CoreContractAddress() common.Address {
+	if n.custom != nil {
+		return common.Address{}
+	}
+
+	switch n {
+	case GOERLI:
+		return common.HexToAddress("0xde29d060D45901Fb19ED6C6e959EB22d8626708e")
+	case MAINNET:
+		return common.HexToAddress("0xc662c410C0ECf747543f5bA90660f6ABeBD9C8c4")
+	case GOERLI2:
+		return common.HexToAddress("0xa4eD3aD27c294565cB0DCc993BDdCC75432D498c")
+	case INTEGRATION:
+		return common.HexToAddress("0xd5c325D183C592C94998000C5e0EED9e6655c020")
+	default:
+		// Should not happen.
+		panic(ErrUnknownNetwork)
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/NethermindEth/juno/core/felt"
 	"github.com/NethermindEth/juno/utils"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -55,6 +56,34 @@ func TestNetwork(t *testing.T) {
 			}
 		}
 	})
+	t.Run("core contract address", func(t *testing.T) {
+		for n := range networkStrings {
+			switch n {
+			case utils.GOERLI:
+				assert.Equal(t, common.HexToAddress("0xde29d060D45901Fb19ED6C6e959EB22d8626708e"), n.CoreContractAddress())
+			case utils.MAINNET:
+				assert.Equal(t, common.HexToAddress("0xc662c410C0ECf747543f5bA90660f6ABeBD9C8c4"), n.CoreContractAddress())
+			case utils.GOERLI2:
+				assert.Equal(t, common.HexToAddress("0xa4eD3aD27c294565cB0DCc993BDdCC75432D498c"), n.CoreContractAddress())
+			case utils.INTEGRATION:
+				assert.Equal(t, common.HexToAddress("0xd5c325D183C592C94998000C5e0EED9e6655c020"), n.CoreContractAddress())
+			default:
+				assert.Fail(t, "unexpected network")
+			}
+		}
+	})
+	t.Run("public key", func(t *testing.T) {
+		for n := range networkStrings {
+			switch n {
+			case utils.MAINNET:
+				assert.Equal(t, utils.HexToFelt(t, "0x48253ff2c3bed7af18bde0b611b083b39445959102d4947c51c4db6aa4f4e58"), n.PublicKey())
+			case utils.GOERLI, utils.GOERLI2, utils.INTEGRATION:
+				assert.Nil(t, n.PublicKey())
+			default:
+				assert.Fail(t, "unexpected network")
+			}
+		}
+	})
 }
 
 //nolint:dupl // see comment in utils/log_test.go
@@ -100,6 +129,67 @@ func TestNetworkUnmarshalText(t *testing.T) {
 	})
 }
 
+func TestCustomNetwork(t *testing.T) {
+	description := &utils.CustomNetwork{
+		Name:                     "devnet",
+		FeederURL:                "http://localhost:5050/feeder_gateway/",
+		ChainID:                  "SN_DEVNET",
+		First07Block:             10,
+		UnverifiableRange:        []uint64{0, 9},
+		FallBackSequencerAddress: utils.HexToFelt(t, "0x55"),
+		PublicKey:                utils.HexToFelt(t, "0x66"),
+	}
+
+	n, err := utils.NewCustomNetwork(description)
+	require.NoError(t, err)
+	assert.Equal(t, "devnet", n.String())
+	assert.Equal(t, "http://localhost:5050/feeder_gateway/", n.URL())
+	assert.Equal(t, new(felt.Felt).SetBytes([]byte("SN_DEVNET")), n.ChainID())
+	assert.Equal(t, common.Address{}, n.CoreContractAddress())
+	assert.Equal(t, utils.HexToFelt(t, "0x66"), n.PublicKey())
+	assert.Equal(t, description, n.Custom())
+	assert.NotSame(t, description, n.Custom())
+
+	for _, public := range networkStrings {
+		n := new(utils.Network)
+		require.NoError(t, n.Set(public))
+		assert.Nil(t, n.Custom())
+	}
+
+	t.Run("custom is set as a network to describe", func(t *testing.T) {
+		n := new(utils.Network)
+		require.NoError(t, n.Set("custom"))
+		require.NotNil(t, n.Custom())
+		assert.Equal(t, "custom", n.String())
+	})
+
+	t.Run("no unverifiable range", func(t *testing.T) {
+		withoutRange := *description
+		withoutRange.UnverifiableRange = []uint64{}
+		n, err := utils.NewCustomNetwork(&withoutRange)
+		require.NoError(t, err)
+		assert.Nil(t, n.Custom().UnverifiableRange)
+	})
+
+	invalid := map[string]func(c *utils.CustomNetwork){
+		"no name":                       func(c *utils.CustomNetwork) { c.Name = "" },
+		"name of a public network":      func(c *utils.CustomNetwork) { c.Name = "mainnet" },
+		"invalid feeder URL":            func(c *utils.CustomNetwork) { c.FeederURL = "not a url" },
+		"no chain ID":                   func(c *utils.CustomNetwork) { c.ChainID = "" },
+		"chain ID too long":             func(c *utils.CustomNetwork) { c.ChainID = strings.Repeat("A", felt.Bytes+1) },
+		"incomplete unverifiable range": func(c *utils.CustomNetwork) { c.UnverifiableRange = []uint64{1} },
+		"inverted unverifiable range":   func(c *utils.CustomNetwork) { c.UnverifiableRange = []uint64{9, 0} },
+	}
+	for name, invalidate := range invalid {
+		t.Run(name, func(t *testing.T) {
+			c := *description
+			invalidate(&c)
+			_, err := utils.NewCustomNetwork(&c)
+			assert.Error(t, err)
+		})
+	}
+}
+
 func TestNetworkType(t *testing.T) {
 	assert.Equal(t, "Network", new(utils.Network).Type())
 }